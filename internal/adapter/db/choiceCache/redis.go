@@ -8,6 +8,32 @@ import (
 	"github.com/go-redis/redis"
 )
 
+// The counts and the versions of the results are kept by the id of the vote,
+// so they don't follow a title when the vote is renamed and a vote that
+// reuses the title of a deleted or renamed vote doesn't get them.
+const (
+	countsPrefix  string = "counts:"
+	versionPrefix string = "version:"
+)
+
+// setVersion keeps the greater version, so an update that was applied
+// earlier can't overwrite the version of a later one.
+var setVersion = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '-1')
+if tonumber(ARGV[1]) > current then
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return redis.call('PEXPIRE', KEYS[1], ARGV[2])`)
+
+// renameField moves the count of the choice to its new title.
+var renameField = redis.NewScript(`
+local count = redis.call('HGET', KEYS[1], ARGV[1])
+if count then
+	redis.call('HSET', KEYS[1], ARGV[2], count)
+	redis.call('HDEL', KEYS[1], ARGV[1])
+end
+return 0`)
+
 type choiceCache struct {
 	logger *logging.Logger
 	client *redis.Client
@@ -17,14 +43,14 @@ func NewChoiceCache(client *redis.Client, logger *logging.Logger) *choiceCache {
 	return &choiceCache{logger: logger, client: client}
 }
 
-func (c *choiceCache) Set(voteTitle string, choiceTitle string, count int, expireAt time.Duration) error {
-	c.logger.Infof("try to save %v : %v", voteTitle, choiceTitle)
-	err := c.client.HSet(voteTitle, choiceTitle, count).Err()
+func (c *choiceCache) Set(voteId int, choiceTitle string, count int, expireAt time.Duration) error {
+	c.logger.Infof("try to save %v : %v", voteId, choiceTitle)
+	err := c.client.HSet(countsKey(voteId), choiceTitle, count).Err()
 	if err != nil {
 		c.logger.Error(err)
 		return err
 	}
-	err = c.client.Expire(voteTitle, expireAt).Err()
+	err = c.client.Expire(countsKey(voteId), expireAt).Err()
 	if err != nil {
 		c.logger.Error(err)
 		return err
@@ -32,8 +58,8 @@ func (c *choiceCache) Set(voteTitle string, choiceTitle string, count int, expir
 	return nil
 }
 
-func (c *choiceCache) Get(voteTitle string, choiceTitle string) (int, error) {
-	value, err := c.client.HGet(voteTitle, choiceTitle).Result()
+func (c *choiceCache) Get(voteId int, choiceTitle string) (int, error) {
+	value, err := c.client.HGet(countsKey(voteId), choiceTitle).Result()
 	if err != nil {
 		c.logger.Info(err)
 		return -1, err
@@ -45,3 +71,48 @@ func (c *choiceCache) Get(voteTitle string, choiceTitle string) (int, error) {
 	}
 	return count, nil
 }
+
+func (c *choiceCache) SetVersion(voteId int, version int64, expireAt time.Duration) error {
+	c.logger.Debugf("try to save version %v of vote id = %v", version, voteId)
+	err := setVersion.Run(c.client, []string{versionKey(voteId)}, version, expireAt.Milliseconds()).Err()
+	if err != nil {
+		c.logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (c *choiceCache) GetVersion(voteId int) (int64, error) {
+	version, err := c.client.Get(versionKey(voteId)).Int64()
+	if err != nil {
+		c.logger.Info(err)
+		return -1, err
+	}
+	return version, nil
+}
+
+func (c *choiceCache) RenameChoice(voteId int, choiceTitle string, newTitle string) error {
+	c.logger.Debugf("try to rename %v : %v to %v", voteId, choiceTitle, newTitle)
+	if err := renameField.Run(c.client, []string{countsKey(voteId)}, choiceTitle, newTitle).Err(); err != nil {
+		c.logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (c *choiceCache) Delete(voteId int, choiceTitles ...string) error {
+	c.logger.Debugf("try to delete %v : %v", voteId, choiceTitles)
+	if err := c.client.HDel(countsKey(voteId), choiceTitles...).Err(); err != nil {
+		c.logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func countsKey(voteId int) string {
+	return countsPrefix + strconv.Itoa(voteId)
+}
+
+func versionKey(voteId int) string {
+	return versionPrefix + strconv.Itoa(voteId)
+}
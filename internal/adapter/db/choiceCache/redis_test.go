@@ -22,7 +22,7 @@ func TestSet(t *testing.T) {
 	repo := NewChoiceCache(redisClient, logging.GetLogger("debug"))
 	type args struct {
 		choiceTitle string
-		voteTitle   string
+		voteId      int
 		count       int
 		expire      time.Duration
 	}
@@ -35,13 +35,13 @@ func TestSet(t *testing.T) {
 	}{
 		{
 			title:   "Success Set()",
-			input:   args{choiceTitle: "choice", voteTitle: "vote", count: 1, expire: 1 * time.Second},
+			input:   args{choiceTitle: "choice", voteId: 1, count: 1, expire: 1 * time.Second},
 			mock:    func() {},
 			isError: false,
 		},
 		{
 			title: "reddis internal error and Set() return error",
-			input: args{choiceTitle: "choice", voteTitle: "vote", count: 1, expire: 1 * time.Second},
+			input: args{choiceTitle: "choice", voteId: 1, count: 1, expire: 1 * time.Second},
 			mock: func() {
 				redisServer.SetError("interanl redis error")
 			},
@@ -51,7 +51,7 @@ func TestSet(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.title, func(t *testing.T) {
 			test.mock()
-			err := repo.Set(test.input.voteTitle, test.input.choiceTitle, test.input.count, test.input.expire)
+			err := repo.Set(test.input.voteId, test.input.choiceTitle, test.input.count, test.input.expire)
 			if test.isError {
 				assert.Error(t, err)
 			} else {
@@ -66,10 +66,10 @@ func TestGet(t *testing.T) {
 	defer teardown()
 
 	repo := NewChoiceCache(redisClient, logging.GetLogger("debug"))
-	type mockCall func(voteTitle string, choiceTitle string, count int, expire time.Duration) error
+	type mockCall func(voteId int, choiceTitle string, count int, expire time.Duration) error
 	type args struct {
 		choiceTitle string
-		voteTitle   string
+		voteId      int
 		count       int
 		expire      time.Duration
 	}
@@ -82,27 +82,27 @@ func TestGet(t *testing.T) {
 	}{
 		{
 			title:   "Get should find title and return count",
-			input:   args{choiceTitle: "choice", voteTitle: "vote", count: 1, expire: 1 * time.Second},
+			input:   args{choiceTitle: "choice", voteId: 1, count: 1, expire: 1 * time.Second},
 			isError: false,
-			mock: func(voteTitle string, choiceTitle string, count int, expire time.Duration) error {
-				return repo.Set(voteTitle, choiceTitle, count, expire)
+			mock: func(voteId int, choiceTitle string, count int, expire time.Duration) error {
+				return repo.Set(voteId, choiceTitle, count, expire)
 			},
 			want: 1,
 		},
 		{
 			title:   "Get doens't find key and should return error",
-			input:   args{choiceTitle: "wrong key", voteTitle: "wrong key", count: 1, expire: 1 * time.Second},
+			input:   args{choiceTitle: "wrong key", voteId: 2, count: 1, expire: 1 * time.Second},
 			isError: true,
-			mock: func(voteTitle string, choiceTitle string, count int, expire time.Duration) error {
-				return repo.Set("some vote", "some choice", count, expire)
+			mock: func(voteId int, choiceTitle string, count int, expire time.Duration) error {
+				return repo.Set(3, "some choice", count, expire)
 			},
 			want: -1,
 		},
 		{
 			title:   "reddis internal error and Get return error ",
-			input:   args{choiceTitle: "choice", voteTitle: "vote", count: 1, expire: 1 * time.Second},
+			input:   args{choiceTitle: "choice", voteId: 1, count: 1, expire: 1 * time.Second},
 			isError: true,
-			mock: func(voteTitle string, choiceTitle string, count int, expire time.Duration) error {
+			mock: func(voteId int, choiceTitle string, count int, expire time.Duration) error {
 				redisServer.SetError("interanl redis error")
 				return errors.New("internal error")
 			},
@@ -111,8 +111,8 @@ func TestGet(t *testing.T) {
 	}
 	for _, test := range testCases {
 		t.Run(test.title, func(t *testing.T) {
-			_ = test.mock(test.input.voteTitle, test.input.choiceTitle, test.input.count, test.input.expire)
-			got, err := repo.Get(test.input.voteTitle, test.input.choiceTitle)
+			_ = test.mock(test.input.voteId, test.input.choiceTitle, test.input.count, test.input.expire)
+			got, err := repo.Get(test.input.voteId, test.input.choiceTitle)
 			if !test.isError {
 				assert.NoError(t, err)
 				assert.Equal(t, test.want, got)
@@ -125,6 +125,71 @@ func TestGet(t *testing.T) {
 
 }
 
+func TestVersion(t *testing.T) {
+	setUp()
+	defer teardown()
+	repo := NewChoiceCache(redisClient, logging.GetLogger("debug"))
+
+	_, err := repo.GetVersion(1)
+	assert.Error(t, err)
+
+	assert.NoError(t, repo.SetVersion(1, 5, time.Minute))
+	got, err := repo.GetVersion(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), got)
+
+	// an older version must not overwrite the newer one
+	assert.NoError(t, repo.SetVersion(1, 4, time.Minute))
+	got, err = repo.GetVersion(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), got)
+
+	assert.NoError(t, repo.Set(1, "choice title", 3, time.Minute))
+	count, err := repo.Get(1, "choice title")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// the version is kept by the id of the vote, another vote with the same
+	// title doesn't get it
+	_, err = repo.GetVersion(2)
+	assert.Error(t, err)
+
+	redisServer.FastForward(2 * time.Minute)
+	_, err = repo.GetVersion(1)
+	assert.Error(t, err)
+
+	redisServer.SetError("interanl redis error")
+	assert.Error(t, repo.SetVersion(1, 6, time.Minute))
+}
+
+func TestRenameChoice(t *testing.T) {
+	setUp()
+	defer teardown()
+	repo := NewChoiceCache(redisClient, logging.GetLogger("debug"))
+
+	assert.NoError(t, repo.Set(1, "Pikachu", 3, time.Minute))
+	assert.NoError(t, repo.Set(1, "Mew", 2, time.Minute))
+
+	assert.NoError(t, repo.RenameChoice(1, "Pikachu", "Raichu"))
+	_, err := repo.Get(1, "Pikachu")
+	assert.Error(t, err)
+	count, err := repo.Get(1, "Raichu")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	assert.NoError(t, repo.Delete(1, "Mew"))
+	_, err = repo.Get(1, "Mew")
+	assert.Error(t, err)
+
+	// nothing is cached under the missing title, so there is nothing to move
+	assert.NoError(t, repo.RenameChoice(1, "Ditto", "Eevee"))
+	_, err = repo.Get(1, "Eevee")
+	assert.Error(t, err)
+
+	redisServer.SetError("interanl redis error")
+	assert.Error(t, repo.RenameChoice(1, "Raichu", "Pikachu"))
+}
+
 func setUp() {
 	redisServer = mockRedis()
 	redisClient = redis.NewClient(&redis.Options{
@@ -0,0 +1,76 @@
+package idempotencyCache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/go-redis/redis"
+)
+
+const keyPrefix string = "idempotency:"
+
+type idempotencyCache struct {
+	logger *logging.Logger
+	client *redis.Client
+}
+
+func NewIdempotencyCache(client *redis.Client, logger *logging.Logger) *idempotencyCache {
+	return &idempotencyCache{logger: logger, client: client}
+}
+
+// Reserve saves the response only if the key isn't used yet and reports
+// whether it was saved.
+func (i *idempotencyCache) Reserve(key string, response entity.IdempotentResponse, expireAt time.Duration) (bool, error) {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		i.logger.Error(err)
+		return false, err
+	}
+	reserved, err := i.client.SetNX(keyPrefix+key, payload, expireAt).Result()
+	if err != nil {
+		i.logger.Error(err)
+		return false, err
+	}
+	return reserved, nil
+}
+
+// Get returns the response saved under the key, found is false if the key
+// doesn't exist or has expired.
+func (i *idempotencyCache) Get(key string) (response entity.IdempotentResponse, found bool, err error) {
+	payload, err := i.client.Get(keyPrefix + key).Bytes()
+	if err == redis.Nil {
+		return response, false, nil
+	}
+	if err != nil {
+		i.logger.Error(err)
+		return response, false, err
+	}
+	if err = json.Unmarshal(payload, &response); err != nil {
+		i.logger.Error(err)
+		return response, false, err
+	}
+	return response, true, nil
+}
+
+func (i *idempotencyCache) Set(key string, response entity.IdempotentResponse, expireAt time.Duration) error {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		i.logger.Error(err)
+		return err
+	}
+	if err = i.client.Set(keyPrefix+key, payload, expireAt).Err(); err != nil {
+		i.logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (i *idempotencyCache) Delete(key string) error {
+	if err := i.client.Del(keyPrefix + key).Err(); err != nil {
+		i.logger.Error(err)
+		return err
+	}
+	return nil
+}
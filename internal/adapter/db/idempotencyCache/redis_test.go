@@ -0,0 +1,88 @@
+package idempotencyCache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	redisServer *miniredis.Miniredis
+	redisClient *redis.Client
+)
+
+func TestReserveAndGet(t *testing.T) {
+	setUp()
+	defer teardown()
+	cache := NewIdempotencyCache(redisClient, logging.GetLogger("debug"))
+	pending := entity.IdempotentResponse{Fingerprint: "fingerprint"}
+
+	reserved, err := cache.Reserve("key", pending, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	reserved, err = cache.Reserve("key", entity.IdempotentResponse{Fingerprint: "other"}, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+
+	got, found, err := cache.Get("key")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, pending, got)
+
+	completed := entity.IdempotentResponse{Fingerprint: "fingerprint", Done: true, Status: 201, ContentType: "application/json", Body: []byte(`{"id":1}`)}
+	assert.NoError(t, cache.Set("key", completed, time.Hour))
+	got, found, err = cache.Get("key")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, completed, got)
+
+	redisServer.FastForward(2 * time.Hour)
+	_, found, err = cache.Get("key")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestDelete(t *testing.T) {
+	setUp()
+	defer teardown()
+	cache := NewIdempotencyCache(redisClient, logging.GetLogger("debug"))
+	_, err := cache.Reserve("key", entity.IdempotentResponse{Fingerprint: "fingerprint"}, time.Minute)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Delete("key"))
+	_, found, err := cache.Get("key")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestRedisError(t *testing.T) {
+	setUp()
+	defer teardown()
+	cache := NewIdempotencyCache(redisClient, logging.GetLogger("debug"))
+	redisServer.SetError("interanl redis error")
+	_, err := cache.Reserve("key", entity.IdempotentResponse{}, time.Minute)
+	assert.Error(t, err)
+	_, _, err = cache.Get("key")
+	assert.Error(t, err)
+	assert.Error(t, cache.Set("key", entity.IdempotentResponse{}, time.Minute))
+	assert.Error(t, cache.Delete("key"))
+}
+
+func setUp() {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	redisServer = s
+	redisClient = redis.NewClient(&redis.Options{
+		Addr: redisServer.Addr(),
+	})
+}
+
+func teardown() {
+	redisServer.Close()
+}
@@ -3,6 +3,8 @@ package psqlStorage
 import (
 	"context"
 	"errors"
+	"sort"
+	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	"github.com/VrMolodyakov/vote-service/internal/errs"
@@ -12,6 +14,73 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// lockSql locks the choices of the vote in the same order in every Tx, so
+// concurrent ballots can't deadlock. The locked rows are counted to check
+// that all of the choices exist.
+const lockSql string = `SELECT choice_title
+			FROM choice
+			WHERE vote_id = $1 AND choice_title = ANY($2)
+			ORDER BY choice_title
+			FOR UPDATE`
+
+// countSql adds the weighted deltas to the counts and the head deltas to the
+// voters of the choices and bumps the version of the vote results in the same
+// statement, $5 is added to the number of ballots.
+const countSql string = `WITH upd AS (
+				UPDATE choice c
+				SET count = c.count + d.delta, voters = c.voters + d.heads
+				FROM unnest($2::varchar[],$3::int[],$4::int[]) AS d(choice_title,delta,heads)
+				WHERE c.vote_id = $1 AND c.choice_title = d.choice_title
+				RETURNING c.choice_title,c.count),
+			ver AS (
+				UPDATE vote
+				SET version = version + 1, ballots = ballots + $5
+				WHERE vote_id = $1 AND EXISTS (SELECT 1 FROM upd) RETURNING version)
+			SELECT upd.choice_title,upd.count,ver.version FROM upd,ver
+			ORDER BY upd.choice_title`
+
+// selectionSql records the selected choices, the rank of a choice is its
+// position on the ballot. The scores are null unless the vote is a score one.
+const selectionSql string = `INSERT INTO ballot_choice(vote_id,voter_id,choice_title,rank,score)
+			SELECT $1,$2,s.choice_title,s.rank,s.score
+			FROM unnest($3::varchar[],$4::int[]) WITH ORDINALITY AS s(choice_title,score,rank)`
+
+// scoreSql adds the deltas to the score histograms of the choices. The deltas
+// of the same score are summed first, so every row is changed once.
+const scoreSql string = `INSERT INTO choice_score(vote_id,choice_title,score,count)
+			SELECT d.vote_id,d.choice_title,d.score,sum(d.delta)
+			FROM unnest($1::int[],$2::varchar[],$3::int[],$4::int[]) AS d(vote_id,choice_title,score,delta)
+			GROUP BY d.vote_id,d.choice_title,d.score
+			ON CONFLICT (vote_id,choice_title,score)
+			DO UPDATE SET count = choice_score.count + EXCLUDED.count`
+
+// writeInLockSql serializes the write-ins of the votes, so two ballots can't
+// add the same write-in under different titles. The votes are locked in the
+// same order by every Tx and before any of their choices.
+const writeInLockSql string = `SELECT pg_advisory_xact_lock(v.vote_id)
+			FROM (SELECT DISTINCT unnest($1::int[]) AS vote_id ORDER BY vote_id) v`
+
+// logHeadSql finds the last entry of the ballot log of the vote. It is read
+// after the vote row is locked, so the entries of a vote are chained by one
+// Tx at a time.
+const logHeadSql string = `SELECT seq,hash FROM ballot_log WHERE vote_id = $1 ORDER BY seq DESC LIMIT 1`
+
+// linkSql points the ballots at the log entries of their current selections.
+const linkSql string = `UPDATE ballot b
+			SET log_seq = d.seq
+			FROM unnest($1::int[],$2::varchar[],$3::bigint[]) AS d(vote_id,voter_id,seq)
+			WHERE b.vote_id = d.vote_id AND b.voter_id = d.voter_id`
+
+var logColumns = []string{"vote_id", "seq", "kind", "replaces", "weight", "choices", "scores", "created_at", "prev_hash", "hash"}
+
+// scoreDelta is a change of the number of ballots that gave the choice the score.
+type scoreDelta struct {
+	voteId int
+	choice string
+	score  int
+	delta  int
+}
+
 type choiceRepository struct {
 	client PostgresClient
 	logger *logging.Logger
@@ -34,7 +103,8 @@ func (c *choiceRepository) Insert(ctx context.Context, choice entity.Choice) (st
 }
 
 func (c *choiceRepository) FindChoices(ctx context.Context, id int) ([]entity.Choice, error) {
-	sql := `SELECT * FROM choice WHERE vote_id = $1`
+	// the counts of a closed vote are the ones frozen when it was closed
+	sql := `SELECT choice_title,COALESCE(final_count,count),vote_id,COALESCE(final_voters,voters),write_in FROM choice WHERE vote_id = $1`
 	rows, err := c.client.Query(ctx, sql, id)
 	if err != nil {
 		err = psql.ErrExecuteQuery(err)
@@ -45,7 +115,7 @@ func (c *choiceRepository) FindChoices(ctx context.Context, id int) ([]entity.Ch
 	choices := make([]entity.Choice, 0)
 	for rows.Next() {
 		var choice entity.Choice
-		if err = rows.Scan(&choice.Title, &choice.Count, &choice.VoteId); err != nil {
+		if err = rows.Scan(&choice.Title, &choice.Count, &choice.VoteId, &choice.Voters, &choice.WriteIn); err != nil {
 			c.logger.Error(err)
 			return nil, err
 		}
@@ -54,6 +124,32 @@ func (c *choiceRepository) FindChoices(ctx context.Context, id int) ([]entity.Ch
 	return choices, nil
 }
 
+// HasVoted reports whether the voter has a ballot in the vote.
+func (c *choiceRepository) HasVoted(ctx context.Context, voteId int, voterId string) (bool, error) {
+	sql := `SELECT EXISTS (SELECT 1 FROM ballot WHERE vote_id = $1 AND voter_id = $2)`
+	var voted bool
+	if err := c.client.QueryRow(ctx, sql, voteId, voterId).Scan(&voted); err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return false, err
+	}
+	return voted, nil
+}
+
+func (c *choiceRepository) FindVersion(ctx context.Context, voteId int) (int64, error) {
+	sql := `SELECT version FROM vote WHERE vote_id = $1`
+	var version int64
+	err := c.client.QueryRow(ctx, sql, voteId).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return -1, errs.ErrVoteNotExist
+		}
+		c.logger.Error(err)
+		return -1, err
+	}
+	return version, nil
+}
+
 func (c *choiceRepository) FindChoice(ctx context.Context, id int, choiceTitle string) (entity.Choice, error) {
 	sql := `SELECT choice_title,vote_id,count
 			FROM choice 
@@ -69,32 +165,1272 @@ func (c *choiceRepository) FindChoice(ctx context.Context, id int, choiceTitle s
 	return choice, nil
 }
 
-func (c *choiceRepository) Update(ctx context.Context, count int, voteId int, title string) (int, error) {
-	sql := `UPDATE choice
-			SET count = count + $1
-			WHERE choice_title = $2 AND vote_id = $3  RETURNING count`
-	tx, err := c.client.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted})
+// Update records the ballot of the voter and adds its weight to the counts of
+// the selected choices in one Tx, the version of the vote results is bumped
+// with the counts. The write-ins of the ballot are added in the same Tx, so
+// they aren't added if the ballot is rejected. The ballot is appended to the
+// ballot log and the hash of its entry is returned as the receipt.
+func (c *choiceRepository) Update(ctx context.Context, ballot entity.Ballot) ([]entity.ChoiceUpdate, string, error) {
+	ballotSql := `INSERT INTO ballot(vote_id,voter_id,weight)
+			VALUES($1,$2,$3)
+			ON CONFLICT (vote_id,voter_id) DO NOTHING`
+	var updates []entity.ChoiceUpdate
+	var receipt string
+	err := c.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		var err error
+		if ballot.WriteIn {
+			if ballot.Choices, err = writeIn(ctx, tx, ballot.VoteId, ballot.Choices); err != nil {
+				return err
+			}
+		}
+		if err = lockChoices(ctx, tx, ballot.VoteId, ballot.Choices); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, ballotSql, ballot.VoteId, ballot.VoterId, ballot.Weight)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		if tag.RowsAffected() == 0 {
+			return errs.ErrAlreadyVoted
+		}
+		if _, err = tx.Exec(ctx, selectionSql, ballot.VoteId, ballot.VoterId, ballot.Choices, ballot.Scores); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		n := len(ballot.Choices)
+		if updates, err = addCounts(ctx, tx, ballot.VoteId, ballot.Choices, deltas(n, ballot.Weight), deltas(n, 1), 1); err != nil {
+			return err
+		}
+		if err = addScores(ctx, tx, scoreDeltas(ballot, 1)); err != nil {
+			return err
+		}
+		entries, err := appendLog(ctx, tx, ballot.VoteId, entity.LogEntry{Kind: entity.LogCast, Weight: ballot.Weight, Choices: ballot.Choices, Scores: ballot.Scores})
+		if err != nil {
+			return err
+		}
+		receipt = entries[0].Hash
+		return linkBallots(ctx, tx, []int{ballot.VoteId}, []string{ballot.VoterId}, []int64{entries[0].Seq})
+	})
+	if err != nil {
+		c.logger.Errorf("cannot save ballot of voter %v in vote id = %v due to %v", ballot.VoterId, ballot.VoteId, err)
+		return nil, "", err
+	}
+	return updates, receipt, nil
+}
+
+// UpdateBatch records the ballots and adds the weights of the accepted ones
+// to the choice counts in one Tx. The results are returned in the order of the ballots,
+// the updates hold the new counts of the changed choices. The version of
+// every changed vote is bumped once. The accepted ballots are appended to the
+// ballot logs of their votes in the order of the batch.
+func (c *choiceRepository) UpdateBatch(ctx context.Context, ballots []entity.Ballot) ([]entity.BallotResult, []entity.ChoiceUpdate, error) {
+	// the choices of the write-in ballots are replaced with the resolved ones
+	ballots = append([]entity.Ballot(nil), ballots...)
+	// rows are locked in the same order by every batch, so concurrent batches can't deadlock
+	lockSql := `SELECT vote_id,choice_title
+			FROM choice
+			WHERE (vote_id,choice_title) IN (SELECT * FROM unnest($1::int[],$2::varchar[]))
+			ORDER BY vote_id,choice_title
+			FOR UPDATE`
+	ballotSql := `INSERT INTO ballot(vote_id,voter_id,weight)
+			SELECT d.vote_id,d.voter_id,d.weight
+			FROM unnest($1::int[],$2::varchar[],$3::int[]) WITH ORDINALITY AS d(vote_id,voter_id,weight,n)
+			ORDER BY d.n
+			ON CONFLICT (vote_id,voter_id) DO NOTHING
+			RETURNING vote_id,voter_id`
+	selectionSql := `INSERT INTO ballot_choice(vote_id,voter_id,choice_title,rank,score)
+			SELECT * FROM unnest($1::int[],$2::varchar[],$3::varchar[],$4::int[],$5::int[])`
+	updateSql := `UPDATE choice c
+			SET count = c.count + d.count, voters = c.voters + d.voters
+			FROM unnest($1::int[],$2::varchar[],$3::int[],$4::int[]) AS d(vote_id,choice_title,count,voters)
+			WHERE c.vote_id = d.vote_id AND c.choice_title = d.choice_title
+			RETURNING c.choice_title,c.vote_id,c.count`
+	versionSql := `UPDATE vote v
+			SET version = v.version + 1, ballots = v.ballots + d.ballots
+			FROM (SELECT vote_id FROM vote WHERE vote_id = ANY($1) ORDER BY vote_id FOR UPDATE) l,
+				unnest($1::int[],$2::int[]) AS d(vote_id,ballots)
+			WHERE v.vote_id = l.vote_id AND d.vote_id = l.vote_id
+			RETURNING v.vote_id,v.version`
+	type choiceKey struct {
+		voteId int
+		title  string
+	}
+	type voterKey struct {
+		voteId  int
+		voterId string
+	}
+	var results []entity.BallotResult
+	var updates []entity.ChoiceUpdate
+	err := c.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		results = make([]entity.BallotResult, len(ballots))
+		updates = make([]entity.ChoiceUpdate, 0)
+		if err := writeInBatch(ctx, tx, ballots, results); err != nil {
+			return err
+		}
+		voteIds := make([]int, 0, len(ballots))
+		voterIds := make([]string, 0, len(ballots))
+		titles := make([]string, 0, len(ballots))
+		for i, ballot := range ballots {
+			if results[i].Err != nil {
+				continue
+			}
+			for _, choice := range ballot.Choices {
+				voteIds = append(voteIds, ballot.VoteId)
+				titles = append(titles, choice)
+			}
+		}
+
+		rows, err := tx.Query(ctx, lockSql, voteIds, titles)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		exist := make(map[choiceKey]bool)
+		for rows.Next() {
+			var key choiceKey
+			if err = rows.Scan(&key.voteId, &key.title); err != nil {
+				rows.Close()
+				return err
+			}
+			exist[key] = true
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		voteIds = voteIds[:0]
+		weights := make([]int, 0, len(ballots))
+		for i, ballot := range ballots {
+			results[i].VoteId = ballot.VoteId
+			if results[i].Err != nil {
+				continue
+			}
+			for _, choice := range ballot.Choices {
+				if !exist[choiceKey{ballot.VoteId, choice}] {
+					results[i].Err = errs.ErrChoiceTitleNotExist
+					break
+				}
+			}
+			if results[i].Err != nil {
+				continue
+			}
+			voteIds = append(voteIds, ballot.VoteId)
+			voterIds = append(voterIds, ballot.VoterId)
+			weights = append(weights, ballot.Weight)
+		}
+		rows, err = tx.Query(ctx, ballotSql, voteIds, voterIds, weights)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		accepted := make(map[voterKey]bool)
+		for rows.Next() {
+			var key voterKey
+			if err = rows.Scan(&key.voteId, &key.voterId); err != nil {
+				rows.Close()
+				return err
+			}
+			accepted[key] = true
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		// ballots were inserted in order, so the first ballot of a voter is the accepted one
+		increments := make(map[choiceKey]int)
+		heads := make(map[choiceKey]int)
+		order := make([]choiceKey, 0)
+		cast := make(map[int][]int)
+		voted := make([]int, 0)
+		voteIds, voterIds, titles = voteIds[:0], voterIds[:0], titles[:0]
+		ranks := make([]int, 0, len(titles))
+		scores := make([]*int, 0, len(titles))
+		histogram := make([]scoreDelta, 0)
+		for i, ballot := range ballots {
+			if results[i].Err != nil {
+				continue
+			}
+			voter := voterKey{ballot.VoteId, ballot.VoterId}
+			if !accepted[voter] {
+				results[i].Err = errs.ErrAlreadyVoted
+				continue
+			}
+			delete(accepted, voter)
+			if _, ok := cast[ballot.VoteId]; !ok {
+				voted = append(voted, ballot.VoteId)
+			}
+			cast[ballot.VoteId] = append(cast[ballot.VoteId], i)
+			histogram = append(histogram, scoreDeltas(ballot, 1)...)
+			for rank, choice := range ballot.Choices {
+				voteIds = append(voteIds, ballot.VoteId)
+				voterIds = append(voterIds, ballot.VoterId)
+				titles = append(titles, choice)
+				ranks = append(ranks, rank+1)
+				if len(ballot.Scores) > 0 {
+					scores = append(scores, &ballot.Scores[rank])
+				} else {
+					scores = append(scores, nil)
+				}
+				key := choiceKey{ballot.VoteId, choice}
+				if _, ok := increments[key]; !ok {
+					order = append(order, key)
+				}
+				increments[key] += ballot.Weight
+				heads[key]++
+			}
+		}
+		if len(order) == 0 {
+			return nil
+		}
+		if _, err = tx.Exec(ctx, selectionSql, voteIds, voterIds, titles, ranks, scores); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		if err = addScores(ctx, tx, histogram); err != nil {
+			return err
+		}
+
+		voteIds, titles = voteIds[:0], titles[:0]
+		counts, voters := make([]int, 0, len(order)), make([]int, 0, len(order))
+		for _, key := range order {
+			voteIds = append(voteIds, key.voteId)
+			titles = append(titles, key.title)
+			counts = append(counts, increments[key])
+			voters = append(voters, heads[key])
+		}
+		rows, err = tx.Query(ctx, updateSql, voteIds, titles, counts, voters)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		totals := make(map[choiceKey]int)
+		for rows.Next() {
+			var update entity.ChoiceUpdate
+			if err = rows.Scan(&update.Choice, &update.VoteId, &update.Count); err != nil {
+				rows.Close()
+				return err
+			}
+			totals[choiceKey{update.VoteId, update.Choice}] = update.Count
+			updates = append(updates, update)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for i, ballot := range ballots {
+			if results[i].Err != nil {
+				continue
+			}
+			results[i].Choices = make([]entity.Choice, 0, len(ballot.Choices))
+			for _, choice := range ballot.Choices {
+				count := totals[choiceKey{ballot.VoteId, choice}]
+				results[i].Choices = append(results[i].Choices, entity.Choice{Title: choice, VoteId: ballot.VoteId, Count: count})
+			}
+		}
+
+		ballotCounts := make([]int, 0, len(voted))
+		for _, voteId := range voted {
+			ballotCounts = append(ballotCounts, len(cast[voteId]))
+		}
+		rows, err = tx.Query(ctx, versionSql, voted, ballotCounts)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		versions := make(map[int]int64)
+		for rows.Next() {
+			var voteId int
+			var version int64
+			if err = rows.Scan(&voteId, &version); err != nil {
+				rows.Close()
+				return err
+			}
+			versions[voteId] = version
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for i := range updates {
+			updates[i].Version = versions[updates[i].VoteId]
+		}
+
+		// the vote rows are locked by versionSql, so the logs can be appended to
+		voteIds, voterIds = voteIds[:0], voterIds[:0]
+		seqs := make([]int64, 0, len(ballots))
+		for _, voteId := range voted {
+			entries := make([]entity.LogEntry, 0, len(cast[voteId]))
+			for _, i := range cast[voteId] {
+				entries = append(entries, entity.LogEntry{Kind: entity.LogCast, Weight: ballots[i].Weight, Choices: ballots[i].Choices, Scores: ballots[i].Scores})
+			}
+			if entries, err = appendLog(ctx, tx, voteId, entries...); err != nil {
+				return err
+			}
+			for j, i := range cast[voteId] {
+				results[i].Receipt = entries[j].Hash
+				voteIds = append(voteIds, voteId)
+				voterIds = append(voterIds, ballots[i].VoterId)
+				seqs = append(seqs, entries[j].Seq)
+			}
+		}
+		return linkBallots(ctx, tx, voteIds, voterIds, seqs)
+	})
 	if err != nil {
-		c.logger.Errorf("cannot begin Tx due to %v", err)
+		c.logger.Errorf("cannot update batch of %v ballots due to %v", len(ballots), err)
+		return nil, nil, err
+	}
+	return results, updates, nil
+}
+
+// ChangeBallot replaces the selection of the voter, the deselected choices
+// are decremented and the newly selected ones are incremented in one Tx by
+// the weight the ballot was cast with. Nothing is changed if the selection,
+// its order and the scores are the same. The write-ins are added first, so
+// the Tx takes the locks in the same order as Update. The new selection is
+// appended to the ballot log in place of the old one and the hash of its
+// entry is returned as the receipt, an unchanged ballot keeps its receipt.
+func (c *choiceRepository) ChangeBallot(ctx context.Context, ballot entity.Ballot) ([]entity.ChoiceUpdate, string, error) {
+	findSql := `SELECT b.weight,b.log_seq,COALESCE(l.hash,''),bc.choice_title,bc.score
+			FROM ballot b JOIN ballot_choice bc USING (vote_id,voter_id)
+				LEFT JOIN ballot_log l ON l.vote_id = b.vote_id AND l.seq = b.log_seq
+			WHERE b.vote_id = $1 AND b.voter_id = $2
+			ORDER BY bc.rank
+			FOR UPDATE OF b`
+	deselectSql := `DELETE FROM ballot_choice WHERE vote_id = $1 AND voter_id = $2`
+	var updates []entity.ChoiceUpdate
+	var receipt string
+	err := c.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		var err error
+		if ballot.WriteIn {
+			if ballot.Choices, err = writeIn(ctx, tx, ballot.VoteId, ballot.Choices); err != nil {
+				return err
+			}
+		}
+		rows, err := tx.Query(ctx, findSql, ballot.VoteId, ballot.VoterId)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		previous := entity.Ballot{VoteId: ballot.VoteId, Choices: make([]string, 0)}
+		var seq int64
+		for rows.Next() {
+			var choice string
+			var score *int
+			if err = rows.Scan(&previous.Weight, &seq, &receipt, &choice, &score); err != nil {
+				rows.Close()
+				return err
+			}
+			previous.Choices = append(previous.Choices, choice)
+			if score != nil {
+				previous.Scores = append(previous.Scores, *score)
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		current := previous.Choices
+		if len(current) == 0 {
+			return errs.ErrBallotNotExist
+		}
+		if sameOrder(current, ballot.Choices) && sameOrder(previous.Scores, ballot.Scores) {
+			return nil
+		}
+
+		// the kept choices are recounted with zero deltas, so the version
+		// is bumped for a new order of the same choices too
+		selected := make(map[string]bool, len(current))
+		for _, choice := range current {
+			selected[choice] = true
+		}
+		titles, changes := make([]string, 0, len(current)+len(ballot.Choices)), make([]int, 0, len(current)+len(ballot.Choices))
+		for _, choice := range ballot.Choices {
+			titles = append(titles, choice)
+			if selected[choice] {
+				delete(selected, choice)
+				changes = append(changes, 0)
+				continue
+			}
+			changes = append(changes, 1)
+		}
+		for _, choice := range current {
+			if selected[choice] {
+				titles = append(titles, choice)
+				changes = append(changes, -1)
+			}
+		}
+		if err = lockChoices(ctx, tx, ballot.VoteId, titles); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(ctx, deselectSql, ballot.VoteId, ballot.VoterId); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		if _, err = tx.Exec(ctx, selectionSql, ballot.VoteId, ballot.VoterId, ballot.Choices, ballot.Scores); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		weighted := make([]int, 0, len(changes))
+		for _, change := range changes {
+			weighted = append(weighted, change*previous.Weight)
+		}
+		if updates, err = addCounts(ctx, tx, ballot.VoteId, titles, weighted, changes, 0); err != nil {
+			return err
+		}
+		if err = addScores(ctx, tx, append(scoreDeltas(previous, -1), scoreDeltas(ballot, 1)...)); err != nil {
+			return err
+		}
+		entries, err := appendLog(ctx, tx, ballot.VoteId, entity.LogEntry{Kind: entity.LogChange, Replaces: seq, Weight: previous.Weight, Choices: ballot.Choices, Scores: ballot.Scores})
+		if err != nil {
+			return err
+		}
+		receipt = entries[0].Hash
+		return linkBallots(ctx, tx, []int{ballot.VoteId}, []string{ballot.VoterId}, []int64{entries[0].Seq})
+	})
+	if err != nil {
+		c.logger.Errorf("cannot change ballot of voter %v in vote id = %v due to %v", ballot.VoterId, ballot.VoteId, err)
+		return nil, "", err
+	}
+	return updates, receipt, nil
+}
+
+// RetractBallot deletes the ballot of the voter and takes its weight back
+// from the counts of the selected choices in one Tx. The retraction of its
+// entry is appended to the ballot log.
+func (c *choiceRepository) RetractBallot(ctx context.Context, voteId int, voterId string) ([]entity.ChoiceUpdate, error) {
+	// the select sees the selections as they were before the delete cascaded to them
+	retractSql := `WITH b AS (
+				DELETE FROM ballot
+				WHERE vote_id = $1 AND voter_id = $2
+				RETURNING vote_id,voter_id,weight,log_seq)
+			SELECT b.weight,b.log_seq,bc.choice_title,bc.score FROM ballot_choice bc JOIN b USING (vote_id,voter_id)`
+	var updates []entity.ChoiceUpdate
+	err := c.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, retractSql, voteId, voterId)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		choices := make([]string, 0)
+		histogram := make([]scoreDelta, 0)
+		weight := 0
+		var seq int64
+		for rows.Next() {
+			var choice string
+			var score *int
+			if err = rows.Scan(&weight, &seq, &choice, &score); err != nil {
+				rows.Close()
+				return err
+			}
+			choices = append(choices, choice)
+			if score != nil {
+				histogram = append(histogram, scoreDelta{voteId: voteId, choice: choice, score: *score, delta: -1})
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if len(choices) == 0 {
+			return errs.ErrBallotNotExist
+		}
+		if err = lockChoices(ctx, tx, voteId, choices); err != nil {
+			return err
+		}
+		n := len(choices)
+		if updates, err = addCounts(ctx, tx, voteId, choices, deltas(n, -weight), deltas(n, -1), -1); err != nil {
+			return err
+		}
+		if err = addScores(ctx, tx, histogram); err != nil {
+			return err
+		}
+		_, err = appendLog(ctx, tx, voteId, entity.LogEntry{Kind: entity.LogRetract, Replaces: seq, Weight: weight, Choices: []string{}})
+		return err
+	})
+	if err != nil {
+		c.logger.Errorf("cannot retract ballot of voter %v in vote id = %v due to %v", voterId, voteId, err)
+		return nil, err
+	}
+	return updates, nil
+}
+
+// EditPoll applies the edit to the vote in one Tx and returns the new version
+// of the results. All choices of the vote are locked first, in the order of
+// lockSql, so the counts checked for the removed choices can't change under
+// the edit. The renames and the removals change the ballots, so they are
+// appended to the ballot log.
+func (c *choiceRepository) EditPoll(ctx context.Context, voteId int, edit entity.PollEdit) (int64, error) {
+	lockAllSql := `SELECT choice_title,count
+			FROM choice
+			WHERE vote_id = $1
+			ORDER BY choice_title
+			FOR UPDATE`
+	// the ballot privacy is kept once the vote has a ballot, so a ballot cast
+	// after the check of the service isn't stored in another mode. A title
+	// taken by another vote violates titleKey.
+	voteSql := `UPDATE vote
+			SET vote_title = $2, min_selections = $3, max_selections = $4, abstention = $5,
+				ballot_privacy = CASE WHEN ballots = 0 THEN $6 ELSE ballot_privacy END, version = version + 1
+			WHERE vote_id = $1
+			RETURNING version, ballot_privacy = $7`
+	removeSql := `DELETE FROM choice WHERE vote_id = $1 AND choice_title = ANY($2)`
+	// ballot_choice and choice_score follow the new titles by ON UPDATE CASCADE
+	renameSql := `UPDATE choice c
+			SET choice_title = r.new_title
+			FROM unnest($2::varchar[],$3::varchar[]) AS r(choice_title,new_title)
+			WHERE c.vote_id = $1 AND c.choice_title = r.choice_title`
+	addSql := `INSERT INTO choice(choice_title,count,vote_id)
+			SELECT unnest($1::varchar[]),0,$2`
+	from := make([]string, 0, len(edit.Rename))
+	for choice := range edit.Rename {
+		from = append(from, choice)
+	}
+	sort.Strings(from)
+	to := make([]string, 0, len(from))
+	for _, choice := range from {
+		to = append(to, edit.Rename[choice])
+	}
+	var version int64
+	err := c.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, lockAllSql, voteId)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		counts := make(map[string]int)
+		for rows.Next() {
+			var choice string
+			var count int
+			if err = rows.Scan(&choice, &count); err != nil {
+				rows.Close()
+				return err
+			}
+			counts[choice] = count
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for _, choice := range from {
+			if _, ok := counts[choice]; !ok {
+				return errs.ErrChoiceTitleNotExist
+			}
+		}
+		for _, choice := range edit.Remove {
+			count, ok := counts[choice]
+			if !ok {
+				return errs.ErrChoiceTitleNotExist
+			}
+			if count != 0 && !edit.Force {
+				return errs.ErrChoiceHasVotes
+			}
+		}
+		var kept bool
+		err = tx.QueryRow(ctx, voteSql, voteId, edit.Title, edit.MinSelections, edit.MaxSelections, edit.Abstention, edit.Privacy, edit.Privacy).Scan(&version, &kept)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errs.ErrVoteNotExist
+			}
+			if psql.IsUniqueViolation(err, titleKey) {
+				return errs.ErrTitleAlreadyExist
+			}
+			return psql.ErrExecuteQuery(err)
+		}
+		if !kept {
+			return errs.ErrPrivacyLocked
+		}
+		if len(edit.Remove) > 0 {
+			if _, err = tx.Exec(ctx, removeSql, voteId, edit.Remove); err != nil {
+				return psql.ErrExecuteQuery(err)
+			}
+		}
+		if len(from) > 0 {
+			if _, err = tx.Exec(ctx, renameSql, voteId, from, to); err != nil {
+				return psql.ErrExecuteQuery(err)
+			}
+		}
+		if len(edit.Add) > 0 {
+			if _, err = tx.Exec(ctx, addSql, edit.Add, voteId); err != nil {
+				return psql.ErrExecuteQuery(err)
+			}
+		}
+		// the log follows the order of the edit, a removed title can be given
+		// to another choice
+		entries := make([]entity.LogEntry, 0, len(from)+1)
+		if len(edit.Remove) > 0 {
+			entries = append(entries, entity.LogEntry{Kind: entity.LogRemove, Choices: edit.Remove})
+		}
+		for i := range from {
+			entries = append(entries, entity.LogEntry{Kind: entity.LogRename, Choices: []string{from[i], to[i]}})
+		}
+		_, err = appendLog(ctx, tx, voteId, entries...)
+		return err
+	})
+	if err != nil {
+		c.logger.Errorf("cannot edit vote id = %v due to %v", voteId, err)
 		return -1, err
 	}
-	defer func() {
+	return version, nil
+}
+
+// MergeChoices merges the write-in choice from into the choice into in one
+// Tx and returns the new count of into. The selections of from are moved to
+// into, a ballot that selected both keeps one selection at the better of the
+// two ranks, so its weight is counted once. The merge is appended to the
+// ballot log.
+func (c *choiceRepository) MergeChoices(ctx context.Context, voteId int, from string, into string) ([]entity.ChoiceUpdate, error) {
+	lockMergedSql := `SELECT choice_title,count,voters,write_in
+			FROM choice
+			WHERE vote_id = $1 AND choice_title = ANY($2)
+			ORDER BY choice_title
+			FOR UPDATE`
+	overlapSql := `WITH f AS (
+				DELETE FROM ballot_choice f
+				USING ballot_choice i
+				WHERE f.vote_id = $1 AND f.choice_title = $2
+					AND i.vote_id = $1 AND i.voter_id = f.voter_id AND i.choice_title = $3
+				RETURNING f.voter_id,f.rank),
+			r AS (
+				UPDATE ballot_choice i
+				SET rank = LEAST(i.rank,f.rank)
+				FROM f
+				WHERE i.vote_id = $1 AND i.choice_title = $3 AND i.voter_id = f.voter_id)
+			SELECT b.weight FROM ballot b JOIN f USING (voter_id) WHERE b.vote_id = $1`
+	moveSql := `UPDATE ballot_choice SET choice_title = $3 WHERE vote_id = $1 AND choice_title = $2`
+	deleteSql := `DELETE FROM choice WHERE vote_id = $1 AND choice_title = $2`
+	var updates []entity.ChoiceUpdate
+	err := c.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		// no ballot can resolve a write-in to from while it is merged
+		if _, err := tx.Exec(ctx, writeInLockSql, []int{voteId}); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		rows, err := tx.Query(ctx, lockMergedSql, voteId, []string{from, into})
 		if err != nil {
-			tx.Rollback(ctx)
+			return psql.ErrExecuteQuery(err)
+		}
+		merged := make(map[string]entity.Choice, 2)
+		for rows.Next() {
+			var choice entity.Choice
+			if err = rows.Scan(&choice.Title, &choice.Count, &choice.Voters, &choice.WriteIn); err != nil {
+				rows.Close()
+				return err
+			}
+			merged[choice.Title] = choice
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if len(merged) < 2 {
+			return errs.ErrChoiceTitleNotExist
+		}
+		if !merged[from].WriteIn {
+			return errs.ErrInvalidMerge
+		}
+		rows, err = tx.Query(ctx, overlapSql, voteId, from, into)
+		if err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		overlap, heads := 0, 0
+		for rows.Next() {
+			var weight int
+			if err = rows.Scan(&weight); err != nil {
+				rows.Close()
+				return err
+			}
+			overlap += weight
+			heads++
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(ctx, moveSql, voteId, from, into); err != nil {
+			return psql.ErrExecuteQuery(err)
 		}
-	}()
-	var updCount int
-	err = tx.QueryRow(ctx, sql, count, title, voteId).Scan(&updCount)
+		if _, err = tx.Exec(ctx, deleteSql, voteId, from); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		updates, err = addCounts(ctx, tx, voteId, []string{into}, []int{merged[from].Count - overlap}, []int{merged[from].Voters - heads}, 0)
+		if err != nil {
+			return err
+		}
+		_, err = appendLog(ctx, tx, voteId, entity.LogEntry{Kind: entity.LogMerge, Choices: []string{from, into}})
+		return err
+	})
+	if err != nil {
+		c.logger.Errorf("cannot merge choice %v into %v in vote id = %v due to %v", from, into, voteId, err)
+		return nil, err
+	}
+	return updates, nil
+}
+
+// FindBallot returns the ballot of the voter with the selected choices and
+// their scores if the vote is a score one.
+func (c *choiceRepository) FindBallot(ctx context.Context, voteId int, voterId string) (entity.Ballot, error) {
+	sql := `SELECT b.created_at,bc.choice_title,bc.score
+			FROM ballot b JOIN ballot_choice bc USING (vote_id,voter_id)
+			WHERE b.vote_id = $1 AND b.voter_id = $2
+			ORDER BY bc.rank`
+	rows, err := c.client.Query(ctx, sql, voteId, voterId)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return entity.Ballot{}, err
+	}
+	defer rows.Close()
+	ballot := entity.Ballot{VoteId: voteId, VoterId: voterId, Choices: make([]string, 0)}
+	for rows.Next() {
+		var choice string
+		var score *int
+		if err = rows.Scan(&ballot.CreatedAt, &choice, &score); err != nil {
+			c.logger.Error(err)
+			return entity.Ballot{}, err
+		}
+		ballot.Choices = append(ballot.Choices, choice)
+		if score != nil {
+			ballot.Scores = append(ballot.Scores, *score)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return entity.Ballot{}, err
+	}
+	if len(ballot.Choices) == 0 {
+		return entity.Ballot{}, errs.ErrBallotNotExist
+	}
+	return ballot, nil
+}
+
+// FindRankings returns the selections of every ballot of the vote in the
+// order of preference.
+func (c *choiceRepository) FindRankings(ctx context.Context, voteId int) ([][]string, error) {
+	sql := `SELECT voter_id,choice_title
+			FROM ballot_choice
+			WHERE vote_id = $1
+			ORDER BY voter_id,rank`
+	rows, err := c.client.Query(ctx, sql, voteId)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	rankings := make([][]string, 0)
+	last := ""
+	for rows.Next() {
+		var voterId, choice string
+		if err = rows.Scan(&voterId, &choice); err != nil {
+			c.logger.Error(err)
+			return nil, err
+		}
+		if len(rankings) == 0 || voterId != last {
+			rankings = append(rankings, make([]string, 0, 1))
+			last = voterId
+		}
+		rankings[len(rankings)-1] = append(rankings[len(rankings)-1], choice)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return rankings, nil
+}
+
+// FindVoters returns the voters of every choice of the vote ordered by
+// the title of the choice, a choice without votes has no voters.
+func (c *choiceRepository) FindVoters(ctx context.Context, voteId int) ([]entity.ChoiceVoters, error) {
+	sql := `SELECT c.choice_title,bc.voter_id
+			FROM choice c LEFT JOIN ballot_choice bc USING (vote_id,choice_title)
+			WHERE c.vote_id = $1
+			ORDER BY c.choice_title,bc.voter_id`
+	rows, err := c.client.Query(ctx, sql, voteId)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	voters := make([]entity.ChoiceVoters, 0)
+	for rows.Next() {
+		var choice string
+		var voterId *string
+		if err = rows.Scan(&choice, &voterId); err != nil {
+			c.logger.Error(err)
+			return nil, err
+		}
+		if len(voters) == 0 || voters[len(voters)-1].Choice != choice {
+			voters = append(voters, entity.ChoiceVoters{Choice: choice, VoterIds: make([]string, 0)})
+		}
+		if voterId != nil {
+			last := &voters[len(voters)-1]
+			last.VoterIds = append(last.VoterIds, *voterId)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return voters, nil
+}
+
+// FindReceipt returns the entry of the ballot log of the vote with the hash
+// and the seq of the entry that has replaced it, ErrReceiptNotExist is
+// returned if the log has no such entry.
+func (c *choiceRepository) FindReceipt(ctx context.Context, voteId int, hash string) (entity.Receipt, error) {
+	sql := `SELECT l.seq,l.kind,l.replaces,l.weight,l.choices,l.scores,l.created_at,l.prev_hash,l.hash,COALESCE(r.seq,0)
+			FROM ballot_log l LEFT JOIN ballot_log r ON r.vote_id = l.vote_id AND r.replaces = l.seq
+			WHERE l.vote_id = $1 AND l.hash = $2`
+	receipt := entity.Receipt{Entry: entity.LogEntry{VoteId: voteId}}
+	entry := &receipt.Entry
+	err := c.client.QueryRow(ctx, sql, voteId, hash).Scan(&entry.Seq, &entry.Kind, &entry.Replaces, &entry.Weight, &entry.Choices, &entry.Scores, &entry.CreatedAt, &entry.PrevHash, &entry.Hash, &receipt.ReplacedBy)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return -1, errs.ErrChoiceTitleNotExist
+			return entity.Receipt{}, errs.ErrReceiptNotExist
 		}
-		return -1, err
+		c.logger.Error(err)
+		return entity.Receipt{}, err
+	}
+	return receipt, nil
+}
+
+// FindLog returns at most limit entries of the ballot log of the vote that
+// come after the entry with the seq after, in the order of the log.
+func (c *choiceRepository) FindLog(ctx context.Context, voteId int, after int64, limit int) ([]entity.LogEntry, error) {
+	sql := `SELECT seq,kind,replaces,weight,choices,scores,created_at,prev_hash,hash
+			FROM ballot_log
+			WHERE vote_id = $1 AND seq > $2
+			ORDER BY seq
+			LIMIT $3`
+	rows, err := c.client.Query(ctx, sql, voteId, after, limit)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return nil, err
 	}
-	if err = tx.Commit(ctx); err != nil {
-		c.logger.Errorf("cannot commit Tx due to %v", err)
+	defer rows.Close()
+	entries, err := scanLog(rows, voteId)
+	if err != nil {
+		c.logger.Error(err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindLogUntil returns the entries of the ballot log of the vote appended
+// at or before until, in the order of the log. The entries are appended
+// under the lock of the vote, so their times follow their seq.
+func (c *choiceRepository) FindLogUntil(ctx context.Context, voteId int, until time.Time) ([]entity.LogEntry, error) {
+	sql := `SELECT seq,kind,replaces,weight,choices,scores,created_at,prev_hash,hash
+			FROM ballot_log
+			WHERE vote_id = $1 AND created_at <= $2
+			ORDER BY seq`
+	rows, err := c.client.Query(ctx, sql, voteId, until)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	entries, err := scanLog(rows, voteId)
+	if err != nil {
+		c.logger.Error(err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindHistograms returns the score histograms of the choices of the vote,
+// the scores no ballot has given are left out.
+func (c *choiceRepository) FindHistograms(ctx context.Context, voteId int) (map[string]entity.Histogram, error) {
+	sql := `SELECT choice_title,score,count
+			FROM choice_score
+			WHERE vote_id = $1 AND count > 0
+			ORDER BY choice_title,score`
+	rows, err := c.client.Query(ctx, sql, voteId)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	histograms := make(map[string]entity.Histogram)
+	for rows.Next() {
+		var choice string
+		var bucket entity.ScoreCount
+		if err = rows.Scan(&choice, &bucket.Score, &bucket.Count); err != nil {
+			c.logger.Error(err)
+			return nil, err
+		}
+		histograms[choice] = append(histograms[choice], bucket)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return histograms, nil
+}
+
+// FindWeights returns the weights of the voters in the vote, the voters
+// without a weight are left out.
+func (c *choiceRepository) FindWeights(ctx context.Context, voteId int, voterIds []string) (map[string]int, error) {
+	sql := `SELECT voter_id,weight
+			FROM voter_weight
+			WHERE vote_id = $1 AND voter_id = ANY($2)`
+	rows, err := c.client.Query(ctx, sql, voteId, voterIds)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	weights := make(map[string]int, len(voterIds))
+	for rows.Next() {
+		var voterId string
+		var weight int
+		if err = rows.Scan(&voterId, &weight); err != nil {
+			c.logger.Error(err)
+			return nil, err
+		}
+		weights[voterId] = weight
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return weights, nil
+}
+
+// FindEligibleWeight returns the total weight of the eligible voters of the
+// vote, it is 0 if the vote has no list of them.
+func (c *choiceRepository) FindEligibleWeight(ctx context.Context, voteId int) (int, error) {
+	sql := `SELECT COALESCE(sum(weight),0) FROM voter_weight WHERE vote_id = $1`
+	var weight int
+	if err := c.client.QueryRow(ctx, sql, voteId).Scan(&weight); err != nil {
+		c.logger.Error(err)
+		return 0, err
+	}
+	return weight, nil
+}
+
+// FindLastVotes returns the time of the last ballot for each of the choices
+// of the vote, the choices nobody has voted for are left out.
+func (c *choiceRepository) FindLastVotes(ctx context.Context, voteId int, choices []string) (map[string]time.Time, error) {
+	sql := `SELECT bc.choice_title,max(b.created_at)
+			FROM ballot_choice bc JOIN ballot b ON b.vote_id = bc.vote_id AND b.voter_id = bc.voter_id
+			WHERE bc.vote_id = $1 AND bc.choice_title = ANY($2)
+			GROUP BY bc.choice_title`
+	rows, err := c.client.Query(ctx, sql, voteId, choices)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		c.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	votes := make(map[string]time.Time, len(choices))
+	for rows.Next() {
+		var choice string
+		var at time.Time
+		if err = rows.Scan(&choice, &at); err != nil {
+			c.logger.Error(err)
+			return nil, err
+		}
+		votes[choice] = at
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return votes, nil
+}
+
+// DecideTie saves the tied choice the owner has picked as the winner of the
+// vote and returns the new version of the results.
+func (c *choiceRepository) DecideTie(ctx context.Context, voteId int, choice string) (int64, error) {
+	sql := `UPDATE vote
+			SET tie_winner = $2, version = version + 1
+			WHERE vote_id = $1
+			RETURNING version`
+	var version int64
+	err := c.client.QueryRow(ctx, sql, voteId, choice).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return -1, errs.ErrVoteNotExist
+		}
+		c.logger.Error(err)
 		return -1, err
 	}
+	return version, nil
+}
+
+// lockChoices locks the choices of the vote, ErrChoiceTitleNotExist is
+// returned if any of them doesn't exist.
+func lockChoices(ctx context.Context, tx pgx.Tx, voteId int, choices []string) error {
+	tag, err := tx.Exec(ctx, lockSql, voteId, choices)
+	if err != nil {
+		return psql.ErrExecuteQuery(err)
+	}
+	if int(tag.RowsAffected()) < len(choices) {
+		return errs.ErrChoiceTitleNotExist
+	}
+	return nil
+}
+
+// writeIn locks the write-ins of the vote and resolves the choices of the
+// ballot by writeIns, the new write-ins are added.
+func writeIn(ctx context.Context, tx pgx.Tx, voteId int, choices []string) ([]string, error) {
+	if _, err := tx.Exec(ctx, writeInLockSql, []int{voteId}); err != nil {
+		return nil, psql.ErrExecuteQuery(err)
+	}
+	set, err := findWriteIns(ctx, tx, voteId)
+	if err != nil {
+		return nil, err
+	}
+	if choices, err = set.resolve(choices); err != nil {
+		return nil, err
+	}
+	return choices, set.add(ctx, tx)
+}
 
-	return updCount, nil
+// writeInBatch locks the write-ins of the votes of the write-in ballots and
+// resolves their choices. A ballot whose write-ins can't be added fails in its
+// result, the write-ins of a ballot rejected later in the batch stay added.
+func writeInBatch(ctx context.Context, tx pgx.Tx, ballots []entity.Ballot, results []entity.BallotResult) error {
+	voteIds := make([]int, 0)
+	sets := make(map[int]*writeIns)
+	for _, ballot := range ballots {
+		if _, ok := sets[ballot.VoteId]; ballot.WriteIn && !ok {
+			voteIds = append(voteIds, ballot.VoteId)
+			sets[ballot.VoteId] = nil
+		}
+	}
+	if len(voteIds) == 0 {
+		return nil
+	}
+	sort.Ints(voteIds)
+	if _, err := tx.Exec(ctx, writeInLockSql, voteIds); err != nil {
+		return psql.ErrExecuteQuery(err)
+	}
+	for _, voteId := range voteIds {
+		set, err := findWriteIns(ctx, tx, voteId)
+		if err != nil {
+			return err
+		}
+		sets[voteId] = set
+	}
+	for i := range ballots {
+		if !ballots[i].WriteIn {
+			continue
+		}
+		choices, err := sets[ballots[i].VoteId].resolve(ballots[i].Choices)
+		if err != nil {
+			results[i] = entity.BallotResult{VoteId: ballots[i].VoteId, Err: err}
+			continue
+		}
+		ballots[i].Choices = choices
+	}
+	for _, voteId := range voteIds {
+		if err := sets[voteId].add(ctx, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIns holds the choices of a vote while its write-ins are locked, the
+// choices resolved to new write-ins are collected in added.
+type writeIns struct {
+	voteId int
+	titles map[string]bool
+	keys   map[string]string
+	count  int
+	added  []string
+}
+
+func findWriteIns(ctx context.Context, tx pgx.Tx, voteId int) (*writeIns, error) {
+	sql := `SELECT choice_title,write_in FROM choice WHERE vote_id = $1`
+	rows, err := tx.Query(ctx, sql, voteId)
+	if err != nil {
+		return nil, psql.ErrExecuteQuery(err)
+	}
+	defer rows.Close()
+	set := &writeIns{voteId: voteId, titles: make(map[string]bool), keys: make(map[string]string)}
+	for rows.Next() {
+		var title string
+		var writeIn bool
+		if err = rows.Scan(&title, &writeIn); err != nil {
+			return nil, err
+		}
+		set.titles[title] = true
+		if _, ok := set.keys[entity.ChoiceKey(title)]; !ok {
+			set.keys[entity.ChoiceKey(title)] = title
+		}
+		if writeIn {
+			set.count++
+		}
+	}
+	return set, rows.Err()
+}
+
+// resolve returns the titles of the choices, a choice is the one with the
+// same title or else the one with the same key. The choices nothing matches
+// become new write-ins with the normalised titles, ErrWriteInLimit is returned
+// if the vote can't have that many.
+func (s *writeIns) resolve(choices []string) ([]string, error) {
+	resolved := make([]string, 0, len(choices))
+	added := make(map[string]string)
+	selected := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		title, ok := choice, s.titles[choice]
+		if !ok {
+			key := entity.ChoiceKey(choice)
+			if title, ok = s.keys[key]; !ok {
+				if title, ok = added[key]; !ok {
+					title = entity.NormalizeChoice(choice)
+					added[key] = title
+				}
+			}
+		}
+		if selected[title] {
+			return nil, errs.ErrDuplicateChoice
+		}
+		selected[title] = true
+		resolved = append(resolved, title)
+	}
+	if s.count+len(s.added)+len(added) > entity.MaxWriteIns {
+		return nil, errs.ErrWriteInLimit
+	}
+	for key, title := range added {
+		s.titles[title] = true
+		s.keys[key] = title
+		s.added = append(s.added, title)
+	}
+	return resolved, nil
+}
+
+// add inserts the new write-ins, they are locked by the Tx until it ends.
+func (s *writeIns) add(ctx context.Context, tx pgx.Tx) error {
+	sql := `INSERT INTO choice(choice_title,count,vote_id,write_in)
+			SELECT unnest($2::varchar[]),0,$1,true`
+	if len(s.added) == 0 {
+		return nil
+	}
+	sort.Strings(s.added)
+	if _, err := tx.Exec(ctx, sql, s.voteId, s.added); err != nil {
+		return psql.ErrExecuteQuery(err)
+	}
+	return nil
+}
+
+// addCounts applies countSql and returns the new counts of the choices.
+func addCounts(ctx context.Context, tx pgx.Tx, voteId int, choices []string, changes []int, heads []int, ballots int) ([]entity.ChoiceUpdate, error) {
+	rows, err := tx.Query(ctx, countSql, voteId, choices, changes, heads, ballots)
+	if err != nil {
+		return nil, psql.ErrExecuteQuery(err)
+	}
+	defer rows.Close()
+	updates := make([]entity.ChoiceUpdate, 0, len(choices))
+	for rows.Next() {
+		update := entity.ChoiceUpdate{VoteId: voteId}
+		if err = rows.Scan(&update.Choice, &update.Count, &update.Version); err != nil {
+			return nil, err
+		}
+		updates = append(updates, update)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(updates) < len(choices) {
+		return nil, errs.ErrChoiceTitleNotExist
+	}
+	return updates, nil
+}
+
+// appendLog chains the entries to the ballot log of the vote and writes them,
+// the entries are returned with their seqs and hashes. The vote row must be
+// locked by the Tx, nothing is written if there are no entries.
+func appendLog(ctx context.Context, tx pgx.Tx, voteId int, entries ...entity.LogEntry) ([]entity.LogEntry, error) {
+	if len(entries) == 0 {
+		return entries, nil
+	}
+	var seq int64
+	var prev string
+	if err := tx.QueryRow(ctx, logHeadSql, voteId).Scan(&seq, &prev); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, psql.ErrExecuteQuery(err)
+	}
+	// postgres keeps microseconds, so the hashes can be checked with the stored times
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	rows := make([][]interface{}, 0, len(entries))
+	for i := range entries {
+		seq++
+		entry := &entries[i]
+		entry.VoteId, entry.Seq, entry.CreatedAt, entry.PrevHash = voteId, seq, at, prev
+		entry.Hash = entry.Digest()
+		prev = entry.Hash
+		rows = append(rows, []interface{}{entry.VoteId, entry.Seq, entry.Kind, entry.Replaces, entry.Weight, entry.Choices, entry.Scores, entry.CreatedAt, entry.PrevHash, entry.Hash})
+	}
+	if _, err := tx.CopyFrom(ctx, pgx.Identifier{"ballot_log"}, logColumns, pgx.CopyFromRows(rows)); err != nil {
+		return nil, psql.ErrExecuteQuery(err)
+	}
+	return entries, nil
+}
+
+// scanLog reads the entries of the ballot log of the vote from the rows of
+// a query selecting the columns of LogEntry.
+func scanLog(rows pgx.Rows, voteId int) ([]entity.LogEntry, error) {
+	entries := make([]entity.LogEntry, 0)
+	for rows.Next() {
+		entry := entity.LogEntry{VoteId: voteId}
+		if err := rows.Scan(&entry.Seq, &entry.Kind, &entry.Replaces, &entry.Weight, &entry.Choices, &entry.Scores, &entry.CreatedAt, &entry.PrevHash, &entry.Hash); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// linkBallots applies linkSql, the ballots are given by the vote and the voter
+// with the same index as the seq.
+func linkBallots(ctx context.Context, tx pgx.Tx, voteIds []int, voterIds []string, seqs []int64) error {
+	if _, err := tx.Exec(ctx, linkSql, voteIds, voterIds, seqs); err != nil {
+		return psql.ErrExecuteQuery(err)
+	}
+	return nil
+}
+
+// addScores applies scoreSql, nothing is written if there are no deltas.
+func addScores(ctx context.Context, tx pgx.Tx, changes []scoreDelta) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	voteIds, choices := make([]int, 0, len(changes)), make([]string, 0, len(changes))
+	scores, deltas := make([]int, 0, len(changes)), make([]int, 0, len(changes))
+	for _, change := range changes {
+		voteIds = append(voteIds, change.voteId)
+		choices = append(choices, change.choice)
+		scores = append(scores, change.score)
+		deltas = append(deltas, change.delta)
+	}
+	if _, err := tx.Exec(ctx, scoreSql, voteIds, choices, scores, deltas); err != nil {
+		return psql.ErrExecuteQuery(err)
+	}
+	return nil
+}
+
+// scoreDeltas returns the histogram changes of the scored ballot, none if
+// the ballot has no scores.
+func scoreDeltas(ballot entity.Ballot, delta int) []scoreDelta {
+	changes := make([]scoreDelta, 0, len(ballot.Scores))
+	for i, score := range ballot.Scores {
+		changes = append(changes, scoreDelta{voteId: ballot.VoteId, choice: ballot.Choices[i], score: score, delta: delta})
+	}
+	return changes
+}
+
+func sameOrder[T comparable](a []T, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func deltas(n int, delta int) []int {
+	result := make([]int, n)
+	for i := range result {
+		result[i] = delta
+	}
+	return result
 }
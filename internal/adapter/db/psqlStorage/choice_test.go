@@ -3,13 +3,17 @@ package psqlStorage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/adapter/db/psqlStorage/mocks"
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 	"github.com/driftprogramming/pgxpoolmock"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -62,6 +66,11 @@ func (this updateRow) Scan(dest ...interface{}) error {
 	return nil
 }
 
+// score returns the nullable score column value.
+func score(value int) *int {
+	return &value
+}
+
 func TestInsertChoice(t *testing.T) {
 
 	ctrl := gomock.NewController(t)
@@ -141,26 +150,26 @@ func TestFindIdByVoteId(t *testing.T) {
 			title: "should find successfully",
 			input: 1,
 			mock: func() {
-				columns := []string{"title", "voteId", "count"}
+				columns := []string{"title", "count", "voteId", "voters", "write_in"}
 				pgxRows := pgxpoolmock.NewRows(columns).
-					AddRow("first", 10, 1).
-					AddRow("second", 11, 1).
-					AddRow("third", 12, 1).
+					AddRow("first", 10, 1, 2, false).
+					AddRow("second", 11, 1, 3, false).
+					AddRow("third", 12, 1, 4, true).
 					ToPgxRows()
 				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(pgxRows, nil)
 			},
-			want:    []entity.Choice{{"first", 1, 10}, {"second", 1, 11}, {"third", 1, 12}},
+			want:    []entity.Choice{{Title: "first", VoteId: 1, Count: 10, Voters: 2}, {Title: "second", VoteId: 1, Count: 11, Voters: 3}, {Title: "third", VoteId: 1, Count: 12, Voters: 4, WriteIn: true}},
 			isError: false,
 		},
 		{
 			title: "should return error inside psql Query request",
 			input: 1,
 			mock: func() {
-				columns := []string{"title", "voteId", "count"}
+				columns := []string{"title", "count", "voteId", "voters", "write_in"}
 				pgxRows := pgxpoolmock.NewRows(columns).
-					AddRow("first", 10, 1).
-					AddRow("second", 11, 1).
-					AddRow("third", 12, 1).
+					AddRow("first", 10, 1, 2, false).
+					AddRow("second", 11, 1, 3, false).
+					AddRow("third", 12, 1, 4, true).
 					ToPgxRows()
 				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(pgxRows, errors.New("not found"))
 			},
@@ -171,11 +180,11 @@ func TestFindIdByVoteId(t *testing.T) {
 			title: "should return error while scanning row",
 			input: 1,
 			mock: func() {
-				columns := []string{"title", "voteId", "count"}
+				columns := []string{"title", "count", "voteId", "voters", "write_in"}
 				pgxRows := pgxpoolmock.NewRows(columns).
-					AddRow("first", 10, 1).
-					AddRow("second", 11, 1).
-					AddRow("third", 12, 1).
+					AddRow("first", 10, 1, 2, false).
+					AddRow("second", 11, 1, 3, false).
+					AddRow("third", 12, 1, 4, true).
 					RowError(1, errors.New("internal psql erroe")).
 					ToPgxRows()
 				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(pgxRows, nil)
@@ -190,10 +199,14 @@ func TestFindIdByVoteId(t *testing.T) {
 			test.mock()
 			got, err := choiceRepo.FindChoices(context.Background(), test.input)
 			if !test.isError {
+				assert.NoError(t, err)
+				assert.Len(t, got, len(test.want))
 				for i, actual := range got {
 					assert.Equal(t, test.want[i].Title, actual.Title)
 					assert.Equal(t, test.want[i].Count, actual.Count)
 					assert.Equal(t, test.want[i].VoteId, actual.VoteId)
+					assert.Equal(t, test.want[i].Voters, actual.Voters)
+					assert.Equal(t, test.want[i].WriteIn, actual.WriteIn)
 				}
 			} else {
 				assert.Error(t, err)
@@ -204,91 +217,358 @@ func TestFindIdByVoteId(t *testing.T) {
 }
 
 func TestUpdateById(t *testing.T) {
-
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	logger := logging.GetLogger("debug")
 	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
 	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	ballot := entity.Ballot{VoteId: 1, VoterId: "voter", Choices: []string{"first", "second"}, Weight: 1}
+	inTx := func(tx pgx.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) error {
+				return f(tx)
+			})
+	}
+	locked := pgconn.CommandTag("SELECT 2")
+	inserted := pgconn.CommandTag("INSERT 0 1")
+	linked := pgconn.CommandTag("UPDATE 1")
+	var written *[][]interface{}
 
-	type args struct {
-		voteId int
-		count  int
+	type mockCall func()
+	tests := []struct {
 		title  string
+		mock   mockCall
+		scores []int
+		weight int
+		want   []entity.ChoiceUpdate
+		log    []entity.LogEntry
+		err    error
+	}{
+		{
+			title: "should save ballot and update counts of the selected choices",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first", "second"}).Return(locked, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "voter", 1).Return(inserted, nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "voter", []string{"first", "second"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("first", 3, int64(7)).
+					AddRow("second", 1, int64(7)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"first", "second"}, []int{1, 1}, []int{1, 1}, 1).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{Err: pgx.ErrNoRows})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"voter"}, []int64{1}).Return(linked, nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "first", Count: 3, Version: 7},
+				{VoteId: 1, Choice: "second", Count: 1, Version: 7},
+			},
+			log: []entity.LogEntry{{VoteId: 1, Seq: 1, Kind: entity.LogCast, Weight: 1, Choices: []string{"first", "second"}}},
+		},
+		{
+			title:  "should add the weight of the ballot to the counts and the voter to the head counts",
+			weight: 40,
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first", "second"}).Return(locked, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "voter", 40).Return(inserted, nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "voter", []string{"first", "second"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("first", 60, int64(3)).
+					AddRow("second", 40, int64(3)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"first", "second"}, []int{40, 40}, []int{1, 1}, 1).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 2, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"voter"}, []int64{3}).Return(linked, nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "first", Count: 60, Version: 3},
+				{VoteId: 1, Choice: "second", Count: 40, Version: 3},
+			},
+			log: []entity.LogEntry{{VoteId: 1, Seq: 3, Kind: entity.LogCast, Weight: 40, Choices: []string{"first", "second"}, PrevHash: "head"}},
+		},
+		{
+			title:  "should save scored ballot and add the scores to the histograms",
+			scores: []int{4, 2},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first", "second"}).Return(locked, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "voter", 1).Return(inserted, nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "voter", []string{"first", "second"}, []int{4, 2}).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("first", 1, int64(2)).
+					AddRow("second", 1, int64(2)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"first", "second"}, []int{1, 1}, []int{1, 1}, 1).Return(rows, nil)
+				tx.EXPECT().Exec(gomock.Any(), scoreSql, []int{1, 1}, []string{"first", "second"}, []int{4, 2}, []int{1, 1}).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 1, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"voter"}, []int64{2}).Return(linked, nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "first", Count: 1, Version: 2},
+				{VoteId: 1, Choice: "second", Count: 1, Version: 2},
+			},
+			log: []entity.LogEntry{{VoteId: 1, Seq: 2, Kind: entity.LogCast, Weight: 1, Choices: []string{"first", "second"}, Scores: []int{4, 2}, PrevHash: "head"}},
+		},
+		{
+			title: "couldn't start Tx and Update() should return error",
+			mock: func() {
+				mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("internal db error"))
+			},
+			err: errors.New("internal db error"),
+		},
+		{
+			title: "voter has already voted and Update() should return error",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first", "second"}).Return(locked, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "voter", 1).Return(pgconn.CommandTag("INSERT 0 0"), nil)
+			},
+			err: errs.ErrAlreadyVoted,
+		},
+		{
+			title: "choice doesn't exist and Update() should return error",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first", "second"}).Return(pgconn.CommandTag("SELECT 1"), nil)
+			},
+			err: errs.ErrChoiceTitleNotExist,
+		},
+		{
+			title: "counts couldn't be updated and Update() should return error",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first", "second"}).Return(locked, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "voter", 1).Return(inserted, nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "voter", []string{"first", "second"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				tx.EXPECT().Query(gomock.Any(), countSql, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+		{
+			title: "head of the log couldn't be read and Update() should return error",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first", "second"}).Return(locked, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "voter", 1).Return(inserted, nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "voter", []string{"first", "second"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("first", 3, int64(7)).
+					AddRow("second", 1, int64(7)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"first", "second"}, []int{1, 1}, []int{1, 1}, 1).Return(rows, nil)
+				tx.EXPECT().QueryRow(gomock.Any(), logHeadSql, 1).Return(logHeadRow{Err: errors.New("psql error")})
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			ballot := ballot
+			ballot.Scores = test.scores
+			if test.weight > 0 {
+				ballot.Weight = test.weight
+			}
+			got, receipt, err := choiceRepo.Update(context.Background(), ballot)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+			if test.log != nil {
+				entries := checkLog(t, *written, test.log)
+				assert.Equal(t, entries[0].Hash, receipt)
+			} else {
+				assert.Empty(t, receipt)
+			}
+		})
+	}
+}
+
+func TestUpdateWriteIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	inTx := func(tx pgx.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) error {
+				return f(tx)
+			})
+	}
+	existing := func() pgx.Rows {
+		return pgxpoolmock.NewRows([]string{"choice_title", "write_in"}).
+			AddRow("Pikachu", false).
+			AddRow("Mew", true).
+			ToPgxRows()
 	}
 
 	type mockCall func()
 	tests := []struct {
 		title   string
+		choices []string
 		mock    mockCall
-		input   args
-		want    int
-		isError bool
+		want    []entity.ChoiceUpdate
+		err     error
 	}{
 		{
-			title: "should update successfully",
-			input: args{1, 1, "title"},
+			title:   "Update() should match the choices by key and add the new write-in",
+			choices: []string{"  pikachu ", "Eevee   the Great"},
 			mock: func() {
 				tx := mocks.NewMockTx(ctrl)
-				mockPool.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(tx, nil)
-				row := updateRow{count: 1, Err: nil}
-				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(row)
-				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), writeInLockSql, []int{1}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(existing(), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, []string{"Eevee the Great"}).Return(pgconn.CommandTag("INSERT 0 1"), nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"Pikachu", "Eevee the Great"}).Return(pgconn.CommandTag("SELECT 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "voter", 1).Return(pgconn.CommandTag("INSERT 0 1"), nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "voter", []string{"Pikachu", "Eevee the Great"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("Eevee the Great", 1, int64(4)).
+					AddRow("Pikachu", 5, int64(4)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"Pikachu", "Eevee the Great"}, []int{1, 1}, []int{1, 1}, 1).Return(rows, nil)
+				expectLog(tx, 1, logHeadRow{seq: 3, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"voter"}, []int64{4}).Return(pgconn.CommandTag("UPDATE 1"), nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "Eevee the Great", Count: 1, Version: 4},
+				{VoteId: 1, Choice: "Pikachu", Count: 5, Version: 4},
 			},
-			want:    1,
-			isError: false,
 		},
 		{
-			title: "couldn't start Tx and Update() should return error",
-			input: args{1, 1, "title"},
+			title:   "Update() should return error if the write-ins are the same choice",
+			choices: []string{"eevee", "EEVEE"},
 			mock: func() {
-				mockPool.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal db error"))
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), writeInLockSql, []int{1}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(existing(), nil)
 			},
-			want:    -1,
-			isError: true,
+			err: errs.ErrDuplicateChoice,
 		},
 		{
-			title: "QueryRow() couldn't be executed and Update() should return error",
-			input: args{1, 1, "title"},
+			title:   "Update() should return error if the vote has the maximum number of write-ins",
+			choices: []string{"Eevee"},
 			mock: func() {
 				tx := mocks.NewMockTx(ctrl)
-				mockPool.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(tx, nil)
-				row := updateRow{count: 0, Err: errors.New("internal db error")}
-				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(row)
-				tx.EXPECT().Rollback(gomock.Any())
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), writeInLockSql, []int{1}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "write_in"})
+				for i := 0; i < entity.MaxWriteIns; i++ {
+					rows.AddRow(fmt.Sprintf("write-in %v", i), true)
+				}
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(rows.ToPgxRows(), nil)
 			},
-			want:    -1,
-			isError: true,
+			err: errs.ErrWriteInLimit,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			ballot := entity.Ballot{VoteId: 1, VoterId: "voter", Choices: test.choices, Weight: 1, WriteIn: true}
+			got, _, err := choiceRepo.Update(context.Background(), ballot)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestMergeChoices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	inTx := func(tx pgx.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) error {
+				return f(tx)
+			})
+	}
+	columns := []string{"choice_title", "count", "voters", "write_in"}
+	var written *[][]interface{}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  []entity.ChoiceUpdate
+		log   []entity.LogEntry
+		err   error
+	}{
+		{
+			title: "MergeChoices() should move the selections and count the ballots with both once",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), writeInLockSql, []int{1}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				locked := pgxpoolmock.NewRows(columns).AddRow("Eevee", 7, 7, true).AddRow("eevee!", 3, 3, true).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, []string{"eevee!", "Eevee"}).Return(locked, nil)
+				overlap := pgxpoolmock.NewRows([]string{"weight"}).AddRow(1).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "eevee!", "Eevee").Return(overlap, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "eevee!", "Eevee").Return(pgconn.CommandTag("UPDATE 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "eevee!").Return(pgconn.CommandTag("DELETE 1"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).AddRow("Eevee", 9, int64(12)).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"Eevee"}, []int{2}, []int{2}, 0).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 10, hash: "head"})
+			},
+			want: []entity.ChoiceUpdate{{VoteId: 1, Choice: "Eevee", Count: 9, Version: 12}},
+			log:  []entity.LogEntry{{VoteId: 1, Seq: 11, Kind: entity.LogMerge, Choices: []string{"eevee!", "Eevee"}, PrevHash: "head"}},
 		},
 		{
-			title: "couldn't execute Commit() and Update() should return error",
-			input: args{1, 1, "title"},
+			title: "MergeChoices() should return error if the merged choice isn't a write-in",
 			mock: func() {
 				tx := mocks.NewMockTx(ctrl)
-				mockPool.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(tx, nil)
-				row := updateRow{count: 1, Err: nil}
-				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(row)
-				tx.EXPECT().Commit(gomock.Any()).Return(errors.New("internal db error"))
-				tx.EXPECT().Rollback(gomock.Any())
-
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), writeInLockSql, []int{1}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				locked := pgxpoolmock.NewRows(columns).AddRow("Eevee", 7, 7, true).AddRow("eevee!", 3, 3, false).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, []string{"eevee!", "Eevee"}).Return(locked, nil)
 			},
-			want:    -1,
-			isError: true,
+			err: errs.ErrInvalidMerge,
+		},
+		{
+			title: "MergeChoices() should return error if a choice doesn't exist",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), writeInLockSql, []int{1}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				locked := pgxpoolmock.NewRows(columns).AddRow("eevee!", 3, 3, true).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, []string{"eevee!", "Eevee"}).Return(locked, nil)
+			},
+			err: errs.ErrChoiceTitleNotExist,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.title, func(t *testing.T) {
 			test.mock()
-			got, err := choiceRepo.Update(context.Background(), test.input.count, test.input.voteId, test.input.title)
-			if !test.isError {
-				assert.NoError(t, err)
-				assert.Equal(t, got, test.want)
+			got, err := choiceRepo.MergeChoices(context.Background(), 1, "eevee!", "Eevee")
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
 			} else {
-				assert.Error(t, err)
-				assert.Equal(t, got, test.want)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+			if test.log != nil {
+				checkLog(t, *written, test.log)
 			}
-
 		})
 	}
 }
@@ -322,7 +602,7 @@ func TestFindChoicesByVoteIdAndTitle(t *testing.T) {
 				row := choiceEntityRow{"choice title", 1, 1, nil}
 				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(row)
 			},
-			want:    entity.Choice{"choice title", 1, 1},
+			want:    entity.Choice{Title: "choice title", VoteId: 1, Count: 1},
 			isError: false,
 		},
 		{
@@ -353,3 +633,1303 @@ func TestFindChoicesByVoteIdAndTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	input := []entity.Ballot{
+		{VoteId: 1, VoterId: "ash", Choices: []string{"first", "second"}, Weight: 1},
+		{VoteId: 1, VoterId: "misty", Choices: []string{"first"}, Weight: 10},
+		{VoteId: 1, VoterId: "ash", Choices: []string{"second"}, Weight: 1},
+		{VoteId: 1, VoterId: "brock", Choices: []string{"second", "third"}, Weight: 1},
+		{VoteId: 1, VoterId: "gary", Choices: []string{"second"}, Weight: 1},
+	}
+	inTx := func(tx pgx.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) error {
+				return f(tx)
+			})
+	}
+	var written *[][]interface{}
+
+	type mockCall func()
+	tests := []struct {
+		title       string
+		mock        mockCall
+		want        []entity.BallotResult
+		wantUpdates []entity.ChoiceUpdate
+		log         []entity.LogEntry
+		isError     bool
+	}{
+		{
+			title: "UpdateBatch() should save ballots and update counts in one Tx",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				lockRows := pgxpoolmock.NewRows([]string{"vote_id", "choice_title"}).
+					AddRow(1, "first").
+					AddRow(1, "second").
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(),
+					[]int{1, 1, 1, 1, 1, 1, 1},
+					[]string{"first", "second", "first", "second", "second", "third", "second"}).Return(lockRows, nil)
+				ballotRows := pgxpoolmock.NewRows([]string{"vote_id", "voter_id"}).
+					AddRow(1, "ash").
+					AddRow(1, "misty").
+					AddRow(1, "gary").
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), []int{1, 1, 1, 1}, []string{"ash", "misty", "ash", "gary"}, []int{1, 10, 1, 1}).Return(ballotRows, nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(),
+					[]int{1, 1, 1, 1},
+					[]string{"ash", "ash", "misty", "gary"},
+					[]string{"first", "second", "first", "second"},
+					[]int{1, 2, 1, 1},
+					[]*int{nil, nil, nil, nil}).Return(pgconn.CommandTag("INSERT 0 4"), nil)
+				countRows := pgxpoolmock.NewRows([]string{"choice_title", "vote_id", "count"}).
+					AddRow("first", 1, 12).
+					AddRow("second", 1, 3).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), []int{1, 1}, []string{"first", "second"}, []int{11, 2}, []int{2, 2}).Return(countRows, nil)
+				versionRows := pgxpoolmock.NewRows([]string{"vote_id", "version"}).
+					AddRow(1, int64(8)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), []int{1}, []int{3}).Return(versionRows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 5, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1, 1, 1}, []string{"ash", "misty", "gary"}, []int64{6, 7, 8}).Return(pgconn.CommandTag("UPDATE 3"), nil)
+			},
+			want: []entity.BallotResult{
+				{VoteId: 1, Choices: []entity.Choice{{Title: "first", VoteId: 1, Count: 12}, {Title: "second", VoteId: 1, Count: 3}}},
+				{VoteId: 1, Choices: []entity.Choice{{Title: "first", VoteId: 1, Count: 12}}},
+				{VoteId: 1, Err: errs.ErrAlreadyVoted},
+				{VoteId: 1, Err: errs.ErrChoiceTitleNotExist},
+				{VoteId: 1, Choices: []entity.Choice{{Title: "second", VoteId: 1, Count: 3}}},
+			},
+			wantUpdates: []entity.ChoiceUpdate{
+				{Choice: "first", VoteId: 1, Count: 12, Version: 8},
+				{Choice: "second", VoteId: 1, Count: 3, Version: 8},
+			},
+			log: []entity.LogEntry{
+				{VoteId: 1, Seq: 6, Kind: entity.LogCast, Weight: 1, Choices: []string{"first", "second"}, PrevHash: "head"},
+				{VoteId: 1, Seq: 7, Kind: entity.LogCast, Weight: 10, Choices: []string{"first"}},
+				{VoteId: 1, Seq: 8, Kind: entity.LogCast, Weight: 1, Choices: []string{"second"}},
+			},
+		},
+		{
+			title: "UpdateBatch() should return error if rows couldn't be locked",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("psql error"))
+			},
+			isError: true,
+		},
+		{
+			title: "UpdateBatch() should return error if ballots couldn't be saved",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				lockRows := pgxpoolmock.NewRows([]string{"vote_id", "choice_title"}).AddRow(1, "first").ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(lockRows, nil)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("psql error"))
+			},
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, updates, err := choiceRepo.UpdateBatch(context.Background(), input)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				// every accepted ballot gets the receipt of its entry
+				entries := checkLog(t, *written, test.log)
+				want := append([]entity.BallotResult(nil), test.want...)
+				for i := range want {
+					if want[i].Err == nil {
+						want[i].Receipt, entries = entries[0].Hash, entries[1:]
+					}
+				}
+				assert.Equal(t, want, got)
+				assert.Equal(t, test.wantUpdates, updates)
+			}
+		})
+	}
+}
+
+func TestFindBallot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	votedAt := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  entity.Ballot
+		err   error
+	}{
+		{
+			title: "FindBallot() should return ballot of the voter",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"created_at", "choice_title", "score"}).
+					AddRow(votedAt, "first", nil).
+					AddRow(votedAt, "second", nil).
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(rows, nil)
+			},
+			want: entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"first", "second"}, CreatedAt: votedAt},
+		},
+		{
+			title: "FindBallot() should return scores of the scored ballot",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"created_at", "choice_title", "score"}).
+					AddRow(votedAt, "first", score(3)).
+					AddRow(votedAt, "second", score(0)).
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(rows, nil)
+			},
+			want: entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"first", "second"}, Scores: []int{3, 0}, CreatedAt: votedAt},
+		},
+		{
+			title: "FindBallot() should return error if voter hasn't voted",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"created_at", "choice_title", "score"}).ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(rows, nil)
+			},
+			err: errs.ErrBallotNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindBallot(context.Background(), 1, "ash")
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindRankings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  [][]string
+		err   error
+	}{
+		{
+			title: "FindRankings() should group the selections by voter",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"voter_id", "choice_title"}).
+					AddRow("ash", "second").
+					AddRow("ash", "first").
+					AddRow("misty", "first").
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(rows, nil)
+			},
+			want: [][]string{{"second", "first"}, {"first"}},
+		},
+		{
+			title: "FindRankings() should return empty rankings without ballots",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"voter_id", "choice_title"}).ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(rows, nil)
+			},
+			want: [][]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindRankings(context.Background(), 1)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindVoters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	misty := "misty"
+	ash := "ash"
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  []entity.ChoiceVoters
+		err   error
+	}{
+		{
+			title: "FindVoters() should group the voters by choice",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "voter_id"}).
+					AddRow("first", &ash).
+					AddRow("first", &misty).
+					AddRow("second", (*string)(nil)).
+					AddRow("third", &misty).
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(rows, nil)
+			},
+			want: []entity.ChoiceVoters{
+				{Choice: "first", VoterIds: []string{"ash", "misty"}},
+				{Choice: "second", VoterIds: []string{}},
+				{Choice: "third", VoterIds: []string{"misty"}},
+			},
+		},
+		{
+			title: "FindVoters() should return error if query failed",
+			mock: func() {
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindVoters(context.Background(), 1)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	at := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"seq", "kind", "replaces", "weight", "choices", "scores", "created_at", "prev_hash", "hash"}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  []entity.LogEntry
+		err   error
+	}{
+		{
+			title: "FindLog() should return the entries after the seq",
+			mock: func() {
+				rows := pgxpoolmock.NewRows(columns).
+					AddRow(int64(3), entity.LogCast, int64(0), 1, []string{"Mew"}, []int(nil), at, "second", "third").
+					AddRow(int64(4), entity.LogChange, int64(3), 1, []string{"Pikachu"}, []int(nil), at, "third", "fourth").
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, int64(2), 10).Return(rows, nil)
+			},
+			want: []entity.LogEntry{
+				{VoteId: 1, Seq: 3, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}, CreatedAt: at, PrevHash: "second", Hash: "third"},
+				{VoteId: 1, Seq: 4, Kind: entity.LogChange, Replaces: 3, Weight: 1, Choices: []string{"Pikachu"}, CreatedAt: at, PrevHash: "third", Hash: "fourth"},
+			},
+		},
+		{
+			title: "FindLog() should return error if query failed",
+			mock: func() {
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, int64(2), 10).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindLog(context.Background(), 1, 2, 10)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindLogUntil(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	at := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"seq", "kind", "replaces", "weight", "choices", "scores", "created_at", "prev_hash", "hash"}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  []entity.LogEntry
+		err   error
+	}{
+		{
+			title: "FindLogUntil() should return the entries up to the time",
+			mock: func() {
+				rows := pgxpoolmock.NewRows(columns).
+					AddRow(int64(1), entity.LogCast, int64(0), 1, []string{"Mew"}, []int(nil), at, "", "first").
+					AddRow(int64(2), entity.LogRetract, int64(1), 1, []string{}, []int(nil), at, "first", "second").
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, at).Return(rows, nil)
+			},
+			want: []entity.LogEntry{
+				{VoteId: 1, Seq: 1, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}, CreatedAt: at, Hash: "first"},
+				{VoteId: 1, Seq: 2, Kind: entity.LogRetract, Replaces: 1, Weight: 1, Choices: []string{}, CreatedAt: at, PrevHash: "first", Hash: "second"},
+			},
+		},
+		{
+			title: "FindLogUntil() should return error if query failed",
+			mock: func() {
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, at).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindLogUntil(context.Background(), 1, at)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+type receiptRow struct {
+	receipt entity.Receipt
+	Err     error
+}
+
+func (this receiptRow) Scan(dest ...interface{}) error {
+	if this.Err != nil {
+		return this.Err
+	}
+	entry := this.receipt.Entry
+	*dest[0].(*int64) = entry.Seq
+	*dest[1].(*string) = entry.Kind
+	*dest[2].(*int64) = entry.Replaces
+	*dest[3].(*int) = entry.Weight
+	*dest[4].(*[]string) = entry.Choices
+	*dest[5].(*[]int) = entry.Scores
+	*dest[6].(*time.Time) = entry.CreatedAt
+	*dest[7].(*string) = entry.PrevHash
+	*dest[8].(*string) = entry.Hash
+	*dest[9].(*int64) = this.receipt.ReplacedBy
+	return nil
+}
+
+func TestFindReceipt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	receipt := entity.Receipt{
+		Entry: entity.LogEntry{
+			VoteId:    1,
+			Seq:       3,
+			Kind:      entity.LogCast,
+			Weight:    1,
+			Choices:   []string{"Mew"},
+			CreatedAt: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+			PrevHash:  "second",
+			Hash:      "third",
+		},
+		ReplacedBy: 5,
+	}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  entity.Receipt
+		err   error
+	}{
+		{
+			title: "FindReceipt() should return the entry and the entry that replaced it",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "third").Return(receiptRow{receipt: receipt})
+			},
+			want: receipt,
+		},
+		{
+			title: "FindReceipt() should return error if the log has no such entry",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "third").Return(receiptRow{Err: pgx.ErrNoRows})
+			},
+			err: errs.ErrReceiptNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindReceipt(context.Background(), 1, "third")
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindHistograms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  map[string]entity.Histogram
+		err   error
+	}{
+		{
+			title: "FindHistograms() should group the scores by choice",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "score", "count"}).
+					AddRow("first", 1, 2).
+					AddRow("first", 5, 1).
+					AddRow("second", 3, 4).
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(rows, nil)
+			},
+			want: map[string]entity.Histogram{
+				"first":  {{Score: 1, Count: 2}, {Score: 5, Count: 1}},
+				"second": {{Score: 3, Count: 4}},
+			},
+		},
+		{
+			title: "FindHistograms() should return error if query failed",
+			mock: func() {
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindHistograms(context.Background(), 1)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  map[string]int
+		err   error
+	}{
+		{
+			title: "FindWeights() should return the weights of the found voters",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"voter_id", "weight"}).AddRow("ash", 100).ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, []string{"ash", "misty"}).Return(rows, nil)
+			},
+			want: map[string]int{"ash": 100},
+		},
+		{
+			title: "FindWeights() should return error if query failed",
+			mock: func() {
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, []string{"ash", "misty"}).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindWeights(context.Background(), 1, []string{"ash", "misty"})
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindEligibleWeight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  int
+		err   error
+	}{
+		{
+			title: "FindEligibleWeight() should return the total weight of the voters",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(updateRow{120, nil})
+			},
+			want: 120,
+		},
+		{
+			title: "FindEligibleWeight() should return error if query failed",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(updateRow{0, errors.New("psql error")})
+			},
+			err: errors.New("psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindEligibleWeight(context.Background(), 1)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestFindLastVotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	at := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  map[string]time.Time
+		err   error
+	}{
+		{
+			title: "FindLastVotes() should return the last votes of the choices",
+			mock: func() {
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "max"}).AddRow("Mew", at).ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, []string{"Ditto", "Mew"}).Return(rows, nil)
+			},
+			want: map[string]time.Time{"Mew": at},
+		},
+		{
+			title: "FindLastVotes() should return error if query failed",
+			mock: func() {
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1, []string{"Ditto", "Mew"}).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindLastVotes(context.Background(), 1, []string{"Ditto", "Mew"})
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestDecideTie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  int64
+		err   error
+	}{
+		{
+			title: "DecideTie() should save the winner and return the new version",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "Mew").Return(versionRow{version: 8})
+			},
+			want: 8,
+		},
+		{
+			title: "DecideTie() should return error if vote doesn't exist",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "Mew").Return(versionRow{Err: pgx.ErrNoRows})
+			},
+			want: -1,
+			err:  errs.ErrVoteNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.DecideTie(context.Background(), 1, "Mew")
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestHasVoted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "ash").Return(votedRow{true, nil})
+	voted, err := choiceRepo.HasVoted(context.Background(), 1, "ash")
+	assert.NoError(t, err)
+	assert.True(t, voted)
+
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "misty").Return(votedRow{false, errors.New("psql error")})
+	voted, err = choiceRepo.HasVoted(context.Background(), 1, "misty")
+	assert.Error(t, err)
+	assert.False(t, voted)
+}
+
+type votedRow struct {
+	voted bool
+	Err   error
+}
+
+func (this votedRow) Scan(dest ...interface{}) error {
+	if this.Err != nil {
+		return this.Err
+	}
+	*dest[0].(*bool) = this.voted
+	return nil
+}
+
+func TestFindVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  int64
+		err   error
+	}{
+		{
+			title: "FindVersion() should return version of the vote",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(versionRow{5, nil})
+			},
+			want: 5,
+		},
+		{
+			title: "FindVersion() should return error if vote doesn't exist",
+			mock: func() {
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(versionRow{0, pgx.ErrNoRows})
+			},
+			want: -1,
+			err:  errs.ErrVoteNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.FindVersion(context.Background(), 1)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+type versionRow struct {
+	version int64
+	Err     error
+}
+
+func (this versionRow) Scan(dest ...interface{}) error {
+	if this.Err != nil {
+		return this.Err
+	}
+	*dest[0].(*int64) = this.version
+	return nil
+}
+
+func TestChangeBallot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	ballot := entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"second", "third"}}
+	inTx := func(tx pgx.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) error {
+				return f(tx)
+			})
+	}
+	columns := []string{"weight", "log_seq", "hash", "choice_title", "score"}
+	selected := func(weight int, choices ...string) pgx.Rows {
+		rows := pgxpoolmock.NewRows(columns)
+		for _, choice := range choices {
+			rows.AddRow(weight, int64(4), "cast", choice, nil)
+		}
+		return rows.ToPgxRows()
+	}
+	var written *[][]interface{}
+
+	type mockCall func()
+	tests := []struct {
+		title   string
+		mock    mockCall
+		scores  []int
+		want    []entity.ChoiceUpdate
+		log     []entity.LogEntry
+		receipt string
+		err     error
+	}{
+		{
+			title: "ChangeBallot() should move the deselected choice to the selected one",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected(1, "first", "second"), nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"second", "third", "first"}).Return(pgconn.CommandTag("SELECT 3"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "ash").Return(pgconn.CommandTag("DELETE 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "ash", []string{"second", "third"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("first", 4, int64(10)).
+					AddRow("second", 5, int64(10)).
+					AddRow("third", 7, int64(10)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"second", "third", "first"}, []int{0, 1, -1}, []int{0, 1, -1}, 0).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 9, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"ash"}, []int64{10}).Return(pgconn.CommandTag("UPDATE 1"), nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "first", Count: 4, Version: 10},
+				{VoteId: 1, Choice: "second", Count: 5, Version: 10},
+				{VoteId: 1, Choice: "third", Count: 7, Version: 10},
+			},
+			log: []entity.LogEntry{{VoteId: 1, Seq: 10, Kind: entity.LogChange, Replaces: 4, Weight: 1, Choices: []string{"second", "third"}, PrevHash: "head"}},
+		},
+		{
+			title: "ChangeBallot() should move the weight the ballot was cast with",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected(30, "first"), nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"second", "third", "first"}).Return(pgconn.CommandTag("SELECT 3"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "ash").Return(pgconn.CommandTag("DELETE 1"), nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "ash", []string{"second", "third"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("first", 0, int64(4)).
+					AddRow("second", 30, int64(4)).
+					AddRow("third", 35, int64(4)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"second", "third", "first"}, []int{30, 30, -30}, []int{1, 1, -1}, 0).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 5, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"ash"}, []int64{6}).Return(pgconn.CommandTag("UPDATE 1"), nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "first", Count: 0, Version: 4},
+				{VoteId: 1, Choice: "second", Count: 30, Version: 4},
+				{VoteId: 1, Choice: "third", Count: 35, Version: 4},
+			},
+			log: []entity.LogEntry{{VoteId: 1, Seq: 6, Kind: entity.LogChange, Replaces: 4, Weight: 30, Choices: []string{"second", "third"}, PrevHash: "head"}},
+		},
+		{
+			title: "ChangeBallot() should rerank the same choices without changing counts",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected(1, "third", "second"), nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"second", "third"}).Return(pgconn.CommandTag("SELECT 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "ash").Return(pgconn.CommandTag("DELETE 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "ash", []string{"second", "third"}, []int(nil)).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("second", 5, int64(11)).
+					AddRow("third", 7, int64(11)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"second", "third"}, []int{0, 0}, []int{0, 0}, 0).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 7, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"ash"}, []int64{8}).Return(pgconn.CommandTag("UPDATE 1"), nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "second", Count: 5, Version: 11},
+				{VoteId: 1, Choice: "third", Count: 7, Version: 11},
+			},
+			log: []entity.LogEntry{{VoteId: 1, Seq: 8, Kind: entity.LogChange, Replaces: 4, Weight: 1, Choices: []string{"second", "third"}, PrevHash: "head"}},
+		},
+		{
+			title:  "ChangeBallot() should move the scores between the histogram buckets",
+			scores: []int{5, 1},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				rows := pgxpoolmock.NewRows(columns).
+					AddRow(1, int64(4), "cast", "second", score(2)).
+					AddRow(1, int64(4), "cast", "third", score(1)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(rows, nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"second", "third"}).Return(pgconn.CommandTag("SELECT 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, "ash").Return(pgconn.CommandTag("DELETE 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), selectionSql, 1, "ash", []string{"second", "third"}, []int{5, 1}).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+				counts := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).
+					AddRow("second", 5, int64(12)).
+					AddRow("third", 7, int64(12)).
+					ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"second", "third"}, []int{0, 0}, []int{0, 0}, 0).Return(counts, nil)
+				tx.EXPECT().Exec(gomock.Any(), scoreSql,
+					[]int{1, 1, 1, 1},
+					[]string{"second", "third", "second", "third"},
+					[]int{2, 1, 5, 1},
+					[]int{-1, -1, 1, 1}).Return(pgconn.CommandTag("INSERT 0 3"), nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 8, hash: "head"})
+				tx.EXPECT().Exec(gomock.Any(), linkSql, []int{1}, []string{"ash"}, []int64{9}).Return(pgconn.CommandTag("UPDATE 1"), nil)
+			},
+			want: []entity.ChoiceUpdate{
+				{VoteId: 1, Choice: "second", Count: 5, Version: 12},
+				{VoteId: 1, Choice: "third", Count: 7, Version: 12},
+			},
+			log: []entity.LogEntry{{VoteId: 1, Seq: 9, Kind: entity.LogChange, Replaces: 4, Weight: 1, Choices: []string{"second", "third"}, Scores: []int{5, 1}, PrevHash: "head"}},
+		},
+		{
+			title: "ChangeBallot() to the same selection should change nothing and keep the receipt",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected(1, "second", "third"), nil)
+			},
+			receipt: "cast",
+		},
+		{
+			title: "ChangeBallot() should return error if voter hasn't voted",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected(1), nil)
+			},
+			err: errs.ErrBallotNotExist,
+		},
+		{
+			title: "ChangeBallot() should return error if new choice doesn't exist",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected(1, "second"), nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"second", "third"}).Return(pgconn.CommandTag("SELECT 1"), nil)
+			},
+			err: errs.ErrChoiceTitleNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			ballot := ballot
+			ballot.Scores = test.scores
+			got, receipt, err := choiceRepo.ChangeBallot(context.Background(), ballot)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+			if test.log != nil {
+				entries := checkLog(t, *written, test.log)
+				assert.Equal(t, entries[0].Hash, receipt)
+			} else {
+				assert.Equal(t, test.receipt, receipt)
+			}
+		})
+	}
+}
+
+func TestRetractBallot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	inTx := func(tx pgx.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) error {
+				return f(tx)
+			})
+	}
+	columns := []string{"weight", "log_seq", "choice_title", "score"}
+	var written *[][]interface{}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  []entity.ChoiceUpdate
+		log   []entity.LogEntry
+		err   error
+	}{
+		{
+			title: "RetractBallot() should delete ballot and decrement counts",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				selected := pgxpoolmock.NewRows(columns).AddRow(1, int64(2), "first", nil).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected, nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first"}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).AddRow("first", 3, int64(11)).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"first"}, []int{-1}, []int{-1}, -1).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 6, hash: "head"})
+			},
+			want: []entity.ChoiceUpdate{{VoteId: 1, Choice: "first", Count: 3, Version: 11}},
+			log:  []entity.LogEntry{{VoteId: 1, Seq: 7, Kind: entity.LogRetract, Replaces: 2, Weight: 1, Choices: []string{}, PrevHash: "head"}},
+		},
+		{
+			title: "RetractBallot() should take the weight of the ballot back",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				selected := pgxpoolmock.NewRows(columns).AddRow(25, int64(2), "first", nil).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected, nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first"}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).AddRow("first", 75, int64(13)).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"first"}, []int{-25}, []int{-1}, -1).Return(rows, nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 6, hash: "head"})
+			},
+			want: []entity.ChoiceUpdate{{VoteId: 1, Choice: "first", Count: 75, Version: 13}},
+			log:  []entity.LogEntry{{VoteId: 1, Seq: 7, Kind: entity.LogRetract, Replaces: 2, Weight: 25, Choices: []string{}, PrevHash: "head"}},
+		},
+		{
+			title: "RetractBallot() should take the scores back from the histograms",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				selected := pgxpoolmock.NewRows(columns).AddRow(1, int64(2), "first", score(4)).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected, nil)
+				tx.EXPECT().Exec(gomock.Any(), lockSql, 1, []string{"first"}).Return(pgconn.CommandTag("SELECT 1"), nil)
+				rows := pgxpoolmock.NewRows([]string{"choice_title", "count", "version"}).AddRow("first", 2, int64(12)).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), countSql, 1, []string{"first"}, []int{-1}, []int{-1}, -1).Return(rows, nil)
+				tx.EXPECT().Exec(gomock.Any(), scoreSql, []int{1}, []string{"first"}, []int{4}, []int{-1}).Return(pgconn.CommandTag("UPDATE 1"), nil)
+				expectLog(tx, 1, logHeadRow{seq: 6, hash: "head"})
+			},
+			want: []entity.ChoiceUpdate{{VoteId: 1, Choice: "first", Count: 2, Version: 12}},
+		},
+		{
+			title: "RetractBallot() should return error if voter hasn't voted",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				selected := pgxpoolmock.NewRows(columns).ToPgxRows()
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(selected, nil)
+			},
+			err: errs.ErrBallotNotExist,
+		},
+		{
+			title: "RetractBallot() should return error if ballot couldn't be deleted",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1, "ash").Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.RetractBallot(context.Background(), 1, "ash")
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+			if test.log != nil {
+				checkLog(t, *written, test.log)
+			}
+		})
+	}
+}
+
+func TestEditPoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	choiceRepo := choiceRepository{client: mockPool, logger: logger}
+	inTx := func(tx pgx.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgx.TxOptions, f func(pgx.Tx) error) error {
+				return f(tx)
+			})
+	}
+	locked := func() pgx.Rows {
+		return pgxpoolmock.NewRows([]string{"choice_title", "count"}).
+			AddRow("first", 0).
+			AddRow("second", 3).
+			AddRow("third", 0).
+			ToPgxRows()
+	}
+	var written *[][]interface{}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		edit  entity.PollEdit
+		mock  mockCall
+		want  int64
+		log   []entity.LogEntry
+		err   error
+	}{
+		{
+			title: "EditPoll() should remove, rename and add choices",
+			edit: entity.PollEdit{
+				Title:         "new title",
+				Add:           []string{"fourth"},
+				Rename:        map[string]string{"third": "3rd", "second": "2nd"},
+				Remove:        []string{"first"},
+				MinSelections: 1,
+				MaxSelections: 1,
+				Abstention:    "2nd",
+				Privacy:       entity.PrivacyPublic,
+			},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(locked(), nil)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "new title", 1, 1, "2nd", entity.PrivacyPublic, entity.PrivacyPublic).Return(editedRow{version: 4})
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, []string{"first"}).Return(pgconn.CommandTag("DELETE 1"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, []string{"second", "third"}, []string{"2nd", "3rd"}).Return(pgconn.CommandTag("UPDATE 2"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), []string{"fourth"}, 1).Return(pgconn.CommandTag("INSERT 0 1"), nil)
+				written = expectLog(tx, 1, logHeadRow{seq: 3, hash: "head"})
+			},
+			want: 4,
+			log: []entity.LogEntry{
+				{VoteId: 1, Seq: 4, Kind: entity.LogRemove, Choices: []string{"first"}, PrevHash: "head"},
+				{VoteId: 1, Seq: 5, Kind: entity.LogRename, Choices: []string{"second", "2nd"}},
+				{VoteId: 1, Seq: 6, Kind: entity.LogRename, Choices: []string{"third", "3rd"}},
+			},
+		},
+		{
+			title: "EditPoll() should remove choice with votes if forced",
+			edit:  entity.PollEdit{Title: "title", Remove: []string{"second"}, Force: true, MinSelections: 1, MaxSelections: 1, Privacy: entity.PrivacyPrivate},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(locked(), nil)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "title", 1, 1, "", entity.PrivacyPrivate, entity.PrivacyPrivate).Return(editedRow{version: 7})
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, []string{"second"}).Return(pgconn.CommandTag("DELETE 1"), nil)
+				written = expectLog(tx, 1, logHeadRow{Err: pgx.ErrNoRows})
+			},
+			want: 7,
+			log:  []entity.LogEntry{{VoteId: 1, Seq: 1, Kind: entity.LogRemove, Choices: []string{"second"}}},
+		},
+		{
+			title: "EditPoll() should return error if removed choice has votes",
+			edit:  entity.PollEdit{Title: "title", Remove: []string{"second"}, MinSelections: 1, MaxSelections: 1},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(locked(), nil)
+			},
+			want: -1,
+			err:  errs.ErrChoiceHasVotes,
+		},
+		{
+			title: "EditPoll() should return error if title is taken",
+			edit:  entity.PollEdit{Title: "taken", MinSelections: 1, MaxSelections: 1, Privacy: entity.PrivacyPrivate},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(locked(), nil)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "taken", 1, 1, "", entity.PrivacyPrivate, entity.PrivacyPrivate).Return(editedRow{Err: &pgconn.PgError{Code: "23505", ConstraintName: titleKey}})
+			},
+			want: -1,
+			err:  errs.ErrTitleAlreadyExist,
+		},
+		{
+			title: "EditPoll() should return error if privacy changes after the first ballot",
+			edit:  entity.PollEdit{Title: "title", MinSelections: 1, MaxSelections: 1, Privacy: entity.PrivacyAnonymous},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(locked(), nil)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "title", 1, 1, "", entity.PrivacyAnonymous, entity.PrivacyAnonymous).Return(editedRow{version: 3, locked: true})
+			},
+			want: -1,
+			err:  errs.ErrPrivacyLocked,
+		},
+		{
+			title: "EditPoll() should return error if vote doesn't exist",
+			edit:  entity.PollEdit{Title: "title", MinSelections: 1, MaxSelections: 1, Privacy: entity.PrivacyPrivate},
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(pgxpoolmock.NewRows([]string{"choice_title", "count"}).ToPgxRows(), nil)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, "title", 1, 1, "", entity.PrivacyPrivate, entity.PrivacyPrivate).Return(editedRow{Err: pgx.ErrNoRows})
+			},
+			want: -1,
+			err:  errs.ErrVoteNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceRepo.EditPoll(context.Background(), 1, test.edit)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+			if test.log != nil {
+				checkLog(t, *written, test.log)
+			}
+		})
+	}
+}
+
+type editedRow struct {
+	version int64
+	locked  bool
+	Err     error
+}
+
+func (this editedRow) Scan(dest ...interface{}) error {
+	if this.Err != nil {
+		return this.Err
+	}
+	*dest[0].(*int64) = this.version
+	*dest[1].(*bool) = !this.locked
+	return nil
+}
+
+type logHeadRow struct {
+	seq  int64
+	hash string
+	Err  error
+}
+
+func (this logHeadRow) Scan(dest ...interface{}) error {
+	if this.Err != nil {
+		return this.Err
+	}
+	*dest[0].(*int64) = this.seq
+	*dest[1].(*string) = this.hash
+	return nil
+}
+
+// expectLog expects the entries of the vote to be appended after the head of
+// its log, the written rows are collected in the returned slice.
+func expectLog(tx *mocks.MockTx, voteId int, head logHeadRow) *[][]interface{} {
+	written := make([][]interface{}, 0)
+	tx.EXPECT().QueryRow(gomock.Any(), logHeadSql, voteId).Return(head)
+	tx.EXPECT().CopyFrom(gomock.Any(), pgx.Identifier{"ballot_log"}, logColumns, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, table pgx.Identifier, columns []string, source pgx.CopyFromSource) (int64, error) {
+			for source.Next() {
+				values, err := source.Values()
+				if err != nil {
+					return 0, err
+				}
+				written = append(written, values)
+			}
+			return int64(len(written)), nil
+		})
+	return &written
+}
+
+// checkLog checks that the written entries are chained and hashed by Digest
+// and match the wanted ones, which leave out the times, the hashes and the
+// previous hashes of all but the first entry.
+func checkLog(t *testing.T, written [][]interface{}, want []entity.LogEntry) []entity.LogEntry {
+	entries := make([]entity.LogEntry, 0, len(written))
+	got := make([]entity.LogEntry, 0, len(written))
+	for i, row := range written {
+		entry := entity.LogEntry{
+			VoteId:    row[0].(int),
+			Seq:       row[1].(int64),
+			Kind:      row[2].(string),
+			Replaces:  row[3].(int64),
+			Weight:    row[4].(int),
+			Choices:   row[5].([]string),
+			Scores:    row[6].([]int),
+			CreatedAt: row[7].(time.Time),
+			PrevHash:  row[8].(string),
+			Hash:      row[9].(string),
+		}
+		assert.Equal(t, entry.Digest(), entry.Hash)
+		stripped := entry
+		stripped.CreatedAt, stripped.Hash = time.Time{}, ""
+		if i > 0 {
+			assert.Equal(t, entries[i-1].Hash, entry.PrevHash)
+			stripped.PrevHash = ""
+		}
+		entries = append(entries, entry)
+		got = append(got, stripped)
+	}
+	assert.Equal(t, want, got)
+	return entries
+}
@@ -3,13 +3,21 @@ package psqlStorage
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	"github.com/VrMolodyakov/vote-service/internal/errs"
+	psql "github.com/VrMolodyakov/vote-service/pkg/client/postgresql"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// titleKey is the unique constraint of the vote titles.
+const titleKey = "vote_title_key"
+
 type voteRepository struct {
 	client PostgresClient
 	logger *logging.Logger
@@ -21,12 +29,11 @@ func NewVoteStorage(pool *pgxpool.Pool, logger *logging.Logger) *voteRepository
 
 func (v *voteRepository) Insert(ctx context.Context, vote string) (int, error) {
 	sql := `INSERT INTO vote(vote_title)
-			SELECT $1
-			WHERE NOT EXISTS (SELECT vote_id FROM vote WHERE vote_title=$2) RETURNING vote_id`
+			VALUES ($1) RETURNING vote_id`
 	var id int
-	err := v.client.QueryRow(ctx, sql, vote, vote).Scan(&id)
+	err := v.client.QueryRow(ctx, sql, vote).Scan(&id)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if psql.IsUniqueViolation(err, titleKey) {
 			return -1, errs.ErrTitleAlreadyExist
 		}
 		return -1, err
@@ -34,6 +41,37 @@ func (v *voteRepository) Insert(ctx context.Context, vote string) (int, error) {
 	return id, nil
 }
 
+func (v *voteRepository) InsertPoll(ctx context.Context, poll entity.Poll) (int, error) {
+	voteSql := `INSERT INTO vote(vote_title,min_selections,max_selections,method,seats,surplus_transfer,min_score,max_score,weighted,status,opens_at,closes_at,visibility,owner_id,allow_write_in,
+				quorum,quorum_percent,threshold,threshold_percent,abstention,count_abstentions,tie_break,tie_seed,ballot_privacy)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24)
+			RETURNING vote_id`
+	choiceSql := `INSERT INTO choice(choice_title,count,vote_id)
+			SELECT unnest($1::varchar[]),0,$2`
+	var id int
+	err := v.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, voteSql, poll.Title, poll.MinSelections, poll.MaxSelections, poll.CountingMethod(), poll.Seats, poll.Transfer, poll.MinScore, poll.MaxScore, poll.Weighted,
+			poll.InitialStatus(time.Now()), poll.OpensAt, poll.ClosesAt, poll.ResultVisibility(), poll.OwnerId, poll.AllowWriteIn,
+			poll.Rules.Quorum, poll.Rules.QuorumPercent, poll.Rules.Threshold, poll.Rules.ThresholdPercent, poll.Rules.Abstention, poll.Rules.CountAbstentions,
+			poll.TieBreaking(), poll.TieSeed, poll.BallotPrivacy()).Scan(&id)
+		if err != nil {
+			if psql.IsUniqueViolation(err, titleKey) {
+				return errs.ErrTitleAlreadyExist
+			}
+			return err
+		}
+		if _, err := tx.Exec(ctx, choiceSql, poll.Choices, id); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		return nil
+	})
+	if err != nil {
+		v.logger.Errorf("cannot create poll %v due to %v", poll.Title, err)
+		return -1, err
+	}
+	return id, nil
+}
+
 func (v *voteRepository) Find(ctx context.Context, title string) (int, error) {
 	sql := `SELECT vote_id FROM vote WHERE vote_title = $1`
 	var id int
@@ -47,6 +85,310 @@ func (v *voteRepository) Find(ctx context.Context, title string) (int, error) {
 	return id, nil
 }
 
+func (v *voteRepository) FindById(ctx context.Context, id int) (entity.Vote, error) {
+	sql := `SELECT vote_id,vote_title,min_selections,max_selections,COALESCE(final_ballots,ballots),method,seats,surplus_transfer,
+				min_score,max_score,weighted,status,opens_at,closes_at,closed_at,visibility,owner_id,allow_write_in,
+				quorum,quorum_percent,threshold,threshold_percent,abstention,count_abstentions,tie_break,tie_seed,tie_winner,ballot_privacy
+			FROM vote
+			WHERE vote_id = $1`
+	var vote entity.Vote
+	err := v.client.QueryRow(ctx, sql, id).Scan(
+		&vote.Id, &vote.Title, &vote.MinSelections, &vote.MaxSelections, &vote.Ballots, &vote.Method, &vote.Seats, &vote.Transfer,
+		&vote.MinScore, &vote.MaxScore, &vote.Weighted, &vote.Status, &vote.OpensAt, &vote.ClosesAt, &vote.ClosedAt,
+		&vote.Visibility, &vote.OwnerId, &vote.AllowWriteIn,
+		&vote.Rules.Quorum, &vote.Rules.QuorumPercent, &vote.Rules.Threshold, &vote.Rules.ThresholdPercent, &vote.Rules.Abstention, &vote.Rules.CountAbstentions,
+		&vote.TieBreak, &vote.TieSeed, &vote.TieWinner, &vote.Privacy)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return entity.Vote{}, errs.ErrVoteNotExist
+		}
+		return entity.Vote{}, err
+	}
+	return vote, nil
+}
+
+func (v *voteRepository) List(ctx context.Context, query entity.VoteQuery) ([]entity.Vote, error) {
+	args := []interface{}{likePattern(query.Prefix, false), likePattern(query.Title, true), query.Now}
+	sortColumn := "created_at"
+	if query.Sort == entity.SortByVotes {
+		sortColumn = "total"
+	}
+	direction, compare := "ASC", ">"
+	if query.Desc {
+		direction, compare = "DESC", "<"
+	}
+	keyset := ""
+	if query.After != nil {
+		var after interface{} = query.After.CreatedAt
+		if query.Sort == entity.SortByVotes {
+			after = query.After.Total
+		}
+		args = append(args, after, query.After.Id)
+		keyset = fmt.Sprintf("WHERE (%s, vote_id) %s ($4, $5)", sortColumn, compare)
+	}
+	args = append(args, query.Limit)
+	// the total of a vote whose results aren't public at $3 is 0, so neither
+	// the totals nor the order of the listing reveal them. The status is the
+	// one of Vote.StatusAt, a vote past its closes_at is closed even if the
+	// scheduler hasn't closed it yet.
+	sql := fmt.Sprintf(`SELECT vote_id,vote_title,created_at,total,visibility,status,opens_at,closes_at FROM (
+				SELECT vote_id,vote_title,created_at,visibility,status,opens_at,closes_at,
+					CASE WHEN visibility = 'always' OR visibility = 'after_close' AND current_status IN ('closed','archived')
+						THEN COALESCE(final_ballots,ballots) ELSE 0 END AS total
+				FROM (
+					SELECT *,
+						CASE WHEN status IN ('draft','open') AND (status = 'open' OR opens_at <= $3) AND closes_at <= $3 THEN 'closed'
+							WHEN status = 'draft' AND opens_at <= $3 THEN 'open'
+							ELSE status END AS current_status
+					FROM vote
+					WHERE vote_title ILIKE $1 AND vote_title ILIKE $2
+				) AS current
+			) AS votes
+			%s
+			ORDER BY %s %s, vote_id %s
+			LIMIT $%d`, keyset, sortColumn, direction, direction, len(args))
+	rows, err := v.client.Query(ctx, sql, args...)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		v.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	votes := make([]entity.Vote, 0, query.Limit)
+	for rows.Next() {
+		var vote entity.Vote
+		if err = rows.Scan(&vote.Id, &vote.Title, &vote.CreatedAt, &vote.Total, &vote.Visibility, &vote.Status, &vote.OpensAt, &vote.ClosesAt); err != nil {
+			v.logger.Error(err)
+			return nil, err
+		}
+		votes = append(votes, vote)
+	}
+	return votes, rows.Err()
+}
+
+// ReplaceWeights replaces the eligible voters of the vote with the weights in
+// one Tx, the ballots already cast keep their weights.
+func (v *voteRepository) ReplaceWeights(ctx context.Context, voteId int, weights []entity.VoterWeight) error {
+	deleteSql := `DELETE FROM voter_weight WHERE vote_id = $1`
+	insertSql := `INSERT INTO voter_weight(vote_id,voter_id,weight)
+			SELECT $1,d.voter_id,d.weight
+			FROM unnest($2::varchar[],$3::int[]) AS d(voter_id,weight)`
+	voterIds, values := make([]string, 0, len(weights)), make([]int, 0, len(weights))
+	for _, weight := range weights {
+		voterIds = append(voterIds, weight.VoterId)
+		values = append(values, weight.Weight)
+	}
+	err := v.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, deleteSql, voteId); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		if _, err := tx.Exec(ctx, insertSql, voteId, voterIds, values); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		return nil
+	})
+	if err != nil {
+		v.logger.Errorf("cannot replace weights of vote id = %v due to %v", voteId, err)
+		return err
+	}
+	return nil
+}
+
+// FindWeights returns the eligible voters of the vote ordered by voter id.
+func (v *voteRepository) FindWeights(ctx context.Context, voteId int) ([]entity.VoterWeight, error) {
+	sql := `SELECT voter_id,weight
+			FROM voter_weight
+			WHERE vote_id = $1
+			ORDER BY voter_id`
+	rows, err := v.client.Query(ctx, sql, voteId)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		v.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	weights := make([]entity.VoterWeight, 0)
+	for rows.Next() {
+		var weight entity.VoterWeight
+		if err = rows.Scan(&weight.VoterId, &weight.Weight); err != nil {
+			v.logger.Error(err)
+			return nil, err
+		}
+		weights = append(weights, weight)
+	}
+	return weights, rows.Err()
+}
+
+// OpenPoll opens the draft, opens_at is moved to the moment unless it has
+// already come. The new version of the results is returned.
+func (v *voteRepository) OpenPoll(ctx context.Context, id int, at time.Time) (int64, error) {
+	sql := `UPDATE vote
+			SET status = 'open', opens_at = LEAST(COALESCE(opens_at,$2),$2), version = version + 1
+			WHERE vote_id = $1 AND status = 'draft'
+			RETURNING version`
+	return v.transition(ctx, sql, id, at)
+}
+
+// ClosePoll closes the vote and freezes the number of ballots and the counts
+// of its choices in one Tx. A vote closed on schedule is closed at its closes_at.
+// The results change with the status, so the new version is returned.
+func (v *voteRepository) ClosePoll(ctx context.Context, id int, at time.Time) (int64, error) {
+	voteSql := `UPDATE vote
+			SET status = 'closed', closed_at = LEAST(COALESCE(closes_at,$2),$2), final_ballots = ballots, version = version + 1
+			WHERE vote_id = $1 AND status = 'open'
+			RETURNING version`
+	choiceSql := `UPDATE choice
+			SET final_count = count, final_voters = voters
+			WHERE vote_id = $1`
+	var version int64
+	err := v.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, voteSql, id, at).Scan(&version); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errs.ErrInvalidTransition
+			}
+			return psql.ErrExecuteQuery(err)
+		}
+		if _, err := tx.Exec(ctx, choiceSql, id); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return -1, v.transitionError(ctx, id, err)
+	}
+	return version, nil
+}
+
+// ReopenPoll opens the closed vote again with the new closes_at, the frozen
+// counts and the tie decided by the owner are dropped and the live counts
+// are reported again. The new version of the results is returned.
+func (v *voteRepository) ReopenPoll(ctx context.Context, id int, closesAt *time.Time) (int64, error) {
+	voteSql := `UPDATE vote
+			SET status = 'open', closes_at = $2, closed_at = NULL, final_ballots = NULL, tie_winner = '', version = version + 1
+			WHERE vote_id = $1 AND status = 'closed'
+			RETURNING version`
+	choiceSql := `UPDATE choice
+			SET final_count = NULL, final_voters = NULL
+			WHERE vote_id = $1`
+	var version int64
+	err := v.client.BeginTxFunc(ctx, pgx.TxOptions{IsoLevel: pgx.ReadCommitted}, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, voteSql, id, closesAt).Scan(&version); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errs.ErrInvalidTransition
+			}
+			return psql.ErrExecuteQuery(err)
+		}
+		if _, err := tx.Exec(ctx, choiceSql, id); err != nil {
+			return psql.ErrExecuteQuery(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return -1, v.transitionError(ctx, id, err)
+	}
+	return version, nil
+}
+
+// ArchivePoll archives the closed vote, it can't be reopened anymore.
+// The new version of the results is returned.
+func (v *voteRepository) ArchivePoll(ctx context.Context, id int) (int64, error) {
+	sql := `UPDATE vote
+			SET status = 'archived', version = version + 1
+			WHERE vote_id = $1 AND status = 'closed'
+			RETURNING version`
+	return v.transition(ctx, sql, id)
+}
+
+// OpenDue opens the drafts whose opens_at has come and returns their new
+// versions by their ids.
+func (v *voteRepository) OpenDue(ctx context.Context, now time.Time) (map[int]int64, error) {
+	sql := `UPDATE vote
+			SET status = 'open', version = version + 1
+			WHERE status = 'draft' AND opens_at <= $1
+			RETURNING vote_id,version`
+	rows, err := v.client.Query(ctx, sql, now)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		v.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	versions := make(map[int]int64)
+	for rows.Next() {
+		var id int
+		var version int64
+		if err = rows.Scan(&id, &version); err != nil {
+			v.logger.Error(err)
+			return nil, err
+		}
+		versions[id] = version
+	}
+	return versions, rows.Err()
+}
+
+// FindClosing returns the ids of the open votes whose closes_at has passed.
+func (v *voteRepository) FindClosing(ctx context.Context, now time.Time) ([]int, error) {
+	sql := `SELECT vote_id
+			FROM vote
+			WHERE status = 'open' AND closes_at <= $1
+			ORDER BY vote_id`
+	return v.findIds(ctx, sql, now)
+}
+
+func (v *voteRepository) findIds(ctx context.Context, sql string, args ...interface{}) ([]int, error) {
+	rows, err := v.client.Query(ctx, sql, args...)
+	if err != nil {
+		err = psql.ErrExecuteQuery(err)
+		v.logger.Error(err)
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			v.logger.Error(err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// transition moves the vote to another status with the single statement,
+// ErrInvalidTransition is returned if the vote isn't in the expected one.
+func (v *voteRepository) transition(ctx context.Context, sql string, id int, args ...interface{}) (int64, error) {
+	var version int64
+	err := v.client.QueryRow(ctx, sql, append([]interface{}{id}, args...)...).Scan(&version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return -1, v.transitionError(ctx, id, errs.ErrInvalidTransition)
+		}
+		return -1, v.transitionError(ctx, id, psql.ErrExecuteQuery(err))
+	}
+	return version, nil
+}
+
+// transitionError tells the missing vote from the one in a wrong status.
+func (v *voteRepository) transitionError(ctx context.Context, id int, err error) error {
+	if !errors.Is(err, errs.ErrInvalidTransition) {
+		v.logger.Errorf("cannot change status of vote id = %v due to %v", id, err)
+		return err
+	}
+	if _, findErr := v.FindById(ctx, id); findErr != nil {
+		return findErr
+	}
+	return err
+}
+
+func likePattern(s string, contains bool) string {
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+	if contains {
+		return "%" + s + "%"
+	}
+	return s + "%"
+}
+
 func (v *voteRepository) Delete(ctx context.Context, id string) error {
 	sql := `DELETE FROM VOTE
 			WHERE vote_id = $1`
@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/VrMolodyakov/vote-service/internal/adapter/db/psqlStorage/mocks"
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 	"github.com/driftprogramming/pgxpoolmock"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx"
+	pgxv4 "github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +47,7 @@ func TestInsertVote(t *testing.T) {
 		input   string
 		want    int
 		isError bool
+		err     error
 	}{
 		{
 			title: "Insert() should insert successfully",
@@ -53,6 +59,17 @@ func TestInsertVote(t *testing.T) {
 			want:    1,
 			isError: false,
 		},
+		{
+			title: "Insert() should return error if title already exist",
+			input: "test title",
+			mock: func() {
+				row := updateRow{0, &pgconn.PgError{Code: "23505", ConstraintName: titleKey}}
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), "test title").Return(row)
+			},
+			want:    -1,
+			isError: true,
+			err:     errs.ErrTitleAlreadyExist,
+		},
 		{
 			title: "Insert() should return error due to psql error",
 			input: "",
@@ -71,6 +88,9 @@ func TestInsertVote(t *testing.T) {
 			got, err := voteRepo.Insert(context.Background(), test.input)
 			if test.isError {
 				assert.Error(t, err)
+				if test.err != nil {
+					assert.Equal(t, test.err, err)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, test.want, got)
@@ -179,3 +199,479 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+type voteEntityRow struct {
+	Id    int
+	Title string
+	Err   error
+}
+
+func (this voteEntityRow) Scan(dest ...interface{}) error {
+	if this.Err != nil {
+		return this.Err
+	}
+	id := dest[0].(*int)
+	title := dest[1].(*string)
+	*id = this.Id
+	*title = this.Title
+	*dest[2].(*int) = 1
+	*dest[3].(*int) = 2
+	*dest[4].(*int) = 5
+	*dest[5].(*string) = entity.MethodPlurality
+	*dest[6].(*int) = 1
+	*dest[11].(*string) = entity.StatusOpen
+	*dest[18].(*int) = 10
+	*dest[20].(*string) = entity.ThresholdMajority
+	*dest[24].(*string) = entity.TieBreakRandom
+	*dest[25].(*int64) = 42
+	*dest[27].(*string) = entity.PrivacyAnonymous
+	return nil
+}
+
+func TestFindById(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+
+	type mockCall func()
+	tests := []struct {
+		title   string
+		mock    mockCall
+		input   int
+		want    entity.Vote
+		isError bool
+	}{
+		{
+			title: "FindById() should find successfully",
+			input: 1,
+			mock: func() {
+				row := voteEntityRow{1, "vote title", nil}
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), gomock.Any()).Return(row)
+			},
+			want: entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 2, Ballots: 5, Method: entity.MethodPlurality, Seats: 1, Status: entity.StatusOpen,
+				Rules: entity.Rules{Quorum: 10, Threshold: entity.ThresholdMajority}, TieBreak: entity.TieBreakRandom, TieSeed: 42, Privacy: entity.PrivacyAnonymous},
+			isError: false,
+		},
+		{
+			title: "FindById() shouldn't find and return error",
+			input: 1,
+			mock: func() {
+				row := voteEntityRow{0, "", pgxv4.ErrNoRows}
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), gomock.Any()).Return(row)
+			},
+			want:    entity.Vote{},
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := voteRepo.FindById(context.Background(), test.input)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestListVotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+	created := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(24 * time.Hour)
+	closesAt := created.Add(time.Hour)
+	var noTime *time.Time
+
+	type mockCall func()
+	tests := []struct {
+		title   string
+		mock    mockCall
+		input   entity.VoteQuery
+		want    []entity.Vote
+		isError bool
+	}{
+		{
+			title: "List() should return first page",
+			input: entity.VoteQuery{Sort: entity.SortByCreated, Desc: true, Limit: 2, Now: now},
+			mock: func() {
+				columns := []string{"vote_id", "vote_title", "created_at", "total", "visibility", "status", "opens_at", "closes_at"}
+				pgxRows := pgxpoolmock.NewRows(columns).
+					AddRow(2, "second", created, 3, entity.VisibilityAlways, entity.StatusOpen, noTime, noTime).
+					AddRow(1, "first", created, 4, entity.VisibilityAfterClose, entity.StatusOpen, noTime, &closesAt).
+					ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), "%", "%%", now, 2).Return(pgxRows, nil)
+			},
+			want: []entity.Vote{
+				{Id: 2, Title: "second", CreatedAt: created, Total: 3, Visibility: entity.VisibilityAlways, Status: entity.StatusOpen},
+				{Id: 1, Title: "first", CreatedAt: created, Total: 4, Visibility: entity.VisibilityAfterClose, Status: entity.StatusOpen, ClosesAt: &closesAt},
+			},
+			isError: false,
+		},
+		{
+			title: "List() should pass cursor and escaped filters",
+			input: entity.VoteQuery{
+				Title: "50%",
+				Sort:  entity.SortByVotes,
+				Limit: 2,
+				After: &entity.VoteCursor{Sort: entity.SortByVotes, Total: 3, Id: 2},
+				Now:   now,
+			},
+			mock: func() {
+				columns := []string{"vote_id", "vote_title", "created_at", "total", "visibility", "status", "opens_at", "closes_at"}
+				pgxRows := pgxpoolmock.NewRows(columns).ToPgxRows()
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), "%", `%50\%%`, now, 3, 2, 2).Return(pgxRows, nil)
+			},
+			want:    []entity.Vote{},
+			isError: false,
+		},
+		{
+			title: "List() should return error due to psql error",
+			input: entity.VoteQuery{Sort: entity.SortByCreated, Limit: 2},
+			mock: func() {
+				mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("psql error"))
+			},
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := voteRepo.List(context.Background(), test.input)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestInsertPoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+	choices := []string{"first", "second"}
+	poll := entity.Poll{Title: "vote", Choices: choices, MinSelections: 1, MaxSelections: 2, Seats: 1}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  int
+		err   error
+	}{
+		{
+			title: "InsertPoll() should insert vote and choices in one Tx",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, opts pgxv4.TxOptions, f func(pgxv4.Tx) error) error {
+						return f(tx)
+					})
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), "vote", 1, 2, entity.MethodPlurality, 1, "", 0, 0, false, entity.StatusOpen, (*time.Time)(nil), (*time.Time)(nil), entity.VisibilityAlways, "", false,
+					0, 0, "", 0, "", false, entity.TieBreakNone, int64(0), entity.PrivacyPrivate).Return(updateRow{1, nil})
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), choices, 1).Return(nil, nil)
+			},
+			want: 1,
+		},
+		{
+			title: "InsertPoll() should return error if title already exist",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, opts pgxv4.TxOptions, f func(pgxv4.Tx) error) error {
+						return f(tx)
+					})
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), "vote", 1, 2, entity.MethodPlurality, 1, "", 0, 0, false, entity.StatusOpen, (*time.Time)(nil), (*time.Time)(nil), entity.VisibilityAlways, "", false,
+					0, 0, "", 0, "", false, entity.TieBreakNone, int64(0), entity.PrivacyPrivate).Return(updateRow{0, &pgconn.PgError{Code: "23505", ConstraintName: titleKey}})
+			},
+			want: -1,
+			err:  errs.ErrTitleAlreadyExist,
+		},
+		{
+			title: "InsertPoll() should return error if choices couldn't be inserted",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, opts pgxv4.TxOptions, f func(pgxv4.Tx) error) error {
+						return f(tx)
+					})
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), "vote", 1, 2, entity.MethodPlurality, 1, "", 0, 0, false, entity.StatusOpen, (*time.Time)(nil), (*time.Time)(nil), entity.VisibilityAlways, "", false,
+					0, 0, "", 0, "", false, entity.TieBreakNone, int64(0), entity.PrivacyPrivate).Return(updateRow{1, nil})
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), choices, 1).Return(nil, errors.New("psql error"))
+			},
+			want: -1,
+			err:  errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := voteRepo.InsertPoll(context.Background(), poll)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestReplaceWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+	weights := []entity.VoterWeight{{VoterId: "ash", Weight: 100}, {VoterId: "misty", Weight: 20}}
+	inTx := func(tx pgxv4.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgxv4.TxOptions, f func(pgxv4.Tx) error) error {
+				return f(tx)
+			})
+	}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		err   error
+	}{
+		{
+			title: "ReplaceWeights() should delete the old weights and insert the new ones in one Tx",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1).Return(pgconn.CommandTag("DELETE 1"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, []string{"ash", "misty"}, []int{100, 20}).Return(pgconn.CommandTag("INSERT 0 2"), nil)
+			},
+		},
+		{
+			title: "ReplaceWeights() should return error if weights couldn't be inserted",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1).Return(pgconn.CommandTag("DELETE 0"), nil)
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1, gomock.Any(), gomock.Any()).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("failed to execute query due to psql error"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			err := voteRepo.ReplaceWeights(context.Background(), 1, weights)
+			if test.err != nil {
+				assert.EqualError(t, err, test.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFindVoterWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+
+	rows := pgxpoolmock.NewRows([]string{"voter_id", "weight"}).
+		AddRow("ash", 100).
+		AddRow("misty", 20).
+		ToPgxRows()
+	mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), 1).Return(rows, nil)
+	got, err := voteRepo.FindWeights(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.VoterWeight{{VoterId: "ash", Weight: 100}, {VoterId: "misty", Weight: 20}}, got)
+}
+
+func TestClosePoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+	at := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	inTx := func(tx pgxv4.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgxv4.TxOptions, f func(pgxv4.Tx) error) error {
+				return f(tx)
+			})
+	}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  int64
+		err   error
+	}{
+		{
+			title: "ClosePoll() should close the vote, freeze the counts and bump the version in one Tx",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, at).Return(versionRow{version: 8})
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1).Return(pgconn.CommandTag("UPDATE 2"), nil)
+			},
+			want: 8,
+		},
+		{
+			title: "ClosePoll() should return error if the vote isn't open",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, at).Return(versionRow{Err: pgxv4.ErrNoRows})
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(voteEntityRow{1, "vote title", nil})
+			},
+			want: -1,
+			err:  errs.ErrInvalidTransition,
+		},
+		{
+			title: "ClosePoll() should return error if the vote doesn't exist",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, at).Return(versionRow{Err: pgxv4.ErrNoRows})
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(voteEntityRow{0, "", pgxv4.ErrNoRows})
+			},
+			want: -1,
+			err:  errs.ErrVoteNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := voteRepo.ClosePoll(context.Background(), 1, at)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestReopenPoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+	closesAt := time.Date(2022, 5, 2, 12, 0, 0, 0, time.UTC)
+	inTx := func(tx pgxv4.Tx) {
+		mockPool.EXPECT().BeginTxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, opts pgxv4.TxOptions, f func(pgxv4.Tx) error) error {
+				return f(tx)
+			})
+	}
+
+	type mockCall func()
+	tests := []struct {
+		title string
+		mock  mockCall
+		want  int64
+		err   error
+	}{
+		{
+			title: "ReopenPoll() should reopen the vote, drop the frozen counts and bump the version in one Tx",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, &closesAt).Return(versionRow{version: 9})
+				tx.EXPECT().Exec(gomock.Any(), gomock.Any(), 1).Return(pgconn.CommandTag("UPDATE 2"), nil)
+			},
+			want: 9,
+		},
+		{
+			title: "ReopenPoll() should return error if the vote isn't closed",
+			mock: func() {
+				tx := mocks.NewMockTx(ctrl)
+				inTx(tx)
+				tx.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, &closesAt).Return(versionRow{Err: pgxv4.ErrNoRows})
+				mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(voteEntityRow{1, "vote title", nil})
+			},
+			want: -1,
+			err:  errs.ErrInvalidTransition,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := voteRepo.ReopenPoll(context.Background(), 1, &closesAt)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestArchivePoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(versionRow{version: 7})
+	version, err := voteRepo.ArchivePoll(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), version)
+
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(versionRow{Err: pgxv4.ErrNoRows})
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(voteEntityRow{1, "vote title", nil})
+	version, err = voteRepo.ArchivePoll(context.Background(), 1)
+	assert.Equal(t, errs.ErrInvalidTransition, err)
+	assert.Equal(t, int64(-1), version)
+}
+
+func TestOpenPoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, now).Return(versionRow{version: 2})
+	version, err := voteRepo.OpenPoll(context.Background(), 1, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1, now).Return(versionRow{Err: pgxv4.ErrNoRows})
+	mockPool.EXPECT().QueryRow(gomock.Any(), gomock.Any(), 1).Return(voteEntityRow{Err: pgxv4.ErrNoRows})
+	version, err = voteRepo.OpenPoll(context.Background(), 1, now)
+	assert.Equal(t, errs.ErrVoteNotExist, err)
+	assert.Equal(t, int64(-1), version)
+}
+
+func TestOpenDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	mockPool := pgxpoolmock.NewMockPgxPool(ctrl)
+	voteRepo := voteRepository{client: mockPool, logger: logger}
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	rows := pgxpoolmock.NewRows([]string{"vote_id", "version"}).AddRow(2, int64(1)).AddRow(5, int64(3)).ToPgxRows()
+	mockPool.EXPECT().Query(gomock.Any(), gomock.Any(), now).Return(rows, nil)
+	got, err := voteRepo.OpenDue(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int64{2: 1, 5: 3}, got)
+}
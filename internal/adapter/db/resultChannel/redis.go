@@ -0,0 +1,78 @@
+package resultChannel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/go-redis/redis"
+)
+
+const subscriberBuffer int = 64
+
+type resultChannel struct {
+	logger *logging.Logger
+	client *redis.Client
+}
+
+func NewResultChannel(client *redis.Client, logger *logging.Logger) *resultChannel {
+	return &resultChannel{logger: logger, client: client}
+}
+
+func (r *resultChannel) Publish(update entity.ChoiceUpdate) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	err = r.client.Publish(channel(update.VoteId), payload).Err()
+	if err != nil {
+		r.logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// Subscribe listens to the updates of the vote until ctx is done, the returned
+// channel is closed after that.
+func (r *resultChannel) Subscribe(ctx context.Context, voteId int) (<-chan entity.ChoiceUpdate, error) {
+	pubsub := r.client.Subscribe(channel(voteId))
+	if _, err := pubsub.Receive(); err != nil {
+		r.logger.Error(err)
+		pubsub.Close()
+		return nil, err
+	}
+	updates := make(chan entity.ChoiceUpdate, subscriberBuffer)
+	go func() {
+		defer close(updates)
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var update entity.ChoiceUpdate
+				if err := json.Unmarshal([]byte(msg.Payload), &update); err != nil {
+					r.logger.Errorf("cannot decode update %v due to %v", msg.Payload, err)
+					continue
+				}
+				select {
+				case updates <- update:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return updates, nil
+}
+
+func channel(voteId int) string {
+	return fmt.Sprintf("vote:%d:results", voteId)
+}
@@ -0,0 +1,71 @@
+package resultChannel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	redisServer *miniredis.Miniredis
+	redisClient *redis.Client
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	setUp()
+	defer teardown()
+	results := NewResultChannel(redisClient, logging.GetLogger("debug"))
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := results.Subscribe(ctx, 1)
+	assert.NoError(t, err)
+
+	want := entity.ChoiceUpdate{VoteId: 1, Choice: "choice", Count: 3}
+	assert.NoError(t, results.Publish(entity.ChoiceUpdate{VoteId: 2, Choice: "other", Count: 1}))
+	assert.NoError(t, results.Publish(want))
+	select {
+	case got := <-updates:
+		assert.Equal(t, want, got)
+	case <-time.After(time.Second):
+		t.Fatal("update wasn't received")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-updates:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("updates weren't closed after cancel")
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	setUp()
+	defer teardown()
+	results := NewResultChannel(redisClient, logging.GetLogger("debug"))
+	redisServer.SetError("interanl redis error")
+	err := results.Publish(entity.ChoiceUpdate{VoteId: 1, Choice: "choice", Count: 1})
+	assert.Error(t, err)
+	_, err = results.Subscribe(context.Background(), 1)
+	assert.Error(t, err)
+}
+
+func setUp() {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	redisServer = s
+	redisClient = redis.NewClient(&redis.Options{
+		Addr: redisServer.Addr(),
+	})
+}
+
+func teardown() {
+	redisServer.Close()
+}
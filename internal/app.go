@@ -4,21 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"syscall"
 	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/adapter/db/choiceCache"
+	"github.com/VrMolodyakov/vote-service/internal/adapter/db/idempotencyCache"
 	"github.com/VrMolodyakov/vote-service/internal/adapter/db/psqlStorage"
+	"github.com/VrMolodyakov/vote-service/internal/adapter/db/resultChannel"
 	"github.com/VrMolodyakov/vote-service/internal/config"
 	"github.com/VrMolodyakov/vote-service/internal/domain/service"
+	"github.com/VrMolodyakov/vote-service/internal/grpcHandler"
 	"github.com/VrMolodyakov/vote-service/internal/handler"
 	"github.com/VrMolodyakov/vote-service/pkg/client/postgresql"
 	"github.com/VrMolodyakov/vote-service/pkg/client/redis"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 	"github.com/VrMolodyakov/vote-service/pkg/shutdown"
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -62,26 +67,47 @@ func (a *app) startHttp() {
 
 	voteRepo := psqlStorage.NewVoteStorage(psqlClient, a.logger)
 	choiceRepo := psqlStorage.NewChoiceStorage(psqlClient, a.logger)
-	voteService := service.NewVoteService(voteRepo, a.logger)
 	choiceCache := choiceCache.NewChoiceCache(rdClient, a.logger)
 	cacheService := service.NewCahceService(choiceCache, a.logger)
-	choiceService := service.NewChoiceService(cacheService, voteService, choiceRepo, a.logger)
+	voteService := service.NewVoteService(voteRepo, cacheService, a.logger)
+	resultChannel := resultChannel.NewResultChannel(rdClient, a.logger)
+	if a.cfg.BallotKey == "" {
+		a.logger.Fatal("ballotkey is required to hash the voters of the anonymous votes")
+	}
+	choiceService := service.NewChoiceService(cacheService, voteService, choiceRepo, resultChannel, []byte(a.cfg.BallotKey), a.logger)
+	resultService := service.NewResultService(resultChannel, voteService, choiceService, a.logger)
+	idempotencyCache := idempotencyCache.NewIdempotencyCache(rdClient, a.logger)
+	idempotencyService := service.NewIdempotencyService(idempotencyCache, a.logger)
+	scheduler := service.NewScheduler(voteService, a.logger)
+	scheduler.Start()
 
 	a.router = mux.NewRouter()
+	// streams are long living responses, so the write timeout is applied
+	// to the rest of the api only
+	streamHandler := handler.NewStreamHandler(a.logger, resultService)
+	streamHandler.InitRoutes(a.router)
+	api := a.router.NewRoute().Subrouter()
+	api.Use(func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, writeTimeout, "")
+	})
+	api.Use(handler.NewIdempotencyHandler(a.logger, idempotencyService).Middleware)
 	handler := handler.NewVoteHandler(a.logger, voteService, choiceService)
-	handler.InitRoutes(a.router)
+	handler.InitRoutes(api)
+
+	grpcServer := grpc.NewServer()
+	grpcHandler.NewServer(a.logger, voteService, choiceService, resultService).Register(grpcServer)
+	go a.startGrpc(grpcServer)
 
 	//a.initializeRouters(choiceService, voteService)
 	a.logger.Info("start listening...")
 	port := fmt.Sprintf(":%s", a.cfg.Port)
 	server := &http.Server{
-		Addr:         port,
-		Handler:      a.router,
-		WriteTimeout: writeTimeout,
-		ReadTimeout:  readTimeout,
+		Addr:        port,
+		Handler:     a.router,
+		ReadTimeout: readTimeout,
 	}
 	a.checkErr(err)
-	go shutdown.Graceful([]os.Signal{syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGHUP, os.Interrupt, syscall.SIGTERM}, rdClient, server)
+	go shutdown.Graceful([]os.Signal{syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGHUP, os.Interrupt, syscall.SIGTERM}, scheduler, rdClient, grpcCloser{grpcServer}, server)
 	defer psqlClient.Close()
 	if err := server.ListenAndServe(); err != nil {
 		switch {
@@ -93,6 +119,26 @@ func (a *app) startHttp() {
 	}
 }
 
+func (a *app) startGrpc(server *grpc.Server) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", a.cfg.GrpcPort))
+	a.checkErr(err)
+	a.logger.Infof("start grpc listening on %v...", listener.Addr())
+	if err := server.Serve(listener); err != nil {
+		a.logger.Fatal(err)
+	}
+}
+
+// grpcCloser lets the grpc server be stopped by shutdown.Graceful,
+// open result streams are cancelled instead of being waited for
+type grpcCloser struct {
+	server *grpc.Server
+}
+
+func (g grpcCloser) Close() error {
+	g.server.Stop()
+	return nil
+}
+
 func (a *app) initializeRouters(choiceService handler.ChoiceService, voteService handler.VoteService) {
 	h := handler.NewVoteHandler(a.logger, voteService, choiceService)
 	a.router.HandleFunc("/api/vote", h.Create).Methods("POST")
@@ -12,11 +12,13 @@ import (
 )
 
 type Config struct {
-	Port       string  `yaml : "port"`
-	Host       string  `yaml : "host"`
-	LogLvl     string  `yaml : "loglvl"`
-	PostgreSql Postgre `yaml : "postgresql"`
-	Redis      Redis   `yaml : "redis"`
+	Port       string  `yaml:"port"`
+	GrpcPort   string  `yaml:"grpcport"`
+	Host       string  `yaml:"host"`
+	LogLvl     string  `yaml:"loglvl"`
+	PostgreSql Postgre `yaml:"postgresql"`
+	Redis      Redis   `yaml:"redis"`
+	BallotKey  string  `yaml:"ballotkey" env:"BALLOT_KEY"`
 }
 
 type Redis struct {
@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// Ballot is the selection of one voter. In a batch the vote is identified by
+// VoteId or, if it is zero, by VoteTitle. Scores are set for a score vote only
+// and hold the score of every selected choice in the order of Choices. Weight
+// is added to the count of every selected choice, it is 1 unless the vote is
+// a weighted one. The Choices of a WriteIn ballot are matched to the choices
+// of the vote by ChoiceKey and the unknown ones are added as write-ins.
+type Ballot struct {
+	VoteId    int
+	VoteTitle string
+	VoterId   string
+	Choices   []string
+	Scores    []int
+	Weight    int
+	WriteIn   bool
+	CreatedAt time.Time
+}
+
+// VoterWeight is the number of votes of an eligible voter of a weighted vote,
+// such as the share count or the size of the delegation.
+type VoterWeight struct {
+	VoterId string
+	Weight  int
+}
+
+// BallotResult is the outcome of the ballot with the same index in the batch.
+// Choices hold the total counts of the selected choices after the batch was
+// applied and Receipt is the hash of the log entry of the cast ballot.
+type BallotResult struct {
+	VoteId  int
+	Choices []Choice
+	Receipt string
+	Err     error
+}
+
+// ChoiceVoters lists the voters who have selected the choice of a public vote.
+type ChoiceVoters struct {
+	Choice   string
+	VoterIds []string
+}
@@ -1,7 +1,99 @@
 package entity
 
+import (
+	"math"
+	"strings"
+)
+
+// MaxWriteIns is the number of write-in choices a vote can have.
+const MaxWriteIns int = 50
+
+// Choice is one option of a vote, the Histogram is set for the choices of
+// a score vote. Count is the weighted total of the ballots and Voters is the
+// number of them, the two are the same unless the vote is a weighted one.
+// A WriteIn choice was added by a ballot. The Rank and the Percent of the
+// total are set in the results only.
 type Choice struct {
-	Title  string
-	VoteId int
-	Count  int
+	Title     string
+	VoteId    int
+	Count     int
+	Voters    int
+	WriteIn   bool
+	Histogram Histogram
+	Rank      int
+	Percent   float64
+}
+
+// NormalizeChoice trims the title and collapses the runs of whitespace in it,
+// a write-in is added with the normalised title.
+func NormalizeChoice(title string) string {
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// ChoiceKey returns the key the write-ins are matched by, the titles that
+// differ only in case and whitespace have the same key.
+func ChoiceKey(title string) string {
+	return strings.ToLower(NormalizeChoice(title))
+}
+
+// ScoreCount is the number of ballots that gave a choice the score.
+type ScoreCount struct {
+	Score int
+	Count int
+}
+
+// Histogram holds the scores of one choice in the ascending order, the
+// statistics are zero if nobody has scored the choice.
+type Histogram []ScoreCount
+
+func (h Histogram) total() (int, int) {
+	count, sum := 0, 0
+	for _, bucket := range h {
+		count += bucket.Count
+		sum += bucket.Score * bucket.Count
+	}
+	return count, sum
+}
+
+func (h Histogram) Mean() float64 {
+	count, sum := h.total()
+	if count == 0 {
+		return 0
+	}
+	return float64(sum) / float64(count)
+}
+
+// Median is the middle score, or the mean of the two middle ones if the
+// number of ballots is even.
+func (h Histogram) Median() float64 {
+	count, _ := h.total()
+	if count == 0 {
+		return 0
+	}
+	return float64(h.nth((count-1)/2)+h.nth(count/2)) / 2
+}
+
+// StdDev is the population standard deviation of the scores.
+func (h Histogram) StdDev() float64 {
+	count, _ := h.total()
+	if count == 0 {
+		return 0
+	}
+	mean, variance := h.Mean(), 0.0
+	for _, bucket := range h {
+		diff := float64(bucket.Score) - mean
+		variance += diff * diff * float64(bucket.Count)
+	}
+	return math.Sqrt(variance / float64(count))
+}
+
+// nth returns the score of the n-th ballot counting from 0 in the ascending order.
+func (h Histogram) nth(n int) int {
+	for _, bucket := range h {
+		if n < bucket.Count {
+			return bucket.Score
+		}
+		n -= bucket.Count
+	}
+	return 0
 }
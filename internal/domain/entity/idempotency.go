@@ -0,0 +1,12 @@
+package entity
+
+// IdempotentResponse is stored under an Idempotency-Key. Fingerprint identifies
+// the request the key was first used with, the response is filled when
+// the request is completed.
+type IdempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// The kinds of the ballot log entries. A cast entry records a new ballot,
+// a change entry the new selection of the ballot it replaces and a retract
+// entry the withdrawal of the ballot it replaces. The rename, remove and
+// merge entries record the edits of the choices that change the ballots.
+const (
+	LogCast    string = "cast"
+	LogChange         = "change"
+	LogRetract        = "retract"
+	LogRename         = "rename"
+	LogRemove         = "remove"
+	LogMerge          = "merge"
+)
+
+// LogEntry is one entry of the append-only ballot log of a vote. Seq numbers
+// the entries of the vote from 1 and PrevHash is the Hash of the entry before,
+// it is empty for the first one, so no entry can be changed or dropped
+// without breaking the hashes of the entries after it. Replaces is the Seq
+// of the ballot entry a change or retract entry replaces and Weight is the
+// weight of the ballot. Choices hold the selection of a ballot in the order
+// of preference and Scores their scores if the vote is a score one, a rename
+// and a merge entry hold the old and the new title and a remove entry the
+// removed titles. The entries never hold the voter.
+type LogEntry struct {
+	VoteId    int
+	Seq       int64
+	Kind      string
+	Replaces  int64
+	Weight    int
+	Choices   []string
+	Scores    []int
+	CreatedAt time.Time
+	PrevHash  string
+	Hash      string
+}
+
+// Digest returns the hex SHA-256 hash of the entry. The fields are hashed as
+// netstrings ("<length>:<bytes>,") in the order PrevHash, VoteId, Seq, Kind,
+// Replaces, Weight and CreatedAt in Unix microseconds, followed by every
+// choice and its score, which is empty without scores.
+func (e LogEntry) Digest() string {
+	h := sha256.New()
+	field := func(s string) {
+		h.Write([]byte(strconv.Itoa(len(s)) + ":" + s + ","))
+	}
+	field(e.PrevHash)
+	field(strconv.Itoa(e.VoteId))
+	field(strconv.FormatInt(e.Seq, 10))
+	field(e.Kind)
+	field(strconv.FormatInt(e.Replaces, 10))
+	field(strconv.Itoa(e.Weight))
+	field(strconv.FormatInt(e.CreatedAt.UnixMicro(), 10))
+	for i, choice := range e.Choices {
+		field(choice)
+		score := ""
+		if i < len(e.Scores) {
+			score = strconv.Itoa(e.Scores[i])
+		}
+		field(score)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Receipt is the log entry of a receipt, ReplacedBy is the Seq of the entry
+// that has replaced it or 0 if none has.
+type Receipt struct {
+	Entry      LogEntry
+	ReplacedBy int64
+}
+
+// Counted reports whether the ballot of the receipt is counted, which it is
+// until it is changed or retracted.
+func (r Receipt) Counted() bool {
+	return (r.Entry.Kind == LogCast || r.Entry.Kind == LogChange) && r.ReplacedBy == 0
+}
+
+// LogPage is a page of the ballot log, Next is the Seq to read the next page
+// after or 0 if it is the last one.
+type LogPage struct {
+	Entries []LogEntry
+	Next    int64
+}
@@ -0,0 +1,65 @@
+package entity
+
+// Passing thresholds of a poll with rules. The leading choice of a plurality
+// poll passes if it is ahead of the others, a majority needs more than a half
+// of the counted votes, two thirds and a custom percent need at least that
+// share of them.
+const (
+	ThresholdPlurality string = "plurality"
+	ThresholdMajority         = "majority"
+	ThresholdTwoThirds        = "two_thirds"
+	ThresholdPercent          = "percent"
+)
+
+// Outcomes of a poll with rules.
+const (
+	OutcomePassed   string = "passed"
+	OutcomeFailed          = "failed"
+	OutcomeNoQuorum        = "no_quorum"
+	OutcomeTied            = "tied"
+)
+
+// Rules turn the counts of a single choice poll into an outcome. The poll
+// is quorate if it has at least Quorum ballots and the ballots cast at least
+// QuorumPercent of the total weight of its eligible voters, only a weighted
+// poll has the list of them. ThresholdPercent is set for the percent
+// threshold only. The Abstention choice never passes, its votes count
+// towards the quorum and towards the share of the leading choice only if
+// CountAbstentions is set.
+type Rules struct {
+	Quorum           int
+	QuorumPercent    int
+	Threshold        string
+	ThresholdPercent int
+	Abstention       string
+	CountAbstentions bool
+}
+
+// Empty reports whether the poll has no rules and therefore no outcome.
+func (r Rules) Empty() bool {
+	return r == Rules{}
+}
+
+// PassingThreshold returns the threshold of the rules, plurality if it is
+// omitted.
+func (r Rules) PassingThreshold() string {
+	if r.Threshold == "" {
+		return ThresholdPlurality
+	}
+	return r.Threshold
+}
+
+// Outcome is the result of a poll with rules. Choice is the leading choice
+// that has passed or failed, Votes are its votes and Share is their percent
+// of the Counted votes. Turnout is the percent of the eligible weight that
+// has voted, it is computed for the polls with QuorumPercent only.
+type Outcome struct {
+	Result      string
+	Choice      string
+	Votes       int
+	Counted     int
+	Share       float64
+	Abstentions int
+	Ballots     int
+	Turnout     float64
+}
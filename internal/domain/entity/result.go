@@ -0,0 +1,56 @@
+package entity
+
+import "time"
+
+const (
+	SnapshotEvent  string = "snapshot"
+	DeltaEvent            = "delta"
+	HeartbeatEvent        = "heartbeat"
+)
+
+// ChoiceUpdate is published every time a choice count is changed in the
+// storage, Count is the new total of the choice and Version is the version
+// of the vote results after the change.
+type ChoiceUpdate struct {
+	VoteId  int    `json:"vote_id"`
+	Choice  string `json:"choice"`
+	Count   int    `json:"count"`
+	Version int64  `json:"version"`
+}
+
+// ResultEvent is one frame of a result stream. Snapshot events carry all
+// choices of the vote, delta events only the changed ones and heartbeat
+// events none.
+type ResultEvent struct {
+	Kind    string
+	VoteId  int
+	Choices []Choice
+	Time    time.Time
+}
+
+// ResultQuery addresses the vote by VoteId or, without one, by VoteTitle.
+// The choices are sorted by votes (the default) or title in the Order, asc or
+// desc, the most voted choice and the titles from A to Z come first unless
+// it is set. Only the Top choices are kept if it is set.
+type ResultQuery struct {
+	VoteId    int
+	VoteTitle string
+	Sort      string
+	Order     string
+	Top       int
+}
+
+// Result is the vote with its ranked choices. Total is the sum of the choice
+// counts the percents are computed of. Tied are the choices that share the
+// lead, the Winner is the only leader or the one picked by the TieBreak
+// strategy of the vote. A ranked vote is won by its tally, so its result
+// has neither of them.
+type Result struct {
+	Vote     Vote
+	Ballots  int
+	Total    int
+	Choices  []Choice
+	Winner   string
+	Tied     []string
+	TieBreak string
+}
@@ -0,0 +1,65 @@
+package entity
+
+// Tally is the outcome of a ranked vote counted by Method. Winners hold
+// several choices if they are tied and none if nobody has voted, the
+// winners of a single transferable vote are the elected choices in the order
+// of election.
+type Tally struct {
+	Method    string
+	Ballots   int
+	Winners   []string
+	Condorcet string
+	Scores    []Score
+	Rounds    []Round
+	Seats     int
+	Quota     int
+	Stages    []Stage
+}
+
+// Score is the number of votes, points or pairwise wins of a choice
+// depending on the counting method.
+type Score struct {
+	Choice string
+	Score  int
+}
+
+// Round is one instant-runoff round. Votes hold the votes of the choices
+// still in the count, Exhausted is the number of ballots that don't rank
+// any of them and Eliminated are the choices dropped after the round.
+type Round struct {
+	Number     int
+	Votes      []Score
+	Exhausted  int
+	Eliminated []string
+}
+
+// Stage is one stage of the count sheet of a single transferable vote.
+// Votes are the votes of the choices that aren't excluded at the start of
+// the stage, the choices that reach the quota are elected and then either
+// a surplus is transferred or a choice is excluded.
+type Stage struct {
+	Number    int
+	Votes     []Share
+	Exhausted float64
+	Elected   []string
+	Excluded  []string
+	Transfer  *Transfer
+}
+
+// Share is the number of votes of a choice, a transferred ballot counts
+// for a fraction of a vote.
+type Share struct {
+	Choice string
+	Votes  float64
+}
+
+// Transfer moves the ballots of an elected or excluded choice to the next
+// preferences, Votes is the total that is moved. Value is the fraction of
+// its value a ballot of the surplus carries on, the ballots of an excluded
+// choice are moved at their current value.
+type Transfer struct {
+	From    string
+	Surplus bool
+	Value   float64
+	Votes   float64
+}
@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// The buckets the timeline of a vote can be grouped by.
+const (
+	BucketMinute string = "1m"
+	BucketHour          = "1h"
+	BucketDay           = "1d"
+)
+
+// Buckets are the lengths of the timeline buckets.
+var Buckets = map[string]time.Duration{
+	BucketMinute: time.Minute,
+	BucketHour:   time.Hour,
+	BucketDay:    24 * time.Hour,
+}
+
+// TimelineQuery addresses the timeline of the vote grouped by the Bucket,
+// the ballot log is replayed up to AsOf, now if it isn't set.
+type TimelineQuery struct {
+	VoteId int
+	Bucket string
+	AsOf   time.Time
+}
+
+// Timeline is the history of the counts of a vote replayed from its ballot
+// log. Choices hold the counts and Ballots the number of the counted ballots
+// at AsOf. The Buckets are in the order of time, the buckets without entries
+// of the log are left out.
+type Timeline struct {
+	Bucket  string
+	AsOf    time.Time
+	Ballots int
+	Choices []Choice
+	Buckets []TimelineBucket
+}
+
+// TimelineBucket holds the changes of the counts from Start to the start of
+// the next bucket. Cast is the number of the ballots cast in the bucket and
+// Ballots the number of the counted ballots at its end. Leader is the only
+// choice with the most votes at the end of the bucket, it is empty if the
+// lead is tied.
+type TimelineBucket struct {
+	Start   time.Time
+	Cast    int
+	Ballots int
+	Leader  string
+	Counts  []TimelineCount
+}
+
+// TimelineCount is the Change of the count of the choice in a bucket and
+// its Total at the end of the bucket.
+type TimelineCount struct {
+	Choice string
+	Change int
+	Total  int
+}
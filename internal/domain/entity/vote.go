@@ -1,6 +1,312 @@
 package entity
 
+import "time"
+
+const (
+	SortByCreated string = "created"
+	SortByVotes   string = "votes"
+	SortByTitle   string = "title"
+)
+
+// Counting methods of a poll. A plurality poll counts every selected choice,
+// a score poll rates every choice on its scale, the other methods are ranked:
+// the ballot orders the choices by preference. A single transferable vote
+// fills several seats.
+const (
+	MethodPlurality string = "plurality"
+	MethodIrv              = "irv"
+	MethodBorda            = "borda"
+	MethodSchulze          = "schulze"
+	MethodStv              = "stv"
+	MethodScore            = "score"
+)
+
+// defaultMaxScore is the top of the scale of a score poll without one.
+const defaultMaxScore int = 10
+
+// Surplus transfer rules of a single transferable vote. Gregory moves all
+// ballots of the elected choice at a fraction of their value, Hare moves
+// the last received ballots whole.
+const (
+	TransferGregory string = "gregory"
+	TransferHare           = "hare"
+)
+
+// Lifecycle of a poll. A draft doesn't accept ballots until it is opened by
+// hand or its opens_at comes, a closed poll keeps its final counts until it
+// is reopened and an archived one is closed for good.
+const (
+	StatusDraft    string = "draft"
+	StatusOpen            = "open"
+	StatusClosed          = "closed"
+	StatusArchived        = "archived"
+)
+
+// Visibility policies of the results. The results of an after_vote poll are
+// shown to the voters who have voted, the ones of an after_close poll are
+// hidden until it is closed. The owner of the poll always sees them.
+const (
+	VisibilityAlways     string = "always"
+	VisibilityAfterVote         = "after_vote"
+	VisibilityAfterClose        = "after_close"
+)
+
+// Tie-break strategies of the choices tied for the lead of a plurality or
+// score poll. The tie of a none poll stays unbroken, the earliest choice is
+// the one whose last vote was cast first, a random poll picks the winner with
+// its published seed and the owner of an owner poll decides it after close.
+const (
+	TieBreakNone     string = "none"
+	TieBreakEarliest        = "earliest"
+	TieBreakRandom          = "random"
+	TieBreakOwner           = "owner"
+)
+
+// Ballot privacy modes of a poll. The voters of a private poll are stored
+// with their ballots and shown to nobody, the ones of a public poll are listed
+// per choice. An anonymous poll stores only a keyed hash of the voter, so its
+// ballots can't be linked to the voters even with the database at hand.
+const (
+	PrivacyPrivate   string = "private"
+	PrivacyPublic           = "public"
+	PrivacyAnonymous        = "anonymous"
+)
+
+// Ranked reports whether the ballots of the method are preference orders.
+func Ranked(method string) bool {
+	switch method {
+	case MethodIrv, MethodBorda, MethodSchulze, MethodStv:
+		return true
+	}
+	return false
+}
+
 type Vote struct {
-	Title string
-	Id    int
+	Title         string
+	Id            int
+	CreatedAt     time.Time
+	Total         int
+	MinSelections int
+	MaxSelections int
+	Ballots       int
+	Method        string
+	Seats         int
+	Transfer      string
+	MinScore      int
+	MaxScore      int
+	Weighted      bool
+	Status        string
+	OpensAt       *time.Time
+	ClosesAt      *time.Time
+	ClosedAt      *time.Time
+	Visibility    string
+	OwnerId       string
+	AllowWriteIn  bool
+	Rules         Rules
+	TieBreak      string
+	TieSeed       int64
+	TieWinner     string
+	Privacy       string
+}
+
+// StatusAt returns the status of the vote at the moment with the schedule
+// applied, the scheduler moves the stored status a bit later.
+func (v Vote) StatusAt(now time.Time) string {
+	status := v.Status
+	if status == StatusDraft && v.OpensAt != nil && !now.Before(*v.OpensAt) {
+		status = StatusOpen
+	}
+	if status == StatusOpen && v.ClosesAt != nil && !now.Before(*v.ClosesAt) {
+		status = StatusClosed
+	}
+	return status
+}
+
+// PublicResults reports whether the results of the vote are visible to
+// everyone at the moment.
+func (v Vote) PublicResults(now time.Time) bool {
+	switch v.Visibility {
+	case VisibilityAfterVote:
+		return false
+	case VisibilityAfterClose:
+		status := v.StatusAt(now)
+		return status == StatusClosed || status == StatusArchived
+	}
+	return true
+}
+
+// Poll is the definition of a new vote. A ballot selects from MinSelections
+// to MaxSelections choices, a poll without the limits is a single choice one.
+// The ballots of a ranked poll list the choices in the order of preference.
+// Only a single transferable vote has several Seats and the surplus Transfer rule,
+// only a score poll has the scale from MinScore to MaxScore. A ballot of
+// a Weighted poll counts with the weight of its voter. A Draft poll or the one
+// with OpensAt in the future is created closed for ballots, the poll stops
+// accepting them at ClosesAt. Visibility is the policy of the results,
+// the OwnerId voter always sees them. The ballots of an AllowWriteIn poll may
+// select the choices the poll doesn't have yet, they are added as write-ins.
+// The Rules of a single choice plurality poll compute its outcome. TieBreak
+// is the strategy the tie for the lead is broken with, TieSeed is the seed
+// of a random one. Privacy is the ballot privacy mode of the poll.
+type Poll struct {
+	Title         string
+	Choices       []string
+	MinSelections int
+	MaxSelections int
+	Method        string
+	Seats         int
+	Transfer      string
+	MinScore      int
+	MaxScore      int
+	Weighted      bool
+	Draft         bool
+	OpensAt       *time.Time
+	ClosesAt      *time.Time
+	Visibility    string
+	OwnerId       string
+	AllowWriteIn  bool
+	Rules         Rules
+	TieBreak      string
+	TieSeed       int64
+	Privacy       string
+}
+
+// InitialStatus returns the status the poll is created with.
+func (p Poll) InitialStatus(now time.Time) string {
+	if p.Draft || p.OpensAt != nil && now.Before(*p.OpensAt) {
+		return StatusDraft
+	}
+	return StatusOpen
+}
+
+// ResultVisibility returns the visibility policy of the poll, the results
+// are always visible by default.
+func (p Poll) ResultVisibility() string {
+	if p.Visibility == "" {
+		return VisibilityAlways
+	}
+	return p.Visibility
+}
+
+// TieBreaking returns the tie-break strategy of the poll, the ties are left
+// unbroken by default.
+func (p Poll) TieBreaking() string {
+	if p.TieBreak == "" {
+		return TieBreakNone
+	}
+	return p.TieBreak
+}
+
+// BallotPrivacy returns the ballot privacy mode of the poll, the ballots
+// are private by default.
+func (p Poll) BallotPrivacy() string {
+	if p.Privacy == "" {
+		return PrivacyPrivate
+	}
+	return p.Privacy
+}
+
+// Selections returns the selection limits of the poll with the omitted
+// ones filled in. A ranked poll may rank all of its choices by default,
+// a ballot of a score poll scores all of them.
+func (p Poll) Selections() (int, int) {
+	min, max := p.MinSelections, p.MaxSelections
+	if p.Method == MethodScore && min == 0 && max == 0 {
+		return len(p.Choices), len(p.Choices)
+	}
+	if min == 0 {
+		min = 1
+	}
+	if max == 0 {
+		max = min
+		if Ranked(p.Method) && len(p.Choices) > max {
+			max = len(p.Choices)
+		}
+	}
+	return min, max
+}
+
+// CountingMethod returns the method of the poll, plurality if it is omitted.
+func (p Poll) CountingMethod() string {
+	if p.Method == "" {
+		return MethodPlurality
+	}
+	return p.Method
+}
+
+// Election returns the number of seats and the surplus transfer rule of the
+// poll with the omitted ones filled in, the rule is empty unless the poll
+// is a single transferable vote.
+func (p Poll) Election() (int, string) {
+	seats, transfer := p.Seats, p.Transfer
+	if seats == 0 {
+		seats = 1
+	}
+	if p.CountingMethod() == MethodStv && transfer == "" {
+		transfer = TransferGregory
+	}
+	return seats, transfer
+}
+
+// Scale returns the lowest and the highest score of the poll, a score poll
+// rates from 0 to 10 by default.
+func (p Poll) Scale() (int, int) {
+	min, max := p.MinScore, p.MaxScore
+	if p.Method == MethodScore && max == 0 {
+		max = defaultMaxScore
+	}
+	return min, max
+}
+
+// PollEdit changes the title and the choices of an existing vote, an empty
+// Title keeps the old one. The choices are removed first, then renamed from
+// the keys to the values of Rename and then added. Removing a choice that
+// has votes takes Force, its ballots keep the rest of their choices.
+// Privacy is the ballot privacy mode after the edit, it can only change
+// before the vote gets its first ballot.
+// MinSelections and MaxSelections are the limits the vote has after the edit
+// and Abstention is the abstention choice of its rules.
+type PollEdit struct {
+	Title         string
+	Add           []string
+	Rename        map[string]string
+	Remove        []string
+	Force         bool
+	MinSelections int
+	MaxSelections int
+	Abstention    string
+	Privacy       string
+}
+
+// Empty reports whether the edit changes nothing.
+func (e PollEdit) Empty() bool {
+	return e.Title == "" && len(e.Add) == 0 && len(e.Rename) == 0 && len(e.Remove) == 0 && e.Privacy == ""
+}
+
+// VoteQuery describes one page of the vote listing. Title and Prefix filter
+// by substring and prefix of the vote title, After is the keyset cursor of the
+// last vote of the previous page.
+type VoteQuery struct {
+	Title  string
+	Prefix string
+	Sort   string
+	Desc   bool
+	Limit  int
+	After  *VoteCursor
+	// Now is the moment the status and the visibility of the results are
+	// evaluated at.
+	Now time.Time
+}
+
+type VoteCursor struct {
+	Sort      string    `json:"s"`
+	Desc      bool      `json:"d"`
+	CreatedAt time.Time `json:"c"`
+	Total     int       `json:"t"`
+	Id        int       `json:"i"`
+}
+
+type VotePage struct {
+	Votes []Vote
+	Next  *VoteCursor
 }
@@ -8,8 +8,12 @@ import (
 )
 
 type RedisCache interface {
-	Set(voteTitle string, choiceTitle string, count int, expireAt time.Duration) error
-	Get(voteTitle string, choiceTitle string) (int, error)
+	Set(voteId int, choiceTitle string, count int, expireAt time.Duration) error
+	Get(voteId int, choiceTitle string) (int, error)
+	SetVersion(voteId int, version int64, expireAt time.Duration) error
+	GetVersion(voteId int) (int64, error)
+	RenameChoice(voteId int, choiceTitle string, newTitle string) error
+	Delete(voteId int, choiceTitles ...string) error
 }
 
 type cacheService struct {
@@ -21,22 +25,56 @@ func NewCahceService(cache RedisCache, logger *logging.Logger) *cacheService {
 	return &cacheService{logger: logger, cache: cache}
 }
 
-func (c *cacheService) Save(voteTitle string, choiceTitle string, count int, expireAt time.Duration) error {
-	if voteTitle == "" {
-		return errs.ErrEmptyVoteTitle
+func (c *cacheService) Save(voteId int, choiceTitle string, count int, expireAt time.Duration) error {
+	if voteId <= 0 {
+		return errs.ErrInvalidVoteId
 	}
 	if choiceTitle == "" {
 		return errs.ErrEmptyChoiceTitle
 	}
-	return c.cache.Set(voteTitle, choiceTitle, count, expireAt)
+	return c.cache.Set(voteId, choiceTitle, count, expireAt)
 }
 
-func (c *cacheService) Get(voteTitle string, choiceTitle string) (int, error) {
-	if voteTitle == "" {
-		return -1, errs.ErrEmptyVoteTitle
+func (c *cacheService) Get(voteId int, choiceTitle string) (int, error) {
+	if voteId <= 0 {
+		return -1, errs.ErrInvalidVoteId
 	}
 	if choiceTitle == "" {
 		return -1, errs.ErrEmptyChoiceTitle
 	}
-	return c.cache.Get(voteTitle, choiceTitle)
+	return c.cache.Get(voteId, choiceTitle)
+}
+
+func (c *cacheService) SaveVersion(voteId int, version int64, expireAt time.Duration) error {
+	if voteId <= 0 {
+		return errs.ErrInvalidVoteId
+	}
+	return c.cache.SetVersion(voteId, version, expireAt)
+}
+
+func (c *cacheService) GetVersion(voteId int) (int64, error) {
+	if voteId <= 0 {
+		return -1, errs.ErrInvalidVoteId
+	}
+	return c.cache.GetVersion(voteId)
+}
+
+func (c *cacheService) RenameChoice(voteId int, choiceTitle string, newTitle string) error {
+	if voteId <= 0 {
+		return errs.ErrInvalidVoteId
+	}
+	if choiceTitle == "" || newTitle == "" {
+		return errs.ErrEmptyChoiceTitle
+	}
+	return c.cache.RenameChoice(voteId, choiceTitle, newTitle)
+}
+
+func (c *cacheService) DeleteChoices(voteId int, choiceTitles []string) error {
+	if voteId <= 0 {
+		return errs.ErrInvalidVoteId
+	}
+	if len(choiceTitles) == 0 {
+		return nil
+	}
+	return c.cache.Delete(voteId, choiceTitles...)
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/domain/service/mocks"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +18,7 @@ func TestSave(t *testing.T) {
 	defer ctrl.Finish()
 	type mock func() *cacheService
 	type args struct {
-		voteTitle   string
+		voteId      int
 		choiceTitle string
 		count       int
 		expireAt    time.Duration
@@ -39,7 +40,7 @@ func TestSave(t *testing.T) {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"voteTitle", "choiceTitle", 1, time.Minute},
+			input:   args{1, "choiceTitle", 1, time.Minute},
 			isError: false,
 		},
 		{
@@ -53,16 +54,16 @@ func TestSave(t *testing.T) {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"voteTitle", "choiceTitle", 1, time.Minute},
+			input:   args{1, "choiceTitle", 1, time.Minute},
 			isError: true,
 		},
 		{
-			title: "wrong vote titlle and save should return error",
+			title: "wrong vote id and save should return error",
 			mockCall: func() *cacheService {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"", "choiceTitle", 1, time.Minute},
+			input:   args{0, "choiceTitle", 1, time.Minute},
 			isError: true,
 		},
 		{
@@ -71,14 +72,14 @@ func TestSave(t *testing.T) {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"vote title", "", 1, time.Minute},
+			input:   args{1, "", 1, time.Minute},
 			isError: true,
 		},
 	}
 	for _, test := range testCases {
 		t.Run(test.title, func(t *testing.T) {
 			cacheService := test.mockCall()
-			err := cacheService.Save(test.input.voteTitle,
+			err := cacheService.Save(test.input.voteId,
 				test.input.choiceTitle,
 				test.input.count,
 				test.input.expireAt)
@@ -98,7 +99,7 @@ func TestGet(t *testing.T) {
 	defer ctrl.Finish()
 	type mock func() *cacheService
 	type args struct {
-		voteTitle   string
+		voteId      int
 		choiceTitle string
 	}
 	testCases := []struct {
@@ -117,7 +118,7 @@ func TestGet(t *testing.T) {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"voteTitle", "choiceTitle"},
+			input:   args{1, "choiceTitle"},
 			want:    1,
 			isError: false,
 		},
@@ -130,17 +131,17 @@ func TestGet(t *testing.T) {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"voteTitle", "choiceTitle"},
+			input:   args{1, "choiceTitle"},
 			want:    -1,
 			isError: true,
 		},
 		{
-			title: "wrong vote titlle and Get should return error",
+			title: "wrong vote id and Get should return error",
 			mockCall: func() *cacheService {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"", "choiceTitle"},
+			input:   args{0, "choiceTitle"},
 			want:    -1,
 			isError: true,
 		},
@@ -150,7 +151,7 @@ func TestGet(t *testing.T) {
 				logger := logging.GetLogger("debug")
 				return NewCahceService(mockedRedis, logger)
 			},
-			input:   args{"vote title", ""},
+			input:   args{1, ""},
 			want:    -1,
 			isError: true,
 		},
@@ -158,7 +159,7 @@ func TestGet(t *testing.T) {
 	for _, test := range testCases {
 		t.Run(test.title, func(t *testing.T) {
 			cacheService := test.mockCall()
-			got, err := cacheService.Get(test.input.voteTitle,
+			got, err := cacheService.Get(test.input.voteId,
 				test.input.choiceTitle)
 			if !test.isError {
 				assert.NoError(t, err)
@@ -171,3 +172,21 @@ func TestGet(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockedRedis := mocks.NewMockRedisCache(ctrl)
+	defer ctrl.Finish()
+	cacheService := NewCahceService(mockedRedis, logging.GetLogger("debug"))
+
+	mockedRedis.EXPECT().SetVersion(1, int64(3), time.Minute).Return(nil)
+	assert.NoError(t, cacheService.SaveVersion(1, 3, time.Minute))
+	mockedRedis.EXPECT().GetVersion(1).Return(int64(3), nil)
+	got, err := cacheService.GetVersion(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), got)
+
+	assert.Equal(t, errs.ErrInvalidVoteId, cacheService.SaveVersion(0, 3, time.Minute))
+	_, err = cacheService.GetVersion(0)
+	assert.Equal(t, errs.ErrInvalidVoteId, err)
+}
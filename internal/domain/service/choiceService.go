@@ -2,109 +2,1116 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/domain/tally"
 	"github.com/VrMolodyakov/vote-service/internal/errs"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 )
 
 const (
-	expire        time.Duration = 5 * time.Minute
-	updateTimeout               = 1 * time.Minute
+	expire     time.Duration = 5 * time.Minute
+	maxBatch   int           = 1000
+	maxVoterId int           = 200
+	maxWriteIn int           = 200
+	// a page of the ballot log is larger than a page of votes, as the log
+	// is downloaded to recount the vote
+	defaultLogPage int = 100
+	maxLogPage         = 1000
+	receiptLength      = 64
 )
 
 type CacheService interface {
-	Save(voteTitle string, choiceTitle string, count int, expireAt time.Duration) error
-	Get(voteTitle string, choiceTitle string) (int, error)
+	Save(voteId int, choiceTitle string, count int, expireAt time.Duration) error
+	SaveVersion(voteId int, version int64, expireAt time.Duration) error
+	GetVersion(voteId int) (int64, error)
+	RenameChoice(voteId int, choiceTitle string, newTitle string) error
+	DeleteChoices(voteId int, choiceTitles []string) error
 }
 
 type VoteService interface {
 	Create(ctx context.Context, title string) (int, error)
 	Get(ctx context.Context, title string) (int, error)
+	GetById(ctx context.Context, id int) (entity.Vote, error)
 }
 
 type СhoiceRepository interface {
 	Insert(ctx context.Context, choice entity.Choice) (string, error)
 	FindChoices(ctx context.Context, id int) ([]entity.Choice, error)
 	FindChoice(ctx context.Context, id int, choiceTitle string) (entity.Choice, error)
-	Update(ctx context.Context, count int, voteId int, title string) (int, error)
+	FindVersion(ctx context.Context, voteId int) (int64, error)
+	FindBallot(ctx context.Context, voteId int, voterId string) (entity.Ballot, error)
+	HasVoted(ctx context.Context, voteId int, voterId string) (bool, error)
+	FindRankings(ctx context.Context, voteId int) ([][]string, error)
+	FindHistograms(ctx context.Context, voteId int) (map[string]entity.Histogram, error)
+	FindVoters(ctx context.Context, voteId int) ([]entity.ChoiceVoters, error)
+	FindWeights(ctx context.Context, voteId int, voterIds []string) (map[string]int, error)
+	FindEligibleWeight(ctx context.Context, voteId int) (int, error)
+	FindLastVotes(ctx context.Context, voteId int, choices []string) (map[string]time.Time, error)
+	FindReceipt(ctx context.Context, voteId int, hash string) (entity.Receipt, error)
+	FindLog(ctx context.Context, voteId int, after int64, limit int) ([]entity.LogEntry, error)
+	FindLogUntil(ctx context.Context, voteId int, until time.Time) ([]entity.LogEntry, error)
+	Update(ctx context.Context, ballot entity.Ballot) ([]entity.ChoiceUpdate, string, error)
+	UpdateBatch(ctx context.Context, ballots []entity.Ballot) ([]entity.BallotResult, []entity.ChoiceUpdate, error)
+	ChangeBallot(ctx context.Context, ballot entity.Ballot) ([]entity.ChoiceUpdate, string, error)
+	RetractBallot(ctx context.Context, voteId int, voterId string) ([]entity.ChoiceUpdate, error)
+	EditPoll(ctx context.Context, voteId int, edit entity.PollEdit) (int64, error)
+	MergeChoices(ctx context.Context, voteId int, from string, into string) ([]entity.ChoiceUpdate, error)
+	DecideTie(ctx context.Context, voteId int, choice string) (int64, error)
+}
+
+type ResultPublisher interface {
+	Publish(update entity.ChoiceUpdate) error
 }
 
 type choiceService struct {
-	cache  CacheService
-	vote   VoteService
-	repo   СhoiceRepository
-	logger *logging.Logger
+	cache     CacheService
+	vote      VoteService
+	repo      СhoiceRepository
+	publisher ResultPublisher
+	ballotKey []byte
+	logger    *logging.Logger
 }
 
-func NewChoiceService(cache CacheService, vote VoteService, repo СhoiceRepository, logger *logging.Logger) *choiceService {
-	return &choiceService{vote: vote, cache: cache, repo: repo, logger: logger}
+// NewChoiceService creates the service, the ballotKey keys the hashes of
+// the voters of the anonymous votes and must be kept out of the database.
+func NewChoiceService(cache CacheService, vote VoteService, repo СhoiceRepository, publisher ResultPublisher, ballotKey []byte, logger *logging.Logger) *choiceService {
+	return &choiceService{vote: vote, cache: cache, repo: repo, publisher: publisher, ballotKey: ballotKey, logger: logger}
 }
 
-func (c *choiceService) Update(ctx context.Context, voteTitle string, choiceTitle string, count int) error {
-	c.logger.Debugf("try to update choice with vote title = %v, choice title = %v,count = %v", voteTitle, choiceTitle, count)
-	lastCount, err := c.cache.Get(voteTitle, choiceTitle)
+// Update casts the ballot of the voter and returns its receipt. Every voter
+// has one ballot per vote, the repeated one is rejected with ErrAlreadyVoted.
+// The ballot of a weighted vote counts with the weight of the voter, the
+// voters without one are rejected with ErrNotEligible.
+func (c *choiceService) Update(ctx context.Context, voteTitle string, choices []string, voterId string) (string, error) {
+	c.logger.Debugf("try to update choices with vote title = %v, choices = %v, voter = %v", voteTitle, choices, voterId)
+	if err := validateBallot(choices, voterId); err != nil {
+		return "", err
+	}
+	id, err := c.vote.Get(ctx, voteTitle)
+	if err != nil {
+		return "", errs.ErrTitleNotExist
+	}
+	vote, err := c.vote.GetById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return c.update(ctx, vote, choices, nil, voterId)
+}
+
+// UpdateById casts the ballot of the voter and returns its receipt, the
+// scores are given for the choices of a score vote only.
+func (c *choiceService) UpdateById(ctx context.Context, voteId int, choices []string, scores []int, voterId string) (string, error) {
+	c.logger.Debugf("try to update choices with vote id = %v, choices = %v, scores = %v, voter = %v", voteId, choices, scores, voterId)
+	if err := validateBallot(choices, voterId); err != nil {
+		return "", err
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
 	if err != nil {
-		updCount, err := c.update(ctx, voteTitle, choiceTitle, count)
+		c.logger.Errorf("cannot find vote with id = %v due to %v", voteId, err)
+		return "", err
+	}
+	return c.update(ctx, vote, choices, scores, voterId)
+}
+
+// UpdateBatch casts the ballots in one Tx. A ballot that can't be cast is
+// reported in its result and doesn't fail the rest of the batch.
+func (c *choiceService) UpdateBatch(ctx context.Context, ballots []entity.Ballot) ([]entity.BallotResult, error) {
+	c.logger.Debugf("try to update batch of %v ballots", len(ballots))
+	if len(ballots) == 0 || len(ballots) > maxBatch {
+		return nil, errs.ErrInvalidBatch
+	}
+	now := time.Now()
+	results := make([]entity.BallotResult, len(ballots))
+	votes := make(map[int]entity.Vote)
+	valid := make([]entity.Ballot, 0, len(ballots))
+	indexes := make([]int, 0, len(ballots))
+	for i, ballot := range ballots {
+		if err := validateBallot(ballot.Choices, ballot.VoterId); err != nil {
+			results[i].Err = err
+			continue
+		}
+		vote, err := c.resolveVote(ctx, ballot, votes)
 		if err != nil {
-			c.logger.Errorf("cannot update for vote title = %v , choice title = %v", voteTitle, choiceTitle)
+			results[i].Err = err
+			continue
+		}
+		if err = acceptsBallots(vote, now); err != nil {
+			results[i] = entity.BallotResult{VoteId: vote.Id, Err: err}
+			continue
+		}
+		if err = validateSelections(vote, ballot.Choices, ballot.Scores); err != nil {
+			results[i] = entity.BallotResult{VoteId: vote.Id, Err: err}
+			continue
+		}
+		if err = validateWriteIns(vote, ballot.Choices); err != nil {
+			results[i] = entity.BallotResult{VoteId: vote.Id, Err: err}
+			continue
+		}
+		ballot.VoteId = vote.Id
+		ballot.Weight = 1
+		ballot.WriteIn = vote.AllowWriteIn
+		valid = append(valid, ballot)
+		indexes = append(indexes, i)
+	}
+	if err := c.weighBatch(ctx, votes, valid, indexes, results); err != nil {
+		return nil, err
+	}
+	eligible := 0
+	for i := range valid {
+		if results[indexes[i]].Err == nil {
+			valid[eligible], indexes[eligible] = valid[i], indexes[i]
+			eligible++
+		}
+	}
+	valid, indexes = valid[:eligible], indexes[:eligible]
+	if len(valid) == 0 {
+		return results, nil
+	}
+	stored := make([]entity.Ballot, len(valid))
+	for i, ballot := range valid {
+		ballot.VoterId = c.ballotVoter(votes[ballot.VoteId], ballot.VoterId)
+		stored[i] = ballot
+	}
+	applied, updates, err := c.repo.UpdateBatch(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range applied {
+		// the voter has just voted, so only the results hidden until the
+		// vote closes are left out
+		if vote := votes[result.VoteId]; !vote.PublicResults(now) && vote.Visibility == entity.VisibilityAfterClose && valid[i].VoterId != vote.OwnerId {
+			result.Choices = nil
+		}
+		results[indexes[i]] = result
+	}
+	changed := make(map[int][]entity.ChoiceUpdate)
+	for _, update := range updates {
+		changed[update.VoteId] = append(changed[update.VoteId], update)
+	}
+	for voteId, updates := range changed {
+		c.refresh(votes[voteId], updates...)
+	}
+	return results, nil
+}
+
+// weighBatch sets the weights of the ballots of the weighted votes, the
+// weights of every vote are looked up at once. The ballots of the voters
+// without a weight are reported as not eligible in their results.
+func (c *choiceService) weighBatch(ctx context.Context, votes map[int]entity.Vote, ballots []entity.Ballot, indexes []int, results []entity.BallotResult) error {
+	voters := make(map[int][]string)
+	for _, ballot := range ballots {
+		if votes[ballot.VoteId].Weighted {
+			voters[ballot.VoteId] = append(voters[ballot.VoteId], ballot.VoterId)
+		}
+	}
+	weights := make(map[int]map[string]int, len(voters))
+	for voteId, voterIds := range voters {
+		found, err := c.repo.FindWeights(ctx, voteId, voterIds)
+		if err != nil {
+			c.logger.Errorf("cannot find weights of vote id = %v due to %v", voteId, err)
 			return err
 		}
-		go func() {
-			err := c.cache.Save(voteTitle, choiceTitle, updCount, expire)
-			if err != nil {
-				c.logger.Errorf("cache.Save() error due to %v", err)
+		weights[voteId] = found
+	}
+	for i := range ballots {
+		if !votes[ballots[i].VoteId].Weighted {
+			continue
+		}
+		weight, ok := weights[ballots[i].VoteId][ballots[i].VoterId]
+		if !ok {
+			results[indexes[i]] = entity.BallotResult{VoteId: ballots[i].VoteId, Err: errs.ErrNotEligible}
+			continue
+		}
+		ballots[i].Weight = weight
+	}
+	return nil
+}
+
+// resolveVote finds the vote of the ballot, the found votes are collected
+// in votes, so every vote of a batch is looked up once.
+func (c *choiceService) resolveVote(ctx context.Context, ballot entity.Ballot, votes map[int]entity.Vote) (entity.Vote, error) {
+	id := ballot.VoteId
+	if id == 0 {
+		if ballot.VoteTitle == "" {
+			return entity.Vote{}, errs.ErrEmptyVoteTitle
+		}
+		for _, vote := range votes {
+			if vote.Title == ballot.VoteTitle {
+				return vote, nil
 			}
-		}()
+		}
+		var err error
+		if id, err = c.vote.Get(ctx, ballot.VoteTitle); err != nil {
+			return entity.Vote{}, errs.ErrTitleNotExist
+		}
+	}
+	if vote, ok := votes[id]; ok {
+		return vote, nil
+	}
+	vote, err := c.vote.GetById(ctx, id)
+	if err != nil {
+		return entity.Vote{}, err
+	}
+	votes[id] = vote
+	return vote, nil
+}
+
+// update saves the ballot and then writes the new counts through to the cache.
+func (c *choiceService) update(ctx context.Context, vote entity.Vote, choices []string, scores []int, voterId string) (string, error) {
+	if err := acceptsBallots(vote, time.Now()); err != nil {
+		return "", err
+	}
+	if err := validateSelections(vote, choices, scores); err != nil {
+		return "", err
+	}
+	if err := validateWriteIns(vote, choices); err != nil {
+		return "", err
+	}
+	weight, err := c.weight(ctx, vote, voterId)
+	if err != nil {
+		return "", err
+	}
+	updates, receipt, err := c.repo.Update(ctx, entity.Ballot{VoteId: vote.Id, VoterId: c.ballotVoter(vote, voterId), Choices: choices, Scores: scores, Weight: weight, WriteIn: vote.AllowWriteIn})
+	if err != nil {
+		c.logger.Errorf("cannot update for vote id = %v , choices = %v due to %v", vote.Id, choices, err)
+		return "", err
+	}
+	c.refresh(vote, updates...)
+	return receipt, nil
+}
+
+// ballotVoter returns the voter id the ballot of the voter is stored with.
+// An anonymous vote stores the HMAC of the vote id and the voter id keyed
+// with the ballot key, so the hashes can't be reversed by trying the voter
+// ids and the ballots of one voter in different votes can't be matched.
+func (c *choiceService) ballotVoter(vote entity.Vote, voterId string) string {
+	if vote.Privacy != entity.PrivacyAnonymous {
+		return voterId
+	}
+	mac := hmac.New(sha256.New, c.ballotKey)
+	mac.Write([]byte(strconv.Itoa(vote.Id) + ":" + voterId))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// weight returns the weight of the voter in the vote, it is 1 unless the vote
+// is a weighted one.
+func (c *choiceService) weight(ctx context.Context, vote entity.Vote, voterId string) (int, error) {
+	if !vote.Weighted {
+		return 1, nil
+	}
+	weights, err := c.repo.FindWeights(ctx, vote.Id, []string{voterId})
+	if err != nil {
+		c.logger.Errorf("cannot find weight of voter %v in vote id = %v due to %v", voterId, vote.Id, err)
+		return 0, err
+	}
+	weight, ok := weights[voterId]
+	if !ok {
+		return 0, errs.ErrNotEligible
+	}
+	return weight, nil
+}
+
+// ChangeBallot replaces the selection and the scores of the voter in the vote
+// and returns the receipt of the new selection, the ballot keeps the weight
+// it was cast with.
+func (c *choiceService) ChangeBallot(ctx context.Context, voteId int, choices []string, scores []int, voterId string) (string, error) {
+	c.logger.Debugf("try to change ballot of voter %v in vote id = %v to %v with scores %v", voterId, voteId, choices, scores)
+	if err := validateBallot(choices, voterId); err != nil {
+		return "", err
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return "", err
+	}
+	if err = acceptsBallots(vote, time.Now()); err != nil {
+		return "", err
+	}
+	if err = validateSelections(vote, choices, scores); err != nil {
+		return "", err
+	}
+	if err = validateWriteIns(vote, choices); err != nil {
+		return "", err
+	}
+	updates, receipt, err := c.repo.ChangeBallot(ctx, entity.Ballot{VoteId: vote.Id, VoterId: c.ballotVoter(vote, voterId), Choices: choices, Scores: scores, WriteIn: vote.AllowWriteIn})
+	if err != nil {
+		return "", err
+	}
+	c.refresh(vote, updates...)
+	return receipt, nil
+}
+
+// RetractBallot withdraws the ballot of the voter, the voter can vote again.
+// The ballots of a vote that isn't open can't be changed or retracted.
+func (c *choiceService) RetractBallot(ctx context.Context, voteId int, voterId string) error {
+	c.logger.Debugf("try to retract ballot of voter %v in vote id = %v", voterId, voteId)
+	if err := validateVoter(voterId); err != nil {
+		return err
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return err
+	}
+	if err = acceptsBallots(vote, time.Now()); err != nil {
+		return err
+	}
+	updates, err := c.repo.RetractBallot(ctx, vote.Id, c.ballotVoter(vote, voterId))
+	if err != nil {
+		return err
+	}
+	c.refresh(vote, updates...)
+	return nil
+}
+
+// refresh writes the counts of the changed choices through to the cache and
+// publishes them, the errors are only logged as the ballot is already saved.
+func (c *choiceService) refresh(vote entity.Vote, updates ...entity.ChoiceUpdate) {
+	var version int64 = -1
+	for _, update := range updates {
+		if err := c.cache.Save(vote.Id, update.Choice, update.Count, expire); err != nil {
+			c.logger.Errorf("cache.Save() error due to %v", err)
+		}
+		if update.Version > version {
+			version = update.Version
+		}
+		if err := c.publisher.Publish(update); err != nil {
+			c.logger.Errorf("couldn't publish update of vote id = %v due to %v", vote.Id, err)
+		}
+	}
+	if version < 0 {
+		return
+	}
+	if err := c.cache.SaveVersion(vote.Id, version, expire); err != nil {
+		c.logger.Errorf("cache.SaveVersion() error due to %v", err)
+	}
+}
+
+// Edit renames the vote and adds, renames and removes its choices. The
+// choices of a closed vote are only renamed, as its counts are frozen.
+// The selection limits follow the number of choices: a vote whose ballots
+// could select all of its choices still can, and the limits never exceed
+// the number of choices. The cached counts follow the renamed choices.
+// Only the owner of the vote edits it.
+func (c *choiceService) Edit(ctx context.Context, voteId int, edit entity.PollEdit, voterId string) error {
+	c.logger.Debugf("try to edit vote id = %v with %+v by %v", voteId, edit, voterId)
+	if err := validateVoter(voterId); err != nil {
+		return err
+	}
+	if edit.Empty() {
+		return errs.ErrEmptyEdit
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return err
+	}
+	if voterId != vote.OwnerId {
+		return errs.ErrNotOwner
+	}
+	if len(edit.Add) > 0 || len(edit.Remove) > 0 {
+		if status := vote.StatusAt(time.Now()); status == entity.StatusClosed || status == entity.StatusArchived {
+			return errs.ErrVoteClosed
+		}
+	}
+	if edit.Privacy == "" {
+		edit.Privacy = vote.Privacy
+	}
+	if edit.Privacy != vote.Privacy {
+		if err = validatePrivacy(edit.Privacy, vote.Weighted); err != nil {
+			return err
+		}
+		// the stored ballots would be left in the old mode
+		if vote.StatusAt(time.Now()) != entity.StatusDraft {
+			return errs.ErrPrivacyLocked
+		}
+	}
+	choices, err := c.repo.FindChoices(ctx, vote.Id)
+	if err != nil {
+		return err
+	}
+	count, err := editedChoices(choices, edit)
+	if err != nil {
+		return err
+	}
+	edit.MinSelections, edit.MaxSelections = vote.MinSelections, vote.MaxSelections
+	// the ballots of a vote with rules select one choice whatever it has
+	if edit.MaxSelections == len(choices) && vote.Rules.Empty() {
+		if edit.MinSelections == edit.MaxSelections {
+			edit.MinSelections = count
+		}
+		edit.MaxSelections = count
+	}
+	if edit.MaxSelections > count {
+		edit.MaxSelections = count
+	}
+	if edit.MinSelections > edit.MaxSelections {
+		edit.MinSelections = edit.MaxSelections
+	}
+	if edit.MinSelections < 1 {
+		return errs.ErrInvalidSelections
+	}
+	if count < vote.Seats {
+		return errs.ErrInvalidSeats
+	}
+	if edit.Title == "" {
+		edit.Title = vote.Title
+	}
+	edit.Abstention = editedAbstention(vote.Rules.Abstention, edit)
+	version, err := c.repo.EditPoll(ctx, vote.Id, edit)
+	if err != nil {
+		return err
+	}
+	if err = c.cache.DeleteChoices(vote.Id, edit.Remove); err != nil {
+		c.logger.Errorf("cache.DeleteChoices() error due to %v", err)
+	}
+	for choice, newTitle := range edit.Rename {
+		if err = c.cache.RenameChoice(vote.Id, choice, newTitle); err != nil {
+			c.logger.Errorf("cache.RenameChoice() error due to %v", err)
+		}
+	}
+	if err = c.cache.SaveVersion(vote.Id, version, expire); err != nil {
+		c.logger.Errorf("cache.SaveVersion() error due to %v", err)
+	}
+	return nil
+}
+
+// editedAbstention returns the abstention choice after the edit, the rules
+// lose it if it is removed.
+func editedAbstention(abstention string, edit entity.PollEdit) string {
+	for _, choice := range edit.Remove {
+		if choice == abstention {
+			return ""
+		}
+	}
+	if newTitle, ok := edit.Rename[abstention]; ok {
+		return newTitle
+	}
+	return abstention
+}
+
+// editedChoices checks the edit against the current choices in the order it
+// is applied and returns the number of choices after it. A choice can't be
+// renamed to a title another choice has before the renames, so the renames
+// never depend on their order.
+func editedChoices(choices []entity.Choice, edit entity.PollEdit) (int, error) {
+	titles := make(map[string]struct{}, len(choices))
+	for _, choice := range choices {
+		titles[choice.Title] = struct{}{}
+	}
+	for _, choice := range edit.Remove {
+		if _, ok := titles[choice]; !ok {
+			return 0, errs.ErrChoiceTitleNotExist
+		}
+		delete(titles, choice)
+	}
+	for choice := range edit.Rename {
+		if _, ok := titles[choice]; !ok {
+			return 0, errs.ErrChoiceTitleNotExist
+		}
+	}
+	renamed := make(map[string]struct{}, len(edit.Rename))
+	for _, newTitle := range edit.Rename {
+		if newTitle == "" {
+			return 0, errs.ErrEmptyChoiceTitle
+		}
+		if _, ok := titles[newTitle]; ok {
+			return 0, errs.ErrDuplicateChoice
+		}
+		if _, ok := renamed[newTitle]; ok {
+			return 0, errs.ErrDuplicateChoice
+		}
+		renamed[newTitle] = struct{}{}
+	}
+	for choice := range edit.Rename {
+		delete(titles, choice)
+	}
+	for newTitle := range renamed {
+		titles[newTitle] = struct{}{}
+	}
+	for _, choice := range edit.Add {
+		if choice == "" {
+			return 0, errs.ErrEmptyChoiceTitle
+		}
+		if _, ok := titles[choice]; ok {
+			return 0, errs.ErrDuplicateChoice
+		}
+		titles[choice] = struct{}{}
+	}
+	return len(titles), nil
+}
+
+// MergeChoices merges the write-in choice from into the choice into and
+// sums their counts, a ballot that selected both counts once. Only the owner
+// of the vote merges its choices, the counts of a closed vote are frozen.
+func (c *choiceService) MergeChoices(ctx context.Context, voteId int, from string, into string, voterId string) error {
+	c.logger.Debugf("try to merge choice %v into %v in vote id = %v by %v", from, into, voteId, voterId)
+	if err := validateVoter(voterId); err != nil {
+		return err
+	}
+	if from == "" || into == "" {
+		return errs.ErrEmptyChoiceTitle
+	}
+	if from == into {
+		return errs.ErrInvalidMerge
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return err
+	}
+	if voterId != vote.OwnerId {
+		return errs.ErrNotOwner
+	}
+	if status := vote.StatusAt(time.Now()); status == entity.StatusClosed || status == entity.StatusArchived {
+		return errs.ErrVoteClosed
+	}
+	updates, err := c.repo.MergeChoices(ctx, vote.Id, from, into)
+	if err != nil {
+		return err
+	}
+	if err = c.cache.DeleteChoices(vote.Id, []string{from}); err != nil {
+		c.logger.Errorf("cache.DeleteChoices() error due to %v", err)
+	}
+	c.refresh(vote, updates...)
+	return nil
+}
+
+// GetBallot returns the ballot the voter has cast in the vote,
+// ErrBallotNotExist is returned if the voter hasn't voted yet.
+func (c *choiceService) GetBallot(ctx context.Context, voteId int, voterId string) (entity.Ballot, error) {
+	c.logger.Debugf("try to find ballot of voter %v in vote id = %v", voterId, voteId)
+	if err := validateVoter(voterId); err != nil {
+		return entity.Ballot{}, err
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return entity.Ballot{}, err
+	}
+	ballot, err := c.repo.FindBallot(ctx, vote.Id, c.ballotVoter(vote, voterId))
+	if err != nil {
+		return entity.Ballot{}, err
+	}
+	ballot.VoterId = voterId
+	return ballot, nil
+}
+
+// Voters lists the voters of every choice of a public vote, the viewer has
+// to be able to see the results of the vote.
+func (c *choiceService) Voters(ctx context.Context, voteId int, viewerId string) ([]entity.ChoiceVoters, error) {
+	c.logger.Debugf("try to find voters of vote id = %v", voteId)
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return nil, err
+	}
+	if vote.Privacy != entity.PrivacyPublic {
+		return nil, errs.ErrBallotsNotPublic
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return nil, err
+	}
+	return c.repo.FindVoters(ctx, vote.Id)
+}
+
+// Receipt looks the receipt up in the ballot log of the vote. Anyone holding
+// a receipt can check it, as the entry doesn't hold the voter.
+func (c *choiceService) Receipt(ctx context.Context, voteId int, receipt string) (entity.Receipt, error) {
+	c.logger.Debugf("try to find receipt %v in vote id = %v", receipt, voteId)
+	if !validReceipt(receipt) {
+		return entity.Receipt{}, errs.ErrInvalidReceipt
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return entity.Receipt{}, err
+	}
+	return c.repo.FindReceipt(ctx, vote.Id, receipt)
+}
+
+// Log returns a page of the ballot log of the vote after the entry with the
+// seq after. The log reveals the results, so it is shown to the viewers who
+// can see them.
+func (c *choiceService) Log(ctx context.Context, voteId int, viewerId string, after int64, limit int) (entity.LogPage, error) {
+	c.logger.Debugf("try to find log of vote id = %v after %v", voteId, after)
+	if after < 0 {
+		return entity.LogPage{}, errs.ErrInvalidAfter
+	}
+	if limit <= 0 {
+		limit = defaultLogPage
+	}
+	if limit > maxLogPage {
+		limit = maxLogPage
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return entity.LogPage{}, err
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return entity.LogPage{}, err
+	}
+	entries, err := c.repo.FindLog(ctx, vote.Id, after, limit+1)
+	if err != nil {
+		return entity.LogPage{}, err
+	}
+	page := entity.LogPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.Next = page.Entries[limit-1].Seq
+	}
+	return page, nil
+}
+
+// Timeline replays the ballot log of the vote up to the AsOf of the query,
+// the present if it isn't set or is yet to come, and groups the counts by
+// the Bucket, 1h by default. The timeline reveals the results, so it is
+// shown to the viewers who can see them.
+func (c *choiceService) Timeline(ctx context.Context, query entity.TimelineQuery, viewerId string) (entity.Timeline, error) {
+	c.logger.Debugf("try to get timeline of vote id = %v by %v", query.VoteId, query.Bucket)
+	if query.Bucket == "" {
+		query.Bucket = entity.BucketHour
+	}
+	if _, ok := entity.Buckets[query.Bucket]; !ok {
+		return entity.Timeline{}, errs.ErrInvalidBucket
+	}
+	now := time.Now()
+	if query.AsOf.IsZero() || query.AsOf.After(now) {
+		query.AsOf = now
+	}
+	vote, err := c.vote.GetById(ctx, query.VoteId)
+	if err != nil {
+		return entity.Timeline{}, err
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return entity.Timeline{}, err
+	}
+	entries, err := c.repo.FindLogUntil(ctx, vote.Id, query.AsOf)
+	if err != nil {
+		return entity.Timeline{}, err
+	}
+	return tally.Timeline(entries, query.Bucket, query.AsOf.UTC()), nil
+}
+
+// acceptsBallots checks that the vote is open at the moment, the schedule is
+// applied before the scheduler moves the vote.
+func acceptsBallots(vote entity.Vote, now time.Time) error {
+	switch vote.StatusAt(now) {
+	case entity.StatusDraft:
+		return errs.ErrVoteNotOpen
+	case entity.StatusClosed, entity.StatusArchived:
+		return errs.ErrVoteClosed
+	}
+	return nil
+}
+
+// validReceipt reports whether the receipt is a hex SHA-256 hash as written
+// by LogEntry.Digest.
+func validReceipt(receipt string) bool {
+	if len(receipt) != receiptLength {
+		return false
+	}
+	for _, r := range receipt {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func validateBallot(choices []string, voterId string) error {
+	unique := make(map[string]struct{}, len(choices))
+	for _, choice := range choices {
+		if choice == "" {
+			return errs.ErrEmptyChoiceTitle
+		}
+		if _, ok := unique[choice]; ok {
+			return errs.ErrDuplicateChoice
+		}
+		unique[choice] = struct{}{}
+	}
+	return validateVoter(voterId)
+}
+
+// validateSelections checks the number of the selected choices against
+// the limits of the vote. A ballot of a score vote scores every selected
+// choice on the scale of the vote, the other ballots have no scores.
+func validateSelections(vote entity.Vote, choices []string, scores []int) error {
+	if len(choices) < vote.MinSelections || len(choices) > vote.MaxSelections {
+		return errs.ErrSelectionCount
+	}
+	if vote.Method != entity.MethodScore {
+		if len(scores) > 0 {
+			return errs.ErrInvalidScores
+		}
+		return nil
+	}
+	if len(scores) != len(choices) {
+		return errs.ErrInvalidScores
+	}
+	for _, score := range scores {
+		if score < vote.MinScore || score > vote.MaxScore {
+			return errs.ErrInvalidScores
+		}
+	}
+	return nil
+}
+
+// validateWriteIns checks the choices of a ballot of a write-in vote, any of
+// them may be a write-in. The repository matches them to the choices of the vote.
+func validateWriteIns(vote entity.Vote, choices []string) error {
+	if !vote.AllowWriteIn {
+		return nil
+	}
+	for _, choice := range choices {
+		title := entity.NormalizeChoice(choice)
+		if title == "" {
+			return errs.ErrEmptyChoiceTitle
+		}
+		if utf8.RuneCountInString(title) > maxWriteIn {
+			return errs.ErrWriteInTooLong
+		}
+	}
+	return nil
+}
+
+func validateVoter(voterId string) error {
+	if voterId == "" {
+		return errs.ErrVoterRequired
+	}
+	if len(voterId) > maxVoterId {
+		return errs.ErrInvalidVoterId
+	}
+	return nil
+}
+
+// checkVisible enforces the visibility policy of the vote for the viewer, it
+// is checked before the results are read from the cache or Postgres.
+func (c *choiceService) checkVisible(ctx context.Context, vote entity.Vote, viewerId string) error {
+	if vote.PublicResults(time.Now()) || viewerId != "" && viewerId == vote.OwnerId {
 		return nil
+	}
+	if vote.Visibility == entity.VisibilityAfterClose {
+		return errs.ErrResultsHidden
+	}
+	if viewerId == "" {
+		return errs.ErrResultsAfterVote
+	}
+	voted, err := c.repo.HasVoted(ctx, vote.Id, c.ballotVoter(vote, viewerId))
+	if err != nil {
+		c.logger.Errorf("cannot find ballot of voter %v in vote id = %v due to %v", viewerId, vote.Id, err)
+		return err
+	}
+	if !voted {
+		return errs.ErrResultsAfterVote
+	}
+	return nil
+}
 
-	} else {
-		newCount := lastCount + count
-		c.logger.Debugf("last choice count = %v", lastCount)
-		err := c.cache.Save(voteTitle, choiceTitle, newCount, expire)
+// CheckVisible returns an error if the viewer can't see the results of the
+// vote at the moment.
+func (c *choiceService) CheckVisible(ctx context.Context, voteId int, viewerId string) error {
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return err
+	}
+	return c.checkVisible(ctx, vote, viewerId)
+}
+
+// GetVersionById returns the version of the vote results, it is bumped on
+// every change of the counts. The cached version is used if there is one.
+func (c *choiceService) GetVersionById(ctx context.Context, voteId int, viewerId string) (int64, error) {
+	c.logger.Debugf("try to get version of vote id = %v", voteId)
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return -1, err
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return -1, err
+	}
+	if version, err := c.cache.GetVersion(vote.Id); err == nil {
+		return version, nil
+	}
+	return c.loadVersion(ctx, vote.Id)
+}
+
+func (c *choiceService) loadVersion(ctx context.Context, voteId int) (int64, error) {
+	version, err := c.repo.FindVersion(ctx, voteId)
+	if err != nil {
+		return -1, err
+	}
+	if err = c.cache.SaveVersion(voteId, version, expire); err != nil {
+		c.logger.Errorf("cache.SaveVersion() error due to %v", err)
+	}
+	return version, nil
+}
+
+// Get returns the results of the vote addressed by the query if the viewer
+// can see them. The choices are ranked with their percents of the total and
+// the winner of a plurality or score vote is declared, the tie for the lead
+// is broken by the tie-break strategy of the vote.
+func (c *choiceService) Get(ctx context.Context, query entity.ResultQuery, viewerId string) (entity.Result, error) {
+	c.logger.Debugf("try to find results with %+v", query)
+	if query.Sort != "" && query.Sort != entity.SortByVotes && query.Sort != entity.SortByTitle {
+		return entity.Result{}, errs.ErrInvalidResultSort
+	}
+	if query.Order != "" && query.Order != "asc" && query.Order != "desc" {
+		return entity.Result{}, errs.ErrInvalidOrder
+	}
+	if query.Top < 0 {
+		return entity.Result{}, errs.ErrInvalidTop
+	}
+	id := query.VoteId
+	if id == 0 {
+		var err error
+		if id, err = c.vote.Get(ctx, query.VoteTitle); err != nil {
+			c.logger.Errorf("Get() error due to %v", err)
+			return entity.Result{}, errs.ErrTitleNotExist
+		}
+	}
+	vote, err := c.vote.GetById(ctx, id)
+	if err != nil {
+		return entity.Result{}, err
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return entity.Result{}, err
+	}
+	choices, err := c.choices(ctx, vote)
+	if err != nil {
+		c.logger.Errorf("Get() error due to %v", err)
+		return entity.Result{}, err
+	}
+	ranked, total, tied := tally.Rank(choices)
+	result := entity.Result{Vote: vote, Ballots: vote.Ballots, Total: total}
+	if !entity.Ranked(vote.Method) {
+		result.Tied = tied
+		if len(tied) == 0 && total > 0 {
+			result.Winner = ranked[0].Title
+		}
+		if len(tied) > 0 {
+			if result.Winner, err = c.breakTie(ctx, vote, tied); err != nil {
+				c.logger.Errorf("Get() error due to %v", err)
+				return entity.Result{}, err
+			}
+			if result.Winner != "" {
+				result.TieBreak = vote.TieBreak
+			}
+		}
+	}
+	result.Choices = tally.Order(ranked, query.Sort, query.Order, query.Top)
+	return result, nil
+}
+
+// breakTie picks the winner of the choices tied for the lead, an empty one
+// is returned if the tie stays unbroken.
+func (c *choiceService) breakTie(ctx context.Context, vote entity.Vote, tied []string) (string, error) {
+	switch vote.TieBreak {
+	case entity.TieBreakEarliest:
+		votes, err := c.repo.FindLastVotes(ctx, vote.Id, tied)
 		if err != nil {
-			_, err := c.update(ctx, voteTitle, choiceTitle, count)
-			if err != nil {
-				c.logger.Errorf("couldn't update count due to %v", err)
-				return err
+			return "", err
+		}
+		// the tied choices are in the order of their titles, so the same
+		// last votes are broken by the titles
+		winner := tied[0]
+		for _, choice := range tied[1:] {
+			if votes[choice].Before(votes[winner]) {
+				winner = choice
 			}
-			return nil
-		}
-		go func() {
-			updCtx, cancel := context.WithTimeout(context.Background(), updateTimeout)
-			defer cancel()
-			_, err := c.update(updCtx, voteTitle, choiceTitle, count)
-			if err != nil {
-				c.logger.Errorf("couldn't update count due to %v", err)
+		}
+		return winner, nil
+	case entity.TieBreakRandom:
+		return tally.Pick(tied, vote.TieSeed), nil
+	case entity.TieBreakOwner:
+		for _, choice := range tied {
+			if choice == vote.TieWinner {
+				return choice, nil
 			}
-		}()
-		return nil
+		}
 	}
+	return "", nil
 }
 
-func (c *choiceService) update(ctx context.Context, voteTitle string, choiceTitle string, count int) (int, error) {
-	id, err := c.vote.Get(ctx, voteTitle)
+// DecideTie declares the tied choice the winner of the vote whose ties are
+// decided by its owner. The vote has to be closed, so the tie can't change
+// under the decision.
+func (c *choiceService) DecideTie(ctx context.Context, voteId int, choice string, voterId string) error {
+	c.logger.Debugf("try to decide tie of vote id = %v for %v by %v", voteId, choice, voterId)
+	if err := validateVoter(voterId); err != nil {
+		return err
+	}
+	if choice == "" {
+		return errs.ErrEmptyChoiceTitle
+	}
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return err
+	}
+	if voterId != vote.OwnerId {
+		return errs.ErrNotOwner
+	}
+	if vote.TieBreak != entity.TieBreakOwner {
+		return errs.ErrTieNotOwners
+	}
+	if status := vote.StatusAt(time.Now()); status != entity.StatusClosed && status != entity.StatusArchived {
+		return errs.ErrVoteNotClosed
+	}
+	choices, err := c.repo.FindChoices(ctx, vote.Id)
 	if err != nil {
-		return -1, errs.ErrTitleNotExist
+		return err
 	}
-	return c.repo.Update(ctx, count, id, choiceTitle)
+	_, _, tied := tally.Rank(choices)
+	found := false
+	for _, title := range tied {
+		found = found || title == choice
+	}
+	if !found {
+		return errs.ErrNotTied
+	}
+	version, err := c.repo.DecideTie(ctx, vote.Id, choice)
+	if err != nil {
+		return err
+	}
+	if err = c.cache.SaveVersion(vote.Id, version, expire); err != nil {
+		c.logger.Errorf("cache.SaveVersion() error due to %v", err)
+	}
+	return nil
 }
 
-func (c *choiceService) Get(ctx context.Context, voteTitle string) ([]entity.Choice, error) {
-	c.logger.Debugf("try to find with choices title %v", voteTitle)
-	id, err := c.vote.Get(ctx, voteTitle)
+// GetById returns the choices of the vote if the viewer can see the results,
+// the choices of a score vote carry the histograms of their scores over the
+// whole scale.
+func (c *choiceService) GetById(ctx context.Context, voteId int, viewerId string) ([]entity.Choice, error) {
+	c.logger.Debugf("try to find choices with vote id %v", voteId)
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		c.logger.Errorf("GetById() error due to %v", err)
+		return nil, err
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return nil, err
+	}
+	choices, err := c.choices(ctx, vote)
 	if err != nil {
-		c.logger.Errorf("GetVoteResult() error due to %v", err)
-		return nil, errs.ErrTitleNotExist
+		c.logger.Errorf("GetById() error due to %v", err)
+		return nil, err
 	}
-	choices, err := c.repo.FindChoices(ctx, id)
+	return choices, nil
+}
+
+// choices returns the choices of the vote, the ones of a score vote with
+// their histograms.
+func (c *choiceService) choices(ctx context.Context, vote entity.Vote) ([]entity.Choice, error) {
+	choices, err := c.repo.FindChoices(ctx, vote.Id)
 	if err != nil {
-		c.logger.Errorf("GetVoteResult() error due to %v", err)
 		return nil, err
 	}
+	if vote.Method != entity.MethodScore {
+		return choices, nil
+	}
+	histograms, err := c.repo.FindHistograms(ctx, vote.Id)
+	if err != nil {
+		return nil, err
+	}
+	for i := range choices {
+		choices[i].Histogram = scale(histograms[choices[i].Title], vote.MinScore, vote.MaxScore)
+	}
 	return choices, nil
+}
+
+// GetRedacted returns the choices of the vote without their counts, it is
+// shown to the viewers who can't see the results.
+func (c *choiceService) GetRedacted(ctx context.Context, voteId int) ([]entity.Choice, error) {
+	c.logger.Debugf("try to find redacted choices with vote id %v", voteId)
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return nil, err
+	}
+	choices, err := c.repo.FindChoices(ctx, vote.Id)
+	if err != nil {
+		c.logger.Errorf("GetRedacted() error due to %v", err)
+		return nil, err
+	}
+	redacted := make([]entity.Choice, 0, len(choices))
+	for _, choice := range choices {
+		redacted = append(redacted, entity.Choice{Title: choice.Title, VoteId: choice.VoteId})
+	}
+	return redacted, nil
+}
+
+// scale fills in the scores from min to max nobody has given.
+func scale(histogram entity.Histogram, min int, max int) entity.Histogram {
+	counts := make(map[int]int, len(histogram))
+	for _, bucket := range histogram {
+		counts[bucket.Score] = bucket.Count
+	}
+	result := make(entity.Histogram, 0, max-min+1)
+	for score := min; score <= max; score++ {
+		result = append(result, entity.ScoreCount{Score: score, Count: counts[score]})
+	}
+	return result
+}
 
+// Tally counts the ranked ballots of the vote with the method of the vote if
+// the viewer can see the results.
+// The choices are counted in the order of their titles, so ties are listed
+// in the same order every time.
+func (c *choiceService) Tally(ctx context.Context, voteId int, viewerId string) (entity.Tally, error) {
+	c.logger.Debugf("try to tally vote id = %v", voteId)
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return entity.Tally{}, err
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return entity.Tally{}, err
+	}
+	choices, err := c.repo.FindChoices(ctx, vote.Id)
+	if err != nil {
+		c.logger.Errorf("Tally() error due to %v", err)
+		return entity.Tally{}, err
+	}
+	titles := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		titles = append(titles, choice.Title)
+	}
+	sort.Strings(titles)
+	rankings, err := c.repo.FindRankings(ctx, vote.Id)
+	if err != nil {
+		c.logger.Errorf("Tally() error due to %v", err)
+		return entity.Tally{}, err
+	}
+	return tally.Count(vote, titles, rankings)
+}
+
+// Outcome applies the quorum and threshold rules of the vote to its counts
+// if the viewer can see the results, a vote without rules has an empty
+// outcome. The eligible voters are looked up for a quorum percent only.
+func (c *choiceService) Outcome(ctx context.Context, voteId int, viewerId string) (entity.Outcome, error) {
+	c.logger.Debugf("try to find outcome of vote id = %v", voteId)
+	vote, err := c.vote.GetById(ctx, voteId)
+	if err != nil {
+		return entity.Outcome{}, err
+	}
+	if err = c.checkVisible(ctx, vote, viewerId); err != nil {
+		return entity.Outcome{}, err
+	}
+	if vote.Rules.Empty() {
+		return entity.Outcome{}, nil
+	}
+	choices, err := c.repo.FindChoices(ctx, vote.Id)
+	if err != nil {
+		c.logger.Errorf("Outcome() error due to %v", err)
+		return entity.Outcome{}, err
+	}
+	eligible := 0
+	if vote.Rules.QuorumPercent > 0 {
+		if eligible, err = c.repo.FindEligibleWeight(ctx, vote.Id); err != nil {
+			c.logger.Errorf("Outcome() error due to %v", err)
+			return entity.Outcome{}, err
+		}
+	}
+	return tally.Outcome(vote.Rules, choices, vote.Ballots, eligible), nil
 }
 
 func (c *choiceService) Create(ctx context.Context, choice entity.Choice) (string, error) {
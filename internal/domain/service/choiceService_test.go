@@ -3,11 +3,14 @@ package service
 import (
 	"context"
 	"errors"
-	"sync"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	"github.com/VrMolodyakov/vote-service/internal/domain/service/mocks"
+	"github.com/VrMolodyakov/vote-service/internal/domain/tally"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -16,6 +19,7 @@ import (
 func TestCreateChoice(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
 	voteRepo := mocks.NewMockVoteRepository(ctrl)
 	mockedRedis := mocks.NewMockRedisCache(ctrl)
 	type mockCall func() *choiceService
@@ -33,9 +37,9 @@ func TestCreateChoice(t *testing.T) {
 			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
 				cacheService := NewCahceService(mockedRedis, logger)
-				voteService := NewVoteService(voteRepo, logger)
+				voteService := NewVoteService(voteRepo, cacheService, logger)
 				choiceRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return("choice title", nil)
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: false,
 		},
@@ -46,8 +50,8 @@ func TestCreateChoice(t *testing.T) {
 			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
 				cacheService := NewCahceService(mockedRedis, logger)
-				voteService := NewVoteService(voteRepo, logger)
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				voteService := NewVoteService(voteRepo, cacheService, logger)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: true,
 		},
@@ -58,9 +62,9 @@ func TestCreateChoice(t *testing.T) {
 			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
 				cacheService := NewCahceService(mockedRedis, logger)
-				voteService := NewVoteService(voteRepo, logger)
+				voteService := NewVoteService(voteRepo, cacheService, logger)
 				choiceRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return("", errors.New("internal db error"))
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: true,
 		},
@@ -84,52 +88,168 @@ func TestCreateChoice(t *testing.T) {
 func TestGetVote(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
 	voteService := mocks.NewMockVoteService(ctrl)
 	mockedRedis := mocks.NewMockRedisCache(ctrl)
 	type mockCall func() *choiceService
 	testCases := []struct {
 		title   string
-		input   string
+		input   entity.ResultQuery
 		mock    mockCall
-		want    []entity.Choice
+		want    entity.Result
 		isError bool
 	}{
 		{
 			title: "success GetVoteResult() method vote result",
-			input: "vote title",
-			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}},
+			input: entity.ResultQuery{VoteTitle: "vote title"},
+			want: entity.Result{
+				Vote:    entity.Vote{Id: 1, Title: "vote title", Ballots: 3},
+				Ballots: 3,
+				Total:   3,
+				Choices: []entity.Choice{{Title: "title2", VoteId: 1, Count: 2, Rank: 1, Percent: 66.7}, {Title: "title1", VoteId: 1, Count: 1, Rank: 2, Percent: 33.3}},
+				Winner:  "title2",
+				Tied:    []string{},
+			},
 			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
 				cacheService := NewCahceService(mockedRedis, logger)
 				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choices := []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Ballots: 3}, nil)
+				choices := []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 2}}
 				choiceRepo.EXPECT().FindChoices(gomock.Any(), gomock.Any()).Return(choices, nil)
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+			},
+			isError: false,
+		},
+		{
+			title: "tie broken by the earliest vote and GetVoteResult() method should declare the winner",
+			input: entity.ResultQuery{VoteId: 1, Sort: entity.SortByTitle, Top: 2},
+			want: entity.Result{
+				Vote:     entity.Vote{Id: 1, Title: "vote title", Ballots: 5, TieBreak: entity.TieBreakEarliest},
+				Ballots:  5,
+				Total:    5,
+				Choices:  []entity.Choice{{Title: "Ditto", VoteId: 1, Count: 1, Rank: 3, Percent: 20}, {Title: "Eevee", VoteId: 1, Count: 2, Rank: 1, Percent: 40}},
+				Winner:   "Mew",
+				Tied:     []string{"Eevee", "Mew"},
+				TieBreak: entity.TieBreakEarliest,
+			},
+			mock: func() *choiceService {
+				logger := logging.GetLogger("debug")
+				cacheService := NewCahceService(mockedRedis, logger)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Ballots: 5, TieBreak: entity.TieBreakEarliest}, nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 2}, {Title: "Ditto", VoteId: 1, Count: 1}, {Title: "Eevee", VoteId: 1, Count: 2}}
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				now := time.Now()
+				last := map[string]time.Time{"Eevee": now, "Mew": now.Add(-time.Minute)}
+				choiceRepo.EXPECT().FindLastVotes(gomock.Any(), 1, []string{"Eevee", "Mew"}).Return(last, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+			},
+			isError: false,
+		},
+		{
+			title: "tie broken at random and GetVoteResult() method should pick the winner with the seed",
+			input: entity.ResultQuery{VoteId: 1},
+			want: entity.Result{
+				Vote:     entity.Vote{Id: 1, Title: "vote title", Ballots: 2, TieBreak: entity.TieBreakRandom, TieSeed: 42},
+				Ballots:  2,
+				Total:    2,
+				Choices:  []entity.Choice{{Title: "Eevee", VoteId: 1, Count: 1, Rank: 1, Percent: 50}, {Title: "Mew", VoteId: 1, Count: 1, Rank: 1, Percent: 50}},
+				Winner:   tally.Pick([]string{"Eevee", "Mew"}, 42),
+				Tied:     []string{"Eevee", "Mew"},
+				TieBreak: entity.TieBreakRandom,
+			},
+			mock: func() *choiceService {
+				logger := logging.GetLogger("debug")
+				cacheService := NewCahceService(mockedRedis, logger)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Ballots: 2, TieBreak: entity.TieBreakRandom, TieSeed: 42}, nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 1}, {Title: "Eevee", VoteId: 1, Count: 1}}
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+			},
+			isError: false,
+		},
+		{
+			title: "tie undecided by the owner and GetVoteResult() method should leave it tied",
+			input: entity.ResultQuery{VoteId: 1},
+			want: entity.Result{
+				Vote:    entity.Vote{Id: 1, Title: "vote title", Ballots: 2, TieBreak: entity.TieBreakOwner},
+				Ballots: 2,
+				Total:   2,
+				Choices: []entity.Choice{{Title: "Eevee", VoteId: 1, Count: 1, Rank: 1, Percent: 50}, {Title: "Mew", VoteId: 1, Count: 1, Rank: 1, Percent: 50}},
+				Tied:    []string{"Eevee", "Mew"},
+			},
+			mock: func() *choiceService {
+				logger := logging.GetLogger("debug")
+				cacheService := NewCahceService(mockedRedis, logger)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Ballots: 2, TieBreak: entity.TieBreakOwner}, nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 1}, {Title: "Eevee", VoteId: 1, Count: 1}}
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+			},
+			isError: false,
+		},
+		{
+			title: "ranked vote and GetVoteResult() method shouldn't declare the winner",
+			input: entity.ResultQuery{VoteId: 1},
+			want: entity.Result{
+				Vote:    entity.Vote{Id: 1, Title: "vote title", Ballots: 1, Method: entity.MethodIrv},
+				Ballots: 1,
+				Total:   1,
+				Choices: []entity.Choice{{Title: "Mew", VoteId: 1, Count: 1, Rank: 1, Percent: 100}},
+			},
+			mock: func() *choiceService {
+				logger := logging.GetLogger("debug")
+				cacheService := NewCahceService(mockedRedis, logger)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Ballots: 1, Method: entity.MethodIrv}, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return([]entity.Choice{{Title: "Mew", VoteId: 1, Count: 1}}, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: false,
 		},
+		{
+			title: "invalid sort and GetVoteResult() method should return error",
+			input: entity.ResultQuery{VoteId: 1, Sort: "created"},
+			mock: func() *choiceService {
+				logger := logging.GetLogger("debug")
+				cacheService := NewCahceService(mockedRedis, logger)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+			},
+			isError: true,
+		},
 		{
 			title: "cannot find by title and GetVoteResult() method should return error",
-			input: "vote title",
-			want:  nil,
+			input: entity.ResultQuery{VoteTitle: "vote title"},
 			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
 				cacheService := NewCahceService(mockedRedis, logger)
 				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(-1, errors.New("title now found"))
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: true,
 		},
 		{
 			title: "cannot find by choice by vote id and GetVoteResult() method should return error",
-			input: "vote title",
-			want:  nil,
+			input: entity.ResultQuery{VoteTitle: "vote title"},
 			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
 				cacheService := NewCahceService(mockedRedis, logger)
 				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
 				choiceRepo.EXPECT().FindChoices(gomock.Any(), gomock.Any()).Return(nil, errors.New("cannot find choices"))
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+			},
+			isError: true,
+		},
+		{
+			title: "results hidden until the vote closes and GetVoteResult() method should return error",
+			input: entity.ResultQuery{VoteTitle: "vote title"},
+			mock: func() *choiceService {
+				logger := logging.GetLogger("debug")
+				cacheService := NewCahceService(mockedRedis, logger)
+				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusOpen, Visibility: entity.VisibilityAfterClose}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: true,
 		},
@@ -138,180 +258,1752 @@ func TestGetVote(t *testing.T) {
 		t.Run(test.title, func(t *testing.T) {
 			choiceService := test.mock()
 			ctx := context.Background()
-			got, err := choiceService.Get(ctx, test.input)
+			got, err := choiceService.Get(ctx, test.input, "")
 			if !test.isError {
 				assert.NoError(t, err)
-				assert.Equal(t, got, test.want)
+				assert.Equal(t, test.want, got)
 			} else {
-				assert.Equal(t, got, test.want)
+				assert.Equal(t, test.want, got)
 				assert.Error(t, err)
 			}
 		})
 	}
 }
 
+// ashBallot is the voter id "ash" is stored with in the anonymous vote 1
+// under the "ballot key".
+const ashBallot string = "541151ecefbd41bd13615c2bb88de12660ee67a8de86ebf5027704eac9b97f90"
+
 func TestUpdateChoice(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
 	voteService := mocks.NewMockVoteService(ctrl)
 	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1}
 	type args struct {
-		voteTitle   string
-		choiceTitle string
-		count       int
+		voteTitle string
+		choices   []string
+		voterId   string
+	}
+	type mockCall func()
+	testCases := []struct {
+		title string
+		input args
+		mock  mockCall
+		err   error
+	}{
+		{
+			title: "success Update() saves ballot and writes count through the cache",
+			input: args{voteTitle: "vote title", choices: []string{"choice title"}, voterId: "ash"},
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "choice title", Count: 5, Version: 9}
+				voteService.EXPECT().Get(gomock.Any(), "vote title").Return(1, nil)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"choice title"}, Weight: 1}).Return([]entity.ChoiceUpdate{update}, "receipt", nil)
+				cacheService.EXPECT().Save(1, "choice title", 5, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(9), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title: "cache and publisher errors don't fail Update()",
+			input: args{voteTitle: "vote title", choices: []string{"choice title"}, voterId: "ash"},
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "choice title", Count: 5, Version: 9}
+				voteService.EXPECT().Get(gomock.Any(), "vote title").Return(1, nil)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return([]entity.ChoiceUpdate{update}, "receipt", nil)
+				cacheService.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("cannot save in cache"))
+				cacheService.EXPECT().SaveVersion(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("cannot save in cache"))
+				publisher.EXPECT().Publish(update).Return(errors.New("redis internal error"))
+			},
+		},
+		{
+			title: "repeated ballot and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{"choice title"}, voterId: "ash"},
+			mock: func() {
+				voteService.EXPECT().Get(gomock.Any(), "vote title").Return(1, nil)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, "", errs.ErrAlreadyVoted)
+			},
+			err: errs.ErrAlreadyVoted,
+		},
+		{
+			title: "too many selections and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{"first", "second"}, voterId: "ash"},
+			mock: func() {
+				voteService.EXPECT().Get(gomock.Any(), "vote title").Return(1, nil)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrSelectionCount,
+		},
+		{
+			title: "vote title not found and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{"choice title"}, voterId: "ash"},
+			mock: func() {
+				voteService.EXPECT().Get(gomock.Any(), "vote title").Return(-1, errors.New("not found"))
+			},
+			err: errs.ErrTitleNotExist,
+		},
+		{
+			title: "cannot execute repo.Update() and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{"choice title"}, voterId: "ash"},
+			mock: func() {
+				voteService.EXPECT().Get(gomock.Any(), "vote title").Return(1, nil)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, "", errors.New("internal db error"))
+			},
+			err: errors.New("internal db error"),
+		},
+		{
+			title: "empty voter and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{"choice title"}},
+			mock:  func() {},
+			err:   errs.ErrVoterRequired,
+		},
+		{
+			title: "too long voter id and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{"choice title"}, voterId: strings.Repeat("v", maxVoterId+1)},
+			mock:  func() {},
+			err:   errs.ErrInvalidVoterId,
+		},
+		{
+			title: "empty choice title and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{""}, voterId: "ash"},
+			mock:  func() {},
+			err:   errs.ErrEmptyChoiceTitle,
+		},
+		{
+			title: "choice selected twice and Update() should return error",
+			input: args{voteTitle: "vote title", choices: []string{"first", "first"}, voterId: "ash"},
+			mock:  func() {},
+			err:   errs.ErrDuplicateChoice,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			receipt, err := choiceService.Update(context.Background(), test.input.voteTitle, test.input.choices, test.input.voterId)
+			assert.Equal(t, test.err, err)
+			if test.err == nil {
+				assert.Equal(t, "receipt", receipt)
+			} else {
+				assert.Empty(t, receipt)
+			}
+		})
 	}
-	type mockCall func(wg *sync.WaitGroup) *choiceService
+}
+
+func TestGetChoicesById(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	type mockCall func() *choiceService
 	testCases := []struct {
 		title   string
-		input   args
+		input   int
+		viewer  string
 		mock    mockCall
 		want    []entity.Choice
 		isError bool
-		wait    bool
 	}{
 		{
-			title: "success UpdateChoice() method vote result",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title: "success GetById() method vote result",
+			input: 1,
+			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}},
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(-1, errors.New("empty cache"))
-				cacheService.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-					func(voteTitle interface{}, choiceTitle interface{}, count interface{}, expireAt interface{}) {
-						wg.Done()
-					})
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+				choices := []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}}
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: false,
-			wait:    true,
 		},
 		{
-			title: "success UpdateChoice() method vote result and error in goroutine",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title: "GetById() fills in the histograms of a score vote",
+			input: 1,
+			want: []entity.Choice{
+				{Title: "title1", VoteId: 1, Count: 3, Histogram: entity.Histogram{{Score: 1, Count: 2}, {Score: 2, Count: 0}, {Score: 3, Count: 1}}},
+				{Title: "title2", VoteId: 1, Count: 0, Histogram: entity.Histogram{{Score: 1, Count: 0}, {Score: 2, Count: 0}, {Score: 3, Count: 0}}},
+			},
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(-1, errors.New("empty cache"))
-				cacheService.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-					func(voteTitle interface{}, choiceTitle interface{}, count interface{}, expireAt interface{}) {
-						wg.Done()
-					}).Return(errors.New("cannot save in cache"))
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				vote := entity.Vote{Id: 1, Title: "vote title", Method: entity.MethodScore, MinScore: 1, MaxScore: 3}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choices := []entity.Choice{{Title: "title1", VoteId: 1, Count: 3}, {Title: "title2", VoteId: 1}}
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				histograms := map[string]entity.Histogram{"title1": {{Score: 1, Count: 2}, {Score: 3, Count: 1}}}
+				choiceRepo.EXPECT().FindHistograms(gomock.Any(), 1).Return(histograms, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: false,
-			wait:    true,
 		},
 		{
-			title: "UpdateChoice() find choice in cache and succes method execute",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title:  "owner sees the results of the vote before it closes",
+			input:  1,
+			viewer: "owner",
+			want:   []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}},
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				cacheService.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-					func(ctx interface{}, count interface{}, voteId interface{}, title interface{}) {
-						wg.Done()
-					})
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusOpen, Visibility: entity.VisibilityAfterClose, OwnerId: "owner"}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return([]entity.Choice{{Title: "title1", VoteId: 1, Count: 1}}, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: false,
-			wait:    true,
 		},
 		{
-			title: "UpdateChoice() find choice in cache and succes method execute but error in goroutine",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title:  "results of the closed vote are public",
+			input:  1,
+			viewer: "voter",
+			want:   []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}},
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				cacheService.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
-					func(ctx interface{}, count interface{}, voteId interface{}, title interface{}) {
-						wg.Done()
-					}).Return(1, errors.New("cannot update "))
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusClosed, Visibility: entity.VisibilityAfterClose, OwnerId: "owner"}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return([]entity.Choice{{Title: "title1", VoteId: 1, Count: 1}}, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: false,
-			wait:    true,
 		},
 		{
-			title: " vote title not found and service.Update() should return error",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title:  "results hidden until the vote closes and GetById() method should return error",
+			input:  1,
+			viewer: "voter",
+			want:   nil,
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(-1, errors.New("empty cache"))
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(-1, errors.New("title not found"))
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusOpen, Visibility: entity.VisibilityAfterClose, OwnerId: "owner"}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: true,
-			wait:    false,
 		},
 		{
-			title: " cannot execute repo.Update() and service should return error",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title:  "voter who has voted sees the results",
+			input:  1,
+			viewer: "voter",
+			want:   []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}},
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(-1, errors.New("empty cache"))
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(-1, errors.New("internal db error"))
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusOpen, Visibility: entity.VisibilityAfterVote}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().HasVoted(gomock.Any(), 1, "voter").Return(true, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return([]entity.Choice{{Title: "title1", VoteId: 1, Count: 1}}, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+			},
+			isError: false,
+		},
+		{
+			title:  "voter who hasn't voted and GetById() method should return error",
+			input:  1,
+			viewer: "voter",
+			want:   nil,
+			mock: func() *choiceService {
+				logger := logging.GetLogger("debug")
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusOpen, Visibility: entity.VisibilityAfterVote}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().HasVoted(gomock.Any(), 1, "voter").Return(false, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: true,
-			wait:    false,
 		},
 		{
-			title: "found in cache but couldn't save and then succes db update",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title: "anonymous viewer of an after_vote vote and GetById() method should return error",
+			input: 1,
+			want:  nil,
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				cacheService.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("reddis internal error"))
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(1, nil)
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusOpen, Visibility: entity.VisibilityAfterVote}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
-			isError: false,
-			wait:    false,
+			isError: true,
 		},
 		{
-			title: "found in cache but couldn't save and then couldn't execute db.update and service.Update() should return error",
-			input: args{voteTitle: "vote title", choiceTitle: "choice title", count: 1},
-			want:  []entity.Choice{{Title: "title1", VoteId: 1, Count: 1}, {Title: "title2", VoteId: 1, Count: 1}},
-			mock: func(wg *sync.WaitGroup) *choiceService {
+			title: "vote not found and GetById() method should return error",
+			input: 1,
+			want:  nil,
+			mock: func() *choiceService {
 				logger := logging.GetLogger("debug")
-				cacheService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				cacheService.EXPECT().Save(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("reddis internal error"))
-				voteService.EXPECT().Get(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(-1, errors.New("internal service error"))
-				return NewChoiceService(cacheService, voteService, choiceRepo, logger)
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+				return NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
 			},
 			isError: true,
-			wait:    false,
 		},
 	}
 	for _, test := range testCases {
 		t.Run(test.title, func(t *testing.T) {
-			var wg sync.WaitGroup
-			if test.wait {
-				wg.Add(1)
-			}
-			choiceService := test.mock(&wg)
-			ctx := context.Background()
-			err := choiceService.Update(ctx, test.input.voteTitle, test.input.choiceTitle, test.input.count)
-			wg.Wait()
+			choiceService := test.mock()
+			got, err := choiceService.GetById(context.Background(), test.input, test.viewer)
 			if !test.isError {
 				assert.NoError(t, err)
 			} else {
 				assert.Error(t, err)
 			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestUpdateChoiceById(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, []byte("ballot key"), logging.GetLogger("debug"))
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		choices []string
+		scores  []int
+		mock    mockCall
+		err     error
+	}{
+		{
+			title:   "success UpdateById() counts every selected choice",
+			choices: []string{"first", "second"},
+			mock: func() {
+				updates := []entity.ChoiceUpdate{
+					{VoteId: 1, Choice: "first", Count: 2, Version: 3},
+					{VoteId: 1, Choice: "second", Count: 1, Version: 3},
+				}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 2}, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"first", "second"}, Weight: 1}).Return(updates, "receipt", nil)
+				cacheService.EXPECT().Save(1, "first", 2, expire).Return(nil)
+				cacheService.EXPECT().Save(1, "second", 1, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(3), expire).Return(nil)
+				publisher.EXPECT().Publish(updates[0]).Return(nil)
+				publisher.EXPECT().Publish(updates[1]).Return(nil)
+			},
+		},
+		{
+			title:   "ballot of anonymous vote is stored with hash of the voter",
+			choices: []string{"first"},
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "first", Count: 3, Version: 4}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, Privacy: entity.PrivacyAnonymous}, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), entity.Ballot{VoteId: 1, VoterId: ashBallot, Choices: []string{"first"}, Weight: 1}).Return([]entity.ChoiceUpdate{update}, "receipt", nil)
+				cacheService.EXPECT().Save(1, "first", 3, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(4), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title:   "success UpdateById() saves the scores of a score vote",
+			choices: []string{"first", "second"},
+			scores:  []int{5, 0},
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "first", Count: 1, Version: 1}
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 2, MaxSelections: 2, Method: entity.MethodScore, MaxScore: 5}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"first", "second"}, Scores: []int{5, 0}, Weight: 1}).Return([]entity.ChoiceUpdate{update}, "receipt", nil)
+				cacheService.EXPECT().Save(1, "first", 1, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(1), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title:   "success UpdateById() counts the ballot of a weighted vote with the weight of the voter",
+			choices: []string{"first"},
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "first", Count: 150, Version: 2}
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, Weighted: true}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindWeights(gomock.Any(), 1, []string{"ash"}).Return(map[string]int{"ash": 100}, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"first"}, Weight: 100}).Return([]entity.ChoiceUpdate{update}, "receipt", nil)
+				cacheService.EXPECT().Save(1, "first", 150, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(2), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title:   "voter without a weight and UpdateById() should return error",
+			choices: []string{"first"},
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, Weighted: true}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindWeights(gomock.Any(), 1, []string{"ash"}).Return(map[string]int{}, nil)
+			},
+			err: errs.ErrNotEligible,
+		},
+		{
+			title:   "score out of the scale and UpdateById() should return error",
+			choices: []string{"first", "second"},
+			scores:  []int{6, 0},
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 2, MaxSelections: 2, Method: entity.MethodScore, MaxScore: 5}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrInvalidScores,
+		},
+		{
+			title:   "missing score and UpdateById() should return error",
+			choices: []string{"first", "second"},
+			scores:  []int{3},
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 2, MaxSelections: 2, Method: entity.MethodScore, MaxScore: 5}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrInvalidScores,
+		},
+		{
+			title:   "scores for a plurality vote and UpdateById() should return error",
+			choices: []string{"first"},
+			scores:  []int{3},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1}, nil)
+			},
+			err: errs.ErrInvalidScores,
+		},
+		{
+			title:   "too few selections and UpdateById() should return error",
+			choices: []string{"first"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", MinSelections: 2, MaxSelections: 3}, nil)
+			},
+			err: errs.ErrSelectionCount,
+		},
+		{
+			title:   "draft vote and UpdateById() should return error",
+			choices: []string{"first"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, Status: entity.StatusDraft}, nil)
+			},
+			err: errs.ErrVoteNotOpen,
+		},
+		{
+			title:   "closes_at has passed and UpdateById() should return error before the scheduler closes the vote",
+			choices: []string{"first"},
+			mock: func() {
+				closesAt := time.Now().Add(-time.Minute)
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, Status: entity.StatusOpen, ClosesAt: &closesAt}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrVoteClosed,
+		},
+		{
+			title:   "success UpdateById() lets the repository add the write-ins of a write-in vote",
+			choices: []string{" eevee "},
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "Eevee", Count: 3, Version: 6}
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, AllowWriteIn: true}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{" eevee "}, Weight: 1, WriteIn: true}).Return([]entity.ChoiceUpdate{update}, "receipt", nil)
+				cacheService.EXPECT().Save(1, "Eevee", 3, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(6), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title:   "blank write-in and UpdateById() should return error",
+			choices: []string{"  "},
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, AllowWriteIn: true}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrEmptyChoiceTitle,
+		},
+		{
+			title:   "too long write-in and UpdateById() should return error",
+			choices: []string{strings.Repeat("e", 201)},
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, AllowWriteIn: true}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrWriteInTooLong,
+		},
+		{
+			title:   "write-in limit reached and UpdateById() should return error",
+			choices: []string{"Eevee"},
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, AllowWriteIn: true}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil, "", errs.ErrWriteInLimit)
+			},
+			err: errs.ErrWriteInLimit,
+		},
+		{
+			title:   "vote not found and UpdateById() should return error",
+			choices: []string{"first"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			err: errs.ErrVoteNotExist,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			receipt, err := choiceService.UpdateById(context.Background(), 1, test.choices, test.scores, "ash")
+			assert.Equal(t, test.err, err)
+			if test.err == nil {
+				assert.Equal(t, "receipt", receipt)
+			} else {
+				assert.Empty(t, receipt)
+			}
+		})
+	}
+}
+
+func TestChangeBallot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1}
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		choices []string
+		mock    mockCall
+		err     error
+	}{
+		{
+			title:   "both choices are written through to the cache and published",
+			choices: []string{"Mew"},
+			mock: func() {
+				old := entity.ChoiceUpdate{VoteId: 1, Choice: "Pikachu", Count: 4, Version: 12}
+				cur := entity.ChoiceUpdate{VoteId: 1, Choice: "Mew", Count: 8, Version: 12}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().ChangeBallot(gomock.Any(), entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"Mew"}}).Return([]entity.ChoiceUpdate{old, cur}, "receipt", nil)
+				cacheService.EXPECT().Save(1, "Pikachu", 4, expire).Return(nil)
+				cacheService.EXPECT().Save(1, "Mew", 8, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(12), expire).Return(nil)
+				publisher.EXPECT().Publish(old).Return(nil)
+				publisher.EXPECT().Publish(cur).Return(nil)
+			},
+		},
+		{
+			title:   "ballot is already cast for the choice and nothing is refreshed",
+			choices: []string{"Mew"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().ChangeBallot(gomock.Any(), gomock.Any()).Return(nil, "receipt", nil)
+			},
+		},
+		{
+			title:   "voter hasn't voted and ChangeBallot() should return error",
+			choices: []string{"Mew"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().ChangeBallot(gomock.Any(), gomock.Any()).Return(nil, "", errs.ErrBallotNotExist)
+			},
+			err: errs.ErrBallotNotExist,
+		},
+		{
+			title:   "too many selections and ChangeBallot() should return error",
+			choices: []string{"Mew", "Pikachu"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrSelectionCount,
+		},
+		{
+			title:   "empty choice and ChangeBallot() should return error",
+			choices: []string{""},
+			mock:    func() {},
+			err:     errs.ErrEmptyChoiceTitle,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			receipt, err := choiceService.ChangeBallot(context.Background(), 1, test.choices, nil, "ash")
+			assert.Equal(t, test.err, err)
+			if test.err == nil {
+				assert.Equal(t, "receipt", receipt)
+			} else {
+				assert.Empty(t, receipt)
+			}
+		})
+	}
+}
+
+func TestRetractBallot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, []byte("ballot key"), logging.GetLogger("debug"))
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		voterId string
+		mock    mockCall
+		err     error
+	}{
+		{
+			title:   "decremented count is written through to the cache and published",
+			voterId: "ash",
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "Pikachu", Count: 4, Version: 13}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+				choiceRepo.EXPECT().RetractBallot(gomock.Any(), 1, "ash").Return([]entity.ChoiceUpdate{update}, nil)
+				cacheService.EXPECT().Save(1, "Pikachu", 4, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(13), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title:   "ballot of anonymous vote is retracted by hash of the voter",
+			voterId: "ash",
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "Pikachu", Count: 3, Version: 14}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Privacy: entity.PrivacyAnonymous}, nil)
+				choiceRepo.EXPECT().RetractBallot(gomock.Any(), 1, ashBallot).Return([]entity.ChoiceUpdate{update}, nil)
+				cacheService.EXPECT().Save(1, "Pikachu", 3, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(14), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title:   "closed vote and RetractBallot() should return error",
+			voterId: "ash",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusClosed}, nil)
+			},
+			err: errs.ErrVoteClosed,
+		},
+		{
+			title:   "voter hasn't voted and RetractBallot() should return error",
+			voterId: "ash",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+				choiceRepo.EXPECT().RetractBallot(gomock.Any(), 1, "ash").Return(nil, errs.ErrBallotNotExist)
+			},
+			err: errs.ErrBallotNotExist,
+		},
+		{
+			title: "empty voter and RetractBallot() should return error",
+			mock:  func() {},
+			err:   errs.ErrVoterRequired,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			err := choiceService.RetractBallot(context.Background(), 1, test.voterId)
+			assert.Equal(t, test.err, err)
 		})
 	}
 }
+
+func TestEdit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "vote title", MinSelections: 1, MaxSelections: 1, OwnerId: "oak"}
+	choices := []entity.Choice{{Title: "Pikachu"}, {Title: "Bulbasaur"}, {Title: "Squirtle"}}
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		edit    entity.PollEdit
+		voterId string
+		mock    mockCall
+		err     error
+	}{
+		{
+			title:   "renamed vote keeps its cache",
+			voterId: "oak",
+			edit:    entity.PollEdit{Title: "new title"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().EditPoll(gomock.Any(), 1, entity.PollEdit{Title: "new title", MinSelections: 1, MaxSelections: 1}).Return(int64(2), nil)
+				cacheService.EXPECT().DeleteChoices(1, nil).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(2), expire).Return(nil)
+			},
+		},
+		{
+			title:   "choices are removed, renamed and added with the selections following them",
+			voterId: "oak",
+			edit: entity.PollEdit{
+				Add:    []string{"Charmander"},
+				Rename: map[string]string{"Bulbasaur": "Ivysaur"},
+				Remove: []string{"Pikachu", "Squirtle"},
+				Force:  true,
+			},
+			mock: func() {
+				ranked := vote
+				ranked.MaxSelections = 3
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(ranked, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().EditPoll(gomock.Any(), 1, entity.PollEdit{
+					Title:         "vote title",
+					Add:           []string{"Charmander"},
+					Rename:        map[string]string{"Bulbasaur": "Ivysaur"},
+					Remove:        []string{"Pikachu", "Squirtle"},
+					Force:         true,
+					MinSelections: 1,
+					MaxSelections: 2,
+				}).Return(int64(5), nil)
+				cacheService.EXPECT().DeleteChoices(1, []string{"Pikachu", "Squirtle"}).Return(nil)
+				cacheService.EXPECT().RenameChoice(1, "Bulbasaur", "Ivysaur").Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(5), expire).Return(nil)
+			},
+		},
+		{
+			title:   "abstention of the rules follows its renamed choice",
+			voterId: "oak",
+			edit:    entity.PollEdit{Rename: map[string]string{"Squirtle": "Wartortle"}},
+			mock: func() {
+				governed := vote
+				governed.Rules = entity.Rules{Threshold: entity.ThresholdMajority, Abstention: "Squirtle"}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(governed, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().EditPoll(gomock.Any(), 1, entity.PollEdit{
+					Title:         "vote title",
+					Rename:        map[string]string{"Squirtle": "Wartortle"},
+					MinSelections: 1,
+					MaxSelections: 1,
+					Abstention:    "Wartortle",
+				}).Return(int64(6), nil)
+				cacheService.EXPECT().DeleteChoices(1, nil).Return(nil)
+				cacheService.EXPECT().RenameChoice(1, "Squirtle", "Wartortle").Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(6), expire).Return(nil)
+			},
+		},
+		{
+			title:   "privacy of draft vote is changed",
+			voterId: "oak",
+			edit:    entity.PollEdit{Privacy: entity.PrivacyAnonymous},
+			mock: func() {
+				draft := vote
+				draft.Status = entity.StatusDraft
+				draft.Privacy = entity.PrivacyPrivate
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(draft, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().EditPoll(gomock.Any(), 1, entity.PollEdit{Title: "vote title", MinSelections: 1, MaxSelections: 1, Privacy: entity.PrivacyAnonymous}).Return(int64(3), nil)
+				cacheService.EXPECT().DeleteChoices(1, nil).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(3), expire).Return(nil)
+			},
+		},
+		{
+			title:   "privacy of open vote and Edit() should return error",
+			voterId: "oak",
+			edit:    entity.PollEdit{Privacy: entity.PrivacyPublic},
+			mock: func() {
+				open := vote
+				open.Status = entity.StatusOpen
+				open.Privacy = entity.PrivacyPrivate
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(open, nil)
+			},
+			err: errs.ErrPrivacyLocked,
+		},
+		{
+			title:   "anonymous weighted vote and Edit() should return error",
+			voterId: "oak",
+			edit:    entity.PollEdit{Privacy: entity.PrivacyAnonymous},
+			mock: func() {
+				weighted := vote
+				weighted.Status = entity.StatusDraft
+				weighted.Privacy = entity.PrivacyPrivate
+				weighted.Weighted = true
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(weighted, nil)
+			},
+			err: errs.ErrInvalidPrivacy,
+		},
+		{
+			title:   "voter who isn't the owner and Edit() should return error",
+			voterId: "ash",
+			edit:    entity.PollEdit{Title: "new title"},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrNotOwner,
+		},
+		{
+			title: "missing voter and Edit() should return error",
+			edit:  entity.PollEdit{Title: "new title"},
+			mock:  func() {},
+			err:   errs.ErrVoterRequired,
+		},
+		{
+			title:   "empty edit and Edit() should return error",
+			voterId: "oak",
+			mock:    func() {},
+			err:     errs.ErrEmptyEdit,
+		},
+		{
+			title:   "choice added to closed vote and Edit() should return error",
+			voterId: "oak",
+			edit:    entity.PollEdit{Add: []string{"Charmander"}},
+			mock: func() {
+				closed := vote
+				closed.Status = entity.StatusClosed
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(closed, nil)
+			},
+			err: errs.ErrVoteClosed,
+		},
+		{
+			title:   "unknown choice and Edit() should return error",
+			voterId: "oak",
+			edit:    entity.PollEdit{Rename: map[string]string{"Mew": "Mewtwo"}},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+			},
+			err: errs.ErrChoiceTitleNotExist,
+		},
+		{
+			title:   "choice renamed to existing one and Edit() should return error",
+			voterId: "oak",
+			edit:    entity.PollEdit{Rename: map[string]string{"Pikachu": "Squirtle"}},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+			},
+			err: errs.ErrDuplicateChoice,
+		},
+		{
+			title:   "all choices removed and Edit() should return error",
+			voterId: "oak",
+			edit:    entity.PollEdit{Remove: []string{"Pikachu", "Bulbasaur", "Squirtle"}},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+			},
+			err: errs.ErrInvalidSelections,
+		},
+		{
+			title:   "removed choice has votes and Edit() should return error",
+			voterId: "oak",
+			edit:    entity.PollEdit{Remove: []string{"Pikachu"}},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().EditPoll(gomock.Any(), 1, gomock.Any()).Return(int64(-1), errs.ErrChoiceHasVotes)
+			},
+			err: errs.ErrChoiceHasVotes,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			err := choiceService.Edit(context.Background(), 1, test.edit, test.voterId)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestMergeChoices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "vote title", AllowWriteIn: true, OwnerId: "oak"}
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		from    string
+		voterId string
+		mock    mockCall
+		err     error
+	}{
+		{
+			title:   "merged choice is deleted from the cache and the sum is written through",
+			from:    "eevee!",
+			voterId: "oak",
+			mock: func() {
+				update := entity.ChoiceUpdate{VoteId: 1, Choice: "Eevee", Count: 9, Version: 12}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().MergeChoices(gomock.Any(), 1, "eevee!", "Eevee").Return([]entity.ChoiceUpdate{update}, nil)
+				cacheService.EXPECT().DeleteChoices(1, []string{"eevee!"}).Return(nil)
+				cacheService.EXPECT().Save(1, "Eevee", 9, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(1, int64(12), expire).Return(nil)
+				publisher.EXPECT().Publish(update).Return(nil)
+			},
+		},
+		{
+			title:   "voter isn't the owner and MergeChoices() should return error",
+			from:    "eevee!",
+			voterId: "ash",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrNotOwner,
+		},
+		{
+			title:   "closed vote and MergeChoices() should return error",
+			from:    "eevee!",
+			voterId: "oak",
+			mock: func() {
+				closed := vote
+				closed.Status = entity.StatusClosed
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(closed, nil)
+			},
+			err: errs.ErrVoteClosed,
+		},
+		{
+			title:   "choice merged into itself and MergeChoices() should return error",
+			from:    "Eevee",
+			voterId: "oak",
+			mock:    func() {},
+			err:     errs.ErrInvalidMerge,
+		},
+		{
+			title:   "merged choice isn't a write-in and MergeChoices() should return error",
+			from:    "Pikachu",
+			voterId: "oak",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().MergeChoices(gomock.Any(), 1, "Pikachu", "Eevee").Return(nil, errs.ErrInvalidMerge)
+			},
+			err: errs.ErrInvalidMerge,
+		},
+		{
+			title: "empty voter and MergeChoices() should return error",
+			from:  "eevee!",
+			mock:  func() {},
+			err:   errs.ErrVoterRequired,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			err := choiceService.MergeChoices(context.Background(), 1, test.from, "Eevee", test.voterId)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestDecideTie(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "vote title", OwnerId: "oak", Status: entity.StatusClosed, TieBreak: entity.TieBreakOwner}
+	choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 2}, {Title: "Eevee", VoteId: 1, Count: 2}, {Title: "Ditto", VoteId: 1, Count: 1}}
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		choice  string
+		voterId string
+		mock    mockCall
+		err     error
+	}{
+		{
+			title:   "owner decides the tie and the version is written through",
+			choice:  "Mew",
+			voterId: "oak",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().DecideTie(gomock.Any(), 1, "Mew").Return(int64(7), nil)
+				cacheService.EXPECT().SaveVersion(1, int64(7), expire).Return(nil)
+			},
+		},
+		{
+			title:   "voter isn't the owner and DecideTie() should return error",
+			choice:  "Mew",
+			voterId: "ash",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrNotOwner,
+		},
+		{
+			title:   "ties broken at random and DecideTie() should return error",
+			choice:  "Mew",
+			voterId: "oak",
+			mock: func() {
+				random := vote
+				random.TieBreak = entity.TieBreakRandom
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(random, nil)
+			},
+			err: errs.ErrTieNotOwners,
+		},
+		{
+			title:   "open vote and DecideTie() should return error",
+			choice:  "Mew",
+			voterId: "oak",
+			mock: func() {
+				open := vote
+				open.Status = entity.StatusOpen
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(open, nil)
+			},
+			err: errs.ErrVoteNotClosed,
+		},
+		{
+			title:   "choice isn't tied for the lead and DecideTie() should return error",
+			choice:  "Ditto",
+			voterId: "oak",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+			},
+			err: errs.ErrNotTied,
+		},
+		{
+			title:  "empty voter and DecideTie() should return error",
+			choice: "Mew",
+			mock:   func() {},
+			err:    errs.ErrVoterRequired,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			err := choiceService.DecideTie(context.Background(), 1, test.choice, test.voterId)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestGetBallot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, []byte("ballot key"), logging.GetLogger("debug"))
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		voterId string
+		mock    mockCall
+		want    entity.Ballot
+		err     error
+	}{
+		{
+			title:   "ballot of the voter is returned",
+			voterId: "ash",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+				choiceRepo.EXPECT().FindBallot(gomock.Any(), 1, "ash").Return(entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"Mew"}}, nil)
+			},
+			want: entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"Mew"}},
+		},
+		{
+			title:   "ballot of anonymous vote is found by hash of the voter",
+			voterId: "ash",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Privacy: entity.PrivacyAnonymous}, nil)
+				choiceRepo.EXPECT().FindBallot(gomock.Any(), 1, ashBallot).Return(entity.Ballot{VoteId: 1, VoterId: ashBallot, Choices: []string{"Mew"}}, nil)
+			},
+			want: entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"Mew"}},
+		},
+		{
+			title:   "vote not found and GetBallot() should return error",
+			voterId: "ash",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			err: errs.ErrVoteNotExist,
+		},
+		{
+			title: "empty voter and GetBallot() should return error",
+			mock:  func() {},
+			err:   errs.ErrVoterRequired,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.GetBallot(context.Background(), 1, test.voterId)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVoters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	public := entity.Vote{Id: 1, Title: "vote title", Privacy: entity.PrivacyPublic}
+	voters := []entity.ChoiceVoters{{Choice: "Mew", VoterIds: []string{"ash", "misty"}}, {Choice: "Pikachu", VoterIds: []string{}}}
+	type mockCall func()
+	testCases := []struct {
+		title    string
+		viewerId string
+		mock     mockCall
+		want     []entity.ChoiceVoters
+		err      error
+	}{
+		{
+			title: "voters of public vote are listed per choice",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(public, nil)
+				choiceRepo.EXPECT().FindVoters(gomock.Any(), 1).Return(voters, nil)
+			},
+			want: voters,
+		},
+		{
+			title: "private vote and Voters() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Privacy: entity.PrivacyPrivate}, nil)
+			},
+			err: errs.ErrBallotsNotPublic,
+		},
+		{
+			title: "anonymous vote and Voters() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Privacy: entity.PrivacyAnonymous}, nil)
+			},
+			err: errs.ErrBallotsNotPublic,
+		},
+		{
+			title:    "voters hidden until close and Voters() should return error",
+			viewerId: "ash",
+			mock: func() {
+				hidden := public
+				hidden.Visibility = entity.VisibilityAfterClose
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(hidden, nil)
+			},
+			err: errs.ErrResultsHidden,
+		},
+		{
+			title: "vote not found and Voters() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			err: errs.ErrVoteNotExist,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.Voters(context.Background(), 1, test.viewerId)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestReceipt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	hash := strings.Repeat("ab", 32)
+	receipt := entity.Receipt{Entry: entity.LogEntry{VoteId: 1, Seq: 2, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}, Hash: hash}}
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		receipt string
+		mock    mockCall
+		want    entity.Receipt
+		err     error
+	}{
+		{
+			title:   "receipt is looked up in the log of the vote",
+			receipt: hash,
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Visibility: entity.VisibilityAfterClose}, nil)
+				choiceRepo.EXPECT().FindReceipt(gomock.Any(), 1, hash).Return(receipt, nil)
+			},
+			want: receipt,
+		},
+		{
+			title:   "receipt isn't in the log and Receipt() should return error",
+			receipt: hash,
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1}, nil)
+				choiceRepo.EXPECT().FindReceipt(gomock.Any(), 1, hash).Return(entity.Receipt{}, errs.ErrReceiptNotExist)
+			},
+			err: errs.ErrReceiptNotExist,
+		},
+		{
+			title:   "receipt isn't a hash and Receipt() should return error",
+			receipt: strings.ToUpper(hash),
+			mock:    func() {},
+			err:     errs.ErrInvalidReceipt,
+		},
+		{
+			title:   "vote not found and Receipt() should return error",
+			receipt: hash,
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			err: errs.ErrVoteNotExist,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.Receipt(context.Background(), 1, test.receipt)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "vote title"}
+	entries := []entity.LogEntry{
+		{VoteId: 1, Seq: 3, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}},
+		{VoteId: 1, Seq: 4, Kind: entity.LogCast, Weight: 1, Choices: []string{"Pikachu"}},
+		{VoteId: 1, Seq: 5, Kind: entity.LogRetract, Replaces: 3, Weight: 1, Choices: []string{}},
+	}
+	type mockCall func()
+	testCases := []struct {
+		title string
+		after int64
+		limit int
+		mock  mockCall
+		want  entity.LogPage
+		err   error
+	}{
+		{
+			title: "log page is followed by the seq of its last entry",
+			after: 2,
+			limit: 2,
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindLog(gomock.Any(), 1, int64(2), 3).Return(entries, nil)
+			},
+			want: entity.LogPage{Entries: entries[:2], Next: 4},
+		},
+		{
+			title: "last page of the log has no next seq",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindLog(gomock.Any(), 1, int64(0), defaultLogPage+1).Return(entries, nil)
+			},
+			want: entity.LogPage{Entries: entries},
+		},
+		{
+			title: "limit is capped by the largest page",
+			limit: maxLogPage + 1,
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindLog(gomock.Any(), 1, int64(0), maxLogPage+1).Return(entries, nil)
+			},
+			want: entity.LogPage{Entries: entries},
+		},
+		{
+			title: "log hidden with the results and Log() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Visibility: entity.VisibilityAfterClose}, nil)
+			},
+			err: errs.ErrResultsHidden,
+		},
+		{
+			title: "negative after and Log() should return error",
+			after: -1,
+			mock:  func() {},
+			err:   errs.ErrInvalidAfter,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.Log(context.Background(), 1, "", test.after, test.limit)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "vote title"}
+	asOf := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	entries := []entity.LogEntry{
+		{VoteId: 1, Seq: 1, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}, CreatedAt: asOf.Add(-90 * time.Minute)},
+		{VoteId: 1, Seq: 2, Kind: entity.LogCast, Weight: 1, Choices: []string{"Pikachu"}, CreatedAt: asOf.Add(-time.Minute)},
+	}
+	type mockCall func()
+	testCases := []struct {
+		title string
+		query entity.TimelineQuery
+		mock  mockCall
+		want  entity.Timeline
+		err   error
+	}{
+		{
+			title: "log up to as of is replayed by the bucket",
+			query: entity.TimelineQuery{VoteId: 1, Bucket: entity.BucketHour, AsOf: asOf},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindLogUntil(gomock.Any(), 1, asOf).Return(entries, nil)
+			},
+			want: entity.Timeline{Bucket: entity.BucketHour, AsOf: asOf, Ballots: 2,
+				Choices: []entity.Choice{{Title: "Mew", Count: 1}, {Title: "Pikachu", Count: 1}},
+				Buckets: []entity.TimelineBucket{
+					{Start: asOf.Add(-2 * time.Hour), Cast: 1, Ballots: 1, Leader: "Mew", Counts: []entity.TimelineCount{{Choice: "Mew", Change: 1, Total: 1}}},
+					{Start: asOf.Add(-time.Hour), Cast: 1, Ballots: 2, Counts: []entity.TimelineCount{{Choice: "Mew", Change: 0, Total: 1}, {Choice: "Pikachu", Change: 1, Total: 1}}},
+				},
+			},
+		},
+		{
+			title: "unknown bucket and Timeline() should return error",
+			query: entity.TimelineQuery{VoteId: 1, Bucket: "1w"},
+			mock:  func() {},
+			err:   errs.ErrInvalidBucket,
+		},
+		{
+			title: "timeline hidden with the results and Timeline() should return error",
+			query: entity.TimelineQuery{VoteId: 1},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Visibility: entity.VisibilityAfterClose}, nil)
+			},
+			err: errs.ErrResultsHidden,
+		},
+		{
+			title: "log error and Timeline() should return error",
+			query: entity.TimelineQuery{VoteId: 1, AsOf: asOf},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceRepo.EXPECT().FindLogUntil(gomock.Any(), 1, asOf).Return(nil, errors.New("internal db error"))
+			},
+			err: errors.New("internal db error"),
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.Timeline(context.Background(), test.query, "")
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestTimelineDefaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	before := time.Now()
+	voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1}, nil)
+	choiceRepo.EXPECT().FindLogUntil(gomock.Any(), 1, gomock.Any()).Return([]entity.LogEntry{}, nil)
+	got, err := choiceService.Timeline(context.Background(), entity.TimelineQuery{VoteId: 1, AsOf: before.Add(time.Hour)}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.BucketHour, got.Bucket)
+	assert.False(t, got.AsOf.Before(before))
+	assert.False(t, got.AsOf.After(time.Now()))
+}
+
+func TestTally(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		want  entity.Tally
+		err   error
+	}{
+		{
+			title: "ranked ballots are counted with the method of the vote",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Method: entity.MethodBorda}, nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 2}, {Title: "Ditto", VoteId: 1, Count: 1}}
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().FindRankings(gomock.Any(), 1).Return([][]string{{"Mew", "Ditto"}, {"Mew"}}, nil)
+			},
+			want: entity.Tally{
+				Method:  entity.MethodBorda,
+				Ballots: 2,
+				Winners: []string{"Mew"},
+				Scores:  []entity.Score{{Choice: "Ditto", Score: 0}, {Choice: "Mew", Score: 2}},
+			},
+		},
+		{
+			title: "plurality vote and Tally() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Method: entity.MethodPlurality}, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return([]entity.Choice{}, nil)
+				choiceRepo.EXPECT().FindRankings(gomock.Any(), 1).Return([][]string{}, nil)
+			},
+			err: errs.ErrInvalidMethod,
+		},
+		{
+			title: "vote not found and Tally() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			err: errs.ErrVoteNotExist,
+		},
+		{
+			title: "repo error and Tally() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title", Method: entity.MethodIrv}, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return([]entity.Choice{{Title: "Mew", VoteId: 1}}, nil)
+				choiceRepo.EXPECT().FindRankings(gomock.Any(), 1).Return(nil, errors.New("internal db error"))
+			},
+			err: errors.New("internal db error"),
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.Tally(context.Background(), 1, "")
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	choices := []entity.Choice{{Title: "abstain", VoteId: 1, Count: 10}, {Title: "no", VoteId: 1, Count: 15}, {Title: "yes", VoteId: 1, Count: 35}}
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		want  entity.Outcome
+		err   error
+	}{
+		{
+			title: "rules of the vote are applied to its counts",
+			mock: func() {
+				rules := entity.Rules{Threshold: entity.ThresholdTwoThirds, Abstention: "abstain"}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Ballots: 60, Method: entity.MethodPlurality, Rules: rules}, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+			},
+			want: entity.Outcome{Result: entity.OutcomePassed, Choice: "yes", Votes: 35, Counted: 50, Share: 70, Abstentions: 10, Ballots: 60},
+		},
+		{
+			title: "quorum percent is checked against the eligible weight",
+			mock: func() {
+				rules := entity.Rules{QuorumPercent: 70, Threshold: entity.ThresholdMajority}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Ballots: 3, Method: entity.MethodPlurality, Weighted: true, Rules: rules}, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().FindEligibleWeight(gomock.Any(), 1).Return(100, nil)
+			},
+			want: entity.Outcome{Result: entity.OutcomeNoQuorum, Ballots: 3, Turnout: 60},
+		},
+		{
+			title: "vote without rules has empty outcome",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Method: entity.MethodPlurality}, nil)
+			},
+		},
+		{
+			title: "hidden results and Outcome() should return error",
+			mock: func() {
+				rules := entity.Rules{Threshold: entity.ThresholdMajority}
+				vote := entity.Vote{Id: 1, Method: entity.MethodPlurality, Status: entity.StatusOpen, Visibility: entity.VisibilityAfterClose, Rules: rules}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			err: errs.ErrResultsHidden,
+		},
+		{
+			title: "repo error and Outcome() should return error",
+			mock: func() {
+				rules := entity.Rules{QuorumPercent: 70, Threshold: entity.ThresholdMajority}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Method: entity.MethodPlurality, Weighted: true, Rules: rules}, nil)
+				choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+				choiceRepo.EXPECT().FindEligibleWeight(gomock.Any(), 1).Return(0, errors.New("internal db error"))
+			},
+			err: errors.New("internal db error"),
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.Outcome(context.Background(), 1, "")
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestUpdateBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	pokemon := entity.Vote{Id: 1, Title: "Pokemon", MinSelections: 1, MaxSelections: 2}
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		input   []entity.Ballot
+		mock    mockCall
+		want    []entity.BallotResult
+		wantErr error
+	}{
+		{
+			title: "valid ballots are resolved and cast in one batch",
+			input: []entity.Ballot{
+				{VoteId: 1, VoterId: "ash", Choices: []string{"Pikachu", "Mew"}},
+				{VoteTitle: "Pokemon", VoterId: "misty", Choices: []string{"Pikachu"}},
+				{VoteId: 1, Choices: []string{"Mew"}},
+				{VoteId: 2, VoterId: "brock", Choices: []string{"Mew"}},
+				{VoteTitle: "Digimon", VoterId: "gary", Choices: []string{"Agumon"}},
+				{VoteId: 1, VoterId: "gary", Choices: []string{"Mew"}},
+				{VoteId: 1, VoterId: "oak", Choices: []string{"Mew", "Pikachu", "Ditto"}},
+			},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(pokemon, nil)
+				voteService.EXPECT().GetById(gomock.Any(), 2).Return(entity.Vote{}, errs.ErrVoteNotExist)
+				voteService.EXPECT().Get(gomock.Any(), "Digimon").Return(-1, errs.ErrTitleNotExist)
+				choiceRepo.EXPECT().UpdateBatch(gomock.Any(), []entity.Ballot{
+					{VoteId: 1, VoterId: "ash", Choices: []string{"Pikachu", "Mew"}, Weight: 1},
+					{VoteId: 1, VoteTitle: "Pokemon", VoterId: "misty", Choices: []string{"Pikachu"}, Weight: 1},
+					{VoteId: 1, VoterId: "gary", Choices: []string{"Mew"}, Weight: 1},
+				}).Return([]entity.BallotResult{
+					{VoteId: 1, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 15}, {Title: "Mew", VoteId: 1, Count: 4}}},
+					{VoteId: 1, Err: errs.ErrAlreadyVoted},
+					{VoteId: 1, Choices: []entity.Choice{{Title: "Mew", VoteId: 1, Count: 4}}},
+				}, []entity.ChoiceUpdate{
+					{Choice: "Pikachu", VoteId: 1, Count: 15, Version: 21},
+					{Choice: "Mew", VoteId: 1, Count: 4, Version: 21},
+				}, nil)
+				cacheService.EXPECT().Save(1, "Pikachu", 15, expire).Return(nil)
+				cacheService.EXPECT().Save(1, "Mew", 4, expire).Return(errors.New("cache internal error"))
+				cacheService.EXPECT().SaveVersion(1, int64(21), expire).Return(nil)
+				publisher.EXPECT().Publish(entity.ChoiceUpdate{VoteId: 1, Choice: "Pikachu", Count: 15, Version: 21}).Return(nil)
+				publisher.EXPECT().Publish(entity.ChoiceUpdate{VoteId: 1, Choice: "Mew", Count: 4, Version: 21}).Return(nil)
+			},
+			want: []entity.BallotResult{
+				{VoteId: 1, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 15}, {Title: "Mew", VoteId: 1, Count: 4}}},
+				{VoteId: 1, Err: errs.ErrAlreadyVoted},
+				{Err: errs.ErrVoterRequired},
+				{Err: errs.ErrVoteNotExist},
+				{Err: errs.ErrTitleNotExist},
+				{VoteId: 1, Choices: []entity.Choice{{Title: "Mew", VoteId: 1, Count: 4}}},
+				{VoteId: 1, Err: errs.ErrSelectionCount},
+			},
+		},
+		{
+			title: "ballots of a closed vote are rejected without a Tx",
+			input: []entity.Ballot{
+				{VoteId: 5, VoterId: "ash", Choices: []string{"Mew"}},
+			},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 5).Return(entity.Vote{Id: 5, Title: "Closed", MinSelections: 1, MaxSelections: 1, Status: entity.StatusClosed}, nil)
+			},
+			want: []entity.BallotResult{{VoteId: 5, Err: errs.ErrVoteClosed}},
+		},
+		{
+			title: "counts of a vote hidden until it closes are left out of the results",
+			input: []entity.Ballot{
+				{VoteId: 6, VoterId: "ash", Choices: []string{"Mew"}},
+				{VoteId: 6, VoterId: "oak", Choices: []string{"Mew"}},
+			},
+			mock: func() {
+				vote := entity.Vote{Id: 6, Title: "Secret", MinSelections: 1, MaxSelections: 1, Status: entity.StatusOpen, Visibility: entity.VisibilityAfterClose, OwnerId: "oak"}
+				voteService.EXPECT().GetById(gomock.Any(), 6).Return(vote, nil)
+				choiceRepo.EXPECT().UpdateBatch(gomock.Any(), []entity.Ballot{
+					{VoteId: 6, VoterId: "ash", Choices: []string{"Mew"}, Weight: 1},
+					{VoteId: 6, VoterId: "oak", Choices: []string{"Mew"}, Weight: 1},
+				}).Return([]entity.BallotResult{
+					{VoteId: 6, Choices: []entity.Choice{{Title: "Mew", VoteId: 6, Count: 1}}},
+					{VoteId: 6, Choices: []entity.Choice{{Title: "Mew", VoteId: 6, Count: 2}}},
+				}, []entity.ChoiceUpdate{{Choice: "Mew", VoteId: 6, Count: 2, Version: 2}}, nil)
+				cacheService.EXPECT().Save(6, "Mew", 2, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(6, int64(2), expire).Return(nil)
+				publisher.EXPECT().Publish(entity.ChoiceUpdate{VoteId: 6, Choice: "Mew", Count: 2, Version: 2}).Return(nil)
+			},
+			want: []entity.BallotResult{
+				{VoteId: 6},
+				{VoteId: 6, Choices: []entity.Choice{{Title: "Mew", VoteId: 6, Count: 2}}},
+			},
+		},
+		{
+			title: "ballots of a weighted vote are cast with the weights of the voters",
+			input: []entity.Ballot{
+				{VoteId: 4, VoterId: "ash", Choices: []string{"Mew"}},
+				{VoteId: 4, VoterId: "misty", Choices: []string{"Mew"}},
+			},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 4).Return(entity.Vote{Id: 4, Title: "Board", MinSelections: 1, MaxSelections: 1, Weighted: true}, nil)
+				choiceRepo.EXPECT().FindWeights(gomock.Any(), 4, []string{"ash", "misty"}).Return(map[string]int{"ash": 30}, nil)
+				choiceRepo.EXPECT().UpdateBatch(gomock.Any(), []entity.Ballot{
+					{VoteId: 4, VoterId: "ash", Choices: []string{"Mew"}, Weight: 30},
+				}).Return([]entity.BallotResult{
+					{VoteId: 4, Choices: []entity.Choice{{Title: "Mew", VoteId: 4, Count: 30}}},
+				}, []entity.ChoiceUpdate{{Choice: "Mew", VoteId: 4, Count: 30, Version: 1}}, nil)
+				cacheService.EXPECT().Save(4, "Mew", 30, expire).Return(nil)
+				cacheService.EXPECT().SaveVersion(4, int64(1), expire).Return(nil)
+				publisher.EXPECT().Publish(entity.ChoiceUpdate{VoteId: 4, Choice: "Mew", Count: 30, Version: 1}).Return(nil)
+			},
+			want: []entity.BallotResult{
+				{VoteId: 4, Choices: []entity.Choice{{Title: "Mew", VoteId: 4, Count: 30}}},
+				{VoteId: 4, Err: errs.ErrNotEligible},
+			},
+		},
+		{
+			title: "nothing to cast and repository isn't called",
+			input: []entity.Ballot{{VoteId: 1, VoterId: "ash", Choices: []string{""}}},
+			mock:  func() {},
+			want:  []entity.BallotResult{{Err: errs.ErrEmptyChoiceTitle}},
+		},
+		{
+			title: "repository error fails the batch",
+			input: []entity.Ballot{{VoteId: 3, VoterId: "ash", Choices: []string{"Mew"}}},
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 3).Return(entity.Vote{Id: 3, Title: "Pokemon", MinSelections: 1, MaxSelections: 1}, nil)
+				choiceRepo.EXPECT().UpdateBatch(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("internal db error"))
+			},
+			wantErr: errors.New("internal db error"),
+		},
+		{
+			title:   "empty batch and UpdateBatch() should return error",
+			mock:    func() {},
+			wantErr: errs.ErrInvalidBatch,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.UpdateBatch(context.Background(), test.input)
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestGetVersionById(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		want  int64
+		err   error
+	}{
+		{
+			title: "cached version is returned",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+				cacheService.EXPECT().GetVersion(1).Return(int64(4), nil)
+			},
+			want: 4,
+		},
+		{
+			title: "version is loaded from db and cached",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+				cacheService.EXPECT().GetVersion(1).Return(int64(-1), errors.New("empty cache"))
+				choiceRepo.EXPECT().FindVersion(gomock.Any(), 1).Return(int64(6), nil)
+				cacheService.EXPECT().SaveVersion(1, int64(6), expire).Return(nil)
+			},
+			want: 6,
+		},
+		{
+			title: "cached version of hidden results isn't returned",
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "vote title", Status: entity.StatusOpen, Visibility: entity.VisibilityAfterClose}
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+			},
+			want: -1,
+			err:  errs.ErrResultsHidden,
+		},
+		{
+			title: "vote not found and GetVersionById() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			want: -1,
+			err:  errs.ErrVoteNotExist,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := choiceService.GetVersionById(context.Background(), 1, "")
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestVersionOfRecreatedVote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteRepo := mocks.NewMockVoteRepository(ctrl)
+	mockedRedis := mocks.NewMockRedisCache(ctrl)
+	defer ctrl.Finish()
+	logger := logging.GetLogger("debug")
+	cacheService := NewCahceService(mockedRedis, logger)
+	voteService := NewVoteService(voteRepo, cacheService, logger)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logger)
+	ctx := context.Background()
+
+	voteRepo.EXPECT().FindById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Pokemon"}, nil)
+	mockedRedis.EXPECT().GetVersion(1).Return(int64(9), nil)
+	deleted, err := choiceService.GetVersionById(ctx, 1, "")
+	assert.NoError(t, err)
+
+	voteRepo.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+	assert.NoError(t, voteService.Delete(ctx, "1"))
+	voteRepo.EXPECT().Insert(gomock.Any(), "Pokemon").Return(2, nil)
+	id, err := voteService.Create(ctx, "Pokemon")
+	assert.NoError(t, err)
+
+	// the version of the deleted vote is still cached, but not for the new id
+	voteRepo.EXPECT().FindById(gomock.Any(), 2).Return(entity.Vote{Id: 2, Title: "Pokemon"}, nil)
+	mockedRedis.EXPECT().GetVersion(2).Return(int64(-1), errors.New("redis: nil"))
+	choiceRepo.EXPECT().FindVersion(gomock.Any(), 2).Return(int64(0), nil)
+	mockedRedis.EXPECT().SetVersion(2, int64(0), expire).Return(nil)
+	recreated, err := choiceService.GetVersionById(ctx, id, "")
+	assert.NoError(t, err)
+	assert.NotEqual(t, deleted, recreated)
+}
+
+func TestGetRedacted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	choiceRepo := mocks.NewMockСhoiceRepository(ctrl)
+	publisher := mocks.NewMockResultPublisher(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	cacheService := mocks.NewMockCacheService(ctrl)
+	choiceService := NewChoiceService(cacheService, voteService, choiceRepo, publisher, nil, logging.GetLogger("debug"))
+
+	voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+	choices := []entity.Choice{{Title: "title1", VoteId: 1, Count: 3, Voters: 2}, {Title: "title2", VoteId: 1, Count: 1, Voters: 1}}
+	choiceRepo.EXPECT().FindChoices(gomock.Any(), 1).Return(choices, nil)
+	got, err := choiceService.GetRedacted(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Choice{{Title: "title1", VoteId: 1}, {Title: "title2", VoteId: 1}}, got)
+
+	voteService.EXPECT().GetById(gomock.Any(), 2).Return(entity.Vote{}, errs.ErrVoteNotExist)
+	_, err = choiceService.GetRedacted(context.Background(), 2)
+	assert.Equal(t, errs.ErrVoteNotExist, err)
+}
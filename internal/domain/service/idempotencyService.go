@@ -0,0 +1,82 @@
+package service
+
+import (
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+)
+
+const (
+	maxIdempotencyKey int = 255
+	// a request holds its key for pendingExpire at most, so the key isn't
+	// locked forever if the instance dies in the middle of the request
+	pendingExpire   time.Duration = time.Minute
+	completedExpire time.Duration = 24 * time.Hour
+)
+
+type IdempotencyStore interface {
+	Reserve(key string, response entity.IdempotentResponse, expireAt time.Duration) (bool, error)
+	Get(key string) (entity.IdempotentResponse, bool, error)
+	Set(key string, response entity.IdempotentResponse, expireAt time.Duration) error
+	Delete(key string) error
+}
+
+type idempotencyService struct {
+	store  IdempotencyStore
+	logger *logging.Logger
+}
+
+func NewIdempotencyService(store IdempotencyStore, logger *logging.Logger) *idempotencyService {
+	return &idempotencyService{store: store, logger: logger}
+}
+
+// Begin reserves the key for the request with the fingerprint. If the key was
+// already used for the same request, the stored response is returned with
+// replay set to true and the request must not be executed again.
+func (i *idempotencyService) Begin(key string, fingerprint string) (response entity.IdempotentResponse, replay bool, err error) {
+	if key == "" || len(key) > maxIdempotencyKey {
+		return response, false, errs.ErrInvalidIdempotencyKey
+	}
+	i.logger.Debugf("try to reserve idempotency key %v", key)
+	// the stored key may expire between Reserve and Get, so reservation is retried once
+	for attempt := 0; attempt < 2; attempt++ {
+		reserved, err := i.store.Reserve(key, entity.IdempotentResponse{Fingerprint: fingerprint}, pendingExpire)
+		if err != nil {
+			return response, false, err
+		}
+		if reserved {
+			return response, false, nil
+		}
+		stored, found, err := i.store.Get(key)
+		if err != nil {
+			return response, false, err
+		}
+		if !found {
+			continue
+		}
+		if stored.Fingerprint != fingerprint {
+			return response, false, errs.ErrIdempotencyKeyReused
+		}
+		if !stored.Done {
+			return response, false, errs.ErrIdempotencyInProgress
+		}
+		return stored, true, nil
+	}
+	return response, false, errs.ErrIdempotencyInProgress
+}
+
+// Complete stores the response of the request, so it is replayed for the
+// retries with the same key.
+func (i *idempotencyService) Complete(key string, response entity.IdempotentResponse) error {
+	i.logger.Debugf("try to save response for idempotency key %v", key)
+	response.Done = true
+	return i.store.Set(key, response, completedExpire)
+}
+
+// Release frees the key of a failed request, so the client can retry it.
+func (i *idempotencyService) Release(key string) error {
+	i.logger.Debugf("try to release idempotency key %v", key)
+	return i.store.Delete(key)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/domain/service/mocks"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeginIdempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mocks.NewMockIdempotencyStore(ctrl)
+	idempotencyService := NewIdempotencyService(store, logging.GetLogger("debug"))
+	pending := entity.IdempotentResponse{Fingerprint: "fingerprint"}
+	completed := entity.IdempotentResponse{Fingerprint: "fingerprint", Done: true, Status: 204}
+	type mockCall func()
+	testCases := []struct {
+		title    string
+		key      string
+		mock     mockCall
+		want     entity.IdempotentResponse
+		isReplay bool
+		err      error
+	}{
+		{
+			title: "new key is reserved",
+			key:   "key",
+			mock: func() {
+				store.EXPECT().Reserve("key", pending, pendingExpire).Return(true, nil)
+			},
+		},
+		{
+			title: "completed key returns stored response",
+			key:   "key",
+			mock: func() {
+				store.EXPECT().Reserve("key", pending, pendingExpire).Return(false, nil)
+				store.EXPECT().Get("key").Return(completed, true, nil)
+			},
+			want:     completed,
+			isReplay: true,
+		},
+		{
+			title: "key with another fingerprint is rejected",
+			key:   "key",
+			mock: func() {
+				store.EXPECT().Reserve("key", pending, pendingExpire).Return(false, nil)
+				store.EXPECT().Get("key").Return(entity.IdempotentResponse{Fingerprint: "other", Done: true}, true, nil)
+			},
+			err: errs.ErrIdempotencyKeyReused,
+		},
+		{
+			title: "pending key is in progress",
+			key:   "key",
+			mock: func() {
+				store.EXPECT().Reserve("key", pending, pendingExpire).Return(false, nil)
+				store.EXPECT().Get("key").Return(pending, true, nil)
+			},
+			err: errs.ErrIdempotencyInProgress,
+		},
+		{
+			title: "key expired after reserve and is reserved again",
+			key:   "key",
+			mock: func() {
+				store.EXPECT().Reserve("key", pending, pendingExpire).Return(false, nil)
+				store.EXPECT().Get("key").Return(entity.IdempotentResponse{}, false, nil)
+				store.EXPECT().Reserve("key", pending, pendingExpire).Return(true, nil)
+			},
+		},
+		{
+			title: "store error is returned",
+			key:   "key",
+			mock: func() {
+				store.EXPECT().Reserve("key", pending, pendingExpire).Return(false, errors.New("internal redis error"))
+			},
+			err: errors.New("internal redis error"),
+		},
+		{
+			title: "empty key is invalid",
+			key:   "",
+			mock:  func() {},
+			err:   errs.ErrInvalidIdempotencyKey,
+		},
+		{
+			title: "too long key is invalid",
+			key:   strings.Repeat("k", maxIdempotencyKey+1),
+			mock:  func() {},
+			err:   errs.ErrInvalidIdempotencyKey,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, replay, err := idempotencyService.Begin(test.key, "fingerprint")
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.isReplay, replay)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestCompleteIdempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mocks.NewMockIdempotencyStore(ctrl)
+	idempotencyService := NewIdempotencyService(store, logging.GetLogger("debug"))
+
+	store.EXPECT().Set("key", entity.IdempotentResponse{Fingerprint: "fingerprint", Done: true, Status: 201}, completedExpire).Return(nil)
+	assert.NoError(t, idempotencyService.Complete("key", entity.IdempotentResponse{Fingerprint: "fingerprint", Status: 201}))
+
+	store.EXPECT().Delete("key").Return(errors.New("internal redis error"))
+	assert.Error(t, idempotencyService.Release("key"))
+}
@@ -34,31 +34,93 @@ func (m *MockRedisCache) EXPECT() *MockRedisCacheMockRecorder {
 	return m.recorder
 }
 
+// Delete mocks base method.
+func (m *MockRedisCache) Delete(voteId int, choiceTitles ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{voteId}
+	for _, a := range choiceTitles {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRedisCacheMockRecorder) Delete(voteId interface{}, choiceTitles ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{voteId}, choiceTitles...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRedisCache)(nil).Delete), varargs...)
+}
+
 // Get mocks base method.
-func (m *MockRedisCache) Get(voteTitle, choiceTitle string) (int, error) {
+func (m *MockRedisCache) Get(voteId int, choiceTitle string) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", voteTitle, choiceTitle)
+	ret := m.ctrl.Call(m, "Get", voteId, choiceTitle)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockRedisCacheMockRecorder) Get(voteTitle, choiceTitle interface{}) *gomock.Call {
+func (mr *MockRedisCacheMockRecorder) Get(voteId, choiceTitle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRedisCache)(nil).Get), voteId, choiceTitle)
+}
+
+// GetVersion mocks base method.
+func (m *MockRedisCache) GetVersion(voteId int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", voteId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockRedisCacheMockRecorder) GetVersion(voteId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRedisCache)(nil).Get), voteTitle, choiceTitle)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockRedisCache)(nil).GetVersion), voteId)
+}
+
+// RenameChoice mocks base method.
+func (m *MockRedisCache) RenameChoice(voteId int, choiceTitle, newTitle string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameChoice", voteId, choiceTitle, newTitle)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameChoice indicates an expected call of RenameChoice.
+func (mr *MockRedisCacheMockRecorder) RenameChoice(voteId, choiceTitle, newTitle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameChoice", reflect.TypeOf((*MockRedisCache)(nil).RenameChoice), voteId, choiceTitle, newTitle)
 }
 
 // Set mocks base method.
-func (m *MockRedisCache) Set(voteTitle, choiceTitle string, count int, expireAt time.Duration) error {
+func (m *MockRedisCache) Set(voteId int, choiceTitle string, count int, expireAt time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", voteTitle, choiceTitle, count, expireAt)
+	ret := m.ctrl.Call(m, "Set", voteId, choiceTitle, count, expireAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Set indicates an expected call of Set.
-func (mr *MockRedisCacheMockRecorder) Set(voteTitle, choiceTitle, count, expireAt interface{}) *gomock.Call {
+func (mr *MockRedisCacheMockRecorder) Set(voteId, choiceTitle, count, expireAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRedisCache)(nil).Set), voteId, choiceTitle, count, expireAt)
+}
+
+// SetVersion mocks base method.
+func (m *MockRedisCache) SetVersion(voteId int, version int64, expireAt time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVersion", voteId, version, expireAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVersion indicates an expected call of SetVersion.
+func (mr *MockRedisCacheMockRecorder) SetVersion(voteId, version, expireAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRedisCache)(nil).Set), voteTitle, choiceTitle, count, expireAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersion", reflect.TypeOf((*MockRedisCache)(nil).SetVersion), voteId, version, expireAt)
 }
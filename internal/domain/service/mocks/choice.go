@@ -36,33 +36,75 @@ func (m *MockCacheService) EXPECT() *MockCacheServiceMockRecorder {
 	return m.recorder
 }
 
-// Get mocks base method.
-func (m *MockCacheService) Get(voteTitle, choiceTitle string) (int, error) {
+// DeleteChoices mocks base method.
+func (m *MockCacheService) DeleteChoices(voteId int, choiceTitles []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", voteTitle, choiceTitle)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "DeleteChoices", voteId, choiceTitles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteChoices indicates an expected call of DeleteChoices.
+func (mr *MockCacheServiceMockRecorder) DeleteChoices(voteId, choiceTitles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteChoices", reflect.TypeOf((*MockCacheService)(nil).DeleteChoices), voteId, choiceTitles)
+}
+
+// GetVersion mocks base method.
+func (m *MockCacheService) GetVersion(voteId int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", voteId)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Get indicates an expected call of Get.
-func (mr *MockCacheServiceMockRecorder) Get(voteTitle, choiceTitle interface{}) *gomock.Call {
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockCacheServiceMockRecorder) GetVersion(voteId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCacheService)(nil).Get), voteTitle, choiceTitle)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockCacheService)(nil).GetVersion), voteId)
+}
+
+// RenameChoice mocks base method.
+func (m *MockCacheService) RenameChoice(voteId int, choiceTitle, newTitle string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameChoice", voteId, choiceTitle, newTitle)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameChoice indicates an expected call of RenameChoice.
+func (mr *MockCacheServiceMockRecorder) RenameChoice(voteId, choiceTitle, newTitle interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameChoice", reflect.TypeOf((*MockCacheService)(nil).RenameChoice), voteId, choiceTitle, newTitle)
 }
 
 // Save mocks base method.
-func (m *MockCacheService) Save(voteTitle, choiceTitle string, count int, expireAt time.Duration) error {
+func (m *MockCacheService) Save(voteId int, choiceTitle string, count int, expireAt time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", voteTitle, choiceTitle, count, expireAt)
+	ret := m.ctrl.Call(m, "Save", voteId, choiceTitle, count, expireAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockCacheServiceMockRecorder) Save(voteTitle, choiceTitle, count, expireAt interface{}) *gomock.Call {
+func (mr *MockCacheServiceMockRecorder) Save(voteId, choiceTitle, count, expireAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockCacheService)(nil).Save), voteId, choiceTitle, count, expireAt)
+}
+
+// SaveVersion mocks base method.
+func (m *MockCacheService) SaveVersion(voteId int, version int64, expireAt time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVersion", voteId, version, expireAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVersion indicates an expected call of SaveVersion.
+func (mr *MockCacheServiceMockRecorder) SaveVersion(voteId, version, expireAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockCacheService)(nil).Save), voteTitle, choiceTitle, count, expireAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVersion", reflect.TypeOf((*MockCacheService)(nil).SaveVersion), voteId, version, expireAt)
 }
 
 // MockVoteService is a mock of VoteService interface.
@@ -118,6 +160,21 @@ func (mr *MockVoteServiceMockRecorder) Get(ctx, title interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockVoteService)(nil).Get), ctx, title)
 }
 
+// GetById mocks base method.
+func (m *MockVoteService) GetById(ctx context.Context, id int) (entity.Vote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(entity.Vote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockVoteServiceMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockVoteService)(nil).GetById), ctx, id)
+}
+
 // MockСhoiceRepository is a mock of СhoiceRepository interface.
 type MockСhoiceRepository struct {
 	ctrl     *gomock.Controller
@@ -141,6 +198,67 @@ func (m *MockСhoiceRepository) EXPECT() *MockСhoiceRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ChangeBallot mocks base method.
+func (m *MockСhoiceRepository) ChangeBallot(ctx context.Context, ballot entity.Ballot) ([]entity.ChoiceUpdate, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeBallot", ctx, ballot)
+	ret0, _ := ret[0].([]entity.ChoiceUpdate)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ChangeBallot indicates an expected call of ChangeBallot.
+func (mr *MockСhoiceRepositoryMockRecorder) ChangeBallot(ctx, ballot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeBallot", reflect.TypeOf((*MockСhoiceRepository)(nil).ChangeBallot), ctx, ballot)
+}
+
+// DecideTie mocks base method.
+func (m *MockСhoiceRepository) DecideTie(ctx context.Context, voteId int, choice string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideTie", ctx, voteId, choice)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DecideTie indicates an expected call of DecideTie.
+func (mr *MockСhoiceRepositoryMockRecorder) DecideTie(ctx, voteId, choice interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideTie", reflect.TypeOf((*MockСhoiceRepository)(nil).DecideTie), ctx, voteId, choice)
+}
+
+// EditPoll mocks base method.
+func (m *MockСhoiceRepository) EditPoll(ctx context.Context, voteId int, edit entity.PollEdit) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditPoll", ctx, voteId, edit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditPoll indicates an expected call of EditPoll.
+func (mr *MockСhoiceRepositoryMockRecorder) EditPoll(ctx, voteId, edit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditPoll", reflect.TypeOf((*MockСhoiceRepository)(nil).EditPoll), ctx, voteId, edit)
+}
+
+// FindBallot mocks base method.
+func (m *MockСhoiceRepository) FindBallot(ctx context.Context, voteId int, voterId string) (entity.Ballot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBallot", ctx, voteId, voterId)
+	ret0, _ := ret[0].(entity.Ballot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBallot indicates an expected call of FindBallot.
+func (mr *MockСhoiceRepositoryMockRecorder) FindBallot(ctx, voteId, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBallot", reflect.TypeOf((*MockСhoiceRepository)(nil).FindBallot), ctx, voteId, voterId)
+}
+
 // FindChoice mocks base method.
 func (m *MockСhoiceRepository) FindChoice(ctx context.Context, id int, choiceTitle string) (entity.Choice, error) {
 	m.ctrl.T.Helper()
@@ -171,6 +289,171 @@ func (mr *MockСhoiceRepositoryMockRecorder) FindChoices(ctx, id interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChoices", reflect.TypeOf((*MockСhoiceRepository)(nil).FindChoices), ctx, id)
 }
 
+// FindEligibleWeight mocks base method.
+func (m *MockСhoiceRepository) FindEligibleWeight(ctx context.Context, voteId int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEligibleWeight", ctx, voteId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEligibleWeight indicates an expected call of FindEligibleWeight.
+func (mr *MockСhoiceRepositoryMockRecorder) FindEligibleWeight(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEligibleWeight", reflect.TypeOf((*MockСhoiceRepository)(nil).FindEligibleWeight), ctx, voteId)
+}
+
+// FindHistograms mocks base method.
+func (m *MockСhoiceRepository) FindHistograms(ctx context.Context, voteId int) (map[string]entity.Histogram, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindHistograms", ctx, voteId)
+	ret0, _ := ret[0].(map[string]entity.Histogram)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHistograms indicates an expected call of FindHistograms.
+func (mr *MockСhoiceRepositoryMockRecorder) FindHistograms(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHistograms", reflect.TypeOf((*MockСhoiceRepository)(nil).FindHistograms), ctx, voteId)
+}
+
+// FindLastVotes mocks base method.
+func (m *MockСhoiceRepository) FindLastVotes(ctx context.Context, voteId int, choices []string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLastVotes", ctx, voteId, choices)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLastVotes indicates an expected call of FindLastVotes.
+func (mr *MockСhoiceRepositoryMockRecorder) FindLastVotes(ctx, voteId, choices interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLastVotes", reflect.TypeOf((*MockСhoiceRepository)(nil).FindLastVotes), ctx, voteId, choices)
+}
+
+// FindLog mocks base method.
+func (m *MockСhoiceRepository) FindLog(ctx context.Context, voteId int, after int64, limit int) ([]entity.LogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLog", ctx, voteId, after, limit)
+	ret0, _ := ret[0].([]entity.LogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLog indicates an expected call of FindLog.
+func (mr *MockСhoiceRepositoryMockRecorder) FindLog(ctx, voteId, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLog", reflect.TypeOf((*MockСhoiceRepository)(nil).FindLog), ctx, voteId, after, limit)
+}
+
+// FindLogUntil mocks base method.
+func (m *MockСhoiceRepository) FindLogUntil(ctx context.Context, voteId int, until time.Time) ([]entity.LogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLogUntil", ctx, voteId, until)
+	ret0, _ := ret[0].([]entity.LogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLogUntil indicates an expected call of FindLogUntil.
+func (mr *MockСhoiceRepositoryMockRecorder) FindLogUntil(ctx, voteId, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLogUntil", reflect.TypeOf((*MockСhoiceRepository)(nil).FindLogUntil), ctx, voteId, until)
+}
+
+// FindRankings mocks base method.
+func (m *MockСhoiceRepository) FindRankings(ctx context.Context, voteId int) ([][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRankings", ctx, voteId)
+	ret0, _ := ret[0].([][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRankings indicates an expected call of FindRankings.
+func (mr *MockСhoiceRepositoryMockRecorder) FindRankings(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRankings", reflect.TypeOf((*MockСhoiceRepository)(nil).FindRankings), ctx, voteId)
+}
+
+// FindReceipt mocks base method.
+func (m *MockСhoiceRepository) FindReceipt(ctx context.Context, voteId int, hash string) (entity.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReceipt", ctx, voteId, hash)
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReceipt indicates an expected call of FindReceipt.
+func (mr *MockСhoiceRepositoryMockRecorder) FindReceipt(ctx, voteId, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReceipt", reflect.TypeOf((*MockСhoiceRepository)(nil).FindReceipt), ctx, voteId, hash)
+}
+
+// FindVersion mocks base method.
+func (m *MockСhoiceRepository) FindVersion(ctx context.Context, voteId int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVersion", ctx, voteId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVersion indicates an expected call of FindVersion.
+func (mr *MockСhoiceRepositoryMockRecorder) FindVersion(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVersion", reflect.TypeOf((*MockСhoiceRepository)(nil).FindVersion), ctx, voteId)
+}
+
+// FindVoters mocks base method.
+func (m *MockСhoiceRepository) FindVoters(ctx context.Context, voteId int) ([]entity.ChoiceVoters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVoters", ctx, voteId)
+	ret0, _ := ret[0].([]entity.ChoiceVoters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVoters indicates an expected call of FindVoters.
+func (mr *MockСhoiceRepositoryMockRecorder) FindVoters(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVoters", reflect.TypeOf((*MockСhoiceRepository)(nil).FindVoters), ctx, voteId)
+}
+
+// FindWeights mocks base method.
+func (m *MockСhoiceRepository) FindWeights(ctx context.Context, voteId int, voterIds []string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWeights", ctx, voteId, voterIds)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWeights indicates an expected call of FindWeights.
+func (mr *MockСhoiceRepositoryMockRecorder) FindWeights(ctx, voteId, voterIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWeights", reflect.TypeOf((*MockСhoiceRepository)(nil).FindWeights), ctx, voteId, voterIds)
+}
+
+// HasVoted mocks base method.
+func (m *MockСhoiceRepository) HasVoted(ctx context.Context, voteId int, voterId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasVoted", ctx, voteId, voterId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasVoted indicates an expected call of HasVoted.
+func (mr *MockСhoiceRepositoryMockRecorder) HasVoted(ctx, voteId, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasVoted", reflect.TypeOf((*MockСhoiceRepository)(nil).HasVoted), ctx, voteId, voterId)
+}
+
 // Insert mocks base method.
 func (m *MockСhoiceRepository) Insert(ctx context.Context, choice entity.Choice) (string, error) {
 	m.ctrl.T.Helper()
@@ -186,17 +469,101 @@ func (mr *MockСhoiceRepositoryMockRecorder) Insert(ctx, choice interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockСhoiceRepository)(nil).Insert), ctx, choice)
 }
 
-// Update mocks base method.
-func (m *MockСhoiceRepository) Update(ctx context.Context, count, voteId int, title string) (int, error) {
+// MergeChoices mocks base method.
+func (m *MockСhoiceRepository) MergeChoices(ctx context.Context, voteId int, from, into string) ([]entity.ChoiceUpdate, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, count, voteId, title)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "MergeChoices", ctx, voteId, from, into)
+	ret0, _ := ret[0].([]entity.ChoiceUpdate)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// MergeChoices indicates an expected call of MergeChoices.
+func (mr *MockСhoiceRepositoryMockRecorder) MergeChoices(ctx, voteId, from, into interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeChoices", reflect.TypeOf((*MockСhoiceRepository)(nil).MergeChoices), ctx, voteId, from, into)
+}
+
+// RetractBallot mocks base method.
+func (m *MockСhoiceRepository) RetractBallot(ctx context.Context, voteId int, voterId string) ([]entity.ChoiceUpdate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetractBallot", ctx, voteId, voterId)
+	ret0, _ := ret[0].([]entity.ChoiceUpdate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetractBallot indicates an expected call of RetractBallot.
+func (mr *MockСhoiceRepositoryMockRecorder) RetractBallot(ctx, voteId, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetractBallot", reflect.TypeOf((*MockСhoiceRepository)(nil).RetractBallot), ctx, voteId, voterId)
+}
+
+// Update mocks base method.
+func (m *MockСhoiceRepository) Update(ctx context.Context, ballot entity.Ballot) ([]entity.ChoiceUpdate, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, ballot)
+	ret0, _ := ret[0].([]entity.ChoiceUpdate)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // Update indicates an expected call of Update.
-func (mr *MockСhoiceRepositoryMockRecorder) Update(ctx, count, voteId, title interface{}) *gomock.Call {
+func (mr *MockСhoiceRepositoryMockRecorder) Update(ctx, ballot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockСhoiceRepository)(nil).Update), ctx, ballot)
+}
+
+// UpdateBatch mocks base method.
+func (m *MockСhoiceRepository) UpdateBatch(ctx context.Context, ballots []entity.Ballot) ([]entity.BallotResult, []entity.ChoiceUpdate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBatch", ctx, ballots)
+	ret0, _ := ret[0].([]entity.BallotResult)
+	ret1, _ := ret[1].([]entity.ChoiceUpdate)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateBatch indicates an expected call of UpdateBatch.
+func (mr *MockСhoiceRepositoryMockRecorder) UpdateBatch(ctx, ballots interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBatch", reflect.TypeOf((*MockСhoiceRepository)(nil).UpdateBatch), ctx, ballots)
+}
+
+// MockResultPublisher is a mock of ResultPublisher interface.
+type MockResultPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockResultPublisherMockRecorder
+}
+
+// MockResultPublisherMockRecorder is the mock recorder for MockResultPublisher.
+type MockResultPublisherMockRecorder struct {
+	mock *MockResultPublisher
+}
+
+// NewMockResultPublisher creates a new mock instance.
+func NewMockResultPublisher(ctrl *gomock.Controller) *MockResultPublisher {
+	mock := &MockResultPublisher{ctrl: ctrl}
+	mock.recorder = &MockResultPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResultPublisher) EXPECT() *MockResultPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockResultPublisher) Publish(update entity.ChoiceUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", update)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockResultPublisherMockRecorder) Publish(update interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockСhoiceRepository)(nil).Update), ctx, count, voteId, title)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockResultPublisher)(nil).Publish), update)
 }
@@ -0,0 +1,103 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/domain/service/resultService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockResultSubscriber is a mock of ResultSubscriber interface.
+type MockResultSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockResultSubscriberMockRecorder
+}
+
+// MockResultSubscriberMockRecorder is the mock recorder for MockResultSubscriber.
+type MockResultSubscriberMockRecorder struct {
+	mock *MockResultSubscriber
+}
+
+// NewMockResultSubscriber creates a new mock instance.
+func NewMockResultSubscriber(ctrl *gomock.Controller) *MockResultSubscriber {
+	mock := &MockResultSubscriber{ctrl: ctrl}
+	mock.recorder = &MockResultSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResultSubscriber) EXPECT() *MockResultSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockResultSubscriber) Subscribe(ctx context.Context, voteId int) (<-chan entity.ChoiceUpdate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, voteId)
+	ret0, _ := ret[0].(<-chan entity.ChoiceUpdate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockResultSubscriberMockRecorder) Subscribe(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockResultSubscriber)(nil).Subscribe), ctx, voteId)
+}
+
+// MockChoiceReader is a mock of ChoiceReader interface.
+type MockChoiceReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockChoiceReaderMockRecorder
+}
+
+// MockChoiceReaderMockRecorder is the mock recorder for MockChoiceReader.
+type MockChoiceReaderMockRecorder struct {
+	mock *MockChoiceReader
+}
+
+// NewMockChoiceReader creates a new mock instance.
+func NewMockChoiceReader(ctrl *gomock.Controller) *MockChoiceReader {
+	mock := &MockChoiceReader{ctrl: ctrl}
+	mock.recorder = &MockChoiceReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockChoiceReader) EXPECT() *MockChoiceReaderMockRecorder {
+	return m.recorder
+}
+
+// CheckVisible mocks base method.
+func (m *MockChoiceReader) CheckVisible(ctx context.Context, voteId int, viewerId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckVisible", ctx, voteId, viewerId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckVisible indicates an expected call of CheckVisible.
+func (mr *MockChoiceReaderMockRecorder) CheckVisible(ctx, voteId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckVisible", reflect.TypeOf((*MockChoiceReader)(nil).CheckVisible), ctx, voteId, viewerId)
+}
+
+// GetById mocks base method.
+func (m *MockChoiceReader) GetById(ctx context.Context, voteId int, viewerId string) ([]entity.Choice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, voteId, viewerId)
+	ret0, _ := ret[0].([]entity.Choice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockChoiceReaderMockRecorder) GetById(ctx, voteId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockChoiceReader)(nil).GetById), ctx, voteId, viewerId)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/domain/service/scheduler.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPollAdvancer is a mock of PollAdvancer interface.
+type MockPollAdvancer struct {
+	ctrl     *gomock.Controller
+	recorder *MockPollAdvancerMockRecorder
+}
+
+// MockPollAdvancerMockRecorder is the mock recorder for MockPollAdvancer.
+type MockPollAdvancerMockRecorder struct {
+	mock *MockPollAdvancer
+}
+
+// NewMockPollAdvancer creates a new mock instance.
+func NewMockPollAdvancer(ctrl *gomock.Controller) *MockPollAdvancer {
+	mock := &MockPollAdvancer{ctrl: ctrl}
+	mock.recorder = &MockPollAdvancerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPollAdvancer) EXPECT() *MockPollAdvancerMockRecorder {
+	return m.recorder
+}
+
+// Advance mocks base method.
+func (m *MockPollAdvancer) Advance(ctx context.Context, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Advance", ctx, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Advance indicates an expected call of Advance.
+func (mr *MockPollAdvancerMockRecorder) Advance(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Advance", reflect.TypeOf((*MockPollAdvancer)(nil).Advance), ctx, now)
+}
@@ -7,7 +7,9 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	entity "github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -34,6 +36,36 @@ func (m *MockVoteRepository) EXPECT() *MockVoteRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ArchivePoll mocks base method.
+func (m *MockVoteRepository) ArchivePoll(ctx context.Context, id int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchivePoll", ctx, id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchivePoll indicates an expected call of ArchivePoll.
+func (mr *MockVoteRepositoryMockRecorder) ArchivePoll(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchivePoll", reflect.TypeOf((*MockVoteRepository)(nil).ArchivePoll), ctx, id)
+}
+
+// ClosePoll mocks base method.
+func (m *MockVoteRepository) ClosePoll(ctx context.Context, id int, at time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClosePoll", ctx, id, at)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClosePoll indicates an expected call of ClosePoll.
+func (mr *MockVoteRepositoryMockRecorder) ClosePoll(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClosePoll", reflect.TypeOf((*MockVoteRepository)(nil).ClosePoll), ctx, id, at)
+}
+
 // Delete mocks base method.
 func (m *MockVoteRepository) Delete(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -63,6 +95,51 @@ func (mr *MockVoteRepositoryMockRecorder) Find(ctx, title interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockVoteRepository)(nil).Find), ctx, title)
 }
 
+// FindById mocks base method.
+func (m *MockVoteRepository) FindById(ctx context.Context, id int) (entity.Vote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", ctx, id)
+	ret0, _ := ret[0].(entity.Vote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockVoteRepositoryMockRecorder) FindById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockVoteRepository)(nil).FindById), ctx, id)
+}
+
+// FindClosing mocks base method.
+func (m *MockVoteRepository) FindClosing(ctx context.Context, now time.Time) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindClosing", ctx, now)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindClosing indicates an expected call of FindClosing.
+func (mr *MockVoteRepositoryMockRecorder) FindClosing(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindClosing", reflect.TypeOf((*MockVoteRepository)(nil).FindClosing), ctx, now)
+}
+
+// FindWeights mocks base method.
+func (m *MockVoteRepository) FindWeights(ctx context.Context, voteId int) ([]entity.VoterWeight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWeights", ctx, voteId)
+	ret0, _ := ret[0].([]entity.VoterWeight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWeights indicates an expected call of FindWeights.
+func (mr *MockVoteRepositoryMockRecorder) FindWeights(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWeights", reflect.TypeOf((*MockVoteRepository)(nil).FindWeights), ctx, voteId)
+}
+
 // Insert mocks base method.
 func (m *MockVoteRepository) Insert(ctx context.Context, vote string) (int, error) {
 	m.ctrl.T.Helper()
@@ -77,3 +154,129 @@ func (mr *MockVoteRepositoryMockRecorder) Insert(ctx, vote interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockVoteRepository)(nil).Insert), ctx, vote)
 }
+
+// InsertPoll mocks base method.
+func (m *MockVoteRepository) InsertPoll(ctx context.Context, poll entity.Poll) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPoll", ctx, poll)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPoll indicates an expected call of InsertPoll.
+func (mr *MockVoteRepositoryMockRecorder) InsertPoll(ctx, poll interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPoll", reflect.TypeOf((*MockVoteRepository)(nil).InsertPoll), ctx, poll)
+}
+
+// List mocks base method.
+func (m *MockVoteRepository) List(ctx context.Context, query entity.VoteQuery) ([]entity.Vote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].([]entity.Vote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockVoteRepositoryMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockVoteRepository)(nil).List), ctx, query)
+}
+
+// OpenDue mocks base method.
+func (m *MockVoteRepository) OpenDue(ctx context.Context, now time.Time) (map[int]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDue", ctx, now)
+	ret0, _ := ret[0].(map[int]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDue indicates an expected call of OpenDue.
+func (mr *MockVoteRepositoryMockRecorder) OpenDue(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDue", reflect.TypeOf((*MockVoteRepository)(nil).OpenDue), ctx, now)
+}
+
+// OpenPoll mocks base method.
+func (m *MockVoteRepository) OpenPoll(ctx context.Context, id int, at time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenPoll", ctx, id, at)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenPoll indicates an expected call of OpenPoll.
+func (mr *MockVoteRepositoryMockRecorder) OpenPoll(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenPoll", reflect.TypeOf((*MockVoteRepository)(nil).OpenPoll), ctx, id, at)
+}
+
+// ReopenPoll mocks base method.
+func (m *MockVoteRepository) ReopenPoll(ctx context.Context, id int, closesAt *time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReopenPoll", ctx, id, closesAt)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReopenPoll indicates an expected call of ReopenPoll.
+func (mr *MockVoteRepositoryMockRecorder) ReopenPoll(ctx, id, closesAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReopenPoll", reflect.TypeOf((*MockVoteRepository)(nil).ReopenPoll), ctx, id, closesAt)
+}
+
+// ReplaceWeights mocks base method.
+func (m *MockVoteRepository) ReplaceWeights(ctx context.Context, voteId int, weights []entity.VoterWeight) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceWeights", ctx, voteId, weights)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceWeights indicates an expected call of ReplaceWeights.
+func (mr *MockVoteRepositoryMockRecorder) ReplaceWeights(ctx, voteId, weights interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceWeights", reflect.TypeOf((*MockVoteRepository)(nil).ReplaceWeights), ctx, voteId, weights)
+}
+
+// MockVersionCache is a mock of VersionCache interface.
+type MockVersionCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockVersionCacheMockRecorder
+}
+
+// MockVersionCacheMockRecorder is the mock recorder for MockVersionCache.
+type MockVersionCacheMockRecorder struct {
+	mock *MockVersionCache
+}
+
+// NewMockVersionCache creates a new mock instance.
+func NewMockVersionCache(ctrl *gomock.Controller) *MockVersionCache {
+	mock := &MockVersionCache{ctrl: ctrl}
+	mock.recorder = &MockVersionCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVersionCache) EXPECT() *MockVersionCacheMockRecorder {
+	return m.recorder
+}
+
+// SaveVersion mocks base method.
+func (m *MockVersionCache) SaveVersion(voteId int, version int64, expireAt time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVersion", voteId, version, expireAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVersion indicates an expected call of SaveVersion.
+func (mr *MockVersionCacheMockRecorder) SaveVersion(voteId, version, expireAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVersion", reflect.TypeOf((*MockVersionCache)(nil).SaveVersion), voteId, version, expireAt)
+}
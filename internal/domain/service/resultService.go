@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+)
+
+const (
+	deltaInterval     time.Duration = 500 * time.Millisecond
+	heartbeatInterval               = 15 * time.Second
+)
+
+type ResultSubscriber interface {
+	Subscribe(ctx context.Context, voteId int) (<-chan entity.ChoiceUpdate, error)
+}
+
+type ChoiceReader interface {
+	GetById(ctx context.Context, voteId int, viewerId string) ([]entity.Choice, error)
+	CheckVisible(ctx context.Context, voteId int, viewerId string) error
+}
+
+// resultService streams vote results: a snapshot of all choices first, then
+// the changed choices at most once per deltaInterval and a heartbeat when
+// nothing has changed for heartbeatInterval.
+type resultService struct {
+	subscriber        ResultSubscriber
+	vote              VoteService
+	choices           ChoiceReader
+	logger            *logging.Logger
+	deltaInterval     time.Duration
+	heartbeatInterval time.Duration
+}
+
+func NewResultService(subscriber ResultSubscriber, vote VoteService, choices ChoiceReader, logger *logging.Logger) *resultService {
+	return &resultService{
+		subscriber:        subscriber,
+		vote:              vote,
+		choices:           choices,
+		logger:            logger,
+		deltaInterval:     deltaInterval,
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// Stream calls send for every event of the vote until ctx is done or send
+// fails. The subscription starts before the snapshot is read, so an update
+// that happens in between is delivered as a delta instead of being lost.
+// The snapshot is read with the visibility policy of the vote, so a viewer
+// who can't see the results gets the error before any event. The policy is
+// checked again before every delta, the stream ends with the error once the
+// results become hidden, e.g. when a closed vote is reopened.
+func (r *resultService) Stream(ctx context.Context, voteId int, viewerId string, send func(event entity.ResultEvent) error) error {
+	r.logger.Debugf("try to stream results of vote id = %v", voteId)
+	if _, err := r.vote.GetById(ctx, voteId); err != nil {
+		r.logger.Errorf("cannot stream vote id = %v due to %v", voteId, err)
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates, err := r.subscriber.Subscribe(ctx, voteId)
+	if err != nil {
+		r.logger.Errorf("cannot subscribe to vote id = %v due to %v", voteId, err)
+		return err
+	}
+	choices, err := r.choices.GetById(ctx, voteId, viewerId)
+	if err != nil {
+		return err
+	}
+	if err := send(entity.ResultEvent{Kind: entity.SnapshotEvent, VoteId: voteId, Choices: choices}); err != nil {
+		return err
+	}
+	delta := time.NewTicker(r.deltaInterval)
+	defer delta.Stop()
+	heartbeat := time.NewTicker(r.heartbeatInterval)
+	defer heartbeat.Stop()
+	pending := make([]entity.Choice, 0)
+	index := make(map[string]int)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if i, ok := index[update.Choice]; ok {
+				pending[i].Count = update.Count
+				continue
+			}
+			index[update.Choice] = len(pending)
+			pending = append(pending, entity.Choice{Title: update.Choice, VoteId: voteId, Count: update.Count})
+		case <-delta.C:
+			if len(pending) == 0 {
+				continue
+			}
+			if err := r.choices.CheckVisible(ctx, voteId, viewerId); err != nil {
+				r.logger.Debugf("stop stream of vote id = %v due to %v", voteId, err)
+				return err
+			}
+			if err := send(entity.ResultEvent{Kind: entity.DeltaEvent, VoteId: voteId, Choices: pending}); err != nil {
+				return err
+			}
+			pending = make([]entity.Choice, 0)
+			index = make(map[string]int)
+			heartbeat.Reset(r.heartbeatInterval)
+		case <-heartbeat.C:
+			if err := send(entity.ResultEvent{Kind: entity.HeartbeatEvent, VoteId: voteId, Time: time.Now().UTC()}); err != nil {
+				return err
+			}
+		}
+	}
+}
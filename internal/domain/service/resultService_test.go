@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/domain/service/mocks"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	subscriber := mocks.NewMockResultSubscriber(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	choiceReader := mocks.NewMockChoiceReader(ctrl)
+	defer ctrl.Finish()
+	resultService := NewResultService(subscriber, voteService, choiceReader, logging.GetLogger("debug"))
+	resultService.deltaInterval = 10 * time.Millisecond
+	resultService.heartbeatInterval = time.Hour
+
+	updates := make(chan entity.ChoiceUpdate)
+	voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote"}, nil)
+	subscriber.EXPECT().Subscribe(gomock.Any(), 1).Return(updates, nil)
+	choiceReader.EXPECT().GetById(gomock.Any(), 1, "voter").Return([]entity.Choice{{Title: "first", VoteId: 1, Count: 1}, {Title: "second", VoteId: 1, Count: 2}}, nil)
+	choiceReader.EXPECT().CheckVisible(gomock.Any(), 1, "voter").Return(nil)
+
+	events := make([]entity.ResultEvent, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- resultService.Stream(ctx, 1, "voter", func(event entity.ResultEvent) error {
+			events = append(events, event)
+			return nil
+		})
+	}()
+	updates <- entity.ChoiceUpdate{VoteId: 1, Choice: "second", Count: 3}
+	updates <- entity.ChoiceUpdate{VoteId: 1, Choice: "first", Count: 2}
+	updates <- entity.ChoiceUpdate{VoteId: 1, Choice: "second", Count: 4}
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	assert.NoError(t, <-done)
+
+	want := []entity.ResultEvent{
+		{Kind: entity.SnapshotEvent, VoteId: 1, Choices: []entity.Choice{{Title: "first", VoteId: 1, Count: 1}, {Title: "second", VoteId: 1, Count: 2}}},
+		{Kind: entity.DeltaEvent, VoteId: 1, Choices: []entity.Choice{{Title: "second", VoteId: 1, Count: 4}, {Title: "first", VoteId: 1, Count: 2}}},
+	}
+	assert.Equal(t, want, events)
+}
+
+func TestStreamResultsBecomeHidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	subscriber := mocks.NewMockResultSubscriber(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	choiceReader := mocks.NewMockChoiceReader(ctrl)
+	defer ctrl.Finish()
+	resultService := NewResultService(subscriber, voteService, choiceReader, logging.GetLogger("debug"))
+	resultService.deltaInterval = 10 * time.Millisecond
+	resultService.heartbeatInterval = time.Hour
+
+	updates := make(chan entity.ChoiceUpdate, 1)
+	voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote"}, nil)
+	subscriber.EXPECT().Subscribe(gomock.Any(), 1).Return(updates, nil)
+	choiceReader.EXPECT().GetById(gomock.Any(), 1, "voter").Return([]entity.Choice{{Title: "first", VoteId: 1, Count: 1}}, nil)
+	choiceReader.EXPECT().CheckVisible(gomock.Any(), 1, "voter").Return(errs.ErrResultsHidden)
+
+	kinds := make([]string, 0)
+	updates <- entity.ChoiceUpdate{VoteId: 1, Choice: "first", Count: 2}
+	err := resultService.Stream(context.Background(), 1, "voter", func(event entity.ResultEvent) error {
+		kinds = append(kinds, event.Kind)
+		return nil
+	})
+	assert.ErrorIs(t, err, errs.ErrResultsHidden)
+	assert.Equal(t, []string{entity.SnapshotEvent}, kinds)
+}
+
+func TestStreamHeartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	subscriber := mocks.NewMockResultSubscriber(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	choiceReader := mocks.NewMockChoiceReader(ctrl)
+	defer ctrl.Finish()
+	resultService := NewResultService(subscriber, voteService, choiceReader, logging.GetLogger("debug"))
+	resultService.heartbeatInterval = 10 * time.Millisecond
+
+	voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote"}, nil)
+	subscriber.EXPECT().Subscribe(gomock.Any(), 1).Return(make(chan entity.ChoiceUpdate), nil)
+	choiceReader.EXPECT().GetById(gomock.Any(), 1, "voter").Return([]entity.Choice{}, nil)
+
+	sendErr := errors.New("client is gone")
+	kinds := make([]string, 0)
+	err := resultService.Stream(context.Background(), 1, "voter", func(event entity.ResultEvent) error {
+		kinds = append(kinds, event.Kind)
+		if event.Kind == entity.HeartbeatEvent {
+			return sendErr
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, sendErr)
+	assert.Equal(t, []string{entity.SnapshotEvent, entity.HeartbeatEvent}, kinds)
+}
+
+func TestStreamErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	subscriber := mocks.NewMockResultSubscriber(ctrl)
+	voteService := mocks.NewMockVoteService(ctrl)
+	choiceReader := mocks.NewMockChoiceReader(ctrl)
+	defer ctrl.Finish()
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		want  error
+	}{
+		{
+			title: "vote doesn't exist and Stream() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			want: errs.ErrVoteNotExist,
+		},
+		{
+			title: "cannot subscribe and Stream() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1}, nil)
+				subscriber.EXPECT().Subscribe(gomock.Any(), 1).Return(nil, errors.New("redis internal error"))
+			},
+			want: errors.New("redis internal error"),
+		},
+		{
+			title: "results are hidden and Stream() should return error",
+			mock: func() {
+				voteService.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1}, nil)
+				subscriber.EXPECT().Subscribe(gomock.Any(), 1).Return(make(chan entity.ChoiceUpdate), nil)
+				choiceReader.EXPECT().GetById(gomock.Any(), 1, "voter").Return(nil, errs.ErrResultsHidden)
+			},
+			want: errs.ErrResultsHidden,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			resultService := NewResultService(subscriber, voteService, choiceReader, logging.GetLogger("debug"))
+			err := resultService.Stream(context.Background(), 1, "voter", func(event entity.ResultEvent) error {
+				t.Fatal("nothing should be sent")
+				return nil
+			})
+			assert.EqualError(t, err, test.want.Error())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+)
+
+const scheduleInterval time.Duration = 10 * time.Second
+
+type PollAdvancer interface {
+	Advance(ctx context.Context, now time.Time) error
+}
+
+// scheduler opens and closes the votes on their schedules every interval,
+// a failed run is logged and retried with the next tick.
+type scheduler struct {
+	polls    PollAdvancer
+	logger   *logging.Logger
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     sync.WaitGroup
+}
+
+func NewScheduler(polls PollAdvancer, logger *logging.Logger) *scheduler {
+	return &scheduler{polls: polls, logger: logger, interval: scheduleInterval}
+}
+
+// Start runs the scheduler in the background until it is closed.
+func (s *scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		s.run(ctx)
+	}()
+}
+
+func (s *scheduler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.advance(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *scheduler) advance(ctx context.Context) {
+	if err := s.polls.Advance(ctx, time.Now()); err != nil && ctx.Err() == nil {
+		s.logger.Errorf("scheduled transition failed due to %v", err)
+	}
+}
+
+// Close stops the scheduler and waits for the current run to finish.
+func (s *scheduler) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.done.Wait()
+	return nil
+}
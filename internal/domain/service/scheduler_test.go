@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/service/mocks"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	polls := mocks.NewMockPollAdvancer(ctrl)
+	scheduler := NewScheduler(polls, logging.GetLogger("debug"))
+	scheduler.interval = 10 * time.Millisecond
+
+	advanced := make(chan struct{}, 1)
+	gomock.InOrder(
+		polls.EXPECT().Advance(gomock.Any(), gomock.Any()).Return(errors.New("psql error")),
+		polls.EXPECT().Advance(gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ interface{}) error {
+			select {
+			case advanced <- struct{}{}:
+			default:
+			}
+			return nil
+		}).MinTimes(1),
+	)
+	scheduler.Start()
+	select {
+	case <-advanced:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler didn't retry after the failed run")
+	}
+	assert.NoError(t, scheduler.Close())
+}
@@ -2,7 +2,13 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"errors"
+	"math"
+	"math/big"
+	"time"
 
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	"github.com/VrMolodyakov/vote-service/internal/errs"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
 )
@@ -10,16 +16,42 @@ import (
 type VoteRepository interface {
 	Delete(ctx context.Context, id string) error
 	Find(ctx context.Context, title string) (int, error)
+	FindById(ctx context.Context, id int) (entity.Vote, error)
 	Insert(ctx context.Context, vote string) (int, error)
+	InsertPoll(ctx context.Context, poll entity.Poll) (int, error)
+	List(ctx context.Context, query entity.VoteQuery) ([]entity.Vote, error)
+	ReplaceWeights(ctx context.Context, voteId int, weights []entity.VoterWeight) error
+	FindWeights(ctx context.Context, voteId int) ([]entity.VoterWeight, error)
+	OpenPoll(ctx context.Context, id int, at time.Time) (int64, error)
+	ClosePoll(ctx context.Context, id int, at time.Time) (int64, error)
+	ReopenPoll(ctx context.Context, id int, closesAt *time.Time) (int64, error)
+	ArchivePoll(ctx context.Context, id int) (int64, error)
+	OpenDue(ctx context.Context, now time.Time) (map[int]int64, error)
+	FindClosing(ctx context.Context, now time.Time) ([]int, error)
+}
+
+const (
+	defaultPageSize int = 20
+	maxPageSize         = 100
+	maxScale            = 100
+	maxWeight           = 1000000
+	maxWeights          = 10000
+)
+
+// VersionCache keeps the versions of the vote results the ETags are made of,
+// the greater version is kept.
+type VersionCache interface {
+	SaveVersion(voteId int, version int64, expireAt time.Duration) error
 }
 
 type voteService struct {
 	repo   VoteRepository
+	cache  VersionCache
 	logger *logging.Logger
 }
 
-func NewVoteService(repo VoteRepository, logger *logging.Logger) *voteService {
-	return &voteService{repo: repo, logger: logger}
+func NewVoteService(repo VoteRepository, cache VersionCache, logger *logging.Logger) *voteService {
+	return &voteService{repo: repo, cache: cache, logger: logger}
 }
 
 func (v *voteService) Create(ctx context.Context, title string) (int, error) {
@@ -36,6 +68,153 @@ func (v *voteService) Create(ctx context.Context, title string) (int, error) {
 	return vote, nil
 }
 
+// CreatePoll validates the vote and all of its choices before anything is
+// written, so a poll is either created together with its choices or not at all.
+func (v *voteService) CreatePoll(ctx context.Context, poll entity.Poll) (int, error) {
+	v.logger.Debugf("try to create poll with title %v and choices %v", poll.Title, poll.Choices)
+	if poll.Title == "" {
+		return -1, errs.ErrEmptyVoteTitle
+	}
+	unique := make(map[string]struct{}, len(poll.Choices))
+	for _, choice := range poll.Choices {
+		if choice == "" {
+			return -1, errs.ErrEmptyChoiceTitle
+		}
+		if _, ok := unique[choice]; ok {
+			return -1, errs.ErrDuplicateChoice
+		}
+		unique[choice] = struct{}{}
+	}
+	poll.Method = poll.CountingMethod()
+	if poll.Method != entity.MethodPlurality && poll.Method != entity.MethodScore && !entity.Ranked(poll.Method) {
+		return -1, errs.ErrInvalidMethod
+	}
+	min, max := poll.Selections()
+	if min < 1 || max < min || (len(poll.Choices) > 0 && max > len(poll.Choices)) {
+		return -1, errs.ErrInvalidSelections
+	}
+	seats, transfer := poll.Election()
+	if seats < 1 || (poll.Method != entity.MethodStv && seats > 1) || (len(poll.Choices) > 0 && seats > len(poll.Choices)) {
+		return -1, errs.ErrInvalidSeats
+	}
+	if poll.Method == entity.MethodStv && transfer != entity.TransferGregory && transfer != entity.TransferHare ||
+		poll.Method != entity.MethodStv && transfer != "" {
+		return -1, errs.ErrInvalidTransfer
+	}
+	minScore, maxScore := poll.Scale()
+	if poll.Method == entity.MethodScore && (minScore < 0 || maxScore <= minScore || maxScore > maxScale) ||
+		poll.Method != entity.MethodScore && (minScore != 0 || maxScore != 0) {
+		return -1, errs.ErrInvalidScale
+	}
+	if poll.Weighted && poll.Method != entity.MethodPlurality {
+		return -1, errs.ErrInvalidWeighted
+	}
+	if poll.AllowWriteIn && poll.Method == entity.MethodScore {
+		return -1, errs.ErrInvalidWriteIn
+	}
+	if !poll.Rules.Empty() {
+		if err := validateRules(poll, max); err != nil {
+			return -1, err
+		}
+		poll.Rules.Threshold = poll.Rules.PassingThreshold()
+	}
+	poll.TieBreak = poll.TieBreaking()
+	if err := validateTieBreak(poll); err != nil {
+		return -1, err
+	}
+	if poll.TieBreak == entity.TieBreakRandom && poll.TieSeed == 0 {
+		seed, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+		if err != nil {
+			v.logger.Errorf("couldn't draw tie seed due to %v", err)
+			return -1, err
+		}
+		// 0 stands for no seed, so the drawn one starts from 1
+		poll.TieSeed = seed.Int64() + 1
+	}
+	poll.Privacy = poll.BallotPrivacy()
+	if err := validatePrivacy(poll.Privacy, poll.Weighted); err != nil {
+		return -1, err
+	}
+	poll.Visibility = poll.ResultVisibility()
+	if poll.Visibility != entity.VisibilityAlways && poll.Visibility != entity.VisibilityAfterVote && poll.Visibility != entity.VisibilityAfterClose {
+		return -1, errs.ErrInvalidVisibility
+	}
+	if poll.ClosesAt != nil && (!poll.ClosesAt.After(time.Now()) || poll.OpensAt != nil && !poll.ClosesAt.After(*poll.OpensAt)) {
+		return -1, errs.ErrInvalidSchedule
+	}
+	poll.MinSelections, poll.MaxSelections = min, max
+	poll.Seats, poll.Transfer = seats, transfer
+	poll.MinScore, poll.MaxScore = minScore, maxScore
+	id, err := v.repo.InsertPoll(ctx, poll)
+	if err != nil {
+		v.logger.Errorf("couldn't create poll for title = %v ", poll.Title)
+		return -1, err
+	}
+	return id, nil
+}
+
+// validateRules checks the quorum and threshold rules of the poll, they
+// apply to the polls whose ballots select one choice only.
+func validateRules(poll entity.Poll, max int) error {
+	rules := poll.Rules
+	if poll.Method != entity.MethodPlurality || max != 1 {
+		return errs.ErrInvalidRules
+	}
+	if rules.Abstention != "" {
+		found := false
+		for _, choice := range poll.Choices {
+			found = found || choice == rules.Abstention
+		}
+		if !found {
+			return errs.ErrInvalidRules
+		}
+	}
+	if rules.Quorum < 0 || rules.QuorumPercent < 0 || rules.QuorumPercent > 100 || rules.QuorumPercent > 0 && !poll.Weighted {
+		return errs.ErrInvalidQuorum
+	}
+	switch rules.PassingThreshold() {
+	case entity.ThresholdPlurality, entity.ThresholdMajority, entity.ThresholdTwoThirds:
+		if rules.ThresholdPercent != 0 {
+			return errs.ErrInvalidThreshold
+		}
+	case entity.ThresholdPercent:
+		if rules.ThresholdPercent < 1 || rules.ThresholdPercent > 100 {
+			return errs.ErrInvalidThreshold
+		}
+	default:
+		return errs.ErrInvalidThreshold
+	}
+	return nil
+}
+
+// validateTieBreak checks the tie-break of the poll, a ranked poll has its
+// own count and the seed is set for a random tie-break only.
+func validateTieBreak(poll entity.Poll) error {
+	switch poll.TieBreak {
+	case entity.TieBreakNone, entity.TieBreakEarliest, entity.TieBreakRandom, entity.TieBreakOwner:
+	default:
+		return errs.ErrInvalidTieBreak
+	}
+	if entity.Ranked(poll.Method) && poll.TieBreak != entity.TieBreakNone || poll.TieBreak != entity.TieBreakRandom && poll.TieSeed != 0 {
+		return errs.ErrInvalidTieBreak
+	}
+	return nil
+}
+
+// validatePrivacy checks the ballot privacy mode, a weight can single out
+// the voter of an anonymous ballot, so an anonymous poll isn't weighted.
+func validatePrivacy(privacy string, weighted bool) error {
+	switch privacy {
+	case entity.PrivacyPrivate, entity.PrivacyPublic:
+		return nil
+	case entity.PrivacyAnonymous:
+		if !weighted {
+			return nil
+		}
+	}
+	return errs.ErrInvalidPrivacy
+}
+
 func (v *voteService) Get(ctx context.Context, title string) (int, error) {
 	v.logger.Debugf("try to get vote with title %v", title)
 	if title == "" {
@@ -44,6 +223,221 @@ func (v *voteService) Get(ctx context.Context, title string) (int, error) {
 	return v.repo.Find(ctx, title)
 }
 
+func (v *voteService) GetById(ctx context.Context, id int) (entity.Vote, error) {
+	v.logger.Debugf("try to get vote with id %v", id)
+	if id <= 0 {
+		return entity.Vote{}, errs.ErrInvalidVoteId
+	}
+	return v.repo.FindById(ctx, id)
+}
+
+// SetWeights replaces the eligible voters of the weighted vote, only the
+// listed voters can cast a ballot. The ballots already cast keep their weights.
+// Only the owner of the vote sets the weights.
+func (v *voteService) SetWeights(ctx context.Context, id int, weights []entity.VoterWeight, voterId string) error {
+	v.logger.Debugf("try to set %v weights of vote with id %v by %v", len(weights), id, voterId)
+	vote, err := v.ownedVote(ctx, id, voterId)
+	if err != nil {
+		return err
+	}
+	if !vote.Weighted {
+		return errs.ErrVoteNotWeighted
+	}
+	if len(weights) > maxWeights {
+		return errs.ErrInvalidWeights
+	}
+	unique := make(map[string]struct{}, len(weights))
+	for _, weight := range weights {
+		if err = validateVoter(weight.VoterId); err != nil {
+			return err
+		}
+		if _, ok := unique[weight.VoterId]; ok || weight.Weight < 1 || weight.Weight > maxWeight {
+			return errs.ErrInvalidWeights
+		}
+		unique[weight.VoterId] = struct{}{}
+	}
+	return v.repo.ReplaceWeights(ctx, vote.Id, weights)
+}
+
+// GetWeights returns the eligible voters of the weighted vote to its owner.
+func (v *voteService) GetWeights(ctx context.Context, id int, voterId string) ([]entity.VoterWeight, error) {
+	v.logger.Debugf("try to get weights of vote with id %v by %v", id, voterId)
+	vote, err := v.ownedVote(ctx, id, voterId)
+	if err != nil {
+		return nil, err
+	}
+	if !vote.Weighted {
+		return nil, errs.ErrVoteNotWeighted
+	}
+	return v.repo.FindWeights(ctx, vote.Id)
+}
+
+// ownedVote returns the vote if the voter is its owner, ErrNotOwner is
+// returned otherwise.
+func (v *voteService) ownedVote(ctx context.Context, id int, voterId string) (entity.Vote, error) {
+	if err := validateVoter(voterId); err != nil {
+		return entity.Vote{}, err
+	}
+	vote, err := v.GetById(ctx, id)
+	if err != nil {
+		return entity.Vote{}, err
+	}
+	if voterId != vote.OwnerId {
+		return entity.Vote{}, errs.ErrNotOwner
+	}
+	return vote, nil
+}
+
+// Open opens the draft for ballots before its opens_at, only the owner of
+// the vote opens it.
+func (v *voteService) Open(ctx context.Context, id int, voterId string) error {
+	v.logger.Debugf("try to open vote with id %v by %v", id, voterId)
+	vote, err := v.ownedVote(ctx, id, voterId)
+	if err != nil {
+		return err
+	}
+	version, err := v.repo.OpenPoll(ctx, vote.Id, time.Now())
+	if err != nil {
+		return err
+	}
+	v.saveVersion(vote.Id, version)
+	return nil
+}
+
+// Close stops the voting and freezes the final counts of the vote, only the
+// owner of the vote closes it before its closes_at.
+func (v *voteService) Close(ctx context.Context, id int, voterId string) error {
+	v.logger.Debugf("try to close vote with id %v by %v", id, voterId)
+	vote, err := v.ownedVote(ctx, id, voterId)
+	if err != nil {
+		return err
+	}
+	version, err := v.repo.ClosePoll(ctx, vote.Id, time.Now())
+	if err != nil {
+		return err
+	}
+	v.saveVersion(vote.Id, version)
+	return nil
+}
+
+// Reopen opens the closed vote again until closesAt, or until it is closed
+// by hand if closesAt is nil. Only the owner of the vote reopens it.
+func (v *voteService) Reopen(ctx context.Context, id int, closesAt *time.Time, voterId string) error {
+	v.logger.Debugf("try to reopen vote with id %v until %v by %v", id, closesAt, voterId)
+	if closesAt != nil && !closesAt.After(time.Now()) {
+		return errs.ErrInvalidSchedule
+	}
+	vote, err := v.ownedVote(ctx, id, voterId)
+	if err != nil {
+		return err
+	}
+	version, err := v.repo.ReopenPoll(ctx, vote.Id, closesAt)
+	if err != nil {
+		return err
+	}
+	v.saveVersion(vote.Id, version)
+	return nil
+}
+
+// Archive archives the closed vote for good, only its owner archives it.
+func (v *voteService) Archive(ctx context.Context, id int, voterId string) error {
+	v.logger.Debugf("try to archive vote with id %v by %v", id, voterId)
+	vote, err := v.ownedVote(ctx, id, voterId)
+	if err != nil {
+		return err
+	}
+	version, err := v.repo.ArchivePoll(ctx, vote.Id)
+	if err != nil {
+		return err
+	}
+	v.saveVersion(vote.Id, version)
+	return nil
+}
+
+// saveVersion caches the version of the results changed by a transition, so
+// the ETags of the results before it aren't matched anymore.
+func (v *voteService) saveVersion(voteId int, version int64) {
+	if err := v.cache.SaveVersion(voteId, version, expire); err != nil {
+		v.logger.Errorf("cache.SaveVersion() error due to %v", err)
+	}
+}
+
+// Advance moves the votes along their schedules: the drafts whose opens_at
+// has come are opened and the open votes whose closes_at has passed are
+// closed. A vote closed by hand in the meantime is skipped.
+func (v *voteService) Advance(ctx context.Context, now time.Time) error {
+	opened, err := v.repo.OpenDue(ctx, now)
+	if err != nil {
+		v.logger.Errorf("couldn't open scheduled votes due to %v", err)
+		return err
+	}
+	for id, version := range opened {
+		v.saveVersion(id, version)
+	}
+	closing, err := v.repo.FindClosing(ctx, now)
+	if err != nil {
+		v.logger.Errorf("couldn't find votes to close due to %v", err)
+		return err
+	}
+	for _, id := range closing {
+		version, err := v.repo.ClosePoll(ctx, id, now)
+		if errors.Is(err, errs.ErrInvalidTransition) || errors.Is(err, errs.ErrVoteNotExist) {
+			continue
+		}
+		if err != nil {
+			v.logger.Errorf("couldn't close vote with id %v due to %v", id, err)
+			return err
+		}
+		v.saveVersion(id, version)
+	}
+	if len(opened) > 0 || len(closing) > 0 {
+		v.logger.Infof("opened votes %v and closed votes %v on schedule", opened, closing)
+	}
+	return nil
+}
+
+func (v *voteService) List(ctx context.Context, query entity.VoteQuery) (entity.VotePage, error) {
+	v.logger.Debugf("try to list votes with %+v", query)
+	if query.Sort == "" {
+		query.Sort = entity.SortByCreated
+	}
+	if query.Sort != entity.SortByCreated && query.Sort != entity.SortByVotes {
+		return entity.VotePage{}, errs.ErrInvalidSort
+	}
+	if query.After != nil && (query.After.Sort != query.Sort || query.After.Desc != query.Desc) {
+		return entity.VotePage{}, errs.ErrInvalidCursor
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+	if query.Limit > maxPageSize {
+		query.Limit = maxPageSize
+	}
+	if query.Now.IsZero() {
+		query.Now = time.Now()
+	}
+	limit := query.Limit
+	query.Limit++
+	votes, err := v.repo.List(ctx, query)
+	if err != nil {
+		v.logger.Errorf("couldn't list votes due to %v", err)
+		return entity.VotePage{}, err
+	}
+	page := entity.VotePage{Votes: votes}
+	if len(votes) > limit {
+		page.Votes = votes[:limit]
+		last := page.Votes[limit-1]
+		page.Next = &entity.VoteCursor{
+			Sort:      query.Sort,
+			Desc:      query.Desc,
+			CreatedAt: last.CreatedAt,
+			Total:     last.Total,
+			Id:        last.Id,
+		}
+	}
+	return page, nil
+}
+
 func (v *voteService) Delete(ctx context.Context, id string) error {
 	v.logger.Debugf("try to get vote with title %v", id)
 	if id == "" {
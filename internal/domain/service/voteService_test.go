@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	"github.com/VrMolodyakov/vote-service/internal/domain/service/mocks"
 	"github.com/VrMolodyakov/vote-service/internal/errs"
 	"github.com/VrMolodyakov/vote-service/pkg/logging"
@@ -29,7 +31,7 @@ func TestCreate(t *testing.T) {
 			mockCall: func() *voteService {
 				mockRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(1, nil)
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "voteTitle",
 			want:    1,
@@ -40,7 +42,7 @@ func TestCreate(t *testing.T) {
 			mockCall: func() *voteService {
 				mockRepo.EXPECT().Insert(gomock.Any(), gomock.Any()).Return(-1, errors.New("repo internal error"))
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "voteTitle",
 			want:    -1,
@@ -50,7 +52,7 @@ func TestCreate(t *testing.T) {
 			title: "wrong vote titlle and Get should return error",
 			mockCall: func() *voteService {
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "",
 			want:    -1,
@@ -89,7 +91,7 @@ func TestGetByTitle(t *testing.T) {
 			mockCall: func() *voteService {
 				mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(1, nil)
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "vote title",
 			want:    1,
@@ -100,7 +102,7 @@ func TestGetByTitle(t *testing.T) {
 			mockCall: func() *voteService {
 				mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(-1, errors.New("repo internal error"))
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "vote title",
 			want:    -1,
@@ -111,7 +113,7 @@ func TestGetByTitle(t *testing.T) {
 			mockCall: func() *voteService {
 				logger := logging.GetLogger("debug")
 				mockRepo.EXPECT().Find(gomock.Any(), gomock.Any()).Return(-1, errs.ErrTitleNotExist)
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "wrong title",
 			want:    -1,
@@ -150,7 +152,7 @@ func TestDeleteByTitle(t *testing.T) {
 			mockCall: func() *voteService {
 				mockRepo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "some id",
 			want:    1,
@@ -161,7 +163,7 @@ func TestDeleteByTitle(t *testing.T) {
 			mockCall: func() *voteService {
 				mockRepo.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errors.New("repo internal error"))
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "some id",
 			want:    -1,
@@ -171,7 +173,7 @@ func TestDeleteByTitle(t *testing.T) {
 			title: "wrong vote titlle and Get should return error",
 			mockCall: func() *voteService {
 				logger := logging.GetLogger("debug")
-				return NewVoteService(mockRepo, logger)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
 			},
 			input:   "",
 			want:    -1,
@@ -190,3 +192,784 @@ func TestDeleteByTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestGetById(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockVoteRepository(ctrl)
+	defer ctrl.Finish()
+	type mock func() *voteService
+	testCases := []struct {
+		title    string
+		mockCall mock
+		input    int
+		want     entity.Vote
+		isError  bool
+	}{
+		{
+			title: "Success GetById and return vote",
+			mockCall: func() *voteService {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "vote title"}, nil)
+				logger := logging.GetLogger("debug")
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
+			},
+			input:   1,
+			want:    entity.Vote{Id: 1, Title: "vote title"},
+			isError: false,
+		},
+		{
+			title: "vote not found and GetById should return error",
+			mockCall: func() *voteService {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(entity.Vote{}, errs.ErrVoteNotExist)
+				logger := logging.GetLogger("debug")
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
+			},
+			input:   1,
+			want:    entity.Vote{},
+			isError: true,
+		},
+		{
+			title: "wrong id and GetById should return error",
+			mockCall: func() *voteService {
+				logger := logging.GetLogger("debug")
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logger)
+			},
+			input:   0,
+			want:    entity.Vote{},
+			isError: true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			voteService := test.mockCall()
+			got, err := voteService.GetById(context.Background(), test.input)
+			if !test.isError {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestListVotes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockVoteRepository(ctrl)
+	defer ctrl.Finish()
+	votes := []entity.Vote{{Id: 3, Title: "third"}, {Id: 2, Title: "second"}, {Id: 1, Title: "first"}}
+	now := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	type mock func() *voteService
+	testCases := []struct {
+		title    string
+		mockCall mock
+		input    entity.VoteQuery
+		want     entity.VotePage
+		isError  bool
+	}{
+		{
+			title: "List returns page and cursor of the last vote",
+			mockCall: func() *voteService {
+				mockRepo.EXPECT().List(gomock.Any(), entity.VoteQuery{Sort: entity.SortByCreated, Desc: true, Limit: 3, Now: now}).Return(votes, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.VoteQuery{Desc: true, Limit: 2, Now: now},
+			want: entity.VotePage{
+				Votes: votes[:2],
+				Next:  &entity.VoteCursor{Sort: entity.SortByCreated, Desc: true, Id: 2},
+			},
+			isError: false,
+		},
+		{
+			title: "List returns last page without cursor",
+			mockCall: func() *voteService {
+				mockRepo.EXPECT().List(gomock.Any(), entity.VoteQuery{Sort: entity.SortByVotes, Limit: defaultPageSize + 1, Now: now}).Return(votes, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input:   entity.VoteQuery{Sort: entity.SortByVotes, Now: now},
+			want:    entity.VotePage{Votes: votes},
+			isError: false,
+		},
+		{
+			title: "wrong sort and List should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input:   entity.VoteQuery{Sort: "title"},
+			isError: true,
+		},
+		{
+			title: "cursor of another sort and List should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input:   entity.VoteQuery{Sort: entity.SortByVotes, After: &entity.VoteCursor{Sort: entity.SortByCreated}},
+			isError: true,
+		},
+		{
+			title: "repo error and List should return error",
+			mockCall: func() *voteService {
+				mockRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return(nil, errors.New("repo internal error"))
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input:   entity.VoteQuery{},
+			isError: true,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			voteService := test.mockCall()
+			got, err := voteService.List(context.Background(), test.input)
+			if !test.isError {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCreatePoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockVoteRepository(ctrl)
+	defer ctrl.Finish()
+	opensAt, closesAt := time.Now().Add(time.Hour), time.Now().Add(2*time.Hour)
+	past := time.Now().Add(-time.Hour)
+	type mock func() *voteService
+	testCases := []struct {
+		title    string
+		mockCall mock
+		input    entity.Poll
+		want     int
+		err      error
+	}{
+		{
+			title: "Success CreatePoll and return vote id",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first", "second"}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Visibility: entity.VisibilityAlways, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(1, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}},
+			want:  1,
+		},
+		{
+			title: "Success CreatePoll of multi-select poll",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first", "second", "third"}, MinSelections: 2, MaxSelections: 3, Method: entity.MethodPlurality, Seats: 1, Visibility: entity.VisibilityAlways, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(2, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second", "third"}, MinSelections: 2, MaxSelections: 3},
+			want:  2,
+		},
+		{
+			title: "Success CreatePoll of ranked poll ranking all choices by default",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first", "second", "third"}, MinSelections: 1, MaxSelections: 3, Method: entity.MethodIrv, Seats: 1, Visibility: entity.VisibilityAlways, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(3, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second", "third"}, Method: entity.MethodIrv},
+			want:  3,
+		},
+		{
+			title: "Success CreatePoll of stv poll with gregory transfer by default",
+			mockCall: func() *voteService {
+				poll := entity.Poll{
+					Title:         "vote",
+					Choices:       []string{"first", "second", "third"},
+					MinSelections: 1,
+					MaxSelections: 3,
+					Method:        entity.MethodStv,
+					Seats:         2,
+					Transfer:      entity.TransferGregory,
+					Visibility:    entity.VisibilityAlways,
+					TieBreak:      entity.TieBreakNone,
+					Privacy:       entity.PrivacyPrivate,
+				}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(4, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second", "third"}, Method: entity.MethodStv, Seats: 2},
+			want:  4,
+		},
+		{
+			title: "several seats of single choice poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Seats: 2},
+			want:  -1,
+			err:   errs.ErrInvalidSeats,
+		},
+		{
+			title: "more seats than choices and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: entity.MethodStv, Seats: 3},
+			want:  -1,
+			err:   errs.ErrInvalidSeats,
+		},
+		{
+			title: "unknown transfer rule and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: entity.MethodStv, Transfer: "meek"},
+			want:  -1,
+			err:   errs.ErrInvalidTransfer,
+		},
+		{
+			title: "Success CreatePoll of score poll scoring every choice from 0 to 10 by default",
+			mockCall: func() *voteService {
+				poll := entity.Poll{
+					Title:         "vote",
+					Choices:       []string{"first", "second"},
+					MinSelections: 2,
+					MaxSelections: 2,
+					Method:        entity.MethodScore,
+					Seats:         1,
+					MaxScore:      10,
+					Visibility:    entity.VisibilityAlways,
+					TieBreak:      entity.TieBreakNone,
+					Privacy:       entity.PrivacyPrivate,
+				}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(5, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: entity.MethodScore},
+			want:  5,
+		},
+		{
+			title: "empty scale and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: entity.MethodScore, MinScore: 5, MaxScore: 5},
+			want:  -1,
+			err:   errs.ErrInvalidScale,
+		},
+		{
+			title: "scale of plurality poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, MaxScore: 5},
+			want:  -1,
+			err:   errs.ErrInvalidScale,
+		},
+		{
+			title: "Success CreatePoll of weighted poll",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first", "second"}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Weighted: true, Visibility: entity.VisibilityAlways, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(5, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Weighted: true},
+			want:  5,
+		},
+		{
+			title: "weighted ranked poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: entity.MethodIrv, Weighted: true},
+			want:  -1,
+			err:   errs.ErrInvalidWeighted,
+		},
+		{
+			title: "unknown method and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: "approval"},
+			want:  -1,
+			err:   errs.ErrInvalidMethod,
+		},
+		{
+			title: "max selections above the number of choices and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, MaxSelections: 3},
+			want:  -1,
+			err:   errs.ErrInvalidSelections,
+		},
+		{
+			title: "min selections above max selections and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, MinSelections: 2, MaxSelections: 1},
+			want:  -1,
+			err:   errs.ErrInvalidSelections,
+		},
+		{
+			title: "empty vote title and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "", Choices: []string{"first", "second"}},
+			want:  -1,
+			err:   errs.ErrEmptyVoteTitle,
+		},
+		{
+			title: "empty choice title and CreatePoll should return error before insert",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", ""}},
+			want:  -1,
+			err:   errs.ErrEmptyChoiceTitle,
+		},
+		{
+			title: "duplicate choice title and CreatePoll should return error before insert",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "first"}},
+			want:  -1,
+			err:   errs.ErrDuplicateChoice,
+		},
+		{
+			title: "Success CreatePoll of scheduled poll",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first"}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, OpensAt: &opensAt, ClosesAt: &closesAt, Visibility: entity.VisibilityAlways, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(3, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first"}, OpensAt: &opensAt, ClosesAt: &closesAt},
+			want:  3,
+		},
+		{
+			title: "closes_at before opens_at and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first"}, OpensAt: &closesAt, ClosesAt: &opensAt},
+			want:  -1,
+			err:   errs.ErrInvalidSchedule,
+		},
+		{
+			title: "closes_at in the past and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first"}, ClosesAt: &past},
+			want:  -1,
+			err:   errs.ErrInvalidSchedule,
+		},
+		{
+			title: "Success CreatePoll of poll with results shown after the vote",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first"}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Visibility: entity.VisibilityAfterVote, OwnerId: "owner", TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(6, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first"}, Visibility: entity.VisibilityAfterVote, OwnerId: "owner"},
+			want:  6,
+		},
+		{
+			title: "Success CreatePoll of write-in poll without choices",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Visibility: entity.VisibilityAlways, AllowWriteIn: true, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(8, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", AllowWriteIn: true},
+			want:  8,
+		},
+		{
+			title: "write-in score poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: entity.MethodScore, AllowWriteIn: true},
+			want:  -1,
+			err:   errs.ErrInvalidWriteIn,
+		},
+		{
+			title: "Success CreatePoll of poll with rules and the default threshold",
+			mockCall: func() *voteService {
+				rules := entity.Rules{Quorum: 10, Threshold: entity.ThresholdPlurality, Abstention: "abstain"}
+				poll := entity.Poll{Title: "vote", Choices: []string{"yes", "no", "abstain"}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Visibility: entity.VisibilityAlways, Rules: rules, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(9, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"yes", "no", "abstain"}, Rules: entity.Rules{Quorum: 10, Abstention: "abstain"}},
+			want:  9,
+		},
+		{
+			title: "rules of multi-select poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"yes", "no"}, MaxSelections: 2, Rules: entity.Rules{Threshold: entity.ThresholdMajority}},
+			want:  -1,
+			err:   errs.ErrInvalidRules,
+		},
+		{
+			title: "unknown abstention and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"yes", "no"}, Rules: entity.Rules{Abstention: "abstain"}},
+			want:  -1,
+			err:   errs.ErrInvalidRules,
+		},
+		{
+			title: "quorum percent of unweighted poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"yes", "no"}, Rules: entity.Rules{QuorumPercent: 50}},
+			want:  -1,
+			err:   errs.ErrInvalidQuorum,
+		},
+		{
+			title: "percent threshold without percent and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"yes", "no"}, Rules: entity.Rules{Threshold: entity.ThresholdPercent}},
+			want:  -1,
+			err:   errs.ErrInvalidThreshold,
+		},
+		{
+			title: "Success CreatePoll of poll with random tie-break and a drawn seed",
+			mockCall: func() *voteService {
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, poll entity.Poll) (int, error) {
+					assert.Equal(t, entity.TieBreakRandom, poll.TieBreak)
+					assert.Greater(t, poll.TieSeed, int64(0))
+					return 10, nil
+				})
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, TieBreak: entity.TieBreakRandom},
+			want:  10,
+		},
+		{
+			title: "Success CreatePoll of poll with random tie-break and a published seed",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first", "second"}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Visibility: entity.VisibilityAlways, TieBreak: entity.TieBreakRandom, TieSeed: 42, Privacy: entity.PrivacyPrivate}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(11, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, TieBreak: entity.TieBreakRandom, TieSeed: 42},
+			want:  11,
+		},
+		{
+			title: "unknown tie-break and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, TieBreak: "coin"},
+			want:  -1,
+			err:   errs.ErrInvalidTieBreak,
+		},
+		{
+			title: "tie-break of ranked poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Method: entity.MethodIrv, TieBreak: entity.TieBreakEarliest},
+			want:  -1,
+			err:   errs.ErrInvalidTieBreak,
+		},
+		{
+			title: "seed without random tie-break and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, TieBreak: entity.TieBreakOwner, TieSeed: 42},
+			want:  -1,
+			err:   errs.ErrInvalidTieBreak,
+		},
+		{
+			title: "Success CreatePoll of anonymous poll",
+			mockCall: func() *voteService {
+				poll := entity.Poll{Title: "vote", Choices: []string{"first", "second"}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Visibility: entity.VisibilityAlways, TieBreak: entity.TieBreakNone, Privacy: entity.PrivacyAnonymous}
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), poll).Return(12, nil)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Privacy: entity.PrivacyAnonymous},
+			want:  12,
+		},
+		{
+			title: "unknown ballot privacy and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Privacy: "secret"},
+			want:  -1,
+			err:   errs.ErrInvalidPrivacy,
+		},
+		{
+			title: "anonymous weighted poll and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first", "second"}, Weighted: true, Privacy: entity.PrivacyAnonymous},
+			want:  -1,
+			err:   errs.ErrInvalidPrivacy,
+		},
+		{
+			title: "unknown visibility and CreatePoll should return error",
+			mockCall: func() *voteService {
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first"}, Visibility: "never"},
+			want:  -1,
+			err:   errs.ErrInvalidVisibility,
+		},
+		{
+			title: "repo error and CreatePoll should return error",
+			mockCall: func() *voteService {
+				mockRepo.EXPECT().InsertPoll(gomock.Any(), gomock.Any()).Return(-1, errs.ErrTitleAlreadyExist)
+				return NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+			},
+			input: entity.Poll{Title: "vote", Choices: []string{"first"}},
+			want:  -1,
+			err:   errs.ErrTitleAlreadyExist,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			voteService := test.mockCall()
+			got, err := voteService.CreatePoll(context.Background(), test.input)
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestSetWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockVoteRepository(ctrl)
+	defer ctrl.Finish()
+	voteService := NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+	weighted := entity.Vote{Id: 1, Title: "Board", Weighted: true, OwnerId: "oak"}
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		mock    mockCall
+		weights []entity.VoterWeight
+		voterId string
+		err     error
+	}{
+		{
+			title:   "Success SetWeights replaces the weights of the vote",
+			voterId: "oak",
+			weights: []entity.VoterWeight{{VoterId: "ash", Weight: 100}, {VoterId: "misty", Weight: 20}},
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(weighted, nil)
+				mockRepo.EXPECT().ReplaceWeights(gomock.Any(), 1, []entity.VoterWeight{{VoterId: "ash", Weight: 100}, {VoterId: "misty", Weight: 20}}).Return(nil)
+			},
+		},
+		{
+			title:   "voter who isn't the owner and SetWeights should return error",
+			voterId: "ash",
+			weights: []entity.VoterWeight{{VoterId: "ash", Weight: 1000000}},
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(weighted, nil)
+			},
+			err: errs.ErrNotOwner,
+		},
+		{
+			title:   "missing voter and SetWeights should return error",
+			weights: []entity.VoterWeight{{VoterId: "ash", Weight: 5}},
+			mock:    func() {},
+			err:     errs.ErrVoterRequired,
+		},
+		{
+			title:   "duplicate voter and SetWeights should return error",
+			voterId: "oak",
+			weights: []entity.VoterWeight{{VoterId: "ash", Weight: 100}, {VoterId: "ash", Weight: 20}},
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(weighted, nil)
+			},
+			err: errs.ErrInvalidWeights,
+		},
+		{
+			title:   "zero weight and SetWeights should return error",
+			voterId: "oak",
+			weights: []entity.VoterWeight{{VoterId: "ash"}},
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(weighted, nil)
+			},
+			err: errs.ErrInvalidWeights,
+		},
+		{
+			title:   "empty voter id and SetWeights should return error",
+			voterId: "oak",
+			weights: []entity.VoterWeight{{Weight: 5}},
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(weighted, nil)
+			},
+			err: errs.ErrVoterRequired,
+		},
+		{
+			title:   "vote isn't weighted and SetWeights should return error",
+			voterId: "oak",
+			weights: []entity.VoterWeight{{VoterId: "ash", Weight: 5}},
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Board", OwnerId: "oak"}, nil)
+			},
+			err: errs.ErrVoteNotWeighted,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			err := voteService.SetWeights(context.Background(), 1, test.weights, test.voterId)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestGetWeights(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockVoteRepository(ctrl)
+	defer ctrl.Finish()
+	voteService := NewVoteService(mockRepo, mocks.NewMockVersionCache(ctrl), logging.GetLogger("debug"))
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		mock    mockCall
+		voterId string
+		want    []entity.VoterWeight
+		err     error
+	}{
+		{
+			title:   "Success GetWeights returns the weights of the vote",
+			voterId: "oak",
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Weighted: true, OwnerId: "oak"}, nil)
+				mockRepo.EXPECT().FindWeights(gomock.Any(), 1).Return([]entity.VoterWeight{{VoterId: "ash", Weight: 100}}, nil)
+			},
+			want: []entity.VoterWeight{{VoterId: "ash", Weight: 100}},
+		},
+		{
+			title:   "voter who isn't the owner and GetWeights should return error",
+			voterId: "ash",
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Weighted: true, OwnerId: "oak"}, nil)
+			},
+			err: errs.ErrNotOwner,
+		},
+		{
+			title:   "vote isn't weighted and GetWeights should return error",
+			voterId: "oak",
+			mock: func() {
+				mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(entity.Vote{Id: 1, OwnerId: "oak"}, nil)
+			},
+			err: errs.ErrVoteNotWeighted,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := voteService.GetWeights(context.Background(), 1, test.voterId)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockVoteRepository(ctrl)
+	mockCache := mocks.NewMockVersionCache(ctrl)
+	defer ctrl.Finish()
+	voteService := NewVoteService(mockRepo, mockCache, logging.GetLogger("debug"))
+	vote := entity.Vote{Id: 1, Title: "Pokemon", OwnerId: "oak"}
+	closesAt := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(vote, nil)
+	mockRepo.EXPECT().OpenPoll(gomock.Any(), 1, gomock.Any()).Return(int64(2), nil)
+	mockCache.EXPECT().SaveVersion(1, int64(2), expire).Return(nil)
+	assert.NoError(t, voteService.Open(context.Background(), 1, "oak"))
+
+	mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(vote, nil)
+	mockRepo.EXPECT().ClosePoll(gomock.Any(), 1, gomock.Any()).Return(int64(8), nil)
+	mockCache.EXPECT().SaveVersion(1, int64(8), expire).Return(nil)
+	assert.NoError(t, voteService.Close(context.Background(), 1, "oak"))
+
+	mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(vote, nil)
+	mockRepo.EXPECT().ClosePoll(gomock.Any(), 1, gomock.Any()).Return(int64(-1), errs.ErrInvalidTransition)
+	assert.Equal(t, errs.ErrInvalidTransition, voteService.Close(context.Background(), 1, "oak"))
+
+	mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(vote, nil)
+	mockRepo.EXPECT().ReopenPoll(gomock.Any(), 1, &closesAt).Return(int64(9), nil)
+	mockCache.EXPECT().SaveVersion(1, int64(9), expire).Return(errors.New("redis error"))
+	assert.NoError(t, voteService.Reopen(context.Background(), 1, &closesAt, "oak"))
+	assert.Equal(t, errs.ErrInvalidSchedule, voteService.Reopen(context.Background(), 1, &past, "oak"))
+
+	mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(vote, nil)
+	mockRepo.EXPECT().ArchivePoll(gomock.Any(), 1).Return(int64(10), nil)
+	mockCache.EXPECT().SaveVersion(1, int64(10), expire).Return(nil)
+	assert.NoError(t, voteService.Archive(context.Background(), 1, "oak"))
+	assert.Equal(t, errs.ErrInvalidVoteId, voteService.Archive(context.Background(), 0, "oak"))
+
+	mockRepo.EXPECT().FindById(gomock.Any(), 1).Return(vote, nil).Times(4)
+	assert.Equal(t, errs.ErrNotOwner, voteService.Open(context.Background(), 1, "ash"))
+	assert.Equal(t, errs.ErrNotOwner, voteService.Close(context.Background(), 1, "ash"))
+	assert.Equal(t, errs.ErrNotOwner, voteService.Reopen(context.Background(), 1, &closesAt, "ash"))
+	assert.Equal(t, errs.ErrNotOwner, voteService.Archive(context.Background(), 1, "ash"))
+	assert.Equal(t, errs.ErrVoterRequired, voteService.Close(context.Background(), 1, ""))
+}
+
+func TestAdvance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockRepo := mocks.NewMockVoteRepository(ctrl)
+	mockCache := mocks.NewMockVersionCache(ctrl)
+	defer ctrl.Finish()
+	voteService := NewVoteService(mockRepo, mockCache, logging.GetLogger("debug"))
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		err   error
+	}{
+		{
+			title: "Advance opens the due drafts and closes the due votes",
+			mock: func() {
+				mockRepo.EXPECT().OpenDue(gomock.Any(), now).Return(map[int]int64{1: 2}, nil)
+				mockCache.EXPECT().SaveVersion(1, int64(2), expire).Return(nil)
+				mockRepo.EXPECT().FindClosing(gomock.Any(), now).Return([]int{2, 3}, nil)
+				mockRepo.EXPECT().ClosePoll(gomock.Any(), 2, now).Return(int64(4), nil)
+				mockCache.EXPECT().SaveVersion(2, int64(4), expire).Return(nil)
+				mockRepo.EXPECT().ClosePoll(gomock.Any(), 3, now).Return(int64(2), nil)
+				mockCache.EXPECT().SaveVersion(3, int64(2), expire).Return(nil)
+			},
+		},
+		{
+			title: "Advance skips the votes closed in the meantime",
+			mock: func() {
+				mockRepo.EXPECT().OpenDue(gomock.Any(), now).Return(map[int]int64{}, nil)
+				mockRepo.EXPECT().FindClosing(gomock.Any(), now).Return([]int{2, 3}, nil)
+				mockRepo.EXPECT().ClosePoll(gomock.Any(), 2, now).Return(int64(-1), errs.ErrInvalidTransition)
+				mockRepo.EXPECT().ClosePoll(gomock.Any(), 3, now).Return(int64(2), nil)
+				mockCache.EXPECT().SaveVersion(3, int64(2), expire).Return(nil)
+			},
+		},
+		{
+			title: "repo error and Advance should return error",
+			mock: func() {
+				mockRepo.EXPECT().OpenDue(gomock.Any(), now).Return(nil, errors.New("psql error"))
+			},
+			err: errors.New("psql error"),
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			err := voteService.Advance(context.Background(), now)
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package tally
+
+import "github.com/VrMolodyakov/vote-service/internal/domain/entity"
+
+// Outcome applies the rules of a single choice poll to its counts. Ballots
+// is the number of ballots and eligible is the total weight of the eligible
+// voters, a poll with a quorum percent and without eligible voters is never
+// quorate. The leading choice other than the abstention passes if it reaches
+// the threshold, the choices tied for the lead are a tie whatever the
+// threshold is. A poll without votes for any of the other choices has failed.
+func Outcome(rules entity.Rules, choices []entity.Choice, ballots int, eligible int) entity.Outcome {
+	outcome := entity.Outcome{Ballots: ballots}
+	cast := 0
+	for _, choice := range choices {
+		cast += choice.Count
+		if choice.Title == rules.Abstention {
+			outcome.Abstentions = choice.Count
+		}
+	}
+	if rules.QuorumPercent > 0 && eligible > 0 {
+		outcome.Turnout = float64(cast) * 100 / float64(eligible)
+	}
+	if ballots < rules.Quorum || rules.QuorumPercent > 0 && (eligible == 0 || cast*100 < rules.QuorumPercent*eligible) {
+		outcome.Result = entity.OutcomeNoQuorum
+		return outcome
+	}
+	outcome.Counted = cast
+	if !rules.CountAbstentions {
+		outcome.Counted -= outcome.Abstentions
+	}
+	leader, second := "", 0
+	for _, choice := range choices {
+		if choice.Title == rules.Abstention {
+			continue
+		}
+		if leader == "" || choice.Count > outcome.Votes {
+			if leader != "" {
+				second = outcome.Votes
+			}
+			leader, outcome.Votes = choice.Title, choice.Count
+		} else if choice.Count > second {
+			second = choice.Count
+		}
+	}
+	if outcome.Votes == 0 {
+		outcome.Result = entity.OutcomeFailed
+		return outcome
+	}
+	outcome.Share = float64(outcome.Votes) * 100 / float64(outcome.Counted)
+	if outcome.Votes == second {
+		outcome.Result = entity.OutcomeTied
+		return outcome
+	}
+	outcome.Choice = leader
+	outcome.Result = entity.OutcomeFailed
+	if passes(rules, outcome.Votes, outcome.Counted) {
+		outcome.Result = entity.OutcomePassed
+	}
+	return outcome
+}
+
+func passes(rules entity.Rules, votes int, counted int) bool {
+	switch rules.PassingThreshold() {
+	case entity.ThresholdMajority:
+		return votes*2 > counted
+	case entity.ThresholdTwoThirds:
+		return votes*3 >= counted*2
+	case entity.ThresholdPercent:
+		return votes*100 >= counted*rules.ThresholdPercent
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package tally
+
+import (
+	"testing"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func motion(yes int, no int, abstain int) []entity.Choice {
+	return []entity.Choice{{Title: "Abstain", Count: abstain}, {Title: "No", Count: no}, {Title: "Yes", Count: yes}}
+}
+
+func TestOutcome(t *testing.T) {
+	testCases := []struct {
+		title    string
+		rules    entity.Rules
+		choices  []entity.Choice
+		ballots  int
+		eligible int
+		want     entity.Outcome
+	}{
+		{
+			title:   "leading choice should pass with majority without abstentions",
+			rules:   entity.Rules{Threshold: entity.ThresholdMajority, Abstention: "Abstain"},
+			choices: motion(6, 4, 5),
+			ballots: 15,
+			want:    entity.Outcome{Result: entity.OutcomePassed, Choice: "Yes", Votes: 6, Counted: 10, Share: 60, Abstentions: 5, Ballots: 15},
+		},
+		{
+			title:   "leading choice should fail majority with counted abstentions",
+			rules:   entity.Rules{Threshold: entity.ThresholdMajority, Abstention: "Abstain", CountAbstentions: true},
+			choices: motion(6, 4, 5),
+			ballots: 15,
+			want:    entity.Outcome{Result: entity.OutcomeFailed, Choice: "Yes", Votes: 6, Counted: 15, Share: 40, Abstentions: 5, Ballots: 15},
+		},
+		{
+			title:   "two thirds should be reached by exactly two thirds",
+			rules:   entity.Rules{Threshold: entity.ThresholdTwoThirds},
+			choices: motion(2, 4, 0),
+			ballots: 6,
+			want:    entity.Outcome{Result: entity.OutcomePassed, Choice: "No", Votes: 4, Counted: 6, Share: 200.0 / 3, Ballots: 6},
+		},
+		{
+			title:   "custom percent shouldn't be reached below it",
+			rules:   entity.Rules{Threshold: entity.ThresholdPercent, ThresholdPercent: 75},
+			choices: motion(7, 3, 0),
+			ballots: 10,
+			want:    entity.Outcome{Result: entity.OutcomeFailed, Choice: "Yes", Votes: 7, Counted: 10, Share: 70, Ballots: 10},
+		},
+		{
+			title:   "poll should be tied if leading choices have the same votes",
+			rules:   entity.Rules{Threshold: entity.ThresholdMajority, Abstention: "Abstain"},
+			choices: motion(4, 4, 9),
+			ballots: 17,
+			want:    entity.Outcome{Result: entity.OutcomeTied, Votes: 4, Counted: 8, Share: 50, Abstentions: 9, Ballots: 17},
+		},
+		{
+			title:   "poll should have no quorum with fewer ballots",
+			rules:   entity.Rules{Quorum: 10, Threshold: entity.ThresholdPlurality},
+			choices: motion(6, 3, 0),
+			ballots: 9,
+			want:    entity.Outcome{Result: entity.OutcomeNoQuorum, Ballots: 9},
+		},
+		{
+			title:    "abstentions should count towards quorum percent",
+			rules:    entity.Rules{QuorumPercent: 50, Threshold: entity.ThresholdMajority, Abstention: "Abstain"},
+			choices:  motion(20, 10, 20),
+			ballots:  3,
+			eligible: 100,
+			want:     entity.Outcome{Result: entity.OutcomePassed, Choice: "Yes", Votes: 20, Counted: 30, Share: 200.0 / 3, Abstentions: 20, Ballots: 3, Turnout: 50},
+		},
+		{
+			title:    "poll should have no quorum below quorum percent",
+			rules:    entity.Rules{QuorumPercent: 50, Threshold: entity.ThresholdMajority},
+			choices:  motion(40, 9, 0),
+			ballots:  2,
+			eligible: 100,
+			want:     entity.Outcome{Result: entity.OutcomeNoQuorum, Ballots: 2, Turnout: 49},
+		},
+		{
+			title:   "poll without eligible voters should have no quorum",
+			rules:   entity.Rules{QuorumPercent: 10, Threshold: entity.ThresholdPlurality},
+			choices: motion(0, 0, 0),
+			want:    entity.Outcome{Result: entity.OutcomeNoQuorum},
+		},
+		{
+			title:   "poll with abstentions only should fail",
+			rules:   entity.Rules{Threshold: entity.ThresholdPlurality, Abstention: "Abstain"},
+			choices: motion(0, 0, 3),
+			ballots: 3,
+			want:    entity.Outcome{Result: entity.OutcomeFailed, Abstentions: 3, Ballots: 3},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			got := Outcome(test.rules, test.choices, test.ballots, test.eligible)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package tally
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+)
+
+// tenths are the units the percents are rounded to.
+const tenths int = 1000
+
+// Rank orders the choices from the most voted one and sets their competition
+// ranks and percents of the total: the choices with the same count share the
+// rank and the next rank skips them. The percents are rounded to tenths by
+// the largest remainder method, so they sum up to 100 unless nobody has
+// voted, the tenths left over go to the largest remainders in the order of
+// the ranks. Tied are the choices that share the lead if there are several.
+func Rank(choices []entity.Choice) ([]entity.Choice, int, []string) {
+	ranked := append([]entity.Choice(nil), choices...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Title < ranked[j].Title
+	})
+	total := 0
+	for i := range ranked {
+		total += ranked[i].Count
+		ranked[i].Rank = i + 1
+		if i > 0 && ranked[i].Count == ranked[i-1].Count {
+			ranked[i].Rank = ranked[i-1].Rank
+		}
+	}
+	tied := make([]string, 0)
+	for _, choice := range ranked {
+		if choice.Rank == 1 && total > 0 {
+			tied = append(tied, choice.Title)
+		}
+	}
+	if len(tied) < 2 {
+		tied = tied[:0]
+	}
+	if total == 0 {
+		return ranked, total, tied
+	}
+	units, left := make([]int, len(ranked)), tenths
+	byRemainder := make([]int, len(ranked))
+	for i, choice := range ranked {
+		units[i] = choice.Count * tenths / total
+		left -= units[i]
+		byRemainder[i] = i
+	}
+	sort.SliceStable(byRemainder, func(i, j int) bool {
+		return ranked[byRemainder[i]].Count*tenths%total > ranked[byRemainder[j]].Count*tenths%total
+	})
+	for _, i := range byRemainder[:left] {
+		units[i]++
+	}
+	for i := range ranked {
+		ranked[i].Percent = float64(units[i]) / 10
+	}
+	return ranked, total, tied
+}
+
+// Pick returns the tied choice picked with the seed. The titles are sorted
+// and the winner is the one at the first Intn of the math/rand source with
+// the seed, so anyone with the seed gets the same winner.
+func Pick(tied []string, seed int64) string {
+	sorted := append([]string(nil), tied...)
+	sort.Strings(sorted)
+	return sorted[rand.New(rand.NewSource(seed)).Intn(len(sorted))]
+}
+
+// Order sorts the ranked choices by votes or title in the order and keeps
+// the top ones if top is set. The choices with the same votes are sorted by
+// their titles.
+func Order(ranked []entity.Choice, by string, order string, top int) []entity.Choice {
+	sorted := append([]entity.Choice(nil), ranked...)
+	switch {
+	case by == entity.SortByTitle:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if order == "desc" {
+				return sorted[i].Title > sorted[j].Title
+			}
+			return sorted[i].Title < sorted[j].Title
+		})
+	case order == "asc":
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if sorted[i].Count != sorted[j].Count {
+				return sorted[i].Count < sorted[j].Count
+			}
+			return sorted[i].Title < sorted[j].Title
+		})
+	}
+	if top > 0 && top < len(sorted) {
+		sorted = sorted[:top]
+	}
+	return sorted
+}
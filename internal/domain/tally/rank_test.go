@@ -0,0 +1,86 @@
+package tally
+
+import (
+	"testing"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRank(t *testing.T) {
+	testCases := []struct {
+		title   string
+		choices []entity.Choice
+		want    []entity.Choice
+		total   int
+		tied    []string
+	}{
+		{
+			title:   "choices with the same count should share the rank",
+			choices: []entity.Choice{{Title: "Ditto", Count: 1}, {Title: "Mew", Count: 3}, {Title: "Eevee", Count: 3}, {Title: "Abra", Count: 0}},
+			want: []entity.Choice{
+				{Title: "Eevee", Count: 3, Rank: 1, Percent: 42.9},
+				{Title: "Mew", Count: 3, Rank: 1, Percent: 42.8},
+				{Title: "Ditto", Count: 1, Rank: 3, Percent: 14.3},
+				{Title: "Abra", Count: 0, Rank: 4, Percent: 0},
+			},
+			total: 7,
+			tied:  []string{"Eevee", "Mew"},
+		},
+		{
+			title:   "percents should sum up to 100 by the largest remainder",
+			choices: []entity.Choice{{Title: "Mew", Count: 1}, {Title: "Ditto", Count: 2}},
+			want:    []entity.Choice{{Title: "Ditto", Count: 2, Rank: 1, Percent: 66.7}, {Title: "Mew", Count: 1, Rank: 2, Percent: 33.3}},
+			total:   3,
+			tied:    []string{},
+		},
+		{
+			title:   "choices without votes shouldn't be tied",
+			choices: []entity.Choice{{Title: "Mew"}, {Title: "Ditto"}},
+			want:    []entity.Choice{{Title: "Ditto", Rank: 1}, {Title: "Mew", Rank: 1}},
+			tied:    []string{},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			got, total, tied := Rank(test.choices)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.total, total)
+			assert.Equal(t, test.tied, tied)
+		})
+	}
+}
+
+func TestPick(t *testing.T) {
+	got := Pick([]string{"Mew", "Ditto", "Eevee"}, 42)
+	assert.Contains(t, []string{"Mew", "Ditto", "Eevee"}, got)
+	assert.Equal(t, got, Pick([]string{"Eevee", "Mew", "Ditto"}, 42))
+}
+
+func TestOrder(t *testing.T) {
+	ranked := []entity.Choice{{Title: "Eevee", Count: 3, Rank: 1}, {Title: "Mew", Count: 3, Rank: 1}, {Title: "Ditto", Count: 1, Rank: 3}}
+	testCases := []struct {
+		title string
+		by    string
+		order string
+		top   int
+		want  []string
+	}{
+		{title: "choices should stay ranked by default", want: []string{"Eevee", "Mew", "Ditto"}},
+		{title: "least voted choices should come first in asc order", by: entity.SortByVotes, order: "asc", want: []string{"Ditto", "Eevee", "Mew"}},
+		{title: "choices should be sorted by title", by: entity.SortByTitle, want: []string{"Ditto", "Eevee", "Mew"}},
+		{title: "choices should be sorted by title in desc order", by: entity.SortByTitle, order: "desc", want: []string{"Mew", "Eevee", "Ditto"}},
+		{title: "top choices should be kept", top: 2, want: []string{"Eevee", "Mew"}},
+		{title: "top above the number of choices should keep all", top: 5, want: []string{"Eevee", "Mew", "Ditto"}},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			got := Order(ranked, test.by, test.order, test.top)
+			titles := make([]string, 0, len(got))
+			for _, choice := range got {
+				titles = append(titles, choice.Title)
+			}
+			assert.Equal(t, test.want, titles)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package tally
+
+import (
+	"sort"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+)
+
+// unit is the value of a whole ballot, fractions of a vote are counted in
+// ten-thousandths so the transfers are exact and reproducible.
+const unit int64 = 10000
+
+type stvBallot struct {
+	ranking []string
+	next    int
+	weight  int64
+}
+
+type stvCount struct {
+	quota     int64
+	running   map[string]bool
+	excluded  map[string]bool
+	piles     map[string][]*stvBallot
+	held      map[string]int64
+	exhausted int64
+}
+
+// Stv fills the seats by the single transferable vote with the Droop quota.
+// Every stage elects the choices that reach the quota and then transfers
+// the largest pending surplus by the transfer rule or, if there is none,
+// excludes the choice with the fewest votes. The choices tied for the fewest
+// votes are excluded in the reverse order of their titles. The count stops
+// when all seats are filled or the remaining choices fill them.
+func Stv(choices []string, ballots [][]string, seats int, transfer string) entity.Tally {
+	tally := entity.Tally{
+		Method:  entity.MethodStv,
+		Ballots: len(ballots),
+		Winners: make([]string, 0, seats),
+		Seats:   seats,
+		Quota:   len(ballots)/(seats+1) + 1,
+		Stages:  make([]entity.Stage, 0),
+	}
+	if len(ballots) == 0 {
+		return tally
+	}
+	count := &stvCount{
+		quota:    int64(tally.Quota) * unit,
+		running:  make(map[string]bool, len(choices)),
+		excluded: make(map[string]bool),
+		piles:    make(map[string][]*stvBallot, len(choices)),
+		held:     make(map[string]int64),
+	}
+	for _, choice := range choices {
+		count.running[choice] = true
+	}
+	for _, ranking := range ballots {
+		count.place(&stvBallot{ranking: ranking, weight: unit})
+	}
+
+	pending := make([]string, 0)
+	for {
+		stage := entity.Stage{
+			Number:    len(tally.Stages) + 1,
+			Votes:     make([]entity.Share, 0, len(choices)),
+			Exhausted: votes(count.exhausted),
+			Elected:   make([]string, 0),
+			Excluded:  make([]string, 0),
+		}
+		for _, choice := range choices {
+			if !count.excluded[choice] {
+				stage.Votes = append(stage.Votes, entity.Share{Choice: choice, Votes: votes(count.total(choice))})
+			}
+		}
+
+		reached := make([]string, 0)
+		for _, choice := range choices {
+			if count.running[choice] && count.total(choice) >= count.quota {
+				reached = append(reached, choice)
+			}
+		}
+		sort.SliceStable(reached, func(i, j int) bool {
+			return count.total(reached[i]) > count.total(reached[j])
+		})
+		for _, choice := range reached {
+			if len(tally.Winners) == seats {
+				break
+			}
+			delete(count.running, choice)
+			tally.Winners = append(tally.Winners, choice)
+			stage.Elected = append(stage.Elected, choice)
+			pending = append(pending, choice)
+		}
+
+		remaining := make([]string, 0, len(count.running))
+		for _, choice := range choices {
+			if count.running[choice] {
+				remaining = append(remaining, choice)
+			}
+		}
+		if len(tally.Winners)+len(remaining) <= seats {
+			for _, choice := range remaining {
+				delete(count.running, choice)
+				tally.Winners = append(tally.Winners, choice)
+				stage.Elected = append(stage.Elected, choice)
+			}
+		}
+		if len(tally.Winners) == seats || len(remaining) == 0 {
+			tally.Stages = append(tally.Stages, stage)
+			break
+		}
+
+		for len(pending) > 0 && stage.Transfer == nil {
+			from := pending[0]
+			pending = pending[1:]
+			stage.Transfer = count.surplus(from, transfer)
+		}
+		if stage.Transfer == nil {
+			lowest := remaining[0]
+			for _, choice := range remaining[1:] {
+				if count.total(choice) <= count.total(lowest) {
+					lowest = choice
+				}
+			}
+			stage.Excluded = append(stage.Excluded, lowest)
+			stage.Transfer = count.exclude(lowest)
+		}
+		tally.Stages = append(tally.Stages, stage)
+	}
+	return tally
+}
+
+// place gives the ballot to its next preference still in the count.
+func (c *stvCount) place(ballot *stvBallot) {
+	for ; ballot.next < len(ballot.ranking); ballot.next++ {
+		choice := ballot.ranking[ballot.next]
+		if c.running[choice] {
+			c.piles[choice] = append(c.piles[choice], ballot)
+			return
+		}
+	}
+	c.exhausted += ballot.weight
+}
+
+func (c *stvCount) total(choice string) int64 {
+	if held, ok := c.held[choice]; ok {
+		return held
+	}
+	var total int64
+	for _, ballot := range c.piles[choice] {
+		total += ballot.weight
+	}
+	return total
+}
+
+// surplus transfers the votes of the elected choice above the quota, nil
+// is returned if there are none.
+func (c *stvCount) surplus(from string, rule string) *entity.Transfer {
+	total := c.total(from)
+	surplus := total - c.quota
+	pile := c.piles[from]
+	c.piles[from] = nil
+	if surplus <= 0 {
+		c.held[from] = total
+		return nil
+	}
+	result := &entity.Transfer{From: from, Surplus: true}
+	if rule == entity.TransferHare {
+		// the last received ballots are moved whole, the ones without a next
+		// preference stay with the elected choice
+		var moved int64
+		for i := len(pile) - 1; i >= 0; i-- {
+			ballot := pile[i]
+			if moved+ballot.weight > surplus || !c.transferable(ballot) {
+				continue
+			}
+			moved += ballot.weight
+			ballot.next++
+			c.place(ballot)
+		}
+		c.held[from] = total - moved
+		result.Value, result.Votes = 1, votes(moved)
+		return result
+	}
+	var moved int64
+	for _, ballot := range pile {
+		ballot.weight = ballot.weight * surplus / total
+		moved += ballot.weight
+		ballot.next++
+		c.place(ballot)
+	}
+	// the fractions lost to rounding are counted as exhausted
+	c.exhausted += surplus - moved
+	c.held[from] = c.quota
+	result.Value, result.Votes = float64(surplus)/float64(total), votes(surplus)
+	return result
+}
+
+// exclude drops the choice from the count and moves its ballots at their
+// current value.
+func (c *stvCount) exclude(choice string) *entity.Transfer {
+	total := c.total(choice)
+	pile := c.piles[choice]
+	delete(c.running, choice)
+	c.excluded[choice] = true
+	c.piles[choice] = nil
+	for _, ballot := range pile {
+		ballot.next++
+		c.place(ballot)
+	}
+	return &entity.Transfer{From: choice, Votes: votes(total)}
+}
+
+func (c *stvCount) transferable(ballot *stvBallot) bool {
+	for _, choice := range ballot.ranking[ballot.next+1:] {
+		if c.running[choice] {
+			return true
+		}
+	}
+	return false
+}
+
+func votes(units int64) float64 {
+	return float64(units) / float64(unit)
+}
@@ -0,0 +1,127 @@
+package tally
+
+import (
+	"testing"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func committee() [][]string {
+	ballots := [][]string{{"A", "C"}}
+	ballots = repeat(ballots, 3, "A", "B")
+	return append(ballots, []string{"B"}, []string{"C"}, []string{"D"})
+}
+
+func TestStv(t *testing.T) {
+	choices := []string{"A", "B", "C", "D"}
+	testCases := []struct {
+		title    string
+		ballots  [][]string
+		transfer string
+		want     entity.Tally
+	}{
+		{
+			title:    "surplus is transferred at a fraction of the value",
+			ballots:  committee(),
+			transfer: entity.TransferGregory,
+			want: entity.Tally{
+				Method:  entity.MethodStv,
+				Ballots: 7,
+				Winners: []string{"A", "B"},
+				Seats:   2,
+				Quota:   3,
+				Stages: []entity.Stage{
+					{
+						Number:   1,
+						Votes:    []entity.Share{{Choice: "A", Votes: 4}, {Choice: "B", Votes: 1}, {Choice: "C", Votes: 1}, {Choice: "D", Votes: 1}},
+						Elected:  []string{"A"},
+						Excluded: []string{},
+						Transfer: &entity.Transfer{From: "A", Surplus: true, Value: 0.25, Votes: 1},
+					},
+					{
+						Number:   2,
+						Votes:    []entity.Share{{Choice: "A", Votes: 3}, {Choice: "B", Votes: 1.75}, {Choice: "C", Votes: 1.25}, {Choice: "D", Votes: 1}},
+						Elected:  []string{},
+						Excluded: []string{"D"},
+						Transfer: &entity.Transfer{From: "D", Votes: 1},
+					},
+					{
+						Number:    3,
+						Votes:     []entity.Share{{Choice: "A", Votes: 3}, {Choice: "B", Votes: 1.75}, {Choice: "C", Votes: 1.25}},
+						Exhausted: 1,
+						Elected:   []string{},
+						Excluded:  []string{"C"},
+						Transfer:  &entity.Transfer{From: "C", Votes: 1.25},
+					},
+					{
+						Number:    4,
+						Votes:     []entity.Share{{Choice: "A", Votes: 3}, {Choice: "B", Votes: 1.75}},
+						Exhausted: 2.25,
+						Elected:   []string{"B"},
+						Excluded:  []string{},
+					},
+				},
+			},
+		},
+		{
+			title:    "last received ballots of the surplus are transferred whole",
+			ballots:  committee(),
+			transfer: entity.TransferHare,
+			want: entity.Tally{
+				Method:  entity.MethodStv,
+				Ballots: 7,
+				Winners: []string{"A", "B"},
+				Seats:   2,
+				Quota:   3,
+				Stages: []entity.Stage{
+					{
+						Number:   1,
+						Votes:    []entity.Share{{Choice: "A", Votes: 4}, {Choice: "B", Votes: 1}, {Choice: "C", Votes: 1}, {Choice: "D", Votes: 1}},
+						Elected:  []string{"A"},
+						Excluded: []string{},
+						Transfer: &entity.Transfer{From: "A", Surplus: true, Value: 1, Votes: 1},
+					},
+					{
+						Number:   2,
+						Votes:    []entity.Share{{Choice: "A", Votes: 3}, {Choice: "B", Votes: 2}, {Choice: "C", Votes: 1}, {Choice: "D", Votes: 1}},
+						Elected:  []string{},
+						Excluded: []string{"D"},
+						Transfer: &entity.Transfer{From: "D", Votes: 1},
+					},
+					{
+						Number:    3,
+						Votes:     []entity.Share{{Choice: "A", Votes: 3}, {Choice: "B", Votes: 2}, {Choice: "C", Votes: 1}},
+						Exhausted: 1,
+						Elected:   []string{},
+						Excluded:  []string{"C"},
+						Transfer:  &entity.Transfer{From: "C", Votes: 1},
+					},
+					{
+						Number:    4,
+						Votes:     []entity.Share{{Choice: "A", Votes: 3}, {Choice: "B", Votes: 2}},
+						Exhausted: 2,
+						Elected:   []string{"B"},
+						Excluded:  []string{},
+					},
+				},
+			},
+		},
+		{
+			title:    "no ballots and no seats are filled",
+			transfer: entity.TransferGregory,
+			want: entity.Tally{
+				Method:  entity.MethodStv,
+				Winners: []string{},
+				Seats:   2,
+				Quota:   1,
+				Stages:  []entity.Stage{},
+			},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.want, Stv(choices, test.ballots, 2, test.transfer))
+		})
+	}
+}
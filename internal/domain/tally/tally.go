@@ -0,0 +1,237 @@
+package tally
+
+import (
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+)
+
+// Count tallies the ranked ballots with the method of the vote. Every ballot
+// lists the choices in the order of preference, the choices it doesn't rank
+// are less preferred than the ranked ones.
+func Count(vote entity.Vote, choices []string, ballots [][]string) (entity.Tally, error) {
+	switch vote.Method {
+	case entity.MethodIrv:
+		return InstantRunoff(choices, ballots), nil
+	case entity.MethodBorda:
+		return Borda(choices, ballots), nil
+	case entity.MethodSchulze:
+		return Schulze(choices, ballots), nil
+	case entity.MethodStv:
+		return Stv(choices, ballots, vote.Seats, vote.Transfer), nil
+	}
+	return entity.Tally{}, errs.ErrInvalidMethod
+}
+
+// InstantRunoff counts every ballot for its most preferred choice still in
+// the count. A choice with more than a half of the votes wins, otherwise the
+// choices with the fewest votes are eliminated and the next round is counted.
+// The choices tied for the fewest votes are eliminated together, if all of
+// the remaining choices are tied they all are the winners.
+func InstantRunoff(choices []string, ballots [][]string) entity.Tally {
+	tally := entity.Tally{Method: entity.MethodIrv, Ballots: len(ballots), Winners: make([]string, 0), Rounds: make([]entity.Round, 0)}
+	if len(ballots) == 0 {
+		return tally
+	}
+	running := make(map[string]bool, len(choices))
+	for _, choice := range choices {
+		running[choice] = true
+	}
+	for len(running) > 0 {
+		votes := make(map[string]int, len(running))
+		round := entity.Round{Number: len(tally.Rounds) + 1, Votes: make([]entity.Score, 0, len(running)), Eliminated: make([]string, 0)}
+		for _, ballot := range ballots {
+			top, ok := topChoice(ballot, running)
+			if !ok {
+				round.Exhausted++
+				continue
+			}
+			votes[top]++
+		}
+		active := len(ballots) - round.Exhausted
+		fewest := active
+		for _, choice := range choices {
+			if !running[choice] {
+				continue
+			}
+			round.Votes = append(round.Votes, entity.Score{Choice: choice, Score: votes[choice]})
+			if votes[choice]*2 > active {
+				tally.Winners = append(tally.Winners, choice)
+			}
+			if votes[choice] < fewest {
+				fewest = votes[choice]
+			}
+		}
+		if len(tally.Winners) == 0 && active > 0 {
+			for _, choice := range choices {
+				if running[choice] && votes[choice] == fewest {
+					round.Eliminated = append(round.Eliminated, choice)
+				}
+			}
+			if len(round.Eliminated) == len(running) {
+				tally.Winners, round.Eliminated = round.Eliminated, make([]string, 0)
+			}
+		}
+		tally.Rounds = append(tally.Rounds, round)
+		if len(tally.Winners) > 0 || active == 0 {
+			break
+		}
+		for _, choice := range round.Eliminated {
+			delete(running, choice)
+		}
+	}
+	return tally
+}
+
+// Borda gives a choice ranked at the position i (counting from 0) the
+// number of choices minus i minus one points, the choice with the most points wins.
+func Borda(choices []string, ballots [][]string) entity.Tally {
+	tally := entity.Tally{Method: entity.MethodBorda, Ballots: len(ballots), Winners: make([]string, 0)}
+	points := make(map[string]int, len(choices))
+	for _, ballot := range ballots {
+		for i, choice := range ballot {
+			points[choice] += len(choices) - i - 1
+		}
+	}
+	tally.Scores = scores(choices, points)
+	if len(ballots) > 0 {
+		tally.Winners = best(tally.Scores)
+	}
+	return tally
+}
+
+// Schulze compares every pair of the choices by the number of ballots that
+// prefer one to another and finds the strongest paths between them. The
+// winners are the choices whose paths to every other choice are at least as
+// strong as the paths back, the score of a choice is the number of the choices
+// it beats this way. Condorcet is the choice that beats every other one in
+// a direct comparison if there is one.
+func Schulze(choices []string, ballots [][]string) entity.Tally {
+	tally := entity.Tally{Method: entity.MethodSchulze, Ballots: len(ballots), Winners: make([]string, 0)}
+	n := len(choices)
+	index := make(map[string]int, n)
+	for i, choice := range choices {
+		index[choice] = i
+	}
+	prefer := square(n)
+	for _, ballot := range ballots {
+		ranked := make([]bool, n)
+		for _, choice := range ballot {
+			i, ok := index[choice]
+			if !ok {
+				continue
+			}
+			ranked[i] = true
+			for j := 0; j < n; j++ {
+				if !ranked[j] {
+					prefer[i][j]++
+				}
+			}
+		}
+	}
+
+	path := square(n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i != j && prefer[i][j] > prefer[j][i] {
+				path[i][j] = prefer[i][j]
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			if i == k {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				if j == i || j == k {
+					continue
+				}
+				if width := minInt(path[i][k], path[k][j]); width > path[i][j] {
+					path[i][j] = width
+				}
+			}
+		}
+	}
+
+	wins := make(map[string]int, n)
+	for i, choice := range choices {
+		beaten := 0
+		for j := 0; j < n; j++ {
+			if i == j {
+				continue
+			}
+			if path[i][j] > path[j][i] {
+				wins[choice]++
+			}
+			if prefer[i][j] > prefer[j][i] {
+				beaten++
+			}
+		}
+		if len(ballots) > 0 && n > 1 && beaten == n-1 {
+			tally.Condorcet = choice
+		}
+	}
+	tally.Scores = scores(choices, wins)
+	if len(ballots) == 0 {
+		return tally
+	}
+	for i, choice := range choices {
+		winner := true
+		for j := 0; j < n; j++ {
+			if i != j && path[i][j] < path[j][i] {
+				winner = false
+				break
+			}
+		}
+		if winner {
+			tally.Winners = append(tally.Winners, choice)
+		}
+	}
+	return tally
+}
+
+func topChoice(ballot []string, running map[string]bool) (string, bool) {
+	for _, choice := range ballot {
+		if running[choice] {
+			return choice, true
+		}
+	}
+	return "", false
+}
+
+func scores(choices []string, points map[string]int) []entity.Score {
+	result := make([]entity.Score, 0, len(choices))
+	for _, choice := range choices {
+		result = append(result, entity.Score{Choice: choice, Score: points[choice]})
+	}
+	return result
+}
+
+func best(scores []entity.Score) []string {
+	winners := make([]string, 0, 1)
+	top := 0
+	for _, score := range scores {
+		switch {
+		case len(winners) == 0 || score.Score > top:
+			winners, top = append(winners[:0], score.Choice), score.Score
+		case score.Score == top:
+			winners = append(winners, score.Choice)
+		}
+	}
+	return winners
+}
+
+func square(n int) [][]int {
+	matrix := make([][]int, n)
+	for i := range matrix {
+		matrix[i] = make([]int, n)
+	}
+	return matrix
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,187 @@
+package tally
+
+import (
+	"testing"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+var cities = []string{"Memphis", "Nashville", "Chattanooga", "Knoxville"}
+
+// tennessee is the classic example of the capital election where the
+// instant-runoff, Borda and Condorcet winners differ.
+func tennessee() [][]string {
+	ballots := make([][]string, 0, 100)
+	ballots = repeat(ballots, 42, "Memphis", "Nashville", "Chattanooga", "Knoxville")
+	ballots = repeat(ballots, 26, "Nashville", "Chattanooga", "Knoxville", "Memphis")
+	ballots = repeat(ballots, 15, "Chattanooga", "Knoxville", "Nashville", "Memphis")
+	ballots = repeat(ballots, 17, "Knoxville", "Chattanooga", "Nashville", "Memphis")
+	return ballots
+}
+
+func repeat(ballots [][]string, n int, ranking ...string) [][]string {
+	for i := 0; i < n; i++ {
+		ballots = append(ballots, ranking)
+	}
+	return ballots
+}
+
+func TestInstantRunoff(t *testing.T) {
+	testCases := []struct {
+		title   string
+		choices []string
+		ballots [][]string
+		want    entity.Tally
+	}{
+		{
+			title:   "lowest choices are eliminated until the majority",
+			choices: cities,
+			ballots: tennessee(),
+			want: entity.Tally{
+				Method:  entity.MethodIrv,
+				Ballots: 100,
+				Winners: []string{"Knoxville"},
+				Rounds: []entity.Round{
+					{
+						Number:     1,
+						Votes:      []entity.Score{{Choice: "Memphis", Score: 42}, {Choice: "Nashville", Score: 26}, {Choice: "Chattanooga", Score: 15}, {Choice: "Knoxville", Score: 17}},
+						Eliminated: []string{"Chattanooga"},
+					},
+					{
+						Number:     2,
+						Votes:      []entity.Score{{Choice: "Memphis", Score: 42}, {Choice: "Nashville", Score: 26}, {Choice: "Knoxville", Score: 32}},
+						Eliminated: []string{"Nashville"},
+					},
+					{
+						Number:     3,
+						Votes:      []entity.Score{{Choice: "Memphis", Score: 42}, {Choice: "Knoxville", Score: 58}},
+						Eliminated: []string{},
+					},
+				},
+			},
+		},
+		{
+			title:   "exhausted ballots don't count for the majority",
+			choices: []string{"A", "B", "C"},
+			ballots: [][]string{{"A"}, {"A"}, {"B"}, {"B"}, {"C"}},
+			want: entity.Tally{
+				Method:  entity.MethodIrv,
+				Ballots: 5,
+				Winners: []string{"A", "B"},
+				Rounds: []entity.Round{
+					{Number: 1, Votes: []entity.Score{{Choice: "A", Score: 2}, {Choice: "B", Score: 2}, {Choice: "C", Score: 1}}, Eliminated: []string{"C"}},
+					{Number: 2, Votes: []entity.Score{{Choice: "A", Score: 2}, {Choice: "B", Score: 2}}, Exhausted: 1, Eliminated: []string{}},
+				},
+			},
+		},
+		{
+			title:   "no ballots and no winners",
+			choices: []string{"A", "B"},
+			want:    entity.Tally{Method: entity.MethodIrv, Winners: []string{}, Rounds: []entity.Round{}},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.want, InstantRunoff(test.choices, test.ballots))
+		})
+	}
+}
+
+func TestBorda(t *testing.T) {
+	testCases := []struct {
+		title   string
+		choices []string
+		ballots [][]string
+		want    entity.Tally
+	}{
+		{
+			title:   "choice with the most points wins",
+			choices: cities,
+			ballots: tennessee(),
+			want: entity.Tally{
+				Method:  entity.MethodBorda,
+				Ballots: 100,
+				Winners: []string{"Nashville"},
+				Scores:  []entity.Score{{Choice: "Memphis", Score: 126}, {Choice: "Nashville", Score: 194}, {Choice: "Chattanooga", Score: 173}, {Choice: "Knoxville", Score: 107}},
+			},
+		},
+		{
+			title:   "unranked choices get no points",
+			choices: []string{"A", "B", "C"},
+			ballots: [][]string{{"A"}, {"B", "A"}},
+			want: entity.Tally{
+				Method:  entity.MethodBorda,
+				Ballots: 2,
+				Winners: []string{"A"},
+				Scores:  []entity.Score{{Choice: "A", Score: 3}, {Choice: "B", Score: 2}, {Choice: "C", Score: 0}},
+			},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.want, Borda(test.choices, test.ballots))
+		})
+	}
+}
+
+func TestSchulze(t *testing.T) {
+	testCases := []struct {
+		title   string
+		choices []string
+		ballots [][]string
+		want    entity.Tally
+	}{
+		{
+			title:   "condorcet winner wins",
+			choices: cities,
+			ballots: tennessee(),
+			want: entity.Tally{
+				Method:    entity.MethodSchulze,
+				Ballots:   100,
+				Winners:   []string{"Nashville"},
+				Condorcet: "Nashville",
+				Scores:    []entity.Score{{Choice: "Memphis", Score: 0}, {Choice: "Nashville", Score: 3}, {Choice: "Chattanooga", Score: 2}, {Choice: "Knoxville", Score: 1}},
+			},
+		},
+		{
+			title:   "cycle is resolved by the strongest paths",
+			choices: []string{"A", "B", "C"},
+			ballots: repeat(repeat(repeat(nil, 4, "A", "B", "C"), 3, "B", "C", "A"), 2, "C", "A", "B"),
+			want: entity.Tally{
+				Method:  entity.MethodSchulze,
+				Ballots: 9,
+				Winners: []string{"A"},
+				Scores:  []entity.Score{{Choice: "A", Score: 2}, {Choice: "B", Score: 1}, {Choice: "C", Score: 0}},
+			},
+		},
+		{
+			title:   "symmetric cycle and tied winners",
+			choices: []string{"A", "B", "C"},
+			ballots: [][]string{{"A", "B", "C"}, {"B", "C", "A"}, {"C", "A", "B"}},
+			want: entity.Tally{
+				Method:  entity.MethodSchulze,
+				Ballots: 3,
+				Winners: []string{"A", "B", "C"},
+				Scores:  []entity.Score{{Choice: "A", Score: 0}, {Choice: "B", Score: 0}, {Choice: "C", Score: 0}},
+			},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.want, Schulze(test.choices, test.ballots))
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	got, err := Count(entity.Vote{Method: entity.MethodBorda}, cities, tennessee())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Nashville"}, got.Winners)
+	got, err = Count(entity.Vote{Method: entity.MethodStv, Seats: 2, Transfer: entity.TransferGregory}, cities, tennessee())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Memphis", "Nashville"}, got.Winners)
+	_, err = Count(entity.Vote{Method: entity.MethodPlurality}, cities, tennessee())
+	assert.Equal(t, errs.ErrInvalidMethod, err)
+}
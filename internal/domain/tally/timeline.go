@@ -0,0 +1,220 @@
+package tally
+
+import (
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+)
+
+// Timeline replays the ballot log of a vote, the entries are in the order
+// of seq and none of them is after asOf. The changes of the counts are
+// grouped by the bucket the entries were appended in. A choice keeps its
+// series when it is renamed and is listed with its title at asOf, the
+// choices removed or merged into another one by asOf are left out, so
+// a merge shows as a jump of the choice merged into. The choices are listed
+// in the order of their first vote, a choice without votes isn't listed.
+func Timeline(entries []entity.LogEntry, bucket string, asOf time.Time) entity.Timeline {
+	length := entity.Buckets[bucket]
+	r := replay{ids: make(map[string]int), ballots: make(map[int64]logBallot)}
+	buckets := make([]bucketState, 0)
+	for _, entry := range entries {
+		start := entry.CreatedAt.UTC().Truncate(length)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].start.Equal(start) {
+			if len(buckets) > 0 {
+				buckets[len(buckets)-1].close(&r)
+			}
+			buckets = append(buckets, bucketState{start: start, changes: make(map[int]int)})
+		}
+		r.apply(entry, &buckets[len(buckets)-1])
+	}
+	if len(buckets) > 0 {
+		buckets[len(buckets)-1].close(&r)
+	}
+	timeline := entity.Timeline{
+		Bucket:  bucket,
+		AsOf:    asOf,
+		Ballots: len(r.ballots),
+		Choices: make([]entity.Choice, 0, len(r.ids)),
+		Buckets: make([]entity.TimelineBucket, 0, len(buckets)),
+	}
+	for id, title := range r.titles {
+		if r.alive[id] {
+			timeline.Choices = append(timeline.Choices, entity.Choice{Title: title, Count: r.counts[id]})
+		}
+	}
+	for _, state := range buckets {
+		b := entity.TimelineBucket{Start: state.start, Cast: state.cast, Ballots: state.ballots, Counts: make([]entity.TimelineCount, 0, len(state.totals))}
+		if state.leader >= 0 {
+			b.Leader = state.leaderTitle
+			if r.alive[state.leader] {
+				b.Leader = r.titles[state.leader]
+			}
+		}
+		for id, total := range state.totals {
+			if !r.alive[id] {
+				continue
+			}
+			b.Counts = append(b.Counts, entity.TimelineCount{Choice: r.titles[id], Change: state.changes[id], Total: total})
+		}
+		timeline.Buckets = append(timeline.Buckets, b)
+	}
+	return timeline
+}
+
+// replay holds the state of the vote while its log is replayed. Choices are
+// numbered in the order they appear, so they keep their number when they
+// are renamed. The ballots are the counted ones by the seq of their entry.
+type replay struct {
+	ids     map[string]int
+	titles  []string
+	alive   []bool
+	counts  []int
+	ballots map[int64]logBallot
+}
+
+type logBallot struct {
+	weight  int
+	choices []int
+}
+
+// bucketState collects the changes of a bucket, the totals and the leader
+// are set when the bucket is closed.
+type bucketState struct {
+	start       time.Time
+	cast        int
+	changes     map[int]int
+	ballots     int
+	totals      []int
+	leader      int
+	leaderTitle string
+}
+
+func (b *bucketState) close(r *replay) {
+	b.ballots = len(r.ballots)
+	b.totals = append([]int(nil), r.counts...)
+	b.leader = -1
+	tied := false
+	for id, count := range r.counts {
+		if !r.alive[id] || count == 0 {
+			continue
+		}
+		switch {
+		case b.leader < 0 || count > r.counts[b.leader]:
+			b.leader, tied = id, false
+		case count == r.counts[b.leader]:
+			tied = true
+		}
+	}
+	if tied {
+		b.leader = -1
+	}
+	if b.leader >= 0 {
+		b.leaderTitle = r.titles[b.leader]
+	}
+}
+
+func (r *replay) apply(entry entity.LogEntry, b *bucketState) {
+	switch entry.Kind {
+	case entity.LogCast:
+		b.cast++
+		r.add(entry, b)
+	case entity.LogChange:
+		r.drop(entry.Replaces, b)
+		r.add(entry, b)
+	case entity.LogRetract:
+		r.drop(entry.Replaces, b)
+	case entity.LogRename:
+		if id, ok := r.ids[entry.Choices[0]]; ok {
+			delete(r.ids, entry.Choices[0])
+			r.ids[entry.Choices[1]] = id
+			r.titles[id] = entry.Choices[1]
+		}
+	case entity.LogRemove:
+		for _, title := range entry.Choices {
+			if id, ok := r.ids[title]; ok {
+				r.remove(id, b)
+			}
+		}
+	case entity.LogMerge:
+		r.merge(entry.Choices[0], entry.Choices[1], b)
+	}
+}
+
+// choice returns the number of the choice with the title, the choice is
+// added if it hasn't been voted for yet.
+func (r *replay) choice(title string) int {
+	if id, ok := r.ids[title]; ok {
+		return id
+	}
+	id := len(r.titles)
+	r.ids[title] = id
+	r.titles = append(r.titles, title)
+	r.alive = append(r.alive, true)
+	r.counts = append(r.counts, 0)
+	return id
+}
+
+func (r *replay) add(entry entity.LogEntry, b *bucketState) {
+	ballot := logBallot{weight: entry.Weight, choices: make([]int, 0, len(entry.Choices))}
+	for _, title := range entry.Choices {
+		id := r.choice(title)
+		ballot.choices = append(ballot.choices, id)
+		r.counts[id] += entry.Weight
+		b.changes[id] += entry.Weight
+	}
+	r.ballots[entry.Seq] = ballot
+}
+
+func (r *replay) drop(seq int64, b *bucketState) {
+	ballot, ok := r.ballots[seq]
+	if !ok {
+		return
+	}
+	delete(r.ballots, seq)
+	for _, id := range ballot.choices {
+		if r.alive[id] {
+			r.counts[id] -= ballot.weight
+			b.changes[id] -= ballot.weight
+		}
+	}
+}
+
+func (r *replay) remove(id int, b *bucketState) {
+	delete(r.ids, r.titles[id])
+	r.alive[id] = false
+	b.changes[id] -= r.counts[id]
+	r.counts[id] = 0
+}
+
+// merge moves the ballots of from to into, a ballot that selects both counts
+// once for into.
+func (r *replay) merge(from string, into string, b *bucketState) {
+	f, ok := r.ids[from]
+	if !ok {
+		return
+	}
+	t := r.choice(into)
+	for seq, ballot := range r.ballots {
+		at, both := -1, false
+		for i, id := range ballot.choices {
+			if id == f {
+				at = i
+			}
+			if id == t {
+				both = true
+			}
+		}
+		if at < 0 {
+			continue
+		}
+		if both {
+			ballot.choices = append(ballot.choices[:at:at], ballot.choices[at+1:]...)
+		} else {
+			ballot.choices[at] = t
+			r.counts[t] += ballot.weight
+			b.changes[t] += ballot.weight
+		}
+		r.ballots[seq] = ballot
+	}
+	r.remove(f, b)
+}
@@ -0,0 +1,103 @@
+package tally
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeline(t *testing.T) {
+	at := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	asOf := at.Add(24 * time.Hour)
+	cast := func(seq int64, minutes int, weight int, choices ...string) entity.LogEntry {
+		return entity.LogEntry{Seq: seq, Kind: entity.LogCast, Weight: weight, Choices: choices, CreatedAt: at.Add(time.Duration(minutes) * time.Minute)}
+	}
+	testCases := []struct {
+		title   string
+		entries []entity.LogEntry
+		bucket  string
+		want    entity.Timeline
+	}{
+		{
+			title:   "empty log should have no buckets",
+			entries: []entity.LogEntry{},
+			bucket:  entity.BucketHour,
+			want:    entity.Timeline{Bucket: entity.BucketHour, AsOf: asOf, Choices: []entity.Choice{}, Buckets: []entity.TimelineBucket{}},
+		},
+		{
+			title: "ballots should be grouped by bucket with cumulative totals",
+			entries: []entity.LogEntry{
+				cast(1, 5, 1, "Mew"),
+				cast(2, 30, 1, "Pikachu"),
+				cast(3, 70, 1, "Pikachu"),
+				cast(4, 190, 2, "Mew", "Pikachu"),
+			},
+			bucket: entity.BucketHour,
+			want: entity.Timeline{Bucket: entity.BucketHour, AsOf: asOf, Ballots: 4,
+				Choices: []entity.Choice{{Title: "Mew", Count: 3}, {Title: "Pikachu", Count: 4}},
+				Buckets: []entity.TimelineBucket{
+					{Start: at, Cast: 2, Ballots: 2, Counts: []entity.TimelineCount{{Choice: "Mew", Change: 1, Total: 1}, {Choice: "Pikachu", Change: 1, Total: 1}}},
+					{Start: at.Add(time.Hour), Cast: 1, Ballots: 3, Leader: "Pikachu", Counts: []entity.TimelineCount{{Choice: "Mew", Change: 0, Total: 1}, {Choice: "Pikachu", Change: 1, Total: 2}}},
+					{Start: at.Add(3 * time.Hour), Cast: 1, Ballots: 4, Leader: "Pikachu", Counts: []entity.TimelineCount{{Choice: "Mew", Change: 2, Total: 3}, {Choice: "Pikachu", Change: 2, Total: 4}}},
+				},
+			},
+		},
+		{
+			title: "changed and retracted ballots should leave the counts",
+			entries: []entity.LogEntry{
+				cast(1, 0, 1, "Mew"),
+				cast(2, 0, 1, "Mew"),
+				{Seq: 3, Kind: entity.LogChange, Replaces: 1, Weight: 1, Choices: []string{"Pikachu"}, CreatedAt: at.Add(time.Minute)},
+				{Seq: 4, Kind: entity.LogRetract, Replaces: 2, Weight: 1, Choices: []string{}, CreatedAt: at.Add(time.Minute)},
+			},
+			bucket: entity.BucketMinute,
+			want: entity.Timeline{Bucket: entity.BucketMinute, AsOf: asOf, Ballots: 1,
+				Choices: []entity.Choice{{Title: "Mew", Count: 0}, {Title: "Pikachu", Count: 1}},
+				Buckets: []entity.TimelineBucket{
+					{Start: at, Cast: 2, Ballots: 2, Leader: "Mew", Counts: []entity.TimelineCount{{Choice: "Mew", Change: 2, Total: 2}}},
+					{Start: at.Add(time.Minute), Ballots: 1, Leader: "Pikachu", Counts: []entity.TimelineCount{{Choice: "Mew", Change: -2, Total: 0}, {Choice: "Pikachu", Change: 1, Total: 1}}},
+				},
+			},
+		},
+		{
+			title: "renamed choice should keep its series and merged choice should be left out",
+			entries: []entity.LogEntry{
+				cast(1, 0, 1, "Mew"),
+				cast(2, 0, 1, "eevee!"),
+				cast(3, 0, 1, "Eevee", "eevee!"),
+				{Seq: 4, Kind: entity.LogRename, Choices: []string{"Mew", "Mewtwo"}, CreatedAt: at.Add(24 * time.Hour)},
+				{Seq: 5, Kind: entity.LogMerge, Choices: []string{"eevee!", "Eevee"}, CreatedAt: at.Add(24 * time.Hour)},
+			},
+			bucket: entity.BucketDay,
+			want: entity.Timeline{Bucket: entity.BucketDay, AsOf: asOf, Ballots: 3,
+				Choices: []entity.Choice{{Title: "Mewtwo", Count: 1}, {Title: "Eevee", Count: 2}},
+				Buckets: []entity.TimelineBucket{
+					{Start: at.Truncate(24 * time.Hour), Cast: 3, Ballots: 3, Leader: "eevee!", Counts: []entity.TimelineCount{{Choice: "Mewtwo", Change: 1, Total: 1}, {Choice: "Eevee", Change: 1, Total: 1}}},
+					{Start: at.Truncate(24 * time.Hour).Add(24 * time.Hour), Ballots: 3, Leader: "Eevee", Counts: []entity.TimelineCount{{Choice: "Mewtwo", Change: 0, Total: 1}, {Choice: "Eevee", Change: 1, Total: 2}}},
+				},
+			},
+		},
+		{
+			title: "removed choice should be dropped from the ballots",
+			entries: []entity.LogEntry{
+				cast(1, 0, 1, "Mew", "Pikachu"),
+				{Seq: 2, Kind: entity.LogRemove, Choices: []string{"Pikachu"}, CreatedAt: at},
+				{Seq: 3, Kind: entity.LogRetract, Replaces: 1, Weight: 1, Choices: []string{}, CreatedAt: at},
+			},
+			bucket: entity.BucketHour,
+			want: entity.Timeline{Bucket: entity.BucketHour, AsOf: asOf,
+				Choices: []entity.Choice{{Title: "Mew", Count: 0}},
+				Buckets: []entity.TimelineBucket{
+					{Start: at, Cast: 1, Counts: []entity.TimelineCount{{Choice: "Mew", Change: 0, Total: 0}}},
+				},
+			},
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			assert.Equal(t, test.want, Timeline(test.entries, test.bucket, asOf))
+		})
+	}
+}
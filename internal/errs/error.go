@@ -3,9 +3,67 @@ package errs
 import "errors"
 
 var (
-	ErrEmptyVoteTitle      error = errors.New("vote title is empty")
-	ErrEmptyChoiceTitle    error = errors.New("choice title is empty")
-	ErrTitleNotExist       error = errors.New("the title doesn't exist")
-	ErrChoiceTitleNotExist error = errors.New("the choice title doesn't exist")
-	ErrTitleAlreadyExist   error = errors.New("title adready exist")
+	ErrEmptyVoteTitle        error = errors.New("vote title is empty")
+	ErrEmptyChoiceTitle      error = errors.New("choice title is empty")
+	ErrTitleNotExist         error = errors.New("the title doesn't exist")
+	ErrChoiceTitleNotExist   error = errors.New("the choice title doesn't exist")
+	ErrTitleAlreadyExist     error = errors.New("title adready exist")
+	ErrDuplicateChoice       error = errors.New("choice titles must be unique")
+	ErrVoteNotExist          error = errors.New("the vote doesn't exist")
+	ErrMalformedBody         error = errors.New("request body is malformed")
+	ErrInvalidVoteId         error = errors.New("vote id is invalid")
+	ErrInvalidSort           error = errors.New("sort must be created or votes")
+	ErrInvalidOrder          error = errors.New("order must be asc or desc")
+	ErrInvalidCursor         error = errors.New("cursor is invalid")
+	ErrInvalidLimit          error = errors.New("limit must be a number")
+	ErrInvalidAfter          error = errors.New("after must be a sequence number of the log")
+	ErrInvalidReceipt        error = errors.New("a receipt is the hex SHA-256 hash of a log entry")
+	ErrInvalidBucket         error = errors.New("bucket must be 1m, 1h or 1d")
+	ErrInvalidAsOf           error = errors.New("as_of must be an RFC 3339 time")
+	ErrInvalidBatch          error = errors.New("batch must contain from 1 to 1000 ballots")
+	ErrVoterRequired         error = errors.New("voter id is required")
+	ErrInvalidVoterId        error = errors.New("voter id must be at most 200 characters")
+	ErrAlreadyVoted          error = errors.New("the voter has already voted")
+	ErrBallotNotExist        error = errors.New("the voter hasn't voted")
+	ErrReceiptNotExist       error = errors.New("the receipt isn't in the ballot log of the vote")
+	ErrInvalidSelections     error = errors.New("min_selections must be positive and max_selections must be between min_selections and the number of choices")
+	ErrSelectionCount        error = errors.New("the number of selected choices is out of the allowed range")
+	ErrInvalidMethod         error = errors.New("method must be plurality, irv, borda, schulze, stv or score")
+	ErrInvalidSeats          error = errors.New("seats must be between 1 and the number of choices, only stv polls have several seats")
+	ErrInvalidTransfer       error = errors.New("transfer must be gregory or hare and is set only for stv polls")
+	ErrInvalidScale          error = errors.New("min_score must not be negative and max_score must be above min_score and at most 100, only score polls have a scale")
+	ErrInvalidScores         error = errors.New("a ballot of a score vote gives every selected choice a score between min_score and max_score")
+	ErrInvalidWeighted       error = errors.New("only plurality polls can be weighted")
+	ErrInvalidWeights        error = errors.New("weights must list every voter once with a weight from 1 to 1000000")
+	ErrVoteNotWeighted       error = errors.New("the vote is not weighted")
+	ErrNotEligible           error = errors.New("the voter has no weight in the vote")
+	ErrInvalidSchedule       error = errors.New("closes_at must be in the future and later than opens_at")
+	ErrVoteNotOpen           error = errors.New("the vote is not open yet")
+	ErrVoteClosed            error = errors.New("the vote is closed")
+	ErrInvalidTransition     error = errors.New("the vote can't move to this status")
+	ErrInvalidVisibility     error = errors.New("visibility must be always, after_vote or after_close")
+	ErrResultsAfterVote      error = errors.New("the results are shown to the voters who have voted")
+	ErrResultsHidden         error = errors.New("the results are hidden until the vote closes")
+	ErrEmptyEdit             error = errors.New("the edit changes neither the title, the choices nor the ballot privacy")
+	ErrChoiceHasVotes        error = errors.New("the choice has votes, force is required to remove it")
+	ErrInvalidWriteIn        error = errors.New("only plurality and ranked polls accept write-ins")
+	ErrWriteInTooLong        error = errors.New("a write-in must be at most 200 characters")
+	ErrWriteInLimit          error = errors.New("the vote has the maximum number of write-in choices")
+	ErrInvalidMerge          error = errors.New("a write-in choice can only be merged into another choice of the vote")
+	ErrNotOwner              error = errors.New("only the owner of the vote can do this")
+	ErrInvalidRules          error = errors.New("quorum and threshold rules apply to single choice plurality polls, the abstention must be one of the choices")
+	ErrInvalidQuorum         error = errors.New("quorum must not be negative, quorum_percent must be from 0 to 100 and needs a weighted poll")
+	ErrInvalidThreshold      error = errors.New("threshold must be plurality, majority, two_thirds or percent with threshold_percent from 1 to 100")
+	ErrInvalidResultSort     error = errors.New("sort of the results must be votes or title")
+	ErrInvalidTop            error = errors.New("top must be a positive number")
+	ErrInvalidTieBreak       error = errors.New("tie_break must be none, earliest, random or owner, ranked polls have no tie-break and tie_seed needs a random one")
+	ErrTieNotOwners          error = errors.New("the ties of the vote aren't decided by its owner")
+	ErrVoteNotClosed         error = errors.New("the vote isn't closed")
+	ErrNotTied               error = errors.New("the choice isn't tied for the lead")
+	ErrInvalidPrivacy        error = errors.New("ballot_privacy must be private, public or anonymous, anonymous polls can't be weighted")
+	ErrPrivacyLocked         error = errors.New("the ballot privacy can't change once the voting starts")
+	ErrBallotsNotPublic      error = errors.New("the voters of the vote aren't public")
+	ErrInvalidIdempotencyKey error = errors.New("idempotency key must be 1-255 characters")
+	ErrIdempotencyKeyReused  error = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyInProgress error = errors.New("request with the same idempotency key is in progress")
 )
@@ -0,0 +1,365 @@
+package grpcHandler
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/VrMolodyakov/vote-service/internal/handler"
+	pb "github.com/VrMolodyakov/vote-service/pkg/api/vote"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const voterIdKey string = "x-voter-id"
+
+type server struct {
+	pb.UnimplementedVoteServiceServer
+	logger        *logging.Logger
+	voteService   handler.VoteService
+	choiceService handler.ChoiceService
+	resultService handler.ResultService
+}
+
+func NewServer(
+	logger *logging.Logger,
+	voteService handler.VoteService,
+	choiceService handler.ChoiceService,
+	resultService handler.ResultService) *server {
+	return &server{
+		logger:        logger,
+		voteService:   voteService,
+		choiceService: choiceService,
+		resultService: resultService,
+	}
+}
+
+func (s *server) Register(grpcServer *grpc.Server) {
+	pb.RegisterVoteServiceServer(grpcServer, s)
+}
+
+func (s *server) CreatePoll(ctx context.Context, req *pb.CreatePollRequest) (*pb.Poll, error) {
+	s.logger.Debugf("try to create poll %v", req.GetTitle())
+	poll := entity.Poll{
+		Title:         req.GetTitle(),
+		Choices:       req.GetChoices(),
+		MinSelections: int(req.GetMinSelections()),
+		MaxSelections: int(req.GetMaxSelections()),
+		Method:        req.GetMethod(),
+		Seats:         int(req.GetSeats()),
+		Transfer:      req.GetSurplusTransfer(),
+		MinScore:      int(req.GetMinScore()),
+		MaxScore:      int(req.GetMaxScore()),
+		Weighted:      req.GetWeighted(),
+		Draft:         req.GetDraft(),
+		OpensAt:       timeOf(req.GetOpensAt()),
+		ClosesAt:      timeOf(req.GetClosesAt()),
+		Visibility:    req.GetVisibility(),
+		OwnerId:       voterId(ctx),
+		AllowWriteIn:  req.GetAllowWriteIn(),
+		Rules:         rulesOf(req.GetRules()),
+		TieBreak:      req.GetTieBreak(),
+		TieSeed:       req.GetTieSeed(),
+		Privacy:       req.GetBallotPrivacy(),
+	}
+	id, err := s.voteService.CreatePoll(ctx, poll)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	min, max := poll.Selections()
+	seats, transfer := poll.Election()
+	minScore, maxScore := poll.Scale()
+	response := &pb.Poll{
+		Id:              int64(id),
+		Title:           req.GetTitle(),
+		Choices:         make([]*pb.Choice, 0, len(req.GetChoices())),
+		MinSelections:   int32(min),
+		MaxSelections:   int32(max),
+		Method:          poll.CountingMethod(),
+		Seats:           int32(seats),
+		SurplusTransfer: transfer,
+		MinScore:        int32(minScore),
+		MaxScore:        int32(maxScore),
+		Weighted:        poll.Weighted,
+		Status:          poll.InitialStatus(time.Now()),
+		OpensAt:         req.GetOpensAt(),
+		ClosesAt:        req.GetClosesAt(),
+		Visibility:      poll.ResultVisibility(),
+		AllowWriteIn:    poll.AllowWriteIn,
+		BallotPrivacy:   poll.BallotPrivacy(),
+	}
+	if tieBreak := poll.TieBreaking(); tieBreak != entity.TieBreakNone {
+		response.TieBreak, response.TieSeed = tieBreak, req.GetTieSeed()
+	}
+	if !poll.Rules.Empty() {
+		rules := poll.Rules
+		rules.Threshold = rules.PassingThreshold()
+		response.Rules = rulesToPb(rules)
+	}
+	for _, choice := range req.GetChoices() {
+		response.Choices = append(response.Choices, &pb.Choice{Title: choice})
+	}
+	return response, nil
+}
+
+func (s *server) GetResults(ctx context.Context, req *pb.GetResultsRequest) (*pb.Results, error) {
+	id, err := voteId(req.GetVoteId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	s.logger.Debugf("try to get results for vote %v", id)
+	query := entity.ResultQuery{VoteId: id, Sort: req.GetSort(), Order: req.GetOrder(), Top: int(req.GetTop())}
+	result, err := s.choiceService.Get(ctx, query, voterId(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	vote, choices := result.Vote, result.Choices
+	results := &pb.Results{
+		VoteId:     int64(id),
+		Ballots:    int64(result.Ballots),
+		Method:     vote.Method,
+		Choices:    choicesToPb(choices),
+		Weighted:   vote.Weighted,
+		Winner:     result.Winner,
+		Tied:       result.Tied,
+		TieBreak:   result.TieBreak,
+		TotalVotes: int64(result.Total),
+	}
+	if result.TieBreak == entity.TieBreakRandom {
+		results.TieSeed = vote.TieSeed
+	}
+	if vote.Weighted {
+		for i, choice := range choices {
+			results.Choices[i].Voters = int64(choice.Voters)
+		}
+	}
+	if entity.Ranked(vote.Method) {
+		tally, err := s.choiceService.Tally(ctx, id, voterId(ctx))
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		results.Tally = tallyToPb(tally, req.GetRounds())
+	}
+	if !vote.Rules.Empty() {
+		outcome, err := s.choiceService.Outcome(ctx, id, voterId(ctx))
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		results.Outcome = &pb.Outcome{
+			Result:      outcome.Result,
+			Choice:      outcome.Choice,
+			Votes:       int64(outcome.Votes),
+			Counted:     int64(outcome.Counted),
+			Share:       outcome.Share,
+			Abstentions: int64(outcome.Abstentions),
+			Turnout:     outcome.Turnout,
+		}
+	}
+	return results, nil
+}
+
+func (s *server) CastVote(ctx context.Context, req *pb.CastVoteRequest) (*pb.CastVoteResponse, error) {
+	id, err := voteId(req.GetVoteId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	choices, scores := ballotOf(req)
+	s.logger.Debugf("try to cast ballot %v with scores %v for vote %v", choices, scores, id)
+	receipt, err := s.choiceService.UpdateById(ctx, id, choices, scores, voterId(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CastVoteResponse{Receipt: receipt}, nil
+}
+
+func (s *server) DeletePoll(ctx context.Context, req *pb.DeletePollRequest) (*pb.DeletePollResponse, error) {
+	id, err := voteId(req.GetVoteId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	s.logger.Debugf("try to delete vote %v", id)
+	if err := s.voteService.Delete(ctx, strconv.Itoa(id)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeletePollResponse{}, nil
+}
+
+func (s *server) StreamResults(req *pb.GetResultsRequest, stream pb.VoteService_StreamResultsServer) error {
+	id, err := voteId(req.GetVoteId())
+	if err != nil {
+		return toStatus(err)
+	}
+	s.logger.Debugf("try to stream results for vote %v", id)
+	err = s.resultService.Stream(stream.Context(), id, voterId(stream.Context()), func(event entity.ResultEvent) error {
+		return stream.Send(eventToPb(event))
+	})
+	if err != nil {
+		return toStatus(err)
+	}
+	return nil
+}
+
+func voteId(id int64) (int, error) {
+	if id <= 0 {
+		return 0, errs.ErrInvalidVoteId
+	}
+	return int(id), nil
+}
+
+// timeOf converts the unix time in milliseconds, 0 stands for no time.
+func timeOf(millis int64) *time.Time {
+	if millis == 0 {
+		return nil
+	}
+	t := time.UnixMilli(millis)
+	return &t
+}
+
+// voterId identifies the voter of the call by the metadata, the same way the
+// http api does by the header.
+func voterId(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, voterIdKey); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ballotOf returns the selected choices and their scores, the scored choices
+// are sorted by title.
+func ballotOf(req *pb.CastVoteRequest) ([]string, []int) {
+	if len(req.GetScores()) > 0 {
+		choices := make([]string, 0, len(req.GetScores()))
+		for choice := range req.GetScores() {
+			choices = append(choices, choice)
+		}
+		sort.Strings(choices)
+		scores := make([]int, 0, len(choices))
+		for _, choice := range choices {
+			scores = append(scores, int(req.GetScores()[choice]))
+		}
+		return choices, scores
+	}
+	if len(req.GetChoices()) > 0 {
+		return req.GetChoices(), nil
+	}
+	return []string{req.GetChoice()}, nil
+}
+
+func choicesToPb(choices []entity.Choice) []*pb.Choice {
+	result := make([]*pb.Choice, 0, len(choices))
+	for _, choice := range choices {
+		result = append(result, &pb.Choice{
+			Title:   choice.Title,
+			Count:   int64(choice.Count),
+			Scores:  scoresToPb(choice.Histogram),
+			WriteIn: choice.WriteIn,
+			Rank:    int32(choice.Rank),
+			Percent: choice.Percent,
+		})
+	}
+	return result
+}
+
+func scoresToPb(histogram entity.Histogram) *pb.ScoreSummary {
+	if histogram == nil {
+		return nil
+	}
+	result := &pb.ScoreSummary{
+		Mean:      histogram.Mean(),
+		Median:    histogram.Median(),
+		Stddev:    histogram.StdDev(),
+		Histogram: make([]*pb.ScoreCount, 0, len(histogram)),
+	}
+	for _, bucket := range histogram {
+		result.Histogram = append(result.Histogram, &pb.ScoreCount{Score: int32(bucket.Score), Count: int64(bucket.Count)})
+	}
+	return result
+}
+
+var eventKinds = map[string]pb.ResultEvent_Kind{
+	entity.SnapshotEvent:  pb.ResultEvent_SNAPSHOT,
+	entity.DeltaEvent:     pb.ResultEvent_DELTA,
+	entity.HeartbeatEvent: pb.ResultEvent_HEARTBEAT,
+}
+
+func rulesOf(rules *pb.Rules) entity.Rules {
+	return entity.Rules{
+		Quorum:           int(rules.GetQuorum()),
+		QuorumPercent:    int(rules.GetQuorumPercent()),
+		Threshold:        rules.GetThreshold(),
+		ThresholdPercent: int(rules.GetThresholdPercent()),
+		Abstention:       rules.GetAbstention(),
+		CountAbstentions: rules.GetCountAbstentions(),
+	}
+}
+
+func rulesToPb(rules entity.Rules) *pb.Rules {
+	return &pb.Rules{
+		Quorum:           int32(rules.Quorum),
+		QuorumPercent:    int32(rules.QuorumPercent),
+		Threshold:        rules.Threshold,
+		ThresholdPercent: int32(rules.ThresholdPercent),
+		Abstention:       rules.Abstention,
+		CountAbstentions: rules.CountAbstentions,
+	}
+}
+
+func tallyToPb(tally entity.Tally, rounds bool) *pb.Tally {
+	result := &pb.Tally{Winners: tally.Winners, CondorcetWinner: tally.Condorcet, Seats: int32(tally.Seats), Quota: int64(tally.Quota)}
+	for _, score := range tally.Scores {
+		result.Scores = append(result.Scores, &pb.Score{Choice: score.Choice, Score: int64(score.Score)})
+	}
+	if !rounds {
+		return result
+	}
+	for _, round := range tally.Rounds {
+		votes := make([]*pb.Choice, 0, len(round.Votes))
+		for _, score := range round.Votes {
+			votes = append(votes, &pb.Choice{Title: score.Choice, Count: int64(score.Score)})
+		}
+		result.Rounds = append(result.Rounds, &pb.Round{
+			Number:     int32(round.Number),
+			Votes:      votes,
+			Exhausted:  int64(round.Exhausted),
+			Eliminated: round.Eliminated,
+		})
+	}
+	for _, stage := range tally.Stages {
+		result.Stages = append(result.Stages, stageToPb(stage))
+	}
+	return result
+}
+
+func stageToPb(stage entity.Stage) *pb.Stage {
+	result := &pb.Stage{
+		Number:    int32(stage.Number),
+		Votes:     make([]*pb.Share, 0, len(stage.Votes)),
+		Exhausted: stage.Exhausted,
+		Elected:   stage.Elected,
+		Excluded:  stage.Excluded,
+	}
+	for _, share := range stage.Votes {
+		result.Votes = append(result.Votes, &pb.Share{Choice: share.Choice, Votes: share.Votes})
+	}
+	if transfer := stage.Transfer; transfer != nil {
+		result.Transfer = &pb.Transfer{Kind: pb.Transfer_EXCLUSION, From: transfer.From, Votes: transfer.Votes}
+		if transfer.Surplus {
+			result.Transfer.Kind, result.Transfer.Value = pb.Transfer_SURPLUS, transfer.Value
+		}
+	}
+	return result
+}
+
+func eventToPb(event entity.ResultEvent) *pb.ResultEvent {
+	result := &pb.ResultEvent{Kind: eventKinds[event.Kind], VoteId: int64(event.VoteId)}
+	if event.Kind == entity.HeartbeatEvent {
+		result.Time = event.Time.UnixMilli()
+		return result
+	}
+	result.Choices = choicesToPb(event.Choices)
+	return result
+}
@@ -0,0 +1,573 @@
+package grpcHandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/VrMolodyakov/vote-service/internal/handler/mocks"
+	pb "github.com/VrMolodyakov/vote-service/pkg/api/vote"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+type testServer struct {
+	client     pb.VoteServiceClient
+	voteServ   *mocks.MockVoteService
+	choiceServ *mocks.MockChoiceService
+	resultServ *mocks.MockResultService
+}
+
+func newTestServer(t *testing.T) testServer {
+	ctrl := gomock.NewController(t)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	resultServ := mocks.NewMockResultService(ctrl)
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	NewServer(logging.GetLogger("debug"), voteServ, choiceServ, resultServ).Register(grpcServer)
+	go grpcServer.Serve(listener)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+	return testServer{pb.NewVoteServiceClient(conn), voteServ, choiceServ, resultServ}
+}
+
+func TestCreatePoll(t *testing.T) {
+	server := newTestServer(t)
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		input *pb.CreatePollRequest
+		want  *pb.Poll
+		code  codes.Code
+	}{
+		{
+			title: "should create poll",
+			mock: func() {
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}}).Return(1, nil)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}},
+			want:  &pb.Poll{Id: 1, Title: "Pokemon", Choices: []*pb.Choice{{Title: "Pikachu"}, {Title: "Mew"}}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways, BallotPrivacy: entity.PrivacyPrivate},
+			code:  codes.OK,
+		},
+		{
+			title: "should create multi-select poll",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu", "Mew", "Ditto"}, MaxSelections: 2}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu", "Mew", "Ditto"}, MaxSelections: 2},
+			want:  &pb.Poll{Id: 1, Title: "Pokemon", Choices: []*pb.Choice{{Title: "Pikachu"}, {Title: "Mew"}, {Title: "Ditto"}}, MinSelections: 1, MaxSelections: 2, Method: entity.MethodPlurality, Seats: 1, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways, BallotPrivacy: entity.PrivacyPrivate},
+			code:  codes.OK,
+		},
+		{
+			title: "should create write-in poll",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu"}, AllowWriteIn: true}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu"}, AllowWriteIn: true},
+			want:  &pb.Poll{Id: 1, Title: "Pokemon", Choices: []*pb.Choice{{Title: "Pikachu"}}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways, AllowWriteIn: true, BallotPrivacy: entity.PrivacyPrivate},
+			code:  codes.OK,
+		},
+		{
+			title: "should create scheduled poll as a draft",
+			mock: func() {
+				opensAt := time.UnixMilli(4102444800000)
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu"}, OpensAt: &opensAt}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu"}, OpensAt: 4102444800000},
+			want:  &pb.Poll{Id: 1, Title: "Pokemon", Choices: []*pb.Choice{{Title: "Pikachu"}}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Status: entity.StatusDraft, OpensAt: 4102444800000, Visibility: entity.VisibilityAlways, BallotPrivacy: entity.PrivacyPrivate},
+			code:  codes.OK,
+		},
+		{
+			title: "should create stv poll",
+			mock: func() {
+				poll := entity.Poll{Title: "Committee", Choices: []string{"Pikachu", "Mew", "Ditto"}, Method: entity.MethodStv, Seats: 2, Transfer: entity.TransferHare}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			input: &pb.CreatePollRequest{Title: "Committee", Choices: []string{"Pikachu", "Mew", "Ditto"}, Method: entity.MethodStv, Seats: 2, SurplusTransfer: entity.TransferHare},
+			want: &pb.Poll{
+				Id:              1,
+				Title:           "Committee",
+				Choices:         []*pb.Choice{{Title: "Pikachu"}, {Title: "Mew"}, {Title: "Ditto"}},
+				MinSelections:   1,
+				MaxSelections:   3,
+				Method:          entity.MethodStv,
+				Seats:           2,
+				SurplusTransfer: entity.TransferHare,
+				Status:          entity.StatusOpen,
+				Visibility:      entity.VisibilityAlways,
+				BallotPrivacy:   entity.PrivacyPrivate,
+			},
+			code: codes.OK,
+		},
+		{
+			title: "should create poll with random tie-break",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}, TieBreak: entity.TieBreakRandom, TieSeed: 42}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}, TieBreak: entity.TieBreakRandom, TieSeed: 42},
+			want: &pb.Poll{
+				Id:            1,
+				Title:         "Pokemon",
+				Choices:       []*pb.Choice{{Title: "Pikachu"}, {Title: "Mew"}},
+				MinSelections: 1,
+				MaxSelections: 1,
+				Method:        entity.MethodPlurality,
+				Seats:         1,
+				Status:        entity.StatusOpen,
+				Visibility:    entity.VisibilityAlways,
+				TieBreak:      entity.TieBreakRandom,
+				TieSeed:       42,
+				BallotPrivacy: entity.PrivacyPrivate,
+			},
+			code: codes.OK,
+		},
+		{
+			title: "should create anonymous poll",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}, Privacy: entity.PrivacyAnonymous}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}, BallotPrivacy: entity.PrivacyAnonymous},
+			want:  &pb.Poll{Id: 1, Title: "Pokemon", Choices: []*pb.Choice{{Title: "Pikachu"}, {Title: "Mew"}}, MinSelections: 1, MaxSelections: 1, Method: entity.MethodPlurality, Seats: 1, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways, BallotPrivacy: entity.PrivacyAnonymous},
+			code:  codes.OK,
+		},
+		{
+			title: "anonymous weighted poll and InvalidArgument code",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}, Weighted: true, Privacy: entity.PrivacyAnonymous}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(-1, errs.ErrInvalidPrivacy)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu", "Mew"}, Weighted: true, BallotPrivacy: entity.PrivacyAnonymous},
+			code:  codes.InvalidArgument,
+		},
+		{
+			title: "unknown visibility and InvalidArgument code",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu"}, Visibility: "never"}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(-1, errs.ErrInvalidVisibility)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu"}, Visibility: "never"},
+			code:  codes.InvalidArgument,
+		},
+		{
+			title: "invalid surplus transfer and InvalidArgument code",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu"}, Transfer: entity.TransferHare}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(-1, errs.ErrInvalidTransfer)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu"}, SurplusTransfer: entity.TransferHare},
+			code:  codes.InvalidArgument,
+		},
+		{
+			title: "invalid selections and InvalidArgument code",
+			mock: func() {
+				poll := entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu"}, MaxSelections: 2}
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(-1, errs.ErrInvalidSelections)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu"}, MaxSelections: 2},
+			code:  codes.InvalidArgument,
+		},
+		{
+			title: "title already exists and AlreadyExists code",
+			mock: func() {
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu"}}).Return(-1, errs.ErrTitleAlreadyExist)
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu"}},
+			code:  codes.AlreadyExists,
+		},
+		{
+			title: "empty title and InvalidArgument code",
+			mock: func() {
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), entity.Poll{Title: "", Choices: []string{"Pikachu"}}).Return(-1, errs.ErrEmptyVoteTitle)
+			},
+			input: &pb.CreatePollRequest{Choices: []string{"Pikachu"}},
+			code:  codes.InvalidArgument,
+		},
+		{
+			title: "unexpected error and Internal code",
+			mock: func() {
+				server.voteServ.EXPECT().CreatePoll(gomock.Any(), entity.Poll{Title: "Pokemon", Choices: []string{"Pikachu"}}).Return(-1, errors.New("internal db error"))
+			},
+			input: &pb.CreatePollRequest{Title: "Pokemon", Choices: []string{"Pikachu"}},
+			code:  codes.Internal,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := server.client.CreatePoll(context.Background(), test.input)
+			assert.Equal(t, test.code, status.Code(err))
+			if test.code == codes.OK {
+				assert.True(t, proto.Equal(test.want, got), "got %v", got)
+			}
+		})
+	}
+}
+
+func TestGetResults(t *testing.T) {
+	server := newTestServer(t)
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		input *pb.GetResultsRequest
+		want  *pb.Results
+		code  codes.Code
+	}{
+		{
+			title: "should return results",
+			mock: func() {
+				result := entity.Result{Vote: entity.Vote{Id: 1, Title: "Pokemon", Ballots: 3, Method: entity.MethodPlurality}, Ballots: 3, Total: 3, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 3}}}
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(result, nil)
+			},
+			input: &pb.GetResultsRequest{VoteId: 1},
+			want:  &pb.Results{VoteId: 1, Ballots: 3, TotalVotes: 3, Method: entity.MethodPlurality, Choices: []*pb.Choice{{Title: "Pikachu", Count: 3}}},
+			code:  codes.OK,
+		},
+		{
+			title: "should return head counts of weighted vote",
+			mock: func() {
+				result := entity.Result{Vote: entity.Vote{Id: 1, Title: "Board", Ballots: 3, Method: entity.MethodPlurality, Weighted: true}, Ballots: 3, Total: 300, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 300, Voters: 3}}}
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(result, nil)
+			},
+			input: &pb.GetResultsRequest{VoteId: 1},
+			want:  &pb.Results{VoteId: 1, Ballots: 3, TotalVotes: 300, Method: entity.MethodPlurality, Weighted: true, Choices: []*pb.Choice{{Title: "Pikachu", Count: 300, Voters: 3}}},
+			code:  codes.OK,
+		},
+		{
+			title: "should return outcome of vote with rules",
+			mock: func() {
+				rules := entity.Rules{Quorum: 5, Threshold: entity.ThresholdMajority}
+				result := entity.Result{Vote: entity.Vote{Id: 1, Title: "Motion", Ballots: 4, Method: entity.MethodPlurality, Rules: rules}, Ballots: 4, Total: 4, Choices: []entity.Choice{{Title: "No", VoteId: 1, Count: 2}, {Title: "Yes", VoteId: 1, Count: 2}}}
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(result, nil)
+				server.choiceServ.EXPECT().Outcome(gomock.Any(), 1, gomock.Any()).Return(entity.Outcome{Result: entity.OutcomeNoQuorum, Ballots: 4}, nil)
+			},
+			input: &pb.GetResultsRequest{VoteId: 1},
+			want: &pb.Results{VoteId: 1, Ballots: 4, TotalVotes: 4, Method: entity.MethodPlurality, Choices: []*pb.Choice{{Title: "No", Count: 2}, {Title: "Yes", Count: 2}},
+				Outcome: &pb.Outcome{Result: entity.OutcomeNoQuorum}},
+			code: codes.OK,
+		},
+		{
+			title: "should return score statistics of score vote",
+			mock: func() {
+				histogram := entity.Histogram{{Score: 0, Count: 1}, {Score: 1, Count: 0}, {Score: 2, Count: 3}}
+				result := entity.Result{Vote: entity.Vote{Id: 1, Title: "Pokemon", Ballots: 4, Method: entity.MethodScore, MaxScore: 2}, Ballots: 4, Total: 4, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 4, Histogram: histogram}}}
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(result, nil)
+			},
+			input: &pb.GetResultsRequest{VoteId: 1},
+			want: &pb.Results{VoteId: 1, Ballots: 4, TotalVotes: 4, Method: entity.MethodScore, Choices: []*pb.Choice{{
+				Title: "Pikachu",
+				Count: 4,
+				Scores: &pb.ScoreSummary{
+					Mean:      1.5,
+					Median:    2,
+					Stddev:    math.Sqrt(0.75),
+					Histogram: []*pb.ScoreCount{{Score: 0, Count: 1}, {Score: 1, Count: 0}, {Score: 2, Count: 3}},
+				},
+			}}},
+			code: codes.OK,
+		},
+		{
+			title: "should return tally of ranked vote with rounds",
+			mock: func() {
+				result := entity.Result{Vote: entity.Vote{Id: 1, Title: "Pokemon", Ballots: 3, Method: entity.MethodIrv}, Ballots: 3, Total: 3, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 3}}}
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(result, nil)
+				tally := entity.Tally{
+					Method:  entity.MethodIrv,
+					Ballots: 3,
+					Winners: []string{"Pikachu"},
+					Rounds:  []entity.Round{{Number: 1, Votes: []entity.Score{{Choice: "Pikachu", Score: 3}}, Eliminated: []string{}}},
+				}
+				server.choiceServ.EXPECT().Tally(gomock.Any(), 1, gomock.Any()).Return(tally, nil)
+			},
+			input: &pb.GetResultsRequest{VoteId: 1, Rounds: true},
+			want: &pb.Results{
+				VoteId:     1,
+				Ballots:    3,
+				TotalVotes: 3,
+				Method:     entity.MethodIrv,
+				Choices:    []*pb.Choice{{Title: "Pikachu", Count: 3}},
+				Tally: &pb.Tally{
+					Winners: []string{"Pikachu"},
+					Rounds:  []*pb.Round{{Number: 1, Votes: []*pb.Choice{{Title: "Pikachu", Count: 3}}}},
+				},
+			},
+			code: codes.OK,
+		},
+		{
+			title: "should return ranked top results with the tie broken at random",
+			mock: func() {
+				vote := entity.Vote{Id: 1, Title: "Pokemon", Ballots: 2, Method: entity.MethodPlurality, TieBreak: entity.TieBreakRandom, TieSeed: 42}
+				choices := []entity.Choice{{Title: "Eevee", VoteId: 1, Count: 1, Rank: 1, Percent: 50}}
+				result := entity.Result{Vote: vote, Ballots: 2, Total: 2, Choices: choices, Winner: "Eevee", Tied: []string{"Eevee", "Mew"}, TieBreak: entity.TieBreakRandom}
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1, Sort: entity.SortByTitle, Top: 1}, gomock.Any()).Return(result, nil)
+			},
+			input: &pb.GetResultsRequest{VoteId: 1, Sort: entity.SortByTitle, Top: 1},
+			want: &pb.Results{
+				VoteId:     1,
+				Ballots:    2,
+				TotalVotes: 2,
+				Method:     entity.MethodPlurality,
+				Choices:    []*pb.Choice{{Title: "Eevee", Count: 1, Rank: 1, Percent: 50}},
+				Winner:     "Eevee",
+				Tied:       []string{"Eevee", "Mew"},
+				TieBreak:   entity.TieBreakRandom,
+				TieSeed:    42,
+			},
+			code: codes.OK,
+		},
+		{
+			title: "invalid sort and InvalidArgument code",
+			mock: func() {
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1, Sort: "created"}, gomock.Any()).Return(entity.Result{}, errs.ErrInvalidResultSort)
+			},
+			input: &pb.GetResultsRequest{VoteId: 1, Sort: "created"},
+			code:  codes.InvalidArgument,
+		},
+		{
+			title: "vote not found and NotFound code",
+			mock: func() {
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 2}, gomock.Any()).Return(entity.Result{}, errs.ErrVoteNotExist)
+			},
+			input: &pb.GetResultsRequest{VoteId: 2},
+			code:  codes.NotFound,
+		},
+		{
+			title: "results hidden until the vote closes and PermissionDenied code",
+			mock: func() {
+				server.choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 3}, gomock.Any()).Return(entity.Result{}, errs.ErrResultsHidden)
+			},
+			input: &pb.GetResultsRequest{VoteId: 3},
+			code:  codes.PermissionDenied,
+		},
+		{
+			title: "invalid id and InvalidArgument code",
+			mock:  func() {},
+			input: &pb.GetResultsRequest{VoteId: 0},
+			code:  codes.InvalidArgument,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			got, err := server.client.GetResults(context.Background(), test.input)
+			assert.Equal(t, test.code, status.Code(err))
+			if test.code == codes.OK {
+				assert.True(t, proto.Equal(test.want, got), "got %v", got)
+			}
+		})
+	}
+}
+
+func TestCastVote(t *testing.T) {
+	server := newTestServer(t)
+	type mockCall func()
+	testCases := []struct {
+		title   string
+		mock    mockCall
+		voterId string
+		input   *pb.CastVoteRequest
+		code    codes.Code
+	}{
+		{
+			title: "should cast vote",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Pikachu"}, []int(nil), "ash").Return("receipt", nil)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Choice: "Pikachu"},
+			code:    codes.OK,
+		},
+		{
+			title: "should cast vote for several choices",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Pikachu", "Mew"}, []int(nil), "ash").Return("receipt", nil)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Choices: []string{"Pikachu", "Mew"}},
+			code:    codes.OK,
+		},
+		{
+			title: "selection count out of range and InvalidArgument code",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Pikachu", "Mew"}, []int(nil), "ash").Return("", errs.ErrSelectionCount)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Choices: []string{"Pikachu", "Mew"}},
+			code:    codes.InvalidArgument,
+		},
+		{
+			title: "write-in limit reached and ResourceExhausted code",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Eevee"}, []int(nil), "ash").Return("", errs.ErrWriteInLimit)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Choices: []string{"Eevee"}},
+			code:    codes.ResourceExhausted,
+		},
+		{
+			title: "should cast scored ballot",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew", "Pikachu"}, []int{4, 2}, "ash").Return("receipt", nil)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Scores: map[string]int32{"Pikachu": 2, "Mew": 4}},
+			code:    codes.OK,
+		},
+		{
+			title: "score out of the scale and InvalidArgument code",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int{42}, "ash").Return("", errs.ErrInvalidScores)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Scores: map[string]int32{"Mew": 42}},
+			code:    codes.InvalidArgument,
+		},
+		{
+			title: "choice not found and NotFound code",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("", errs.ErrChoiceTitleNotExist)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Choice: "Mew"},
+			code:    codes.NotFound,
+		},
+		{
+			title: "repeated ballot and AlreadyExists code",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("", errs.ErrAlreadyVoted)
+			},
+			voterId: "ash",
+			input:   &pb.CastVoteRequest{VoteId: 1, Choice: "Mew"},
+			code:    codes.AlreadyExists,
+		},
+		{
+			title: "missing voter and Unauthenticated code",
+			mock: func() {
+				server.choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "").Return("", errs.ErrVoterRequired)
+			},
+			input: &pb.CastVoteRequest{VoteId: 1, Choice: "Mew"},
+			code:  codes.Unauthenticated,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			ctx := context.Background()
+			if test.voterId != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, voterIdKey, test.voterId)
+			}
+			response, err := server.client.CastVote(ctx, test.input)
+			assert.Equal(t, test.code, status.Code(err))
+			if err == nil {
+				assert.Equal(t, "receipt", response.Receipt)
+			}
+		})
+	}
+}
+
+func TestDeletePoll(t *testing.T) {
+	server := newTestServer(t)
+	type mockCall func()
+	testCases := []struct {
+		title string
+		mock  mockCall
+		input *pb.DeletePollRequest
+		code  codes.Code
+	}{
+		{
+			title: "should delete poll",
+			mock: func() {
+				server.voteServ.EXPECT().Delete(gomock.Any(), "1").Return(nil)
+			},
+			input: &pb.DeletePollRequest{VoteId: 1},
+			code:  codes.OK,
+		},
+		{
+			title: "unexpected error and Internal code",
+			mock: func() {
+				server.voteServ.EXPECT().Delete(gomock.Any(), "1").Return(errors.New("internal db error"))
+			},
+			input: &pb.DeletePollRequest{VoteId: 1},
+			code:  codes.Internal,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			_, err := server.client.DeletePoll(context.Background(), test.input)
+			assert.Equal(t, test.code, status.Code(err))
+		})
+	}
+}
+
+func TestStreamResults(t *testing.T) {
+	server := newTestServer(t)
+	heartbeat := time.Date(2022, 8, 1, 0, 0, 15, 0, time.UTC)
+	events := []entity.ResultEvent{
+		{Kind: entity.SnapshotEvent, VoteId: 1, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 1}}},
+		{Kind: entity.DeltaEvent, VoteId: 1, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 2}}},
+		{Kind: entity.HeartbeatEvent, VoteId: 1, Time: heartbeat},
+	}
+	server.resultServ.EXPECT().Stream(gomock.Any(), 1, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, voteId int, viewerId string, send func(event entity.ResultEvent) error) error {
+			for _, event := range events {
+				if err := send(event); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	server.resultServ.EXPECT().Stream(gomock.Any(), 2, gomock.Any(), gomock.Any()).Return(errs.ErrVoteNotExist)
+
+	want := []*pb.ResultEvent{
+		{Kind: pb.ResultEvent_SNAPSHOT, VoteId: 1, Choices: []*pb.Choice{{Title: "Pikachu", Count: 1}}},
+		{Kind: pb.ResultEvent_DELTA, VoteId: 1, Choices: []*pb.Choice{{Title: "Pikachu", Count: 2}}},
+		{Kind: pb.ResultEvent_HEARTBEAT, VoteId: 1, Time: heartbeat.UnixMilli()},
+	}
+	stream, err := server.client.StreamResults(context.Background(), &pb.GetResultsRequest{VoteId: 1})
+	assert.NoError(t, err)
+	for _, expected := range want {
+		got, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(expected, got), "got %v", got)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	stream, err = server.client.StreamResults(context.Background(), &pb.GetResultsRequest{VoteId: 2})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
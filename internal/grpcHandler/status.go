@@ -0,0 +1,75 @@
+package grpcHandler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type statusCode struct {
+	err  error
+	code codes.Code
+}
+
+var statusCodes = []statusCode{
+	{errs.ErrMalformedBody, codes.InvalidArgument},
+	{errs.ErrInvalidVoteId, codes.InvalidArgument},
+	{errs.ErrEmptyVoteTitle, codes.InvalidArgument},
+	{errs.ErrEmptyChoiceTitle, codes.InvalidArgument},
+	{errs.ErrDuplicateChoice, codes.InvalidArgument},
+	{errs.ErrInvalidVoterId, codes.InvalidArgument},
+	{errs.ErrInvalidSelections, codes.InvalidArgument},
+	{errs.ErrSelectionCount, codes.InvalidArgument},
+	{errs.ErrInvalidMethod, codes.InvalidArgument},
+	{errs.ErrInvalidSeats, codes.InvalidArgument},
+	{errs.ErrInvalidTransfer, codes.InvalidArgument},
+	{errs.ErrInvalidScale, codes.InvalidArgument},
+	{errs.ErrInvalidScores, codes.InvalidArgument},
+	{errs.ErrInvalidWeighted, codes.InvalidArgument},
+	{errs.ErrInvalidSchedule, codes.InvalidArgument},
+	{errs.ErrInvalidVisibility, codes.InvalidArgument},
+	{errs.ErrInvalidWriteIn, codes.InvalidArgument},
+	{errs.ErrWriteInTooLong, codes.InvalidArgument},
+	{errs.ErrInvalidRules, codes.InvalidArgument},
+	{errs.ErrInvalidQuorum, codes.InvalidArgument},
+	{errs.ErrInvalidThreshold, codes.InvalidArgument},
+	{errs.ErrInvalidTieBreak, codes.InvalidArgument},
+	{errs.ErrInvalidResultSort, codes.InvalidArgument},
+	{errs.ErrInvalidOrder, codes.InvalidArgument},
+	{errs.ErrInvalidTop, codes.InvalidArgument},
+	{errs.ErrInvalidPrivacy, codes.InvalidArgument},
+	{errs.ErrVoterRequired, codes.Unauthenticated},
+	{errs.ErrNotEligible, codes.PermissionDenied},
+	{errs.ErrResultsAfterVote, codes.PermissionDenied},
+	{errs.ErrResultsHidden, codes.PermissionDenied},
+	{errs.ErrBallotsNotPublic, codes.PermissionDenied},
+	{errs.ErrTitleNotExist, codes.NotFound},
+	{errs.ErrVoteNotExist, codes.NotFound},
+	{errs.ErrChoiceTitleNotExist, codes.NotFound},
+	{errs.ErrTitleAlreadyExist, codes.AlreadyExists},
+	{errs.ErrAlreadyVoted, codes.AlreadyExists},
+	{errs.ErrVoteNotOpen, codes.FailedPrecondition},
+	{errs.ErrVoteClosed, codes.FailedPrecondition},
+	{errs.ErrInvalidTransition, codes.FailedPrecondition},
+	{errs.ErrPrivacyLocked, codes.FailedPrecondition},
+	{errs.ErrWriteInLimit, codes.ResourceExhausted},
+	{context.Canceled, codes.Canceled},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+}
+
+// toStatus converts the service error to a grpc status error, unknown
+// errors are reported as Internal without leaking the message
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	for _, known := range statusCodes {
+		if errors.Is(err, known.err) {
+			return status.Error(known.code, err.Error())
+		}
+	}
+	return status.Error(codes.Internal, "internal error")
+}
@@ -1,25 +1,391 @@
 package handler
 
+import "time"
+
 type FullVoteRequest struct {
-	VoteTitle string   `json:"vote"`
-	Choices   []string `json:"choices"`
+	VoteTitle     string       `json:"vote"`
+	Choices       []string     `json:"choices"`
+	MinSelections int          `json:"min_selections"`
+	MaxSelections int          `json:"max_selections"`
+	Method        string       `json:"method"`
+	Seats         int          `json:"seats"`
+	Transfer      string       `json:"surplus_transfer"`
+	MinScore      int          `json:"min_score"`
+	MaxScore      int          `json:"max_score"`
+	Weighted      bool         `json:"weighted"`
+	Visibility    string       `json:"visibility"`
+	AllowWriteIn  bool         `json:"allow_write_in"`
+	Rules         RulesRequest `json:"rules"`
+	TieBreak      string       `json:"tie_break"`
+	TieSeed       int64        `json:"tie_seed"`
+	BallotPrivacy string       `json:"ballot_privacy"`
+	Draft         bool         `json:"draft"`
+	OpensAt       *time.Time   `json:"opens_at"`
+	ClosesAt      *time.Time   `json:"closes_at"`
+}
+
+// RulesRequest sets the quorum and the passing threshold of a single choice
+// vote, the results of the vote carry its outcome then.
+type RulesRequest struct {
+	Quorum           int    `json:"quorum"`
+	QuorumPercent    int    `json:"quorum_percent"`
+	Threshold        string `json:"threshold"`
+	ThresholdPercent int    `json:"threshold_percent"`
+	Abstention       string `json:"abstention"`
+	CountAbstentions bool   `json:"count_abstentions"`
 }
 
 type VoteTitleRequest struct {
 	VoteTitle string `json:"vote"`
 }
 
+// EditVoteRequest renames the vote and changes its choices, rename_choices
+// maps the old titles to the new ones. Removing a choice with votes takes force.
+// The ballot privacy can only change before the vote opens.
+type EditVoteRequest struct {
+	VoteTitle     string            `json:"vote"`
+	AddChoices    []string          `json:"add_choices"`
+	RenameChoices map[string]string `json:"rename_choices"`
+	RemoveChoices []string          `json:"remove_choices"`
+	Force         bool              `json:"force"`
+	BallotPrivacy string            `json:"ballot_privacy"`
+}
+
+// MergeChoicesRequest merges the write-in choice from into the choice into.
+type MergeChoicesRequest struct {
+	From string `json:"from"`
+	Into string `json:"into"`
+}
+
+// DecideTieRequest names the tied choice the owner declares the winner.
+type DecideTieRequest struct {
+	ChoiceTitle string `json:"choice"`
+}
+
 type UpdateChoiceRequest struct {
-	VoteTitle   string `json:"vote"`
+	VoteTitle   string   `json:"vote"`
+	ChoiceTitle string   `json:"choice"`
+	Choices     []string `json:"choices"`
+}
+
+// BallotRequest selects a choice or several choices, a ballot of a score
+// vote gives the choices their scores instead.
+type BallotRequest struct {
+	ChoiceTitle string         `json:"choice"`
+	Choices     []string       `json:"choices"`
+	Scores      map[string]int `json:"scores"`
+}
+
+// ReopenRequest sets the new closes_at of the reopened vote, the vote stays
+// open until it is closed by hand without one.
+type ReopenRequest struct {
+	ClosesAt *time.Time `json:"closes_at"`
+}
+
+// WeightsRequest lists all eligible voters of a weighted vote, the voters
+// left out can't vote anymore.
+type WeightsRequest struct {
+	Weights []VoterWeightRequest `json:"weights"`
+}
+
+type VoterWeightRequest struct {
+	VoterId string `json:"voter_id"`
+	Weight  int    `json:"weight"`
+}
+
+type WeightsResponse struct {
+	VoteId  int                   `json:"vote_id"`
+	Weights []VoterWeightResponse `json:"weights"`
+}
+
+type VoterWeightResponse struct {
+	VoterId string `json:"voter_id"`
+	Weight  int    `json:"weight"`
+}
+
+type BatchRequest struct {
+	Ballots []BatchBallotRequest `json:"ballots"`
+}
+
+type BatchBallotRequest struct {
+	VoteId      int            `json:"vote_id"`
+	VoteTitle   string         `json:"vote"`
+	ChoiceTitle string         `json:"choice"`
+	Choices     []string       `json:"choices"`
+	Scores      map[string]int `json:"scores"`
+}
+
+type BatchResponse struct {
+	Applied int                   `json:"applied"`
+	Failed  int                   `json:"failed"`
+	Results []BatchResultResponse `json:"results"`
+}
+
+type BatchResultResponse struct {
+	Index   int              `json:"index"`
+	Status  int              `json:"status"`
+	VoteId  int              `json:"vote_id,omitempty"`
+	Choices []ChoiceResponse `json:"choices,omitempty"`
+	Receipt string           `json:"receipt,omitempty"`
+	Error   *ProblemResponse `json:"error,omitempty"`
+}
+
+// ReceiptResponse holds the receipt of a cast or changed ballot, the hash of
+// its entry in the ballot log.
+type ReceiptResponse struct {
+	Receipt string `json:"receipt"`
+}
+
+// ReceiptCheckResponse tells whether the receipt is in the ballot log of the
+// vote and whether its ballot is still counted, replaced_by is the seq of the
+// entry that has changed or retracted it.
+type ReceiptCheckResponse struct {
+	VoteId     int               `json:"vote_id"`
+	Receipt    string            `json:"receipt"`
+	Included   bool              `json:"included"`
+	Counted    bool              `json:"counted"`
+	ReplacedBy int64             `json:"replaced_by,omitempty"`
+	Entry      *LogEntryResponse `json:"entry,omitempty"`
+}
+
+// LogResponse is a page of the ballot log, next_after is the seq to request
+// the next page after and is left out on the last page.
+type LogResponse struct {
+	VoteId    int                `json:"vote_id"`
+	Entries   []LogEntryResponse `json:"entries"`
+	NextAfter int64              `json:"next_after,omitempty"`
+}
+
+type LogEntryResponse struct {
+	Seq       int64     `json:"seq"`
+	Kind      string    `json:"kind"`
+	Replaces  int64     `json:"replaces,omitempty"`
+	Weight    int       `json:"weight,omitempty"`
+	Choices   []string  `json:"choices"`
+	Scores    []int     `json:"scores,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	PrevHash  string    `json:"prev_hash"`
+	Hash      string    `json:"hash"`
+}
+
+// TimelineResponse is the history of the counts of the vote up to as_of,
+// choices hold the counts at as_of.
+type TimelineResponse struct {
+	VoteId  int                      `json:"vote_id"`
+	Bucket  string                   `json:"bucket"`
+	AsOf    time.Time                `json:"as_of"`
+	Ballots int                      `json:"ballots"`
+	Choices []ChoiceResponse         `json:"choices"`
+	Buckets []TimelineBucketResponse `json:"buckets"`
+}
+
+// TimelineBucketResponse holds the votes the choices got in the bucket and
+// their totals at its end, leader is left out if the lead is tied.
+type TimelineBucketResponse struct {
+	Start   time.Time               `json:"start"`
+	Cast    int                     `json:"cast"`
+	Ballots int                     `json:"ballots"`
+	Leader  string                  `json:"leader,omitempty"`
+	Choices []TimelineCountResponse `json:"choices"`
+}
+
+type TimelineCountResponse struct {
 	ChoiceTitle string `json:"choice"`
+	Votes       int    `json:"votes"`
+	Total       int    `json:"total"`
+}
+
+type BallotResponse struct {
+	VoteId  int            `json:"vote_id"`
+	Voted   bool           `json:"voted"`
+	Choices []string       `json:"choices,omitempty"`
+	Scores  map[string]int `json:"scores,omitempty"`
+	VotedAt *time.Time     `json:"voted_at,omitempty"`
+}
+
+// VotersResponse lists the voters of every choice of a public vote.
+type VotersResponse struct {
+	VoteId  int                    `json:"vote_id"`
+	Choices []ChoiceVotersResponse `json:"choices"`
+}
+
+type ChoiceVotersResponse struct {
+	ChoiceTitle string   `json:"choice"`
+	Voters      []string `json:"voters"`
 }
 
 type VoteResponse struct {
-	VoteTitle string           `json:"vote"`
-	Choices   []ChoiceResponse `json:"choices"`
+	Id            int              `json:"id"`
+	VoteTitle     string           `json:"vote"`
+	Method        string           `json:"method"`
+	MinSelections int              `json:"min_selections"`
+	MaxSelections int              `json:"max_selections"`
+	Seats         int              `json:"seats"`
+	Transfer      string           `json:"surplus_transfer,omitempty"`
+	Scale         *ScaleResponse   `json:"scale,omitempty"`
+	Weighted      bool             `json:"weighted,omitempty"`
+	Status        string           `json:"status"`
+	OpensAt       *time.Time       `json:"opens_at,omitempty"`
+	ClosesAt      *time.Time       `json:"closes_at,omitempty"`
+	ClosedAt      *time.Time       `json:"closed_at,omitempty"`
+	Visibility    string           `json:"visibility"`
+	AllowWriteIn  bool             `json:"allow_write_in,omitempty"`
+	Rules         *RulesResponse   `json:"rules,omitempty"`
+	TieBreak      string           `json:"tie_break,omitempty"`
+	TieSeed       int64            `json:"tie_seed,omitempty"`
+	BallotPrivacy string           `json:"ballot_privacy,omitempty"`
+	ResultsHidden bool             `json:"results_hidden,omitempty"`
+	Ballots       int              `json:"ballots"`
+	Choices       []ChoiceResponse `json:"choices"`
 }
 
-type ChoiceResponse struct {
+type RulesResponse struct {
+	Quorum           int    `json:"quorum,omitempty"`
+	QuorumPercent    int    `json:"quorum_percent,omitempty"`
+	Threshold        string `json:"threshold"`
+	ThresholdPercent int    `json:"threshold_percent,omitempty"`
+	Abstention       string `json:"abstention,omitempty"`
+	CountAbstentions bool   `json:"count_abstentions,omitempty"`
+}
+
+// ScaleResponse is the range of the scores of a score vote.
+type ScaleResponse struct {
+	MinScore int `json:"min_score"`
+	MaxScore int `json:"max_score"`
+}
+
+// ResultsResponse holds the number of ballots separately from the choice
+// counts, a ballot of a multi-select vote adds to several choices. The tally
+// is filled for ranked votes only and the outcome for the votes with rules.
+// The choice counts of a weighted vote are the weighted totals, the choices
+// carry the numbers of voters too. total_votes is the sum of the choice
+// counts the percents are of. The winner of a plurality or score vote is
+// declared unless the choices tied for the lead are left undecided,
+// tie_break names the strategy that has broken the tie.
+type ResultsResponse struct {
+	VoteId   int              `json:"vote_id"`
+	Method   string           `json:"method"`
+	Weighted bool             `json:"weighted,omitempty"`
+	Ballots  int              `json:"ballots"`
+	Total    int              `json:"total_votes"`
+	Winner   string           `json:"winner,omitempty"`
+	Tied     []string         `json:"tied,omitempty"`
+	TieBreak string           `json:"tie_break,omitempty"`
+	TieSeed  int64            `json:"tie_seed,omitempty"`
+	Choices  []ChoiceResponse `json:"choices"`
+	Tally    *TallyResponse   `json:"tally,omitempty"`
+	Outcome  *OutcomeResponse `json:"outcome,omitempty"`
+}
+
+// OutcomeResponse is the result of the rules of the vote, choice is the
+// leading choice unless the vote is tied or has no quorum. share and turnout
+// are percents, turnout is set for a quorum percent only.
+type OutcomeResponse struct {
+	Result      string  `json:"result"`
+	Choice      string  `json:"choice,omitempty"`
+	Votes       int     `json:"votes"`
+	Counted     int     `json:"counted"`
+	Share       float64 `json:"share"`
+	Abstentions int     `json:"abstentions"`
+	Turnout     float64 `json:"turnout,omitempty"`
+}
+
+type TallyResponse struct {
+	Winners   []string        `json:"winners"`
+	Condorcet string          `json:"condorcet_winner,omitempty"`
+	Scores    []ScoreResponse `json:"scores,omitempty"`
+	Rounds    []RoundResponse `json:"rounds,omitempty"`
+	Seats     int             `json:"seats,omitempty"`
+	Quota     int             `json:"quota,omitempty"`
+	Stages    []StageResponse `json:"stages,omitempty"`
+}
+
+type ScoreResponse struct {
 	ChoiceTitle string `json:"choice"`
-	Count       int    `json:"vote_count"`
+	Score       int    `json:"score"`
+}
+
+// StageResponse is one stage of the count sheet of a single transferable vote.
+type StageResponse struct {
+	Stage     int               `json:"stage"`
+	Votes     []ShareResponse   `json:"votes"`
+	Exhausted float64           `json:"exhausted"`
+	Elected   []string          `json:"elected"`
+	Excluded  []string          `json:"excluded"`
+	Transfer  *TransferResponse `json:"transfer,omitempty"`
+}
+
+type ShareResponse struct {
+	ChoiceTitle string  `json:"choice"`
+	Votes       float64 `json:"votes"`
+}
+
+// TransferResponse is a surplus or exclusion transfer, value is set for
+// surplus transfers only.
+type TransferResponse struct {
+	Kind  string  `json:"kind"`
+	From  string  `json:"from"`
+	Value float64 `json:"value,omitempty"`
+	Votes float64 `json:"votes"`
+}
+
+type RoundResponse struct {
+	Round      int              `json:"round"`
+	Votes      []ChoiceResponse `json:"votes"`
+	Exhausted  int              `json:"exhausted"`
+	Eliminated []string         `json:"eliminated"`
+}
+
+// ChoiceResponse carries the score statistics for the choices of a score vote
+// and the number of voters for the choices of a weighted vote. write_in marks
+// the choices added by the ballots. rank and percent are set in the results.
+type ChoiceResponse struct {
+	ChoiceTitle string                `json:"choice"`
+	Count       int                   `json:"vote_count"`
+	Rank        int                   `json:"rank,omitempty"`
+	Percent     *float64              `json:"percent,omitempty"`
+	WriteIn     bool                  `json:"write_in,omitempty"`
+	Voters      *int                  `json:"voters,omitempty"`
+	Scores      *ScoreSummaryResponse `json:"scores,omitempty"`
+}
+
+type ScoreSummaryResponse struct {
+	Mean      float64              `json:"mean"`
+	Median    float64              `json:"median"`
+	StdDev    float64              `json:"stddev"`
+	Histogram []ScoreCountResponse `json:"histogram"`
+}
+
+type ScoreCountResponse struct {
+	Score int `json:"score"`
+	Count int `json:"count"`
+}
+
+type VoteListResponse struct {
+	Votes      []VoteSummaryResponse `json:"votes"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// VoteSummaryResponse carries 0 votes for a vote whose results aren't
+// public, results_hidden is set then.
+type VoteSummaryResponse struct {
+	Id            int       `json:"id"`
+	VoteTitle     string    `json:"vote"`
+	CreatedAt     time.Time `json:"created_at"`
+	Total         int       `json:"total_votes"`
+	ResultsHidden bool      `json:"results_hidden,omitempty"`
+}
+
+type ResultEventResponse struct {
+	VoteId  int              `json:"vote_id"`
+	Choices []ChoiceResponse `json:"choices"`
+}
+
+type HeartbeatResponse struct {
+	Time time.Time `json:"time"`
+}
+
+type StreamEventResponse struct {
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"`
 }
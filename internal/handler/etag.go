@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// notModified sets the ETag of the results with the version and answers 304
+// if the client already has it. Clients have to revalidate every time,
+// because the version changes with each vote.
+func notModified(w http.ResponseWriter, r *http.Request, version int64) bool {
+	etag := strconv.Quote(strconv.FormatInt(version, 10))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	for _, tag := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
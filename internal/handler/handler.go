@@ -16,6 +16,31 @@ func NewVoteHandler(logger *logging.Logger, voteService VoteService, choiceServi
 }
 
 func (h *handler) InitRoutes(router *mux.Router) {
+	router.HandleFunc("/api/votes", h.Create).Methods("POST")
+	router.HandleFunc("/api/votes", h.ListVotes).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}", h.GetVote).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}", h.UpdateVote).Methods("PATCH")
+	router.HandleFunc("/api/votes/{id:[0-9]+}", h.DeleteVote).Methods("DELETE")
+	router.HandleFunc("/api/votes/{id:[0-9]+}:open", h.OpenVote).Methods("POST")
+	router.HandleFunc("/api/votes/{id:[0-9]+}:close", h.CloseVote).Methods("POST")
+	router.HandleFunc("/api/votes/{id:[0-9]+}:reopen", h.ReopenVote).Methods("POST")
+	router.HandleFunc("/api/votes/{id:[0-9]+}:archive", h.ArchiveVote).Methods("POST")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/choices:merge", h.MergeChoices).Methods("POST")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/results", h.GetResults).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/tie:decide", h.DecideTie).Methods("POST")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/weights", h.GetWeights).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/weights", h.SetWeights).Methods("PUT")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/ballots", h.CastBallot).Methods("POST")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/ballot", h.GetBallot).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/ballot", h.ChangeBallot).Methods("PUT")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/ballot", h.RetractBallot).Methods("DELETE")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/voters", h.GetVoters).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/log", h.GetLog).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/receipts/{receipt}", h.GetReceipt).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/timeline", h.GetTimeline).Methods("GET")
+	router.HandleFunc("/api/ballots:batch", h.CastBallots).Methods("POST")
+
+	// title based routes are kept for the clients of the first api version
 	router.HandleFunc("/api/vote", h.Create).Methods("POST")
 	router.HandleFunc("/api/result", h.GetChoices).Methods("POST")
 	router.HandleFunc("/api/choice", h.UpdateChoice).Methods("POST")
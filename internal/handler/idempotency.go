@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+)
+
+const (
+	idempotencyKeyHeader string = "Idempotency-Key"
+	replayedHeader       string = "Idempotent-Replayed"
+)
+
+type idempotencyHandler struct {
+	logger             *logging.Logger
+	idempotencyService IdempotencyService
+}
+
+func NewIdempotencyHandler(logger *logging.Logger, idempotencyService IdempotencyService) *idempotencyHandler {
+	return &idempotencyHandler{logger: logger, idempotencyService: idempotencyService}
+}
+
+// Middleware executes a mutating request with an Idempotency-Key header only
+// once. Retries with the same key and payload get the stored response, a key
+// reused with a different payload is rejected.
+func (i *idempotencyHandler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header, ok := r.Header[idempotencyKeyHeader]
+		if !ok || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		key := storageKey(r, header[0])
+		stored, replay, err := i.idempotencyService.Begin(key, fingerprint(r, body))
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		if replay {
+			i.logger.Debugf("replay response for idempotency key %v", key)
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set(replayedHeader, "true")
+			w.WriteHeader(stored.Status)
+			w.Write(stored.Body)
+			return
+		}
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		// server errors aren't stored, the client has to be able to retry them
+		if recorder.status >= http.StatusInternalServerError {
+			if err := i.idempotencyService.Release(key); err != nil {
+				i.logger.Error(err)
+			}
+			return
+		}
+		err = i.idempotencyService.Complete(key, entity.IdempotentResponse{
+			Status:      recorder.status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			i.logger.Error(err)
+		}
+	})
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// storageKey scopes the key by the voter, so two voters sending the same key
+// don't share the stored response. The voter is escaped and can't contain the
+// separator.
+func storageKey(r *http.Request, key string) string {
+	return url.QueryEscape(voterId(r)) + ":" + key
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	// the voter is a part of the request, one key can't replay a ballot of another voter
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n" + voterId(r) + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
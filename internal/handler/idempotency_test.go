@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/VrMolodyakov/vote-service/internal/handler/mocks"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	idempotencyServ := mocks.NewMockIdempotencyService(ctrl)
+	router.Use(NewIdempotencyHandler(logging.GetLogger("debug"), idempotencyServ).Middleware)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		key            string
+		inputRequest   string
+		mock           mockCall
+		want           string
+		replayed       string
+		expectedStatus int
+	}{
+		{
+			title:        "first request is executed and stored",
+			key:          "key-1",
+			inputRequest: `{"vote":"Best pokemon","choice":"Pikachu"}`,
+			mock: func() {
+				idempotencyServ.EXPECT().Begin("ash:key-1", gomock.Any()).Return(entity.IdempotentResponse{}, false, nil)
+				choiceServ.EXPECT().Update(gomock.Any(), "Best pokemon", []string{"Pikachu"}, "ash").Return("receipt", nil)
+				idempotencyServ.EXPECT().Complete("ash:key-1", entity.IdempotentResponse{Status: 201, ContentType: "application/json", Body: []byte("{\n   \"receipt\": \"receipt\"\n}")}).Return(nil)
+			},
+			want:           "{\n   \"receipt\": \"receipt\"\n}",
+			expectedStatus: 201,
+		},
+		{
+			title:        "retry gets the stored response",
+			key:          "key-2",
+			inputRequest: `{"vote":"Best pokemon","choice":"Pikachu"}`,
+			mock: func() {
+				stored := entity.IdempotentResponse{Status: 404, ContentType: problemContentType, Body: []byte(`{"code":"vote_title_not_found"}`), Done: true}
+				idempotencyServ.EXPECT().Begin("ash:key-2", gomock.Any()).Return(stored, true, nil)
+			},
+			want:           `{"code":"vote_title_not_found"}`,
+			replayed:       "true",
+			expectedStatus: 404,
+		},
+		{
+			title:        "server error releases the key",
+			key:          "key-3",
+			inputRequest: `{"vote":"Best pokemon","choice":"Pikachu"}`,
+			mock: func() {
+				idempotencyServ.EXPECT().Begin("ash:key-3", gomock.Any()).Return(entity.IdempotentResponse{}, false, nil)
+				choiceServ.EXPECT().Update(gomock.Any(), "Best pokemon", []string{"Pikachu"}, "ash").Return("", errors.New("internal db error"))
+				idempotencyServ.EXPECT().Release("ash:key-3").Return(nil)
+			},
+			expectedStatus: 500,
+		},
+		{
+			title:        "key reused with another payload and 422 response",
+			key:          "key-4",
+			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
+			mock: func() {
+				idempotencyServ.EXPECT().Begin("ash:key-4", gomock.Any()).Return(entity.IdempotentResponse{}, false, errs.ErrIdempotencyKeyReused)
+			},
+			expectedStatus: 422,
+		},
+		{
+			title:        "request is in progress and 409 response",
+			key:          "key-5",
+			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
+			mock: func() {
+				idempotencyServ.EXPECT().Begin("ash:key-5", gomock.Any()).Return(entity.IdempotentResponse{}, false, errs.ErrIdempotencyInProgress)
+			},
+			expectedStatus: 409,
+		},
+		{
+			title:        "request without key isn't tracked",
+			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
+			mock: func() {
+				choiceServ.EXPECT().Update(gomock.Any(), "Best pokemon", []string{"Mew"}, "ash").Return("receipt", nil)
+			},
+			expectedStatus: 201,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				"POST",
+				"/api/choice",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, "ash")
+			if test.key != "" {
+				req.Header.Set(idempotencyKeyHeader, test.key)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.replayed, recorder.Header().Get(replayedHeader))
+			if test.want != "" {
+				assert.Equal(t, test.want, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	first := httptest.NewRequest("POST", "/api/votes/1/ballots", nil)
+	second := httptest.NewRequest("POST", "/api/votes/2/ballots", nil)
+	assert.Equal(t, fingerprint(first, []byte(`{"choice":"Mew"}`)), fingerprint(first, []byte(`{"choice":"Mew"}`)))
+	assert.NotEqual(t, fingerprint(first, []byte(`{"choice":"Mew"}`)), fingerprint(first, []byte(`{"choice":"Pikachu"}`)))
+	assert.NotEqual(t, fingerprint(first, []byte(`{"choice":"Mew"}`)), fingerprint(second, []byte(`{"choice":"Mew"}`)))
+}
+
+func TestStorageKey(t *testing.T) {
+	ash := httptest.NewRequest("POST", "/api/votes/1/ballots", nil)
+	ash.Header.Set(voterIdHeader, "ash")
+	misty := httptest.NewRequest("POST", "/api/votes/1/ballots", nil)
+	misty.Header.Set(voterIdHeader, "misty")
+	colon := httptest.NewRequest("POST", "/api/votes/1/ballots", nil)
+	colon.Header.Set(voterIdHeader, "ash:key")
+	assert.Equal(t, "ash:key-1", storageKey(ash, "key-1"))
+	assert.NotEqual(t, storageKey(ash, "key-1"), storageKey(misty, "key-1"))
+	assert.NotEqual(t, storageKey(ash, "key:1"), storageKey(colon, "1"))
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,34 @@ func (m *MockVoteService) EXPECT() *MockVoteServiceMockRecorder {
 	return m.recorder
 }
 
+// Archive mocks base method.
+func (m *MockVoteService) Archive(ctx context.Context, id int, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, id, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockVoteServiceMockRecorder) Archive(ctx, id, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockVoteService)(nil).Archive), ctx, id, voterId)
+}
+
+// Close mocks base method.
+func (m *MockVoteService) Close(ctx context.Context, id int, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close", ctx, id, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockVoteServiceMockRecorder) Close(ctx, id, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockVoteService)(nil).Close), ctx, id, voterId)
+}
+
 // Create mocks base method.
 func (m *MockVoteService) Create(ctx context.Context, vote string) (int, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +79,21 @@ func (mr *MockVoteServiceMockRecorder) Create(ctx, vote interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockVoteService)(nil).Create), ctx, vote)
 }
 
+// CreatePoll mocks base method.
+func (m *MockVoteService) CreatePoll(ctx context.Context, poll entity.Poll) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePoll", ctx, poll)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePoll indicates an expected call of CreatePoll.
+func (mr *MockVoteServiceMockRecorder) CreatePoll(ctx, poll interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePoll", reflect.TypeOf((*MockVoteService)(nil).CreatePoll), ctx, poll)
+}
+
 // Delete mocks base method.
 func (m *MockVoteService) Delete(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -79,6 +123,93 @@ func (mr *MockVoteServiceMockRecorder) Get(ctx, title interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockVoteService)(nil).Get), ctx, title)
 }
 
+// GetById mocks base method.
+func (m *MockVoteService) GetById(ctx context.Context, id int) (entity.Vote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(entity.Vote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockVoteServiceMockRecorder) GetById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockVoteService)(nil).GetById), ctx, id)
+}
+
+// GetWeights mocks base method.
+func (m *MockVoteService) GetWeights(ctx context.Context, id int, voterId string) ([]entity.VoterWeight, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWeights", ctx, id, voterId)
+	ret0, _ := ret[0].([]entity.VoterWeight)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWeights indicates an expected call of GetWeights.
+func (mr *MockVoteServiceMockRecorder) GetWeights(ctx, id, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWeights", reflect.TypeOf((*MockVoteService)(nil).GetWeights), ctx, id, voterId)
+}
+
+// List mocks base method.
+func (m *MockVoteService) List(ctx context.Context, query entity.VoteQuery) (entity.VotePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].(entity.VotePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockVoteServiceMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockVoteService)(nil).List), ctx, query)
+}
+
+// Open mocks base method.
+func (m *MockVoteService) Open(ctx context.Context, id int, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, id, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockVoteServiceMockRecorder) Open(ctx, id, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockVoteService)(nil).Open), ctx, id, voterId)
+}
+
+// Reopen mocks base method.
+func (m *MockVoteService) Reopen(ctx context.Context, id int, closesAt *time.Time, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reopen", ctx, id, closesAt, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reopen indicates an expected call of Reopen.
+func (mr *MockVoteServiceMockRecorder) Reopen(ctx, id, closesAt, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reopen", reflect.TypeOf((*MockVoteService)(nil).Reopen), ctx, id, closesAt, voterId)
+}
+
+// SetWeights mocks base method.
+func (m *MockVoteService) SetWeights(ctx context.Context, id int, weights []entity.VoterWeight, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWeights", ctx, id, weights, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWeights indicates an expected call of SetWeights.
+func (mr *MockVoteServiceMockRecorder) SetWeights(ctx, id, weights, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWeights", reflect.TypeOf((*MockVoteService)(nil).SetWeights), ctx, id, weights, voterId)
+}
+
 // MockChoiceService is a mock of ChoiceService interface.
 type MockChoiceService struct {
 	ctrl     *gomock.Controller
@@ -102,6 +233,21 @@ func (m *MockChoiceService) EXPECT() *MockChoiceServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangeBallot mocks base method.
+func (m *MockChoiceService) ChangeBallot(ctx context.Context, voteId int, choices []string, scores []int, voterId string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeBallot", ctx, voteId, choices, scores, voterId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeBallot indicates an expected call of ChangeBallot.
+func (mr *MockChoiceServiceMockRecorder) ChangeBallot(ctx, voteId, choices, scores, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeBallot", reflect.TypeOf((*MockChoiceService)(nil).ChangeBallot), ctx, voteId, choices, scores, voterId)
+}
+
 // Create mocks base method.
 func (m *MockChoiceService) Create(ctx context.Context, choice entity.Choice) (string, error) {
 	m.ctrl.T.Helper()
@@ -117,31 +263,372 @@ func (mr *MockChoiceServiceMockRecorder) Create(ctx, choice interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockChoiceService)(nil).Create), ctx, choice)
 }
 
+// DecideTie mocks base method.
+func (m *MockChoiceService) DecideTie(ctx context.Context, voteId int, choice, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideTie", ctx, voteId, choice, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideTie indicates an expected call of DecideTie.
+func (mr *MockChoiceServiceMockRecorder) DecideTie(ctx, voteId, choice, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideTie", reflect.TypeOf((*MockChoiceService)(nil).DecideTie), ctx, voteId, choice, voterId)
+}
+
+// Edit mocks base method.
+func (m *MockChoiceService) Edit(ctx context.Context, voteId int, edit entity.PollEdit, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Edit", ctx, voteId, edit, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Edit indicates an expected call of Edit.
+func (mr *MockChoiceServiceMockRecorder) Edit(ctx, voteId, edit, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Edit", reflect.TypeOf((*MockChoiceService)(nil).Edit), ctx, voteId, edit, voterId)
+}
+
 // Get mocks base method.
-func (m *MockChoiceService) Get(ctx context.Context, voteTitle string) ([]entity.Choice, error) {
+func (m *MockChoiceService) Get(ctx context.Context, query entity.ResultQuery, viewerId string) (entity.Result, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, voteTitle)
-	ret0, _ := ret[0].([]entity.Choice)
+	ret := m.ctrl.Call(m, "Get", ctx, query, viewerId)
+	ret0, _ := ret[0].(entity.Result)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockChoiceServiceMockRecorder) Get(ctx, voteTitle interface{}) *gomock.Call {
+func (mr *MockChoiceServiceMockRecorder) Get(ctx, query, viewerId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockChoiceService)(nil).Get), ctx, voteTitle)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockChoiceService)(nil).Get), ctx, query, viewerId)
 }
 
-// Update mocks base method.
-func (m *MockChoiceService) Update(ctx context.Context, voteTitle, choiceTitle string, count int) error {
+// GetBallot mocks base method.
+func (m *MockChoiceService) GetBallot(ctx context.Context, voteId int, voterId string) (entity.Ballot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBallot", ctx, voteId, voterId)
+	ret0, _ := ret[0].(entity.Ballot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBallot indicates an expected call of GetBallot.
+func (mr *MockChoiceServiceMockRecorder) GetBallot(ctx, voteId, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBallot", reflect.TypeOf((*MockChoiceService)(nil).GetBallot), ctx, voteId, voterId)
+}
+
+// GetById mocks base method.
+func (m *MockChoiceService) GetById(ctx context.Context, voteId int, viewerId string) ([]entity.Choice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, voteId, viewerId)
+	ret0, _ := ret[0].([]entity.Choice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockChoiceServiceMockRecorder) GetById(ctx, voteId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockChoiceService)(nil).GetById), ctx, voteId, viewerId)
+}
+
+// GetRedacted mocks base method.
+func (m *MockChoiceService) GetRedacted(ctx context.Context, voteId int) ([]entity.Choice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRedacted", ctx, voteId)
+	ret0, _ := ret[0].([]entity.Choice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRedacted indicates an expected call of GetRedacted.
+func (mr *MockChoiceServiceMockRecorder) GetRedacted(ctx, voteId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRedacted", reflect.TypeOf((*MockChoiceService)(nil).GetRedacted), ctx, voteId)
+}
+
+// GetVersionById mocks base method.
+func (m *MockChoiceService) GetVersionById(ctx context.Context, voteId int, viewerId string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, voteTitle, choiceTitle, count)
+	ret := m.ctrl.Call(m, "GetVersionById", ctx, voteId, viewerId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersionById indicates an expected call of GetVersionById.
+func (mr *MockChoiceServiceMockRecorder) GetVersionById(ctx, voteId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersionById", reflect.TypeOf((*MockChoiceService)(nil).GetVersionById), ctx, voteId, viewerId)
+}
+
+// Log mocks base method.
+func (m *MockChoiceService) Log(ctx context.Context, voteId int, viewerId string, after int64, limit int) (entity.LogPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Log", ctx, voteId, viewerId, after, limit)
+	ret0, _ := ret[0].(entity.LogPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Log indicates an expected call of Log.
+func (mr *MockChoiceServiceMockRecorder) Log(ctx, voteId, viewerId, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Log", reflect.TypeOf((*MockChoiceService)(nil).Log), ctx, voteId, viewerId, after, limit)
+}
+
+// MergeChoices mocks base method.
+func (m *MockChoiceService) MergeChoices(ctx context.Context, voteId int, from, into, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeChoices", ctx, voteId, from, into, voterId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeChoices indicates an expected call of MergeChoices.
+func (mr *MockChoiceServiceMockRecorder) MergeChoices(ctx, voteId, from, into, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeChoices", reflect.TypeOf((*MockChoiceService)(nil).MergeChoices), ctx, voteId, from, into, voterId)
+}
+
+// Outcome mocks base method.
+func (m *MockChoiceService) Outcome(ctx context.Context, voteId int, viewerId string) (entity.Outcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outcome", ctx, voteId, viewerId)
+	ret0, _ := ret[0].(entity.Outcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Outcome indicates an expected call of Outcome.
+func (mr *MockChoiceServiceMockRecorder) Outcome(ctx, voteId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outcome", reflect.TypeOf((*MockChoiceService)(nil).Outcome), ctx, voteId, viewerId)
+}
+
+// Receipt mocks base method.
+func (m *MockChoiceService) Receipt(ctx context.Context, voteId int, receipt string) (entity.Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Receipt", ctx, voteId, receipt)
+	ret0, _ := ret[0].(entity.Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Receipt indicates an expected call of Receipt.
+func (mr *MockChoiceServiceMockRecorder) Receipt(ctx, voteId, receipt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Receipt", reflect.TypeOf((*MockChoiceService)(nil).Receipt), ctx, voteId, receipt)
+}
+
+// RetractBallot mocks base method.
+func (m *MockChoiceService) RetractBallot(ctx context.Context, voteId int, voterId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetractBallot", ctx, voteId, voterId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// RetractBallot indicates an expected call of RetractBallot.
+func (mr *MockChoiceServiceMockRecorder) RetractBallot(ctx, voteId, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetractBallot", reflect.TypeOf((*MockChoiceService)(nil).RetractBallot), ctx, voteId, voterId)
+}
+
+// Tally mocks base method.
+func (m *MockChoiceService) Tally(ctx context.Context, voteId int, viewerId string) (entity.Tally, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tally", ctx, voteId, viewerId)
+	ret0, _ := ret[0].(entity.Tally)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tally indicates an expected call of Tally.
+func (mr *MockChoiceServiceMockRecorder) Tally(ctx, voteId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tally", reflect.TypeOf((*MockChoiceService)(nil).Tally), ctx, voteId, viewerId)
+}
+
+// Timeline mocks base method.
+func (m *MockChoiceService) Timeline(ctx context.Context, query entity.TimelineQuery, viewerId string) (entity.Timeline, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Timeline", ctx, query, viewerId)
+	ret0, _ := ret[0].(entity.Timeline)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Timeline indicates an expected call of Timeline.
+func (mr *MockChoiceServiceMockRecorder) Timeline(ctx, query, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Timeline", reflect.TypeOf((*MockChoiceService)(nil).Timeline), ctx, query, viewerId)
+}
+
+// Update mocks base method.
+func (m *MockChoiceService) Update(ctx context.Context, voteTitle string, choices []string, voterId string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, voteTitle, choices, voterId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Update indicates an expected call of Update.
-func (mr *MockChoiceServiceMockRecorder) Update(ctx, voteTitle, choiceTitle, count interface{}) *gomock.Call {
+func (mr *MockChoiceServiceMockRecorder) Update(ctx, voteTitle, choices, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockChoiceService)(nil).Update), ctx, voteTitle, choices, voterId)
+}
+
+// UpdateBatch mocks base method.
+func (m *MockChoiceService) UpdateBatch(ctx context.Context, ballots []entity.Ballot) ([]entity.BallotResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBatch", ctx, ballots)
+	ret0, _ := ret[0].([]entity.BallotResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBatch indicates an expected call of UpdateBatch.
+func (mr *MockChoiceServiceMockRecorder) UpdateBatch(ctx, ballots interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBatch", reflect.TypeOf((*MockChoiceService)(nil).UpdateBatch), ctx, ballots)
+}
+
+// UpdateById mocks base method.
+func (m *MockChoiceService) UpdateById(ctx context.Context, voteId int, choices []string, scores []int, voterId string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateById", ctx, voteId, choices, scores, voterId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateById indicates an expected call of UpdateById.
+func (mr *MockChoiceServiceMockRecorder) UpdateById(ctx, voteId, choices, scores, voterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateById", reflect.TypeOf((*MockChoiceService)(nil).UpdateById), ctx, voteId, choices, scores, voterId)
+}
+
+// Voters mocks base method.
+func (m *MockChoiceService) Voters(ctx context.Context, voteId int, viewerId string) ([]entity.ChoiceVoters, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Voters", ctx, voteId, viewerId)
+	ret0, _ := ret[0].([]entity.ChoiceVoters)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Voters indicates an expected call of Voters.
+func (mr *MockChoiceServiceMockRecorder) Voters(ctx, voteId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Voters", reflect.TypeOf((*MockChoiceService)(nil).Voters), ctx, voteId, viewerId)
+}
+
+// MockResultService is a mock of ResultService interface.
+type MockResultService struct {
+	ctrl     *gomock.Controller
+	recorder *MockResultServiceMockRecorder
+}
+
+// MockResultServiceMockRecorder is the mock recorder for MockResultService.
+type MockResultServiceMockRecorder struct {
+	mock *MockResultService
+}
+
+// NewMockResultService creates a new mock instance.
+func NewMockResultService(ctrl *gomock.Controller) *MockResultService {
+	mock := &MockResultService{ctrl: ctrl}
+	mock.recorder = &MockResultServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResultService) EXPECT() *MockResultServiceMockRecorder {
+	return m.recorder
+}
+
+// Stream mocks base method.
+func (m *MockResultService) Stream(ctx context.Context, voteId int, viewerId string, send func(entity.ResultEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, voteId, viewerId, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockResultServiceMockRecorder) Stream(ctx, voteId, viewerId, send interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockResultService)(nil).Stream), ctx, voteId, viewerId, send)
+}
+
+// MockIdempotencyService is a mock of IdempotencyService interface.
+type MockIdempotencyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyServiceMockRecorder
+}
+
+// MockIdempotencyServiceMockRecorder is the mock recorder for MockIdempotencyService.
+type MockIdempotencyServiceMockRecorder struct {
+	mock *MockIdempotencyService
+}
+
+// NewMockIdempotencyService creates a new mock instance.
+func NewMockIdempotencyService(ctrl *gomock.Controller) *MockIdempotencyService {
+	mock := &MockIdempotencyService{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyService) EXPECT() *MockIdempotencyServiceMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockIdempotencyService) Begin(key, fingerprint string) (entity.IdempotentResponse, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", key, fingerprint)
+	ret0, _ := ret[0].(entity.IdempotentResponse)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockIdempotencyServiceMockRecorder) Begin(key, fingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockIdempotencyService)(nil).Begin), key, fingerprint)
+}
+
+// Complete mocks base method.
+func (m *MockIdempotencyService) Complete(key string, response entity.IdempotentResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", key, response)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIdempotencyServiceMockRecorder) Complete(key, response interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIdempotencyService)(nil).Complete), key, response)
+}
+
+// Release mocks base method.
+func (m *MockIdempotencyService) Release(key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockIdempotencyServiceMockRecorder) Release(key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockChoiceService)(nil).Update), ctx, voteTitle, choiceTitle, count)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockIdempotencyService)(nil).Release), key)
 }
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+)
+
+const problemContentType string = "application/problem+json"
+
+// ProblemResponse is an RFC 7807 problem details body. Code is a stable
+// machine readable identifier of the error, clients should branch on it
+// instead of the detail message.
+type ProblemResponse struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+}
+
+type problem struct {
+	err    error
+	status int
+	code   string
+}
+
+var problems = []problem{
+	{errs.ErrMalformedBody, http.StatusBadRequest, "malformed_body"},
+	{errs.ErrInvalidVoteId, http.StatusBadRequest, "invalid_vote_id"},
+	{errs.ErrInvalidSort, http.StatusBadRequest, "invalid_sort"},
+	{errs.ErrInvalidResultSort, http.StatusBadRequest, "invalid_sort"},
+	{errs.ErrInvalidOrder, http.StatusBadRequest, "invalid_order"},
+	{errs.ErrInvalidTop, http.StatusBadRequest, "invalid_top"},
+	{errs.ErrInvalidLimit, http.StatusBadRequest, "invalid_limit"},
+	{errs.ErrInvalidCursor, http.StatusBadRequest, "invalid_cursor"},
+	{errs.ErrInvalidAfter, http.StatusBadRequest, "invalid_after"},
+	{errs.ErrInvalidReceipt, http.StatusBadRequest, "invalid_receipt"},
+	{errs.ErrInvalidBucket, http.StatusBadRequest, "invalid_bucket"},
+	{errs.ErrInvalidAsOf, http.StatusBadRequest, "invalid_as_of"},
+	{errs.ErrInvalidBatch, http.StatusBadRequest, "invalid_batch"},
+	{errs.ErrInvalidVoterId, http.StatusBadRequest, "invalid_voter_id"},
+	{errs.ErrVoterRequired, http.StatusUnauthorized, "voter_required"},
+	{errs.ErrNotEligible, http.StatusForbidden, "not_eligible"},
+	{errs.ErrResultsAfterVote, http.StatusForbidden, "results_after_vote"},
+	{errs.ErrResultsHidden, http.StatusForbidden, "results_hidden"},
+	{errs.ErrNotOwner, http.StatusForbidden, "not_owner"},
+	{errs.ErrBallotsNotPublic, http.StatusForbidden, "ballots_not_public"},
+	{errs.ErrInvalidIdempotencyKey, http.StatusBadRequest, "invalid_idempotency_key"},
+	{errs.ErrTitleNotExist, http.StatusNotFound, "vote_title_not_found"},
+	{errs.ErrVoteNotExist, http.StatusNotFound, "vote_not_found"},
+	{errs.ErrChoiceTitleNotExist, http.StatusNotFound, "choice_not_found"},
+	{errs.ErrBallotNotExist, http.StatusNotFound, "ballot_not_found"},
+	{errs.ErrReceiptNotExist, http.StatusNotFound, "receipt_not_found"},
+	{errs.ErrTitleAlreadyExist, http.StatusConflict, "vote_title_already_exists"},
+	{errs.ErrIdempotencyInProgress, http.StatusConflict, "idempotency_request_in_progress"},
+	{errs.ErrAlreadyVoted, http.StatusConflict, "already_voted"},
+	{errs.ErrVoteNotWeighted, http.StatusConflict, "vote_not_weighted"},
+	{errs.ErrVoteNotOpen, http.StatusConflict, "vote_not_open"},
+	{errs.ErrVoteClosed, http.StatusConflict, "vote_closed"},
+	{errs.ErrInvalidTransition, http.StatusConflict, "invalid_status_transition"},
+	{errs.ErrChoiceHasVotes, http.StatusConflict, "choice_has_votes"},
+	{errs.ErrWriteInLimit, http.StatusConflict, "write_in_limit"},
+	{errs.ErrTieNotOwners, http.StatusConflict, "tie_not_owners"},
+	{errs.ErrVoteNotClosed, http.StatusConflict, "vote_not_closed"},
+	{errs.ErrNotTied, http.StatusConflict, "not_tied"},
+	{errs.ErrPrivacyLocked, http.StatusConflict, "privacy_locked"},
+	{errs.ErrEmptyVoteTitle, http.StatusUnprocessableEntity, "empty_vote_title"},
+	{errs.ErrEmptyChoiceTitle, http.StatusUnprocessableEntity, "empty_choice_title"},
+	{errs.ErrDuplicateChoice, http.StatusUnprocessableEntity, "duplicate_choice"},
+	{errs.ErrInvalidSelections, http.StatusUnprocessableEntity, "invalid_selections"},
+	{errs.ErrSelectionCount, http.StatusUnprocessableEntity, "invalid_selection_count"},
+	{errs.ErrInvalidMethod, http.StatusUnprocessableEntity, "invalid_method"},
+	{errs.ErrInvalidSeats, http.StatusUnprocessableEntity, "invalid_seats"},
+	{errs.ErrInvalidTransfer, http.StatusUnprocessableEntity, "invalid_surplus_transfer"},
+	{errs.ErrInvalidScale, http.StatusUnprocessableEntity, "invalid_scale"},
+	{errs.ErrInvalidScores, http.StatusUnprocessableEntity, "invalid_scores"},
+	{errs.ErrInvalidWeighted, http.StatusUnprocessableEntity, "invalid_weighted"},
+	{errs.ErrInvalidWeights, http.StatusUnprocessableEntity, "invalid_weights"},
+	{errs.ErrInvalidSchedule, http.StatusUnprocessableEntity, "invalid_schedule"},
+	{errs.ErrInvalidVisibility, http.StatusUnprocessableEntity, "invalid_visibility"},
+	{errs.ErrEmptyEdit, http.StatusUnprocessableEntity, "empty_edit"},
+	{errs.ErrInvalidWriteIn, http.StatusUnprocessableEntity, "invalid_write_in"},
+	{errs.ErrWriteInTooLong, http.StatusUnprocessableEntity, "write_in_too_long"},
+	{errs.ErrInvalidMerge, http.StatusUnprocessableEntity, "invalid_merge"},
+	{errs.ErrInvalidRules, http.StatusUnprocessableEntity, "invalid_rules"},
+	{errs.ErrInvalidQuorum, http.StatusUnprocessableEntity, "invalid_quorum"},
+	{errs.ErrInvalidThreshold, http.StatusUnprocessableEntity, "invalid_threshold"},
+	{errs.ErrInvalidTieBreak, http.StatusUnprocessableEntity, "invalid_tie_break"},
+	{errs.ErrInvalidPrivacy, http.StatusUnprocessableEntity, "invalid_privacy"},
+	{errs.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency_key_reused"},
+}
+
+var internalProblem = problem{status: http.StatusInternalServerError, code: "internal_error"}
+
+func errorResponse(w http.ResponseWriter, err error) {
+	problem := problemOf(err)
+	body, marshalErr := json.MarshalIndent(problem, prefix, indent)
+	if marshalErr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	w.Write(body)
+}
+
+// problemOf describes the error, unknown errors are reported as internal
+// without the detail.
+func problemOf(err error) ProblemResponse {
+	p, detail := internalProblem, ""
+	for _, known := range problems {
+		if errors.Is(err, known.err) {
+			p, detail = known, err.Error()
+			break
+		}
+	}
+	return ProblemResponse{
+		Type:   "about:blank",
+		Title:  http.StatusText(p.status),
+		Status: p.status,
+		Detail: detail,
+		Code:   p.code,
+	}
+}
+
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrMalformedBody, err)
+	}
+	return nil
+}
+
+// decodeOptionalBody is decodeBody for the requests whose body can be empty,
+// v is left as is then.
+func decodeOptionalBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %v", errs.ErrMalformedBody, err)
+	}
+	return nil
+}
@@ -2,18 +2,55 @@ package handler
 
 import (
 	"context"
+	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 )
 
 type VoteService interface {
 	Create(ctx context.Context, vote string) (int, error)
+	CreatePoll(ctx context.Context, poll entity.Poll) (int, error)
 	Get(ctx context.Context, title string) (int, error)
+	GetById(ctx context.Context, id int) (entity.Vote, error)
+	List(ctx context.Context, query entity.VoteQuery) (entity.VotePage, error)
 	Delete(ctx context.Context, id string) error
+	SetWeights(ctx context.Context, id int, weights []entity.VoterWeight, voterId string) error
+	GetWeights(ctx context.Context, id int, voterId string) ([]entity.VoterWeight, error)
+	Open(ctx context.Context, id int, voterId string) error
+	Close(ctx context.Context, id int, voterId string) error
+	Reopen(ctx context.Context, id int, closesAt *time.Time, voterId string) error
+	Archive(ctx context.Context, id int, voterId string) error
 }
 
 type ChoiceService interface {
 	Create(ctx context.Context, choice entity.Choice) (string, error)
-	Get(ctx context.Context, voteTitle string) ([]entity.Choice, error)
-	Update(ctx context.Context, voteTitle string, choiceTitle string, count int) error
+	Get(ctx context.Context, query entity.ResultQuery, viewerId string) (entity.Result, error)
+	GetById(ctx context.Context, voteId int, viewerId string) ([]entity.Choice, error)
+	GetRedacted(ctx context.Context, voteId int) ([]entity.Choice, error)
+	GetVersionById(ctx context.Context, voteId int, viewerId string) (int64, error)
+	GetBallot(ctx context.Context, voteId int, voterId string) (entity.Ballot, error)
+	Voters(ctx context.Context, voteId int, viewerId string) ([]entity.ChoiceVoters, error)
+	Receipt(ctx context.Context, voteId int, receipt string) (entity.Receipt, error)
+	Log(ctx context.Context, voteId int, viewerId string, after int64, limit int) (entity.LogPage, error)
+	Timeline(ctx context.Context, query entity.TimelineQuery, viewerId string) (entity.Timeline, error)
+	Update(ctx context.Context, voteTitle string, choices []string, voterId string) (string, error)
+	UpdateById(ctx context.Context, voteId int, choices []string, scores []int, voterId string) (string, error)
+	UpdateBatch(ctx context.Context, ballots []entity.Ballot) ([]entity.BallotResult, error)
+	ChangeBallot(ctx context.Context, voteId int, choices []string, scores []int, voterId string) (string, error)
+	RetractBallot(ctx context.Context, voteId int, voterId string) error
+	Edit(ctx context.Context, voteId int, edit entity.PollEdit, voterId string) error
+	MergeChoices(ctx context.Context, voteId int, from string, into string, voterId string) error
+	DecideTie(ctx context.Context, voteId int, choice string, voterId string) error
+	Tally(ctx context.Context, voteId int, viewerId string) (entity.Tally, error)
+	Outcome(ctx context.Context, voteId int, viewerId string) (entity.Outcome, error)
+}
+
+type ResultService interface {
+	Stream(ctx context.Context, voteId int, viewerId string, send func(event entity.ResultEvent) error) error
+}
+
+type IdempotencyService interface {
+	Begin(key string, fingerprint string) (entity.IdempotentResponse, bool, error)
+	Complete(key string, response entity.IdempotentResponse) error
+	Release(key string) error
 }
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+const wsWriteTimeout time.Duration = 10 * time.Second
+
+type streamHandler struct {
+	logger        *logging.Logger
+	resultService ResultService
+	upgrader      websocket.Upgrader
+}
+
+func NewStreamHandler(logger *logging.Logger, resultService ResultService) *streamHandler {
+	return &streamHandler{logger: logger, resultService: resultService}
+}
+
+func (s *streamHandler) InitRoutes(router *mux.Router) {
+	router.HandleFunc("/api/votes/{id:[0-9]+}/stream", s.StreamEvents).Methods("GET")
+	router.HandleFunc("/api/votes/{id:[0-9]+}/ws", s.StreamWebSocket).Methods("GET")
+}
+
+func (s *streamHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		errorResponse(w, fmt.Errorf("streaming is not supported by %T", w))
+		return
+	}
+	started := false
+	err = s.resultService.Stream(r.Context(), id, voterId(r), func(event entity.ResultEvent) error {
+		data, err := json.Marshal(eventToDto(event))
+		if err != nil {
+			return err
+		}
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		if _, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Kind, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			errorResponse(w, err)
+			return
+		}
+		s.logger.Errorf("event stream of vote %v stopped due to %v", id, err)
+	}
+}
+
+func (s *streamHandler) StreamWebSocket(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var conn *websocket.Conn
+	upgraded := false
+	err = s.resultService.Stream(ctx, id, voterId(r), func(event entity.ResultEvent) error {
+		if !upgraded {
+			// Upgrade replies with an http error by itself if it fails
+			upgraded = true
+			c, err := s.upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return err
+			}
+			conn = c
+			// the client doesn't send anything, but reading is required to
+			// handle control frames and to notice that the connection was closed
+			go func() {
+				defer cancel()
+				for {
+					if _, _, err := c.NextReader(); err != nil {
+						return
+					}
+				}
+			}()
+		}
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteJSON(StreamEventResponse{Event: event.Kind, Data: eventToDto(event)})
+	})
+	if conn != nil {
+		defer conn.Close()
+	}
+	if err != nil {
+		if !upgraded {
+			errorResponse(w, err)
+			return
+		}
+		s.logger.Errorf("websocket stream of vote %v stopped due to %v", id, err)
+	}
+}
+
+func eventToDto(event entity.ResultEvent) interface{} {
+	if event.Kind == entity.HeartbeatEvent {
+		return HeartbeatResponse{Time: event.Time}
+	}
+	return ResultEventResponse{VoteId: event.VoteId, Choices: choicesToDto(event.Choices)}
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
+	"github.com/VrMolodyakov/vote-service/internal/errs"
+	"github.com/VrMolodyakov/vote-service/internal/handler/mocks"
+	"github.com/VrMolodyakov/vote-service/pkg/logging"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+var streamEvents = []entity.ResultEvent{
+	{Kind: entity.SnapshotEvent, VoteId: 1, Choices: []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 1}, {Title: "Mew", VoteId: 1, Count: 2}}},
+	{Kind: entity.DeltaEvent, VoteId: 1, Choices: []entity.Choice{{Title: "Mew", VoteId: 1, Count: 4}}},
+	{Kind: entity.HeartbeatEvent, VoteId: 1, Time: time.Date(2022, 8, 1, 0, 0, 15, 0, time.UTC)},
+}
+
+func sendEvents(ctx context.Context, voteId int, viewerId string, send func(event entity.ResultEvent) error) error {
+	for _, event := range streamEvents {
+		if err := send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStreamEventsHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	resultServ := mocks.NewMockResultService(ctrl)
+	handler := NewStreamHandler(logging.GetLogger("debug"), resultServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		mock           mockCall
+		want           string
+		contentType    string
+		expectedStatus int
+	}{
+		{
+			title: "stream events and 200 response",
+			mock: func() {
+				resultServ.EXPECT().Stream(gomock.Any(), 1, gomock.Any(), gomock.Any()).DoAndReturn(sendEvents)
+			},
+			want: "event: snapshot\n" +
+				`data: {"vote_id":1,"choices":[{"choice":"Pikachu","vote_count":1},{"choice":"Mew","vote_count":2}]}` + "\n\n" +
+				"event: delta\n" +
+				`data: {"vote_id":1,"choices":[{"choice":"Mew","vote_count":4}]}` + "\n\n" +
+				"event: heartbeat\n" +
+				`data: {"time":"2022-08-01T00:00:15Z"}` + "\n\n",
+			contentType:    "text/event-stream",
+			expectedStatus: 200,
+		},
+		{
+			title: "vote not found and 404 response",
+			mock: func() {
+				resultServ.EXPECT().Stream(gomock.Any(), 1, gomock.Any(), gomock.Any()).Return(errs.ErrVoteNotExist)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Not Found\",\"status\": 404,\"detail\": \"the vote doesn't exist\",\"code\": \"vote_not_found\"}",
+			contentType:    problemContentType,
+			expectedStatus: 404,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", "/api/votes/1/stream", nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.contentType, recorder.Header().Get("Content-Type"))
+			if test.contentType == problemContentType {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			} else {
+				assert.Equal(t, test.want, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestStreamWebSocketHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	resultServ := mocks.NewMockResultService(ctrl)
+	handler := NewStreamHandler(logging.GetLogger("debug"), resultServ)
+	handler.InitRoutes(router)
+	resultServ.EXPECT().Stream(gomock.Any(), 1, gomock.Any(), gomock.Any()).DoAndReturn(sendEvents)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/votes/1/ws"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	want := []string{
+		`{"event":"snapshot","data":{"vote_id":1,"choices":[{"choice":"Pikachu","vote_count":1},{"choice":"Mew","vote_count":2}]}}`,
+		`{"event":"delta","data":{"vote_id":1,"choices":[{"choice":"Mew","vote_count":4}]}}`,
+		`{"event":"heartbeat","data":{"time":"2022-08-01T00:00:15Z"}}`,
+	}
+	for _, frame := range want {
+		_, got, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, frame, strings.TrimSpace(string(got)))
+	}
+}
@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	"github.com/VrMolodyakov/vote-service/internal/errs"
@@ -11,36 +16,81 @@ import (
 )
 
 const (
-	prefix string = ""
-	indent string = "   "
+	prefix        string = ""
+	indent        string = "   "
+	voterIdHeader string = "X-Voter-Id"
 )
 
 func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("inside Create handler")
 	var vote FullVoteRequest
-	err := json.NewDecoder(r.Body).Decode(&vote)
+	err := decodeBody(r, &vote)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		errorResponse(w, err)
 		return
 	}
-	ctx := r.Context()
-	id, err := h.voteService.Create(ctx, vote.VoteTitle)
+	poll := entity.Poll{
+		Title:         vote.VoteTitle,
+		Choices:       vote.Choices,
+		MinSelections: vote.MinSelections,
+		MaxSelections: vote.MaxSelections,
+		Method:        vote.Method,
+		Seats:         vote.Seats,
+		Transfer:      vote.Transfer,
+		MinScore:      vote.MinScore,
+		MaxScore:      vote.MaxScore,
+		Weighted:      vote.Weighted,
+		Draft:         vote.Draft,
+		OpensAt:       vote.OpensAt,
+		ClosesAt:      vote.ClosesAt,
+		Visibility:    vote.Visibility,
+		OwnerId:       voterId(r),
+		AllowWriteIn:  vote.AllowWriteIn,
+		Rules: entity.Rules{
+			Quorum:           vote.Rules.Quorum,
+			QuorumPercent:    vote.Rules.QuorumPercent,
+			Threshold:        vote.Rules.Threshold,
+			ThresholdPercent: vote.Rules.ThresholdPercent,
+			Abstention:       vote.Rules.Abstention,
+			CountAbstentions: vote.Rules.CountAbstentions,
+		},
+		TieBreak: vote.TieBreak,
+		TieSeed:  vote.TieSeed,
+		Privacy:  vote.BallotPrivacy,
+	}
+	id, err := h.voteService.CreatePoll(r.Context(), poll)
 	if err != nil {
 		errorResponse(w, err)
 		return
 	}
-	choices := dtoToChoices(id, vote.Choices)
 	var response VoteResponse
+	response.Id = id
 	response.VoteTitle = vote.VoteTitle
-	for _, choice := range choices {
-		choice, err := h.choiceService.Create(ctx, choice)
-		if err != nil {
-			errorResponse(w, err)
-			return
-		}
-		response.Choices = append(response.Choices, ChoiceResponse{ChoiceTitle: choice, Count: 0})
-
+	response.Method = poll.CountingMethod()
+	response.MinSelections, response.MaxSelections = poll.Selections()
+	response.Seats, response.Transfer = poll.Election()
+	if response.Method == entity.MethodScore {
+		response.Scale = &ScaleResponse{}
+		response.Scale.MinScore, response.Scale.MaxScore = poll.Scale()
+	}
+	response.Weighted = poll.Weighted
+	response.Status = poll.InitialStatus(time.Now())
+	response.OpensAt, response.ClosesAt = poll.OpensAt, poll.ClosesAt
+	response.Visibility = poll.ResultVisibility()
+	response.AllowWriteIn = poll.AllowWriteIn
+	if response.Rules = rulesToDto(poll.Rules); response.Rules != nil {
+		response.Rules.Threshold = poll.Rules.PassingThreshold()
+	}
+	if tieBreak := poll.TieBreaking(); tieBreak != entity.TieBreakNone {
+		// a seed drawn by the service is published by the vote itself
+		response.TieBreak, response.TieSeed = tieBreak, poll.TieSeed
 	}
+	response.BallotPrivacy = ballotPrivacy(entity.Vote{Privacy: poll.BallotPrivacy()})
+	created := make([]entity.Choice, 0, len(vote.Choices))
+	for _, choice := range vote.Choices {
+		created = append(created, entity.Choice{Title: choice, VoteId: id})
+	}
+	response.Choices = voteChoicesToDto(entity.Vote{Weighted: poll.Weighted}, created)
 	jsonReponce, err := json.MarshalIndent(response, prefix, indent)
 	if err != nil {
 		errorResponse(w, err)
@@ -54,23 +104,25 @@ func (h *handler) Create(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) GetChoices(w http.ResponseWriter, r *http.Request) {
 	var vote VoteTitleRequest
-	err := json.NewDecoder(r.Body).Decode(&vote)
+	err := decodeBody(r, &vote)
 	if err != nil {
 		errorResponse(w, err)
 		return
 	}
+	query, err := resultQuery(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	query.VoteTitle = vote.VoteTitle
 	h.logger.Debugf("try to get choices for %v", vote)
 	ctx := r.Context()
-	choices, err := h.choiceService.Get(ctx, vote.VoteTitle)
+	result, err := h.choiceService.Get(ctx, query, voterId(r))
 	if err != nil {
 		errorResponse(w, err)
 		return
 	}
-	choiceDto := make([]ChoiceResponse, 0, len(choices))
-	for _, choice := range choices {
-		choiceDto = append(choiceDto, choiceToDto(choice))
-	}
-	jsonReponce, err := json.MarshalIndent(choiceDto, prefix, indent)
+	jsonReponce, err := json.MarshalIndent(choicesToDto(result.Choices), prefix, indent)
 	if err != nil {
 		errorResponse(w, err)
 		return
@@ -82,19 +134,19 @@ func (h *handler) GetChoices(w http.ResponseWriter, r *http.Request) {
 
 func (h *handler) UpdateChoice(w http.ResponseWriter, r *http.Request) {
 	var updateReq UpdateChoiceRequest
-	err := json.NewDecoder(r.Body).Decode(&updateReq)
+	err := decodeBody(r, &updateReq)
 	if err != nil {
 		errorResponse(w, err)
 		return
 	}
 	h.logger.Debugf("try tot update choice %v", updateReq)
 	ctx := r.Context()
-	err = h.choiceService.Update(ctx, updateReq.VoteTitle, updateReq.ChoiceTitle, 1)
+	receipt, err := h.choiceService.Update(ctx, updateReq.VoteTitle, selections(updateReq.ChoiceTitle, updateReq.Choices), voterId(r))
 	if err != nil {
 		errorResponse(w, err)
 		return
 	}
-	w.WriteHeader(http.StatusNoContent)
+	jsonResponse(w, http.StatusCreated, ReceiptResponse{Receipt: receipt})
 }
 
 func (h *handler) DeleteVote(w http.ResponseWriter, r *http.Request) {
@@ -109,27 +161,936 @@ func (h *handler) DeleteVote(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func errorResponse(w http.ResponseWriter, err error) {
-	if errors.Is(err, errs.ErrEmptyChoiceTitle) ||
-		errors.Is(err, errs.ErrEmptyVoteTitle) ||
-		errors.Is(err, errs.ErrTitleNotExist) ||
-		errors.Is(err, errs.ErrChoiceTitleNotExist) ||
-		errors.Is(err, errs.ErrTitleAlreadyExist) {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-	} else {
-		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+func (h *handler) GetVote(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
 	}
+	h.logger.Debugf("try to get vote %v", id)
+	ctx := r.Context()
+	response, err := h.voteView(ctx, id, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, response)
 }
 
-func dtoToChoices(voteId int, choices []string) []entity.Choice {
-	chs := make([]entity.Choice, 0, len(choices))
-	for _, choice := range choices {
-		choice := entity.Choice{Title: choice, VoteId: voteId, Count: 0}
-		chs = append(chs, choice)
+// voteView returns the vote with the choices, the counts are left out if the
+// viewer can't see the results.
+func (h *handler) voteView(ctx context.Context, id int, viewerId string) (VoteResponse, error) {
+	vote, err := h.voteService.GetById(ctx, id)
+	if err != nil {
+		return VoteResponse{}, err
+	}
+	choices, err := h.choiceService.GetById(ctx, id, viewerId)
+	hidden := errors.Is(err, errs.ErrResultsHidden) || errors.Is(err, errs.ErrResultsAfterVote)
+	if hidden {
+		choices, err = h.choiceService.GetRedacted(ctx, id)
+	}
+	if err != nil {
+		return VoteResponse{}, err
+	}
+	if hidden {
+		// the number of ballots is a result too
+		vote.Ballots = 0
+	}
+	response := voteToDto(vote, choices)
+	response.ResultsHidden = hidden
+	return response, nil
+}
+
+func (h *handler) GetResults(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to get results for vote %v", id)
+	// the results differ by the viewer unless they are public, so shared
+	// caches must not serve them to another voter
+	w.Header().Set("Vary", voterIdHeader)
+	viewerId := voterId(r)
+	// the version is read before the results, so the ETag is never newer than the body
+	version, err := h.choiceService.GetVersionById(r.Context(), id, viewerId)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	if notModified(w, r, version) {
+		return
+	}
+	query, err := resultQuery(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	query.VoteId = id
+	result, err := h.choiceService.Get(r.Context(), query, viewerId)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	vote := result.Vote
+	response := resultsToDto(result)
+	if entity.Ranked(vote.Method) {
+		tally, err := h.choiceService.Tally(r.Context(), id, viewerId)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		response.Tally = tallyToDto(tally, r.URL.Query().Get("rounds") == "true")
+	}
+	if !vote.Rules.Empty() {
+		outcome, err := h.choiceService.Outcome(r.Context(), id, viewerId)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+		response.Outcome = outcomeToDto(outcome)
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+func (h *handler) CastBallot(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	var ballot BallotRequest
+	err = decodeBody(r, &ballot)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to cast ballot %v for vote %v", ballot, id)
+	choices, scores := ballotOf(ballot.ChoiceTitle, ballot.Choices, ballot.Scores)
+	receipt, err := h.choiceService.UpdateById(r.Context(), id, choices, scores, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusCreated, ReceiptResponse{Receipt: receipt})
+}
+
+func (h *handler) GetBallot(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to get ballot for vote %v", id)
+	ballot, err := h.choiceService.GetBallot(r.Context(), id, voterId(r))
+	if errors.Is(err, errs.ErrBallotNotExist) {
+		jsonResponse(w, http.StatusOK, BallotResponse{VoteId: id})
+		return
+	}
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response := BallotResponse{
+		VoteId:  id,
+		Voted:   true,
+		Choices: ballot.Choices,
+		VotedAt: &ballot.CreatedAt,
+	}
+	if len(ballot.Scores) > 0 {
+		response.Scores = make(map[string]int, len(ballot.Scores))
+		for i, score := range ballot.Scores {
+			response.Scores[ballot.Choices[i]] = score
+		}
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+// GetVoters lists the voters of every choice of a public vote to the viewers
+// who can see its results.
+func (h *handler) GetVoters(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to get voters of vote %v", id)
+	voters, err := h.choiceService.Voters(r.Context(), id, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response := VotersResponse{VoteId: id, Choices: make([]ChoiceVotersResponse, 0, len(voters))}
+	for _, choice := range voters {
+		response.Choices = append(response.Choices, ChoiceVotersResponse{ChoiceTitle: choice.Choice, Voters: choice.VoterIds})
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+// GetReceipt checks the receipt against the ballot log of the vote, a receipt
+// the log doesn't have is reported as not included.
+func (h *handler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	hash := mux.Vars(r)["receipt"]
+	h.logger.Debugf("try to check receipt %v of vote %v", hash, id)
+	receipt, err := h.choiceService.Receipt(r.Context(), id, hash)
+	if errors.Is(err, errs.ErrReceiptNotExist) {
+		jsonResponse(w, http.StatusOK, ReceiptCheckResponse{VoteId: id, Receipt: hash})
+		return
+	}
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	entry := logEntryToDto(receipt.Entry)
+	jsonResponse(w, http.StatusOK, ReceiptCheckResponse{
+		VoteId:     id,
+		Receipt:    hash,
+		Included:   true,
+		Counted:    receipt.Counted(),
+		ReplacedBy: receipt.ReplacedBy,
+		Entry:      &entry,
+	})
+}
+
+// GetLog returns a page of the ballot log of the vote, the pages are read
+// one after another with the after of the next page.
+func (h *handler) GetLog(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	after, limit, err := logQuery(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to get log of vote %v after %v", id, after)
+	page, err := h.choiceService.Log(r.Context(), id, voterId(r), after, limit)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response := LogResponse{VoteId: id, Entries: make([]LogEntryResponse, 0, len(page.Entries)), NextAfter: page.Next}
+	for _, entry := range page.Entries {
+		response.Entries = append(response.Entries, logEntryToDto(entry))
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+// GetTimeline returns the counts of the vote grouped by the bucket of the
+// query up to its as_of.
+func (h *handler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	query, err := timelineQuery(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	query.VoteId = id
+	h.logger.Debugf("try to get timeline of vote %v", id)
+	timeline, err := h.choiceService.Timeline(r.Context(), query, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response := TimelineResponse{
+		VoteId:  id,
+		Bucket:  timeline.Bucket,
+		AsOf:    timeline.AsOf,
+		Ballots: timeline.Ballots,
+		Choices: make([]ChoiceResponse, 0, len(timeline.Choices)),
+		Buckets: make([]TimelineBucketResponse, 0, len(timeline.Buckets)),
+	}
+	for _, choice := range timeline.Choices {
+		response.Choices = append(response.Choices, ChoiceResponse{ChoiceTitle: choice.Title, Count: choice.Count})
+	}
+	for _, bucket := range timeline.Buckets {
+		b := TimelineBucketResponse{Start: bucket.Start, Cast: bucket.Cast, Ballots: bucket.Ballots, Leader: bucket.Leader, Choices: make([]TimelineCountResponse, 0, len(bucket.Counts))}
+		for _, count := range bucket.Counts {
+			b.Choices = append(b.Choices, TimelineCountResponse{ChoiceTitle: count.Choice, Votes: count.Change, Total: count.Total})
+		}
+		response.Buckets = append(response.Buckets, b)
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+func (h *handler) ChangeBallot(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	var ballot BallotRequest
+	err = decodeBody(r, &ballot)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to change ballot to %v for vote %v", ballot, id)
+	choices, scores := ballotOf(ballot.ChoiceTitle, ballot.Choices, ballot.Scores)
+	receipt, err := h.choiceService.ChangeBallot(r.Context(), id, choices, scores, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, ReceiptResponse{Receipt: receipt})
+}
+
+func (h *handler) RetractBallot(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to retract ballot for vote %v", id)
+	err = h.choiceService.RetractBallot(r.Context(), id, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CastBallots casts the ballots of the batch on behalf of the X-Voter-Id
+// voter, as the single ballot routes do, so a batch can't cast the ballots
+// of other voters.
+func (h *handler) CastBallots(w http.ResponseWriter, r *http.Request) {
+	viewerId := voterId(r)
+	if viewerId == "" {
+		errorResponse(w, errs.ErrVoterRequired)
+		return
+	}
+	var batch BatchRequest
+	err := decodeBody(r, &batch)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to cast batch of %v ballots by %v", len(batch.Ballots), viewerId)
+	ballots := make([]entity.Ballot, 0, len(batch.Ballots))
+	for _, ballot := range batch.Ballots {
+		choices, scores := ballotOf(ballot.ChoiceTitle, ballot.Choices, ballot.Scores)
+		ballots = append(ballots, entity.Ballot{
+			VoteId:    ballot.VoteId,
+			VoteTitle: ballot.VoteTitle,
+			VoterId:   viewerId,
+			Choices:   choices,
+			Scores:    scores,
+		})
+	}
+	results, err := h.choiceService.UpdateBatch(r.Context(), ballots)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response := BatchResponse{Results: make([]BatchResultResponse, 0, len(results))}
+	for i, result := range results {
+		if result.Err != nil {
+			problem := problemOf(result.Err)
+			response.Failed++
+			response.Results = append(response.Results, BatchResultResponse{Index: i, Status: problem.Status, Error: &problem})
+			continue
+		}
+		response.Applied++
+		response.Results = append(response.Results, BatchResultResponse{
+			Index:   i,
+			Status:  http.StatusOK,
+			VoteId:  result.VoteId,
+			Choices: choicesToDto(result.Choices),
+			Receipt: result.Receipt,
+		})
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+// SetWeights replaces the eligible voters of a weighted vote. The weights are
+// managed by the owner of the vote, the X-Voter-Id of the request has to be
+// the owner and a voter can't set them with the ballot.
+func (h *handler) SetWeights(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	var request WeightsRequest
+	err = decodeBody(r, &request)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to set %v weights for vote %v", len(request.Weights), id)
+	weights := make([]entity.VoterWeight, 0, len(request.Weights))
+	for _, weight := range request.Weights {
+		weights = append(weights, entity.VoterWeight{VoterId: weight.VoterId, Weight: weight.Weight})
+	}
+	err = h.voteService.SetWeights(r.Context(), id, weights, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) GetWeights(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
 	}
-	return chs
+	h.logger.Debugf("try to get weights for vote %v", id)
+	weights, err := h.voteService.GetWeights(r.Context(), id, voterId(r))
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response := WeightsResponse{VoteId: id, Weights: make([]VoterWeightResponse, 0, len(weights))}
+	for _, weight := range weights {
+		response.Weights = append(response.Weights, VoterWeightResponse{VoterId: weight.VoterId, Weight: weight.Weight})
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+func (h *handler) OpenVote(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "open", h.voteService.Open)
+}
+
+// CloseVote stops the voting, the results of the closed vote are the counts
+// frozen at the moment it was closed.
+func (h *handler) CloseVote(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "close", h.voteService.Close)
+}
+
+// ReopenVote opens the closed vote again, the body with the new closes_at
+// is optional.
+func (h *handler) ReopenVote(w http.ResponseWriter, r *http.Request) {
+	var request ReopenRequest
+	if err := decodeOptionalBody(r, &request); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.transition(w, r, "reopen", func(ctx context.Context, id int, voterId string) error {
+		return h.voteService.Reopen(ctx, id, request.ClosesAt, voterId)
+	})
+}
+
+func (h *handler) ArchiveVote(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "archive", h.voteService.Archive)
+}
+
+// transition changes the status of the vote on behalf of the X-Voter-Id
+// voter, who has to be its owner, and responds with the vote.
+func (h *handler) transition(w http.ResponseWriter, r *http.Request, action string, change func(ctx context.Context, id int, voterId string) error) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to %v vote %v", action, id)
+	ctx := r.Context()
+	viewerId := voterId(r)
+	if err = change(ctx, id, viewerId); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response, err := h.voteView(ctx, id, viewerId)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+func (h *handler) UpdateVote(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	var update EditVoteRequest
+	err = decodeBody(r, &update)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to update vote %v with %v", id, update)
+	ctx := r.Context()
+	viewerId := voterId(r)
+	err = h.choiceService.Edit(ctx, id, entity.PollEdit{
+		Title:   update.VoteTitle,
+		Add:     update.AddChoices,
+		Rename:  update.RenameChoices,
+		Remove:  update.RemoveChoices,
+		Force:   update.Force,
+		Privacy: update.BallotPrivacy,
+	}, viewerId)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response, err := h.voteView(ctx, id, viewerId)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+// MergeChoices merges a write-in choice into another choice of the vote and
+// responds with the vote, only the owner of the vote can merge.
+func (h *handler) MergeChoices(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	var merge MergeChoicesRequest
+	err = decodeBody(r, &merge)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to merge choices %v of vote %v", merge, id)
+	ctx := r.Context()
+	viewerId := voterId(r)
+	if err = h.choiceService.MergeChoices(ctx, id, merge.From, merge.Into, viewerId); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response, err := h.voteView(ctx, id, viewerId)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+// DecideTie declares the choice tied for the lead the winner of the closed
+// vote and responds with the results, only the owner of a vote whose ties
+// are decided by its owner can decide.
+func (h *handler) DecideTie(w http.ResponseWriter, r *http.Request) {
+	id, err := voteId(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	var decide DecideTieRequest
+	err = decodeBody(r, &decide)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	h.logger.Debugf("try to decide tie of vote %v for %v", id, decide.ChoiceTitle)
+	ctx := r.Context()
+	viewerId := voterId(r)
+	if err = h.choiceService.DecideTie(ctx, id, decide.ChoiceTitle, viewerId); err != nil {
+		errorResponse(w, err)
+		return
+	}
+	result, err := h.choiceService.Get(ctx, entity.ResultQuery{VoteId: id}, viewerId)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, resultsToDto(result))
+}
+
+func (h *handler) ListVotes(w http.ResponseWriter, r *http.Request) {
+	query, err := listQuery(r)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	query.Now = time.Now()
+	h.logger.Debugf("try to list votes with %+v", query)
+	page, err := h.voteService.List(r.Context(), query)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	response := VoteListResponse{Votes: make([]VoteSummaryResponse, 0, len(page.Votes))}
+	for _, vote := range page.Votes {
+		response.Votes = append(response.Votes, VoteSummaryResponse{
+			Id:            vote.Id,
+			VoteTitle:     vote.Title,
+			CreatedAt:     vote.CreatedAt,
+			Total:         vote.Total,
+			ResultsHidden: !vote.PublicResults(query.Now),
+		})
+	}
+	if page.Next != nil {
+		response.NextCursor, err = encodeCursor(*page.Next)
+		if err != nil {
+			errorResponse(w, err)
+			return
+		}
+	}
+	jsonResponse(w, http.StatusOK, response)
+}
+
+func listQuery(r *http.Request) (entity.VoteQuery, error) {
+	values := r.URL.Query()
+	query := entity.VoteQuery{
+		Title:  values.Get("title"),
+		Prefix: values.Get("prefix"),
+		Sort:   values.Get("sort"),
+		Desc:   values.Get("order") != "asc",
+	}
+	if order := values.Get("order"); order != "" && order != "asc" && order != "desc" {
+		return entity.VoteQuery{}, errs.ErrInvalidOrder
+	}
+	if limit := values.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			return entity.VoteQuery{}, errs.ErrInvalidLimit
+		}
+		query.Limit = l
+	}
+	if cursor := values.Get("cursor"); cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return entity.VoteQuery{}, err
+		}
+		query.After = &after
+	}
+	return query, nil
+}
+
+// resultQuery reads the sort, order and top of the results, the vote is set
+// by the caller.
+func resultQuery(r *http.Request) (entity.ResultQuery, error) {
+	values := r.URL.Query()
+	query := entity.ResultQuery{Sort: values.Get("sort"), Order: values.Get("order")}
+	if top := values.Get("top"); top != "" {
+		t, err := strconv.Atoi(top)
+		if err != nil || t <= 0 {
+			return entity.ResultQuery{}, errs.ErrInvalidTop
+		}
+		query.Top = t
+	}
+	return query, nil
+}
+
+// logQuery reads the seq to read the ballot log after and the size of the
+// page, both are optional.
+func logQuery(r *http.Request) (int64, int, error) {
+	values := r.URL.Query()
+	var after int64
+	var limit int
+	var err error
+	if value := values.Get("after"); value != "" {
+		if after, err = strconv.ParseInt(value, 10, 64); err != nil || after < 0 {
+			return 0, 0, errs.ErrInvalidAfter
+		}
+	}
+	if value := values.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			return 0, 0, errs.ErrInvalidLimit
+		}
+	}
+	return after, limit, nil
+}
+
+// timelineQuery reads the bucket and the as_of time of the timeline, the vote
+// is set by the caller.
+func timelineQuery(r *http.Request) (entity.TimelineQuery, error) {
+	values := r.URL.Query()
+	query := entity.TimelineQuery{Bucket: values.Get("bucket")}
+	if value := values.Get("as_of"); value != "" {
+		asOf, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return entity.TimelineQuery{}, errs.ErrInvalidAsOf
+		}
+		query.AsOf = asOf
+	}
+	return query, nil
+}
+
+func encodeCursor(cursor entity.VoteCursor) (string, error) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (entity.VoteCursor, error) {
+	var cursor entity.VoteCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor, errs.ErrInvalidCursor
+	}
+	if err = json.Unmarshal(b, &cursor); err != nil {
+		return cursor, errs.ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func voteId(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return -1, errs.ErrInvalidVoteId
+	}
+	return id, nil
+}
+
+// voterId identifies the voter of the request, the header is expected to be
+// set from the auth claims by the gateway.
+func voterId(r *http.Request) string {
+	return r.Header.Get(voterIdHeader)
+}
+
+// selections returns the selected choices of the request, choice is the
+// single selection sent by the clients of single choice votes.
+func selections(choice string, choices []string) []string {
+	if len(choices) > 0 {
+		return choices
+	}
+	return []string{choice}
+}
+
+// ballotOf returns the selected choices and their scores, the scored choices
+// are sorted by title so the same ballot is always passed in the same order.
+func ballotOf(choice string, choices []string, scores map[string]int) ([]string, []int) {
+	if len(scores) == 0 {
+		return selections(choice, choices), nil
+	}
+	titles := make([]string, 0, len(scores))
+	for title := range scores {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	values := make([]int, 0, len(titles))
+	for _, title := range titles {
+		values = append(values, scores[title])
+	}
+	return titles, values
+}
+
+func jsonResponse(w http.ResponseWriter, status int, body interface{}) {
+	jsonReponce, err := json.MarshalIndent(body, prefix, indent)
+	if err != nil {
+		errorResponse(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonReponce)
 }
 
 func choiceToDto(choice entity.Choice) ChoiceResponse {
-	return ChoiceResponse{ChoiceTitle: choice.Title, Count: choice.Count}
+	response := ChoiceResponse{ChoiceTitle: choice.Title, Count: choice.Count, WriteIn: choice.WriteIn}
+	if choice.Rank > 0 {
+		percent := choice.Percent
+		response.Rank, response.Percent = choice.Rank, &percent
+	}
+	if choice.Histogram == nil {
+		return response
+	}
+	response.Scores = &ScoreSummaryResponse{
+		Mean:      choice.Histogram.Mean(),
+		Median:    choice.Histogram.Median(),
+		StdDev:    choice.Histogram.StdDev(),
+		Histogram: make([]ScoreCountResponse, 0, len(choice.Histogram)),
+	}
+	for _, bucket := range choice.Histogram {
+		response.Scores.Histogram = append(response.Scores.Histogram, ScoreCountResponse{Score: bucket.Score, Count: bucket.Count})
+	}
+	return response
+}
+
+func choicesToDto(choices []entity.Choice) []ChoiceResponse {
+	choiceDto := make([]ChoiceResponse, 0, len(choices))
+	for _, choice := range choices {
+		choiceDto = append(choiceDto, choiceToDto(choice))
+	}
+	return choiceDto
+}
+
+// voteChoicesToDto converts the choices of the vote, the choices of a weighted
+// vote carry the numbers of voters next to the weighted counts.
+func voteChoicesToDto(vote entity.Vote, choices []entity.Choice) []ChoiceResponse {
+	response := choicesToDto(choices)
+	if !vote.Weighted {
+		return response
+	}
+	for i := range response {
+		voters := choices[i].Voters
+		response[i].Voters = &voters
+	}
+	return response
+}
+
+func voteToDto(vote entity.Vote, choices []entity.Choice) VoteResponse {
+	return VoteResponse{
+		Id:            vote.Id,
+		VoteTitle:     vote.Title,
+		Method:        vote.Method,
+		MinSelections: vote.MinSelections,
+		MaxSelections: vote.MaxSelections,
+		Seats:         vote.Seats,
+		Transfer:      vote.Transfer,
+		Scale:         scaleToDto(vote),
+		Weighted:      vote.Weighted,
+		Status:        vote.StatusAt(time.Now()),
+		OpensAt:       vote.OpensAt,
+		ClosesAt:      vote.ClosesAt,
+		ClosedAt:      vote.ClosedAt,
+		Visibility:    vote.Visibility,
+		AllowWriteIn:  vote.AllowWriteIn,
+		Rules:         rulesToDto(vote.Rules),
+		TieBreak:      tieBreak(vote),
+		TieSeed:       tieSeed(vote),
+		BallotPrivacy: ballotPrivacy(vote),
+		Ballots:       vote.Ballots,
+		Choices:       voteChoicesToDto(vote, choices),
+	}
+}
+
+func logEntryToDto(entry entity.LogEntry) LogEntryResponse {
+	return LogEntryResponse{
+		Seq:       entry.Seq,
+		Kind:      entry.Kind,
+		Replaces:  entry.Replaces,
+		Weight:    entry.Weight,
+		Choices:   entry.Choices,
+		Scores:    entry.Scores,
+		CreatedAt: entry.CreatedAt,
+		PrevHash:  entry.PrevHash,
+		Hash:      entry.Hash,
+	}
+}
+
+func scaleToDto(vote entity.Vote) *ScaleResponse {
+	if vote.Method != entity.MethodScore {
+		return nil
+	}
+	return &ScaleResponse{MinScore: vote.MinScore, MaxScore: vote.MaxScore}
+}
+
+// resultsToDto converts the results without the tally and the outcome, the
+// seed is published if the tie has been broken at random.
+func resultsToDto(result entity.Result) ResultsResponse {
+	response := ResultsResponse{
+		VoteId:   result.Vote.Id,
+		Method:   result.Vote.Method,
+		Weighted: result.Vote.Weighted,
+		Ballots:  result.Ballots,
+		Total:    result.Total,
+		Winner:   result.Winner,
+		Tied:     result.Tied,
+		TieBreak: result.TieBreak,
+		Choices:  voteChoicesToDto(result.Vote, result.Choices),
+	}
+	if result.TieBreak == entity.TieBreakRandom {
+		response.TieSeed = result.Vote.TieSeed
+	}
+	return response
+}
+
+// tieBreak leaves out the tie-break of a vote whose ties stay undecided.
+func tieBreak(vote entity.Vote) string {
+	if vote.TieBreak == entity.TieBreakNone {
+		return ""
+	}
+	return vote.TieBreak
+}
+
+// tieSeed publishes the seed of a vote whose ties are broken at random.
+func tieSeed(vote entity.Vote) int64 {
+	if vote.TieBreak != entity.TieBreakRandom {
+		return 0
+	}
+	return vote.TieSeed
+}
+
+// ballotPrivacy leaves out the ballot privacy of a vote with private ballots.
+func ballotPrivacy(vote entity.Vote) string {
+	if vote.Privacy == entity.PrivacyPrivate {
+		return ""
+	}
+	return vote.Privacy
+}
+
+func rulesToDto(rules entity.Rules) *RulesResponse {
+	if rules.Empty() {
+		return nil
+	}
+	return &RulesResponse{
+		Quorum:           rules.Quorum,
+		QuorumPercent:    rules.QuorumPercent,
+		Threshold:        rules.Threshold,
+		ThresholdPercent: rules.ThresholdPercent,
+		Abstention:       rules.Abstention,
+		CountAbstentions: rules.CountAbstentions,
+	}
+}
+
+func outcomeToDto(outcome entity.Outcome) *OutcomeResponse {
+	return &OutcomeResponse{
+		Result:      outcome.Result,
+		Choice:      outcome.Choice,
+		Votes:       outcome.Votes,
+		Counted:     outcome.Counted,
+		Share:       outcome.Share,
+		Abstentions: outcome.Abstentions,
+		Turnout:     outcome.Turnout,
+	}
+}
+
+// tallyToDto converts the tally, the instant-runoff rounds and the count
+// sheet of a single transferable vote are included only if they are requested.
+func tallyToDto(tally entity.Tally, rounds bool) *TallyResponse {
+	response := &TallyResponse{Winners: tally.Winners, Condorcet: tally.Condorcet, Seats: tally.Seats, Quota: tally.Quota}
+	for _, score := range tally.Scores {
+		response.Scores = append(response.Scores, ScoreResponse{ChoiceTitle: score.Choice, Score: score.Score})
+	}
+	if !rounds {
+		return response
+	}
+	for _, round := range tally.Rounds {
+		votes := make([]ChoiceResponse, 0, len(round.Votes))
+		for _, score := range round.Votes {
+			votes = append(votes, ChoiceResponse{ChoiceTitle: score.Choice, Count: score.Score})
+		}
+		response.Rounds = append(response.Rounds, RoundResponse{
+			Round:      round.Number,
+			Votes:      votes,
+			Exhausted:  round.Exhausted,
+			Eliminated: round.Eliminated,
+		})
+	}
+	for _, stage := range tally.Stages {
+		response.Stages = append(response.Stages, stageToDto(stage))
+	}
+	return response
+}
+
+func stageToDto(stage entity.Stage) StageResponse {
+	response := StageResponse{
+		Stage:     stage.Number,
+		Votes:     make([]ShareResponse, 0, len(stage.Votes)),
+		Exhausted: stage.Exhausted,
+		Elected:   stage.Elected,
+		Excluded:  stage.Excluded,
+	}
+	for _, share := range stage.Votes {
+		response.Votes = append(response.Votes, ShareResponse{ChoiceTitle: share.Choice, Votes: share.Votes})
+	}
+	if transfer := stage.Transfer; transfer != nil {
+		response.Transfer = &TransferResponse{Kind: "exclusion", From: transfer.From, Votes: transfer.Votes}
+		if transfer.Surplus {
+			response.Transfer.Kind, response.Transfer.Value = "surplus", transfer.Value
+		}
+	}
+	return response
 }
@@ -3,9 +3,12 @@ package handler
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/VrMolodyakov/vote-service/internal/domain/entity"
 	"github.com/VrMolodyakov/vote-service/internal/errs"
@@ -41,47 +44,120 @@ func TestCreateHandler(t *testing.T) {
 			inputRequest: `{"vote":"Best pokemon","choices":["Pikachu","Mew","Noone"]}`,
 			inputBody:    args{voteTitle: "Best pokemon", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Noone", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
 			mock: func(choices []entity.Choice) {
-				voteServ.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
-				for _, c := range choices {
-					choiceServ.EXPECT().Create(gomock.Any(), c).Return(c.Title, nil)
-				}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), entity.Poll{Title: "Best pokemon", Choices: []string{"Pikachu", "Mew", "Noone"}}).Return(1, nil)
 
 			},
-			want:           "{\"vote\": \"Best pokemon\",\"choices\": [{\"choice\": \"Pikachu\",\"vote_count\": 0},{\"choice\": \"Mew\",\"vote_count\": 0},{\"choice\": \"Noone\",\"vote_count\": 0}]}",
+			want:           "{\"id\": 1,\"vote\": \"Best pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"visibility\": \"always\",\"ballots\": 0,\"choices\": [{\"choice\": \"Pikachu\",\"vote_count\": 0},{\"choice\": \"Mew\",\"vote_count\": 0},{\"choice\": \"Noone\",\"vote_count\": 0}]}",
+			expectedStatus: 201,
+		},
+		{
+			title:        "create anonymous poll and 201 response",
+			inputRequest: `{"vote":"Best pokemon","choices":["Pikachu","Mew"],"ballot_privacy":"anonymous"}`,
+			inputBody:    args{voteTitle: "Best pokemon", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
+			mock: func(choices []entity.Choice) {
+				poll := entity.Poll{Title: "Best pokemon", Choices: []string{"Pikachu", "Mew"}, Privacy: entity.PrivacyAnonymous}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Best pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"visibility\": \"always\",\"ballot_privacy\": \"anonymous\",\"ballots\": 0,\"choices\": [{\"choice\": \"Pikachu\",\"vote_count\": 0},{\"choice\": \"Mew\",\"vote_count\": 0}]}",
+			expectedStatus: 201,
+		},
+		{
+			title:        "create poll with rules and 201 response",
+			inputRequest: `{"vote":"Motion","choices":["Yes","No","Abstain"],"rules":{"quorum":10,"abstention":"Abstain"}}`,
+			inputBody:    args{voteTitle: "Motion", choices: []entity.Choice{{Title: "Yes", VoteId: 1}, {Title: "No", VoteId: 1}, {Title: "Abstain", VoteId: 1}}},
+			mock: func(choices []entity.Choice) {
+				poll := entity.Poll{Title: "Motion", Choices: []string{"Yes", "No", "Abstain"}, Rules: entity.Rules{Quorum: 10, Abstention: "Abstain"}}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Motion\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"visibility\": \"always\",\"rules\": {\"quorum\": 10,\"threshold\": \"plurality\",\"abstention\": \"Abstain\"},\"ballots\": 0,\"choices\": [{\"choice\": \"Yes\",\"vote_count\": 0},{\"choice\": \"No\",\"vote_count\": 0},{\"choice\": \"Abstain\",\"vote_count\": 0}]}",
+			expectedStatus: 201,
+		},
+		{
+			title:        "invalid threshold and 422 code response",
+			inputRequest: `{"vote":"Motion","choices":["Yes","No"],"rules":{"threshold":"unanimous"}}`,
+			inputBody:    args{voteTitle: "Motion", choices: []entity.Choice{{Title: "Yes", VoteId: 1}, {Title: "No", VoteId: 1}}},
+			mock: func(choices []entity.Choice) {
+				poll := entity.Poll{Title: "Motion", Choices: []string{"Yes", "No"}, Rules: entity.Rules{Threshold: "unanimous"}}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(-1, errs.ErrInvalidThreshold)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Unprocessable Entity\",\"status\": 422,\"detail\": \"threshold must be plurality, majority, two_thirds or percent with threshold_percent from 1 to 100\",\"code\": \"invalid_threshold\"}",
+			expectedStatus: 422,
+		},
+		{
+			title:        "invalid selections and 422 code response",
+			inputRequest: `{"vote":"Best pokemon","choices":["Pikachu","Mew"],"min_selections":1,"max_selections":3}`,
+			inputBody:    args{voteTitle: "Best pokemon", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
+			mock: func(choices []entity.Choice) {
+				poll := entity.Poll{Title: "Best pokemon", Choices: []string{"Pikachu", "Mew"}, MinSelections: 1, MaxSelections: 3}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(-1, errs.ErrInvalidSelections)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Unprocessable Entity\",\"status\": 422,\"detail\": \"min_selections must be positive and max_selections must be between min_selections and the number of choices\",\"code\": \"invalid_selections\"}",
+			expectedStatus: 422,
+		},
+		{
+			title:        "create stv poll and 201 response",
+			inputRequest: `{"vote":"Committee","choices":["Pikachu","Mew","Noone"],"method":"stv","seats":2}`,
+			inputBody:    args{voteTitle: "Committee", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Mew", VoteId: 1}, {Title: "Noone", VoteId: 1}}},
+			mock: func(choices []entity.Choice) {
+				poll := entity.Poll{Title: "Committee", Choices: []string{"Pikachu", "Mew", "Noone"}, Method: entity.MethodStv, Seats: 2}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Committee\",\"method\": \"stv\",\"min_selections\": 1,\"max_selections\": 3,\"seats\": 2,\"surplus_transfer\": \"gregory\",\"status\": \"open\",\"visibility\": \"always\",\"ballots\": 0,\"choices\": [{\"choice\": \"Pikachu\",\"vote_count\": 0},{\"choice\": \"Mew\",\"vote_count\": 0},{\"choice\": \"Noone\",\"vote_count\": 0}]}",
 			expectedStatus: 201,
 		},
 		{
-			title:        "empty request title and  400 code response",
+			title:        "create score poll and 201 response",
+			inputRequest: `{"vote":"Rate pokemon","choices":["Pikachu","Mew"],"method":"score","min_score":1,"max_score":5}`,
+			inputBody:    args{voteTitle: "Rate pokemon", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
+			mock: func(choices []entity.Choice) {
+				poll := entity.Poll{Title: "Rate pokemon", Choices: []string{"Pikachu", "Mew"}, Method: entity.MethodScore, MinScore: 1, MaxScore: 5}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(1, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Rate pokemon\",\"method\": \"score\",\"min_selections\": 2,\"max_selections\": 2,\"seats\": 1,\"scale\": {\"min_score\": 1,\"max_score\": 5},\"status\": \"open\",\"visibility\": \"always\",\"ballots\": 0,\"choices\": [{\"choice\": \"Pikachu\",\"vote_count\": 0},{\"choice\": \"Mew\",\"vote_count\": 0}]}",
+			expectedStatus: 201,
+		},
+		{
+			title:        "invalid seats and 422 code response",
+			inputRequest: `{"vote":"Best pokemon","choices":["Pikachu","Mew"],"seats":2}`,
+			inputBody:    args{voteTitle: "Best pokemon", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
+			mock: func(choices []entity.Choice) {
+				poll := entity.Poll{Title: "Best pokemon", Choices: []string{"Pikachu", "Mew"}, Seats: 2}
+				voteServ.EXPECT().CreatePoll(gomock.Any(), poll).Return(-1, errs.ErrInvalidSeats)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Unprocessable Entity\",\"status\": 422,\"detail\": \"seats must be between 1 and the number of choices, only stv polls have several seats\",\"code\": \"invalid_seats\"}",
+			expectedStatus: 422,
+		},
+		{
+			title:        "empty request title and 422 code response",
 			inputRequest: `{"vote":"","choices":["Pikachu","Mew","Noone"]}`,
 			inputBody:    args{voteTitle: "", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Noone", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
 			mock: func(choices []entity.Choice) {
-				voteServ.EXPECT().Create(gomock.Any(), gomock.Any()).Return(-1, errs.ErrEmptyVoteTitle)
+				voteServ.EXPECT().CreatePoll(gomock.Any(), gomock.Any()).Return(-1, errs.ErrEmptyVoteTitle)
 
 			},
-			want:           "vote title is empty",
-			expectedStatus: 400,
+			want:           "{\"type\": \"about:blank\",\"title\": \"Unprocessable Entity\",\"status\": 422,\"detail\": \"vote title is empty\",\"code\": \"empty_vote_title\"}",
+			expectedStatus: 422,
 		},
 		{
 			title:        " service error and 500 code response",
 			inputRequest: `{"vote":"Best pokemon","choices":["Pikachu","Mew","Noone"]}`,
 			inputBody:    args{voteTitle: "Best pokemon", choices: []entity.Choice{{Title: "Pikachu", VoteId: 1}, {Title: "Noone", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
 			mock: func(choices []entity.Choice) {
-				voteServ.EXPECT().Create(gomock.Any(), gomock.Any()).Return(-1, errors.New("internal service error"))
+				voteServ.EXPECT().CreatePoll(gomock.Any(), gomock.Any()).Return(-1, errors.New("internal service error"))
 
 			},
-			want:           "500 Internal Server Error",
+			want:           "{\"type\": \"about:blank\",\"title\": \"Internal Server Error\",\"status\": 500,\"code\": \"internal_error\"}",
 			expectedStatus: 500,
 		},
 		{
-			title:        "empty request choice title and  400 code response",
+			title:        "empty request choice title and 422 code response",
 			inputRequest: `{"vote":"Best pokemon","choices":["","Mew","Noone"]}`,
 			inputBody:    args{voteTitle: "Best pokemon", choices: []entity.Choice{{Title: "", VoteId: 1}, {Title: "Noone", VoteId: 1}, {Title: "Mew", VoteId: 1}}},
 			mock: func(choices []entity.Choice) {
-				voteServ.EXPECT().Create(gomock.Any(), gomock.Any()).Return(1, nil)
-				choiceServ.EXPECT().Create(gomock.Any(), gomock.Any()).Return("", errs.ErrEmptyChoiceTitle)
+				voteServ.EXPECT().CreatePoll(gomock.Any(), gomock.Any()).Return(-1, errs.ErrEmptyChoiceTitle)
 			},
-			want:           "choice title is empty",
-			expectedStatus: 400,
+			want:           "{\"type\": \"about:blank\",\"title\": \"Unprocessable Entity\",\"status\": 422,\"detail\": \"choice title is empty\",\"code\": \"empty_choice_title\"}",
+			expectedStatus: 422,
 		},
 	}
 	for _, test := range testCases {
@@ -121,33 +197,40 @@ func TestGetChoiceHandler(t *testing.T) {
 			title:        "get vote result and 200 response",
 			inputRequest: `{"vote":"Best pokemon"}`,
 			mock: func() {
-				choices := []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 1}, {Title: "Noone", VoteId: 1, Count: 3}, {Title: "Mew", VoteId: 1, Count: 2}}
-				choiceServ.EXPECT().Get(gomock.Any(), gomock.Any()).Return(choices, nil)
+				choices := []entity.Choice{{Title: "Noone", VoteId: 1, Count: 3, Rank: 1, Percent: 50}, {Title: "Mew", VoteId: 1, Count: 2, Rank: 2, Percent: 33.3}, {Title: "Pikachu", VoteId: 1, Count: 1, Rank: 3, Percent: 16.7}}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteTitle: "Best pokemon"}, gomock.Any()).Return(entity.Result{Choices: choices}, nil)
 
 			},
-			want:           "[{\"choice\": \"Pikachu\",\"vote_count\": 1},{\"choice\": \"Noone\",\"vote_count\": 3},{\"choice\": \"Mew\",\"vote_count\": 2}]",
+			want:           "[{\"choice\": \"Noone\",\"vote_count\": 3,\"rank\": 1,\"percent\": 50},{\"choice\": \"Mew\",\"vote_count\": 2,\"rank\": 2,\"percent\": 33.3},{\"choice\": \"Pikachu\",\"vote_count\": 1,\"rank\": 3,\"percent\": 16.7}]",
 			expectedStatus: 200,
 		},
 		{
-			title:        "title not found and 400 response",
+			title:        "title not found and 404 response",
 			inputRequest: `{"vote":"wrong title"}`,
 			mock: func() {
-				choiceServ.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, errs.ErrTitleNotExist)
+				choiceServ.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(entity.Result{}, errs.ErrTitleNotExist)
 
 			},
-			want:           "the title doesn't exist",
-			expectedStatus: 400,
+			want:           "{\"type\": \"about:blank\",\"title\": \"Not Found\",\"status\": 404,\"detail\": \"the title doesn't exist\",\"code\": \"vote_title_not_found\"}",
+			expectedStatus: 404,
 		},
 		{
 			title:        "service internal error and 500 response",
 			inputRequest: `{"vote":"Best pokemon"}`,
 			mock: func() {
-				choiceServ.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal service error"))
+				choiceServ.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(entity.Result{}, errors.New("internal service error"))
 
 			},
-			want:           "500 Internal Server Error",
+			want:           "{\"type\": \"about:blank\",\"title\": \"Internal Server Error\",\"status\": 500,\"code\": \"internal_error\"}",
 			expectedStatus: 500,
 		},
+		{
+			title:          "malformed json and 400 response",
+			inputRequest:   `{"vote":`,
+			mock:           func() {},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Bad Request\",\"status\": 400,\"detail\": \"request body is malformed: unexpected EOF\",\"code\": \"malformed_body\"}",
+			expectedStatus: 400,
+		},
 	}
 	for _, test := range testCases {
 		t.Run(test.title, func(t *testing.T) {
@@ -182,41 +265,47 @@ func TestUpdateChoiceHandler(t *testing.T) {
 		expectedStatus int
 	}{
 		{
-			title:        "success update and 204 response",
+			title:        "success update and 201 response",
 			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
 			mock: func() {
-				choiceServ.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				choiceServ.EXPECT().Update(gomock.Any(), "Best pokemon", []string{"Mew"}, "ash").Return("receipt", nil)
 
 			},
-			expectedStatus: 204,
+			expectedStatus: 201,
 		},
 		{
-			title:        "vote title not found and 400 response",
+			title:        "vote title not found and 404 response",
 			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
 			mock: func() {
-				choiceServ.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errs.ErrTitleNotExist)
+				choiceServ.EXPECT().Update(gomock.Any(), "Best pokemon", []string{"Mew"}, "ash").Return("", errs.ErrTitleNotExist)
 
 			},
-			expectedStatus: 400,
+			expectedStatus: 404,
 		},
 		{
-			title:        "choice title not found and 400 response",
+			title:        "choice title not found and 404 response",
 			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
 			mock: func() {
-				choiceServ.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errs.ErrChoiceTitleNotExist)
+				choiceServ.EXPECT().Update(gomock.Any(), "Best pokemon", []string{"Mew"}, "ash").Return("", errs.ErrChoiceTitleNotExist)
 
 			},
-			expectedStatus: 400,
+			expectedStatus: 404,
 		},
 		{
 			title:        "internal service error and  500 response",
 			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
 			mock: func() {
-				choiceServ.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("internal service error"))
+				choiceServ.EXPECT().Update(gomock.Any(), "Best pokemon", []string{"Mew"}, "ash").Return("", errors.New("internal service error"))
 
 			},
 			expectedStatus: 500,
 		},
+		{
+			title:          "malformed json and 400 response",
+			inputRequest:   `{"vote":"Best pokemon","choice":}`,
+			mock:           func() {},
+			expectedStatus: 400,
+		},
 	}
 	for _, test := range testCases {
 		t.Run(test.title, func(t *testing.T) {
@@ -227,6 +316,7 @@ func TestUpdateChoiceHandler(t *testing.T) {
 				bytes.NewBufferString(test.inputRequest),
 			)
 			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, "ash")
 			recorder := httptest.NewRecorder()
 			router.ServeHTTP(recorder, req)
 			assert.Equal(t, test.expectedStatus, recorder.Code)
@@ -259,22 +349,22 @@ func TestDeleteChoiceHandler(t *testing.T) {
 			expectedStatus: 204,
 		},
 		{
-			title:        "vote title is empty and 400 response",
+			title:        "vote title is empty and 422 response",
 			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
 			mock: func() {
 				voteServ.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errs.ErrEmptyVoteTitle)
 
 			},
-			expectedStatus: 400,
+			expectedStatus: 422,
 		},
 		{
-			title:        "choice title not found and 400 response",
+			title:        "vote title not found and 404 response",
 			inputRequest: `{"vote":"Best pokemon","choice":"Mew"}`,
 			mock: func() {
 				voteServ.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errs.ErrTitleNotExist)
 
 			},
-			expectedStatus: 400,
+			expectedStatus: 404,
 		},
 		{
 			title:        "internal service error and  500 response",
@@ -303,7 +393,1480 @@ func TestDeleteChoiceHandler(t *testing.T) {
 	}
 }
 
-func clearResponse(s string) string {
-	temp := strings.ReplaceAll(s, "   ", "")
-	return strings.ReplaceAll(temp, "\n", "")
+func TestGetVoteHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		url            string
+		want           string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title: "get vote and 200 response",
+			url:   "/api/votes/1",
+			mock: func() {
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Best pokemon", MinSelections: 1, MaxSelections: 2, Seats: 1, Ballots: 2, Method: entity.MethodPlurality, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways}, nil)
+				choices := []entity.Choice{{Title: "Pikachu", VoteId: 1, Count: 1}, {Title: "Mew", VoteId: 1, Count: 2}}
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return(choices, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Best pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 2,\"seats\": 1,\"status\": \"open\",\"visibility\": \"always\",\"ballots\": 2,\"choices\": [{\"choice\": \"Pikachu\",\"vote_count\": 1},{\"choice\": \"Mew\",\"vote_count\": 2}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get vote results and 200 response",
+			url:   "/api/votes/1/results",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(3), nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 2, Rank: 1, Percent: 66.7}, {Title: "Pikachu", VoteId: 1, Count: 1, Rank: 2, Percent: 33.3}}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 1, Title: "Best pokemon", MinSelections: 1, MaxSelections: 2, Seats: 1, Ballots: 2, Method: entity.MethodPlurality}, Ballots: 2, Total: 3, Choices: choices, Winner: "Mew", Tied: []string{}}, nil)
+			},
+			want: "{\"vote_id\": 1,\"method\": \"plurality\",\"ballots\": 2,\"total_votes\": 3,\"winner\": \"Mew\"," +
+				"\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 2,\"rank\": 1,\"percent\": 66.7},{\"choice\": \"Pikachu\",\"vote_count\": 1,\"rank\": 2,\"percent\": 33.3}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get top results with the tie broken at random and 200 response",
+			url:   "/api/votes/1/results?sort=title&order=desc&top=1",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(3), nil)
+				vote := entity.Vote{Id: 1, Title: "Best pokemon", Ballots: 2, Method: entity.MethodPlurality, TieBreak: entity.TieBreakRandom, TieSeed: 42}
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 1, Rank: 1, Percent: 50}}
+				query := entity.ResultQuery{VoteId: 1, Sort: entity.SortByTitle, Order: "desc", Top: 1}
+				result := entity.Result{Vote: vote, Ballots: 2, Total: 2, Choices: choices, Winner: "Eevee", Tied: []string{"Eevee", "Mew"}, TieBreak: entity.TieBreakRandom}
+				choiceServ.EXPECT().Get(gomock.Any(), query, gomock.Any()).Return(result, nil)
+			},
+			want: "{\"vote_id\": 1,\"method\": \"plurality\",\"ballots\": 2,\"total_votes\": 2,\"winner\": \"Eevee\",\"tied\": [\"Eevee\",\"Mew\"]," +
+				"\"tie_break\": \"random\",\"tie_seed\": 42,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 1,\"rank\": 1,\"percent\": 50}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "top isn't a number and 400 response",
+			url:   "/api/votes/1/results?top=all",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(3), nil)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Bad Request\",\"status\": 400,\"detail\": \"top must be a positive number\",\"code\": \"invalid_top\"}",
+			expectedStatus: 400,
+		},
+		{
+			title: "get ranked vote results with rounds and 200 response",
+			url:   "/api/votes/1/results?rounds=true",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(3), nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 3}, {Title: "Pikachu", VoteId: 1, Count: 2}}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 1, Title: "Best pokemon", Ballots: 3, Method: entity.MethodIrv}, Ballots: 3, Total: 5, Choices: choices}, nil)
+				tally := entity.Tally{
+					Method:  entity.MethodIrv,
+					Ballots: 3,
+					Winners: []string{"Mew"},
+					Rounds: []entity.Round{
+						{Number: 1, Votes: []entity.Score{{Choice: "Mew", Score: 2}, {Choice: "Pikachu", Score: 1}}, Eliminated: []string{}},
+					},
+				}
+				choiceServ.EXPECT().Tally(gomock.Any(), 1, gomock.Any()).Return(tally, nil)
+			},
+			want: "{\"vote_id\": 1,\"method\": \"irv\",\"ballots\": 3,\"total_votes\": 5," +
+				"\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 3},{\"choice\": \"Pikachu\",\"vote_count\": 2}]," +
+				"\"tally\": {\"winners\": [\"Mew\"],\"rounds\": [{\"round\": 1," +
+				"\"votes\": [{\"choice\": \"Mew\",\"vote_count\": 2},{\"choice\": \"Pikachu\",\"vote_count\": 1}]," +
+				"\"exhausted\": 0,\"eliminated\": []}]}}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get ranked vote results without rounds and 200 response",
+			url:   "/api/votes/1/results",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(3), nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 2}}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 1, Title: "Best pokemon", Ballots: 2, Method: entity.MethodSchulze}, Ballots: 2, Total: 2, Choices: choices}, nil)
+				tally := entity.Tally{
+					Method:    entity.MethodSchulze,
+					Ballots:   2,
+					Winners:   []string{"Mew"},
+					Condorcet: "Mew",
+					Scores:    []entity.Score{{Choice: "Mew", Score: 1}},
+				}
+				choiceServ.EXPECT().Tally(gomock.Any(), 1, gomock.Any()).Return(tally, nil)
+			},
+			want: "{\"vote_id\": 1,\"method\": \"schulze\",\"ballots\": 2,\"total_votes\": 2,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 2}]," +
+				"\"tally\": {\"winners\": [\"Mew\"],\"condorcet_winner\": \"Mew\",\"scores\": [{\"choice\": \"Mew\",\"score\": 1}]}}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get score vote results with statistics and 200 response",
+			url:   "/api/votes/1/results",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(2), nil)
+				histogram := entity.Histogram{{Score: 1, Count: 1}, {Score: 2, Count: 0}, {Score: 3, Count: 1}}
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 2, Histogram: histogram}}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 1, Title: "Best pokemon", Ballots: 2, Method: entity.MethodScore, MinScore: 1, MaxScore: 3}, Ballots: 2, Total: 2, Choices: choices}, nil)
+			},
+			want: "{\"vote_id\": 1,\"method\": \"score\",\"ballots\": 2,\"total_votes\": 2,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 2," +
+				"\"scores\": {\"mean\": 2,\"median\": 2,\"stddev\": 1," +
+				"\"histogram\": [{\"score\": 1,\"count\": 1},{\"score\": 2,\"count\": 0},{\"score\": 3,\"count\": 1}]}}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get weighted vote results with head counts and 200 response",
+			url:   "/api/votes/1/results",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(2), nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 120, Voters: 2}}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 1, Title: "Board", Ballots: 2, Method: entity.MethodPlurality, Weighted: true}, Ballots: 2, Total: 120, Choices: choices}, nil)
+			},
+			want:           "{\"vote_id\": 1,\"method\": \"plurality\",\"weighted\": true,\"ballots\": 2,\"total_votes\": 120,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 120,\"voters\": 2}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get stv vote results with count sheet and 200 response",
+			url:   "/api/votes/1/results?rounds=true",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(3), nil)
+				choices := []entity.Choice{{Title: "Mew", VoteId: 1, Count: 3}, {Title: "Pikachu", VoteId: 1, Count: 1}}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 1, Title: "Best pokemon", Ballots: 3, Method: entity.MethodStv, Seats: 1, Transfer: entity.TransferGregory}, Ballots: 3, Total: 4, Choices: choices}, nil)
+				tally := entity.Tally{
+					Method:  entity.MethodStv,
+					Ballots: 3,
+					Winners: []string{"Mew"},
+					Seats:   1,
+					Quota:   2,
+					Stages: []entity.Stage{
+						{Number: 1, Votes: []entity.Share{{Choice: "Mew", Votes: 2}, {Choice: "Pikachu", Votes: 1}}, Elected: []string{"Mew"}, Excluded: []string{}},
+					},
+				}
+				choiceServ.EXPECT().Tally(gomock.Any(), 1, gomock.Any()).Return(tally, nil)
+			},
+			want: "{\"vote_id\": 1,\"method\": \"stv\",\"ballots\": 3,\"total_votes\": 4," +
+				"\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 3},{\"choice\": \"Pikachu\",\"vote_count\": 1}]," +
+				"\"tally\": {\"winners\": [\"Mew\"],\"seats\": 1,\"quota\": 2,\"stages\": [{\"stage\": 1," +
+				"\"votes\": [{\"choice\": \"Mew\",\"votes\": 2},{\"choice\": \"Pikachu\",\"votes\": 1}]," +
+				"\"exhausted\": 0,\"elected\": [\"Mew\"],\"excluded\": []}]}}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get vote with hidden results and 200 response without the counts",
+			url:   "/api/votes/3",
+			mock: func() {
+				vote := entity.Vote{Id: 3, Title: "Secret", MinSelections: 1, MaxSelections: 1, Seats: 1, Ballots: 2, Method: entity.MethodPlurality, Status: entity.StatusOpen, Visibility: entity.VisibilityAfterClose}
+				voteServ.EXPECT().GetById(gomock.Any(), 3).Return(vote, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 3, gomock.Any()).Return(nil, errs.ErrResultsHidden)
+				choiceServ.EXPECT().GetRedacted(gomock.Any(), 3).Return([]entity.Choice{{Title: "Mew", VoteId: 3}}, nil)
+			},
+			want:           "{\"id\": 3,\"vote\": \"Secret\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"visibility\": \"after_close\",\"results_hidden\": true,\"ballots\": 0,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 0}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "get results with outcome of the rules and 200 response",
+			url:   "/api/votes/4/results",
+			mock: func() {
+				rules := entity.Rules{Threshold: entity.ThresholdTwoThirds, Abstention: "Abstain"}
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 4, gomock.Any()).Return(int64(3), nil)
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 4}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 4, Title: "Motion", Method: entity.MethodPlurality, Ballots: 12, Rules: rules}, Ballots: 12, Total: 12, Choices: []entity.Choice{{Title: "Yes", VoteId: 4, Count: 7}, {Title: "No", VoteId: 4, Count: 3}, {Title: "Abstain", VoteId: 4, Count: 2}}}, nil)
+				choiceServ.EXPECT().Outcome(gomock.Any(), 4, gomock.Any()).Return(entity.Outcome{Result: entity.OutcomePassed, Choice: "Yes", Votes: 7, Counted: 10, Share: 70, Abstentions: 2, Ballots: 12}, nil)
+			},
+			want:           "{\"vote_id\": 4,\"method\": \"plurality\",\"ballots\": 12,\"total_votes\": 12,\"choices\": [{\"choice\": \"Yes\",\"vote_count\": 7},{\"choice\": \"No\",\"vote_count\": 3},{\"choice\": \"Abstain\",\"vote_count\": 2}],\"outcome\": {\"result\": \"passed\",\"choice\": \"Yes\",\"votes\": 7,\"counted\": 10,\"share\": 70,\"abstentions\": 2}}",
+			expectedStatus: 200,
+		},
+		{
+			title: "results shown after the vote and 403 response",
+			url:   "/api/votes/3/results",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 3, gomock.Any()).Return(int64(-1), errs.ErrResultsAfterVote)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Forbidden\",\"status\": 403,\"detail\": \"the results are shown to the voters who have voted\",\"code\": \"results_after_vote\"}",
+			expectedStatus: 403,
+		},
+		{
+			title: "vote not found and 404 response",
+			url:   "/api/votes/2",
+			mock: func() {
+				voteServ.EXPECT().GetById(gomock.Any(), 2).Return(entity.Vote{}, errs.ErrVoteNotExist)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Not Found\",\"status\": 404,\"detail\": \"the vote doesn't exist\",\"code\": \"vote_not_found\"}",
+			expectedStatus: 404,
+		},
+		{
+			title: "service internal error and 500 response",
+			url:   "/api/votes/2/results",
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 2, gomock.Any()).Return(int64(3), nil)
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 2}, gomock.Any()).Return(entity.Result{}, errors.New("internal service error"))
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Internal Server Error\",\"status\": 500,\"code\": \"internal_error\"}",
+			expectedStatus: 500,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", test.url, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus != 200 {
+				assert.Equal(t, problemContentType, recorder.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestCastBallotHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		inputRequest   string
+		voterId        string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title:        "success ballot and 201 response",
+			inputRequest: `{"choice":"Mew"}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("receipt", nil)
+			},
+			expectedStatus: 201,
+		},
+		{
+			title:        "success scored ballot and 201 response",
+			inputRequest: `{"scores":{"Pikachu":3,"Mew":5}}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew", "Pikachu"}, []int{5, 3}, "ash").Return("receipt", nil)
+			},
+			expectedStatus: 201,
+		},
+		{
+			title:        "score out of the scale and 422 response",
+			inputRequest: `{"scores":{"Mew":11}}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int{11}, "ash").Return("", errs.ErrInvalidScores)
+			},
+			expectedStatus: 422,
+		},
+		{
+			title:        "choice title not found and 404 response",
+			inputRequest: `{"choice":"Mew"}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("", errs.ErrChoiceTitleNotExist)
+			},
+			expectedStatus: 404,
+		},
+		{
+			title:        "repeated ballot and 409 response",
+			inputRequest: `{"choice":"Mew"}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("", errs.ErrAlreadyVoted)
+			},
+			expectedStatus: 409,
+		},
+		{
+			title:        "multiple choices and 201 response",
+			inputRequest: `{"choices":["Mew","Pikachu"]}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew", "Pikachu"}, []int(nil), "ash").Return("receipt", nil)
+			},
+			expectedStatus: 201,
+		},
+		{
+			title:        "too many choices and 422 response",
+			inputRequest: `{"choices":["Mew","Pikachu","Ditto"]}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew", "Pikachu", "Ditto"}, []int(nil), "ash").Return("", errs.ErrSelectionCount)
+			},
+			expectedStatus: 422,
+		},
+		{
+			title:        "voter without a weight in a weighted vote and 403 response",
+			inputRequest: `{"choice":"Mew"}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("", errs.ErrNotEligible)
+			},
+			expectedStatus: 403,
+		},
+		{
+			title:        "missing voter and 401 response",
+			inputRequest: `{"choice":"Mew"}`,
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "").Return("", errs.ErrVoterRequired)
+			},
+			expectedStatus: 401,
+		},
+		{
+			title:          "malformed body and 400 response",
+			inputRequest:   `{"choice":`,
+			mock:           func() {},
+			expectedStatus: 400,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				"POST",
+				"/api/votes/1/ballots",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, test.voterId)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestGetBallotHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title: "voter has voted and 200 response",
+			mock: func() {
+				ballot := entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"Mew"}, CreatedAt: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)}
+				choiceServ.EXPECT().GetBallot(gomock.Any(), 1, "ash").Return(ballot, nil)
+			},
+			want:           `{"vote_id": 1,"voted": true,"choices": ["Mew"],"voted_at": "2022-08-01T00:00:00Z"}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "voter has scored the choices and 200 response",
+			mock: func() {
+				ballot := entity.Ballot{VoteId: 1, VoterId: "ash", Choices: []string{"Mew", "Pikachu"}, Scores: []int{5, 3}, CreatedAt: time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)}
+				choiceServ.EXPECT().GetBallot(gomock.Any(), 1, "ash").Return(ballot, nil)
+			},
+			want:           `{"vote_id": 1,"voted": true,"choices": ["Mew","Pikachu"],"scores": {"Mew": 5,"Pikachu": 3},"voted_at": "2022-08-01T00:00:00Z"}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "voter hasn't voted and 200 response",
+			mock: func() {
+				choiceServ.EXPECT().GetBallot(gomock.Any(), 1, "ash").Return(entity.Ballot{}, errs.ErrBallotNotExist)
+			},
+			want:           `{"vote_id": 1,"voted": false}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "vote not found and 404 response",
+			mock: func() {
+				choiceServ.EXPECT().GetBallot(gomock.Any(), 1, "ash").Return(entity.Ballot{}, errs.ErrVoteNotExist)
+			},
+			expectedStatus: 404,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", "/api/votes/1/ballot", nil)
+			req.Header.Set(voterIdHeader, "ash")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+func TestGetVotersHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title: "voters of public vote and 200 response",
+			mock: func() {
+				voters := []entity.ChoiceVoters{{Choice: "Mew", VoterIds: []string{"ash", "misty"}}, {Choice: "Pikachu", VoterIds: []string{}}}
+				choiceServ.EXPECT().Voters(gomock.Any(), 1, "ash").Return(voters, nil)
+			},
+			want:           `{"vote_id": 1,"choices": [{"choice": "Mew","voters": ["ash","misty"]},{"choice": "Pikachu","voters": []}]}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "voters of private vote and 403 response",
+			mock: func() {
+				choiceServ.EXPECT().Voters(gomock.Any(), 1, "ash").Return(nil, errs.ErrBallotsNotPublic)
+			},
+			want:           `{"type": "about:blank","title": "Forbidden","status": 403,"detail": "the voters of the vote aren't public","code": "ballots_not_public"}`,
+			expectedStatus: 403,
+		},
+		{
+			title: "vote not found and 404 response",
+			mock: func() {
+				choiceServ.EXPECT().Voters(gomock.Any(), 1, "ash").Return(nil, errs.ErrVoteNotExist)
+			},
+			expectedStatus: 404,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", "/api/votes/1/voters", nil)
+			req.Header.Set(voterIdHeader, "ash")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+func TestGetReceiptHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	hash := strings.Repeat("ab", 32)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title: "counted receipt and 200 response",
+			mock: func() {
+				entry := entity.LogEntry{VoteId: 1, Seq: 2, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}, CreatedAt: created, PrevHash: "prev", Hash: hash}
+				choiceServ.EXPECT().Receipt(gomock.Any(), 1, hash).Return(entity.Receipt{Entry: entry}, nil)
+			},
+			want:           `{"vote_id": 1,"receipt": "` + hash + `","included": true,"counted": true,"entry": {"seq": 2,"kind": "cast","weight": 1,"choices": ["Mew"],"created_at": "2026-01-02T03:04:05Z","prev_hash": "prev","hash": "` + hash + `"}}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "replaced receipt and 200 response",
+			mock: func() {
+				entry := entity.LogEntry{VoteId: 1, Seq: 2, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}, CreatedAt: created, PrevHash: "prev", Hash: hash}
+				choiceServ.EXPECT().Receipt(gomock.Any(), 1, hash).Return(entity.Receipt{Entry: entry, ReplacedBy: 5}, nil)
+			},
+			want:           `{"vote_id": 1,"receipt": "` + hash + `","included": true,"counted": false,"replaced_by": 5,"entry": {"seq": 2,"kind": "cast","weight": 1,"choices": ["Mew"],"created_at": "2026-01-02T03:04:05Z","prev_hash": "prev","hash": "` + hash + `"}}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "receipt not in the log and 200 response",
+			mock: func() {
+				choiceServ.EXPECT().Receipt(gomock.Any(), 1, hash).Return(entity.Receipt{}, errs.ErrReceiptNotExist)
+			},
+			want:           `{"vote_id": 1,"receipt": "` + hash + `","included": false,"counted": false}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "malformed receipt and 400 response",
+			mock: func() {
+				choiceServ.EXPECT().Receipt(gomock.Any(), 1, hash).Return(entity.Receipt{}, errs.ErrInvalidReceipt)
+			},
+			want:           `{"type": "about:blank","title": "Bad Request","status": 400,"detail": "a receipt is the hex SHA-256 hash of a log entry","code": "invalid_receipt"}`,
+			expectedStatus: 400,
+		},
+		{
+			title: "vote not found and 404 response",
+			mock: func() {
+				choiceServ.EXPECT().Receipt(gomock.Any(), 1, hash).Return(entity.Receipt{}, errs.ErrVoteNotExist)
+			},
+			expectedStatus: 404,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", "/api/votes/1/receipts/"+hash, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+func TestGetLogHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		query          string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title: "first page and 200 response",
+			query: "?limit=2",
+			mock: func() {
+				page := entity.LogPage{Entries: []entity.LogEntry{
+					{VoteId: 1, Seq: 1, Kind: entity.LogCast, Weight: 1, Choices: []string{"Mew"}, CreatedAt: created, Hash: "a"},
+					{VoteId: 1, Seq: 2, Kind: entity.LogChange, Replaces: 1, Weight: 1, Choices: []string{"Pikachu"}, CreatedAt: created, PrevHash: "a", Hash: "b"},
+				}, Next: 2}
+				choiceServ.EXPECT().Log(gomock.Any(), 1, "ash", int64(0), 2).Return(page, nil)
+			},
+			want:           `{"vote_id": 1,"entries": [{"seq": 1,"kind": "cast","weight": 1,"choices": ["Mew"],"created_at": "2026-01-02T03:04:05Z","prev_hash": "","hash": "a"},{"seq": 2,"kind": "change","replaces": 1,"weight": 1,"choices": ["Pikachu"],"created_at": "2026-01-02T03:04:05Z","prev_hash": "a","hash": "b"}],"next_after": 2}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "last page and 200 response",
+			query: "?after=2",
+			mock: func() {
+				page := entity.LogPage{Entries: []entity.LogEntry{
+					{VoteId: 1, Seq: 3, Kind: entity.LogRetract, Replaces: 2, Choices: []string{}, CreatedAt: created, PrevHash: "b", Hash: "c"},
+				}}
+				choiceServ.EXPECT().Log(gomock.Any(), 1, "ash", int64(2), 0).Return(page, nil)
+			},
+			want:           `{"vote_id": 1,"entries": [{"seq": 3,"kind": "retract","replaces": 2,"choices": [],"created_at": "2026-01-02T03:04:05Z","prev_hash": "b","hash": "c"}]}`,
+			expectedStatus: 200,
+		},
+		{
+			title:          "negative after and 400 response",
+			query:          "?after=-1",
+			mock:           func() {},
+			want:           `{"type": "about:blank","title": "Bad Request","status": 400,"detail": "after must be a sequence number of the log","code": "invalid_after"}`,
+			expectedStatus: 400,
+		},
+		{
+			title:          "malformed limit and 400 response",
+			query:          "?limit=ten",
+			mock:           func() {},
+			expectedStatus: 400,
+		},
+		{
+			title: "hidden results and 403 response",
+			mock: func() {
+				choiceServ.EXPECT().Log(gomock.Any(), 1, "ash", int64(0), 0).Return(entity.LogPage{}, errs.ErrResultsHidden)
+			},
+			expectedStatus: 403,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", "/api/votes/1/log"+test.query, nil)
+			req.Header.Set(voterIdHeader, "ash")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+func TestGetTimelineHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	asOf := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		query          string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title: "timeline as of a past time and 200 response",
+			query: "?bucket=1h&as_of=2022-08-01T12:00:00Z",
+			mock: func() {
+				timeline := entity.Timeline{Bucket: entity.BucketHour, AsOf: asOf, Ballots: 2,
+					Choices: []entity.Choice{{Title: "Mew", Count: 1}, {Title: "Pikachu", Count: 1}},
+					Buckets: []entity.TimelineBucket{
+						{Start: asOf.Add(-2 * time.Hour), Cast: 1, Ballots: 1, Leader: "Mew", Counts: []entity.TimelineCount{{Choice: "Mew", Change: 1, Total: 1}}},
+						{Start: asOf.Add(-time.Hour), Cast: 1, Ballots: 2, Counts: []entity.TimelineCount{{Choice: "Mew", Change: 0, Total: 1}, {Choice: "Pikachu", Change: 1, Total: 1}}},
+					},
+				}
+				choiceServ.EXPECT().Timeline(gomock.Any(), entity.TimelineQuery{VoteId: 1, Bucket: entity.BucketHour, AsOf: asOf}, "ash").Return(timeline, nil)
+			},
+			want:           `{"vote_id": 1,"bucket": "1h","as_of": "2022-08-01T12:00:00Z","ballots": 2,"choices": [{"choice": "Mew","vote_count": 1},{"choice": "Pikachu","vote_count": 1}],"buckets": [{"start": "2022-08-01T10:00:00Z","cast": 1,"ballots": 1,"leader": "Mew","choices": [{"choice": "Mew","votes": 1,"total": 1}]},{"start": "2022-08-01T11:00:00Z","cast": 1,"ballots": 2,"choices": [{"choice": "Mew","votes": 0,"total": 1},{"choice": "Pikachu","votes": 1,"total": 1}]}]}`,
+			expectedStatus: 200,
+		},
+		{
+			title: "unknown bucket and 400 response",
+			query: "?bucket=1w",
+			mock: func() {
+				choiceServ.EXPECT().Timeline(gomock.Any(), entity.TimelineQuery{VoteId: 1, Bucket: "1w"}, "ash").Return(entity.Timeline{}, errs.ErrInvalidBucket)
+			},
+			want:           `{"type": "about:blank","title": "Bad Request","status": 400,"detail": "bucket must be 1m, 1h or 1d","code": "invalid_bucket"}`,
+			expectedStatus: 400,
+		},
+		{
+			title:          "malformed as of and 400 response",
+			query:          "?as_of=yesterday",
+			mock:           func() {},
+			want:           `{"type": "about:blank","title": "Bad Request","status": 400,"detail": "as_of must be an RFC 3339 time","code": "invalid_as_of"}`,
+			expectedStatus: 400,
+		},
+		{
+			title: "hidden results and 403 response",
+			mock: func() {
+				choiceServ.EXPECT().Timeline(gomock.Any(), entity.TimelineQuery{VoteId: 1}, "ash").Return(entity.Timeline{}, errs.ErrResultsHidden)
+			},
+			expectedStatus: 403,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", "/api/votes/1/timeline"+test.query, nil)
+			req.Header.Set(voterIdHeader, "ash")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+func TestUpdateVoteHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		inputRequest   string
+		want           string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title:        "success rename and 200 response",
+			inputRequest: `{"vote":"Best pokemon ever"}`,
+			mock: func() {
+				choiceServ.EXPECT().Edit(gomock.Any(), 1, entity.PollEdit{Title: "Best pokemon ever"}, "oak").Return(nil)
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Best pokemon ever", MinSelections: 1, MaxSelections: 1, Seats: 1, Ballots: 2, Method: entity.MethodPlurality, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways}, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return([]entity.Choice{{Title: "Mew", VoteId: 1, Count: 2}}, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Best pokemon ever\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"visibility\": \"always\",\"ballots\": 2,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 2}]}",
+			expectedStatus: 200,
+		},
+		{
+			title:        "title already exist and 409 response",
+			inputRequest: `{"vote":"Best pokemon ever"}`,
+			mock: func() {
+				choiceServ.EXPECT().Edit(gomock.Any(), 1, entity.PollEdit{Title: "Best pokemon ever"}, "oak").Return(errs.ErrTitleAlreadyExist)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Conflict\",\"status\": 409,\"detail\": \"title adready exist\",\"code\": \"vote_title_already_exists\"}",
+			expectedStatus: 409,
+		},
+		{
+			title:        "voter isn't the owner and 403 response",
+			inputRequest: `{"vote":"Best pokemon ever"}`,
+			mock: func() {
+				choiceServ.EXPECT().Edit(gomock.Any(), 1, entity.PollEdit{Title: "Best pokemon ever"}, "oak").Return(errs.ErrNotOwner)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Forbidden\",\"status\": 403,\"detail\": \"only the owner of the vote can do this\",\"code\": \"not_owner\"}",
+			expectedStatus: 403,
+		},
+		{
+			title:        "choices edited and 200 response",
+			inputRequest: `{"add_choices":["Ditto"],"rename_choices":{"Mew":"Mewtwo"},"remove_choices":["Pikachu"],"force":true}`,
+			mock: func() {
+				edit := entity.PollEdit{Add: []string{"Ditto"}, Rename: map[string]string{"Mew": "Mewtwo"}, Remove: []string{"Pikachu"}, Force: true}
+				choiceServ.EXPECT().Edit(gomock.Any(), 1, edit, "oak").Return(nil)
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Best pokemon", MinSelections: 1, MaxSelections: 1, Seats: 1, Ballots: 2, Method: entity.MethodPlurality, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways}, nil)
+				choices := []entity.Choice{{Title: "Ditto", VoteId: 1}, {Title: "Mewtwo", VoteId: 1, Count: 2}}
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return(choices, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Best pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"visibility\": \"always\",\"ballots\": 2,\"choices\": [{\"choice\": \"Ditto\",\"vote_count\": 0},{\"choice\": \"Mewtwo\",\"vote_count\": 2}]}",
+			expectedStatus: 200,
+		},
+		{
+			title:        "choice with votes removed without force and 409 response",
+			inputRequest: `{"remove_choices":["Mew"]}`,
+			mock: func() {
+				choiceServ.EXPECT().Edit(gomock.Any(), 1, entity.PollEdit{Remove: []string{"Mew"}}, "oak").Return(errs.ErrChoiceHasVotes)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Conflict\",\"status\": 409,\"detail\": \"the choice has votes, force is required to remove it\",\"code\": \"choice_has_votes\"}",
+			expectedStatus: 409,
+		},
+		{
+			title:        "ballot privacy of draft changed and 200 response",
+			inputRequest: `{"ballot_privacy":"public"}`,
+			mock: func() {
+				choiceServ.EXPECT().Edit(gomock.Any(), 1, entity.PollEdit{Privacy: entity.PrivacyPublic}, "oak").Return(nil)
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Best pokemon", MinSelections: 1, MaxSelections: 1, Seats: 1, Method: entity.MethodPlurality, Status: entity.StatusDraft, Visibility: entity.VisibilityAlways, Privacy: entity.PrivacyPublic}, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return([]entity.Choice{{Title: "Mew", VoteId: 1}}, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Best pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"draft\",\"visibility\": \"always\",\"ballot_privacy\": \"public\",\"ballots\": 0,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 0}]}",
+			expectedStatus: 200,
+		},
+		{
+			title:        "ballot privacy of open vote changed and 409 response",
+			inputRequest: `{"ballot_privacy":"anonymous"}`,
+			mock: func() {
+				choiceServ.EXPECT().Edit(gomock.Any(), 1, entity.PollEdit{Privacy: entity.PrivacyAnonymous}, "oak").Return(errs.ErrPrivacyLocked)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Conflict\",\"status\": 409,\"detail\": \"the ballot privacy can't change once the voting starts\",\"code\": \"privacy_locked\"}",
+			expectedStatus: 409,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				"PATCH",
+				"/api/votes/1",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, "oak")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestMergeChoicesHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		inputRequest   string
+		want           string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title:        "write-in merged and 200 response",
+			inputRequest: `{"from":"eevee!","into":"Eevee"}`,
+			mock: func() {
+				choiceServ.EXPECT().MergeChoices(gomock.Any(), 1, "eevee!", "Eevee", "oak").Return(nil)
+				vote := entity.Vote{Id: 1, Title: "Best pokemon", MinSelections: 1, MaxSelections: 1, Seats: 1, Ballots: 9, Method: entity.MethodPlurality, Status: entity.StatusOpen, Visibility: entity.VisibilityAlways, AllowWriteIn: true}
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choices := []entity.Choice{{Title: "Eevee", VoteId: 1, Count: 9, WriteIn: true}, {Title: "Pikachu", VoteId: 1}}
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, "oak").Return(choices, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Best pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"visibility\": \"always\",\"allow_write_in\": true,\"ballots\": 9,\"choices\": [{\"choice\": \"Eevee\",\"vote_count\": 9,\"write_in\": true},{\"choice\": \"Pikachu\",\"vote_count\": 0}]}",
+			expectedStatus: 200,
+		},
+		{
+			title:        "voter isn't the owner and 403 response",
+			inputRequest: `{"from":"eevee!","into":"Eevee"}`,
+			mock: func() {
+				choiceServ.EXPECT().MergeChoices(gomock.Any(), 1, "eevee!", "Eevee", "oak").Return(errs.ErrNotOwner)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Forbidden\",\"status\": 403,\"detail\": \"only the owner of the vote can do this\",\"code\": \"not_owner\"}",
+			expectedStatus: 403,
+		},
+		{
+			title:        "choice isn't a write-in and 422 response",
+			inputRequest: `{"from":"Pikachu","into":"Eevee"}`,
+			mock: func() {
+				choiceServ.EXPECT().MergeChoices(gomock.Any(), 1, "Pikachu", "Eevee", "oak").Return(errs.ErrInvalidMerge)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Unprocessable Entity\",\"status\": 422,\"detail\": \"a write-in choice can only be merged into another choice of the vote\",\"code\": \"invalid_merge\"}",
+			expectedStatus: 422,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				"POST",
+				"/api/votes/1/choices:merge",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, "oak")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestDecideTieHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		inputRequest   string
+		want           string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title:        "tie decided and 200 response",
+			inputRequest: `{"choice":"Mew"}`,
+			mock: func() {
+				choiceServ.EXPECT().DecideTie(gomock.Any(), 1, "Mew", "oak").Return(nil)
+				vote := entity.Vote{Id: 1, Title: "Best pokemon", Ballots: 2, Method: entity.MethodPlurality, TieBreak: entity.TieBreakOwner, TieWinner: "Mew"}
+				choices := []entity.Choice{{Title: "Eevee", VoteId: 1, Count: 1, Rank: 1, Percent: 50}, {Title: "Mew", VoteId: 1, Count: 1, Rank: 1, Percent: 50}}
+				result := entity.Result{Vote: vote, Ballots: 2, Total: 2, Choices: choices, Winner: "Mew", Tied: []string{"Eevee", "Mew"}, TieBreak: entity.TieBreakOwner}
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, "oak").Return(result, nil)
+			},
+			want: "{\"vote_id\": 1,\"method\": \"plurality\",\"ballots\": 2,\"total_votes\": 2,\"winner\": \"Mew\",\"tied\": [\"Eevee\",\"Mew\"],\"tie_break\": \"owner\"," +
+				"\"choices\": [{\"choice\": \"Eevee\",\"vote_count\": 1,\"rank\": 1,\"percent\": 50},{\"choice\": \"Mew\",\"vote_count\": 1,\"rank\": 1,\"percent\": 50}]}",
+			expectedStatus: 200,
+		},
+		{
+			title:        "vote still open and 409 response",
+			inputRequest: `{"choice":"Mew"}`,
+			mock: func() {
+				choiceServ.EXPECT().DecideTie(gomock.Any(), 1, "Mew", "oak").Return(errs.ErrVoteNotClosed)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Conflict\",\"status\": 409,\"detail\": \"the vote isn't closed\",\"code\": \"vote_not_closed\"}",
+			expectedStatus: 409,
+		},
+		{
+			title:        "choice isn't tied and 409 response",
+			inputRequest: `{"choice":"Ditto"}`,
+			mock: func() {
+				choiceServ.EXPECT().DecideTie(gomock.Any(), 1, "Ditto", "oak").Return(errs.ErrNotTied)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Conflict\",\"status\": 409,\"detail\": \"the choice isn't tied for the lead\",\"code\": \"not_tied\"}",
+			expectedStatus: 409,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				"POST",
+				"/api/votes/1/tie:decide",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, "oak")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestListVotesHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	created := time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)
+	next := entity.VoteCursor{Sort: entity.SortByVotes, Desc: true, CreatedAt: created, Total: 2, Id: 1}
+	cursor, _ := encodeCursor(next)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		url            string
+		want           string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title: "list votes and 200 response",
+			url:   "/api/votes?sort=votes&limit=1&prefix=Best",
+			mock: func() {
+				query := entity.VoteQuery{Prefix: "Best", Sort: entity.SortByVotes, Desc: true, Limit: 1}
+				page := entity.VotePage{Votes: []entity.Vote{{Id: 1, Title: "Best pokemon", CreatedAt: created, Total: 2}}, Next: &next}
+				voteServ.EXPECT().List(gomock.Any(), queryAtAnyTime{query}).Return(page, nil)
+			},
+			want:           "{\"votes\": [{\"id\": 1,\"vote\": \"Best pokemon\",\"created_at\": \"2022-08-01T00:00:00Z\",\"total_votes\": 2}],\"next_cursor\": \"" + cursor + "\"}",
+			expectedStatus: 200,
+		},
+		{
+			title: "list votes with hidden results and 200 response",
+			url:   "/api/votes?limit=1",
+			mock: func() {
+				query := entity.VoteQuery{Desc: true, Limit: 1}
+				vote := entity.Vote{Id: 3, Title: "Secret", CreatedAt: created, Status: entity.StatusOpen, Visibility: entity.VisibilityAfterVote}
+				voteServ.EXPECT().List(gomock.Any(), queryAtAnyTime{query}).Return(entity.VotePage{Votes: []entity.Vote{vote}}, nil)
+			},
+			want:           "{\"votes\": [{\"id\": 3,\"vote\": \"Secret\",\"created_at\": \"2022-08-01T00:00:00Z\",\"total_votes\": 0,\"results_hidden\": true}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "list votes past their closes_at and 200 response",
+			url:   "/api/votes?limit=1",
+			mock: func() {
+				query := entity.VoteQuery{Desc: true, Limit: 1}
+				closesAt := created.Add(time.Hour)
+				vote := entity.Vote{Id: 4, Title: "Closed", CreatedAt: created, Total: 5, Status: entity.StatusOpen, ClosesAt: &closesAt, Visibility: entity.VisibilityAfterClose}
+				voteServ.EXPECT().List(gomock.Any(), queryAtAnyTime{query}).Return(entity.VotePage{Votes: []entity.Vote{vote}}, nil)
+			},
+			want:           "{\"votes\": [{\"id\": 4,\"vote\": \"Closed\",\"created_at\": \"2022-08-01T00:00:00Z\",\"total_votes\": 5}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "next page by cursor and 200 response",
+			url:   "/api/votes?sort=votes&limit=1&cursor=" + cursor,
+			mock: func() {
+				query := entity.VoteQuery{Sort: entity.SortByVotes, Desc: true, Limit: 1, After: &next}
+				voteServ.EXPECT().List(gomock.Any(), queryAtAnyTime{query}).Return(entity.VotePage{}, nil)
+			},
+			want:           "{\"votes\": []}",
+			expectedStatus: 200,
+		},
+		{
+			title:          "malformed cursor and 400 response",
+			url:            "/api/votes?cursor=???",
+			mock:           func() {},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Bad Request\",\"status\": 400,\"detail\": \"cursor is invalid\",\"code\": \"invalid_cursor\"}",
+			expectedStatus: 400,
+		},
+		{
+			title:          "malformed limit and 400 response",
+			url:            "/api/votes?limit=ten",
+			mock:           func() {},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Bad Request\",\"status\": 400,\"detail\": \"limit must be a number\",\"code\": \"invalid_limit\"}",
+			expectedStatus: 400,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", test.url, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+// queryAtAnyTime matches the query the handler evaluates at the current time.
+type queryAtAnyTime struct {
+	query entity.VoteQuery
+}
+
+func (q queryAtAnyTime) Matches(x interface{}) bool {
+	query, ok := x.(entity.VoteQuery)
+	if !ok || query.Now.IsZero() {
+		return false
+	}
+	query.Now = time.Time{}
+	return reflect.DeepEqual(query, q.query)
+}
+
+func (q queryAtAnyTime) String() string {
+	return fmt.Sprintf("is equal to %v at any time", q.query)
+}
+
+func clearResponse(s string) string {
+	temp := strings.ReplaceAll(s, "   ", "")
+	return strings.ReplaceAll(temp, "\n", "")
+}
+
+func TestChangeBallotHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		method         string
+		inputRequest   string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title:        "ballot changed and 200 response",
+			method:       "PUT",
+			inputRequest: `{"choice":"Mew"}`,
+			mock: func() {
+				choiceServ.EXPECT().ChangeBallot(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("receipt", nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			title:        "voter hasn't voted and 404 response",
+			method:       "PUT",
+			inputRequest: `{"choice":"Mew"}`,
+			mock: func() {
+				choiceServ.EXPECT().ChangeBallot(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("", errs.ErrBallotNotExist)
+			},
+			expectedStatus: 404,
+		},
+		{
+			title:          "malformed body and 400 response",
+			method:         "PUT",
+			inputRequest:   `{"choice":`,
+			mock:           func() {},
+			expectedStatus: 400,
+		},
+		{
+			title:  "ballot retracted and 204 response",
+			method: "DELETE",
+			mock: func() {
+				choiceServ.EXPECT().RetractBallot(gomock.Any(), 1, "ash").Return(nil)
+			},
+			expectedStatus: 204,
+		},
+		{
+			title:  "nothing to retract and 404 response",
+			method: "DELETE",
+			mock: func() {
+				choiceServ.EXPECT().RetractBallot(gomock.Any(), 1, "ash").Return(errs.ErrBallotNotExist)
+			},
+			expectedStatus: 404,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				test.method,
+				"/api/votes/1/ballot",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, "ash")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestWeightsHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		method         string
+		inputRequest   string
+		voterId        string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title:        "weights replaced and 204 response",
+			method:       "PUT",
+			inputRequest: `{"weights":[{"voter_id":"ash","weight":100},{"voter_id":"misty","weight":20}]}`,
+			mock: func() {
+				weights := []entity.VoterWeight{{VoterId: "ash", Weight: 100}, {VoterId: "misty", Weight: 20}}
+				voteServ.EXPECT().SetWeights(gomock.Any(), 1, weights, "oak").Return(nil)
+			},
+			expectedStatus: 204,
+		},
+		{
+			title:        "voter who isn't the owner and 403 response",
+			method:       "PUT",
+			voterId:      "ash",
+			inputRequest: `{"weights":[{"voter_id":"ash","weight":1000000}]}`,
+			mock: func() {
+				weights := []entity.VoterWeight{{VoterId: "ash", Weight: 1000000}}
+				voteServ.EXPECT().SetWeights(gomock.Any(), 1, weights, "ash").Return(errs.ErrNotOwner)
+			},
+			want:           `{"type": "about:blank","title": "Forbidden","status": 403,"detail": "only the owner of the vote can do this","code": "not_owner"}`,
+			expectedStatus: 403,
+		},
+		{
+			title:        "vote isn't weighted and 409 response",
+			method:       "PUT",
+			inputRequest: `{"weights":[{"voter_id":"ash","weight":100}]}`,
+			mock: func() {
+				voteServ.EXPECT().SetWeights(gomock.Any(), 1, gomock.Any(), "oak").Return(errs.ErrVoteNotWeighted)
+			},
+			expectedStatus: 409,
+		},
+		{
+			title:        "invalid weight and 422 response",
+			method:       "PUT",
+			inputRequest: `{"weights":[{"voter_id":"ash","weight":0}]}`,
+			mock: func() {
+				voteServ.EXPECT().SetWeights(gomock.Any(), 1, gomock.Any(), "oak").Return(errs.ErrInvalidWeights)
+			},
+			expectedStatus: 422,
+		},
+		{
+			title:  "weights listed and 200 response",
+			method: "GET",
+			mock: func() {
+				voteServ.EXPECT().GetWeights(gomock.Any(), 1, "oak").Return([]entity.VoterWeight{{VoterId: "ash", Weight: 100}}, nil)
+			},
+			want:           "{\"vote_id\": 1,\"weights\": [{\"voter_id\": \"ash\",\"weight\": 100}]}",
+			expectedStatus: 200,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				test.method,
+				"/api/votes/1/weights",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			voter := test.voterId
+			if voter == "" {
+				voter = "oak"
+			}
+			req.Header.Set(voterIdHeader, voter)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+func TestCastBallotsHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		inputRequest   string
+		voterId        string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title:        "partially applied batch and 200 response",
+			inputRequest: `{"ballots":[{"vote_id":1,"choice":"Mew"},{"vote":"Pokemon","choice":"Ditto"}]}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateBatch(gomock.Any(), []entity.Ballot{
+					{VoteId: 1, VoterId: "ash", Choices: []string{"Mew"}},
+					{VoteTitle: "Pokemon", VoterId: "ash", Choices: []string{"Ditto"}},
+				}).Return([]entity.BallotResult{{VoteId: 1, Choices: []entity.Choice{{Title: "Mew", VoteId: 1, Count: 7}}}, {VoteId: 1, Err: errs.ErrChoiceTitleNotExist}}, nil)
+			},
+			want: `{"applied": 1,"failed": 1,"results": [` +
+				`{"index": 0,"status": 200,"vote_id": 1,"choices": [{"choice": "Mew","vote_count": 7}]},` +
+				`{"index": 1,"status": 404,"error": {"type": "about:blank","title": "Not Found","status": 404,"detail": "the choice title doesn't exist","code": "choice_not_found"}}]}`,
+			expectedStatus: 200,
+		},
+		{
+			title:        "voter id of the body is ignored and the ballots are cast by the header voter",
+			inputRequest: `{"ballots":[{"vote_id":1,"voter_id":"misty","choice":"Mew"}]}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateBatch(gomock.Any(), []entity.Ballot{
+					{VoteId: 1, VoterId: "ash", Choices: []string{"Mew"}},
+				}).Return([]entity.BallotResult{{VoteId: 1, Choices: []entity.Choice{{Title: "Mew", VoteId: 1, Count: 8}}}}, nil)
+			},
+			want:           `{"applied": 1,"failed": 0,"results": [{"index": 0,"status": 200,"vote_id": 1,"choices": [{"choice": "Mew","vote_count": 8}]}]}`,
+			expectedStatus: 200,
+		},
+		{
+			title:          "missing voter and 401 response",
+			inputRequest:   `{"ballots":[{"vote_id":1,"voter_id":"misty","choice":"Mew"}]}`,
+			mock:           func() {},
+			want:           `{"type": "about:blank","title": "Unauthorized","status": 401,"detail": "voter id is required","code": "voter_required"}`,
+			expectedStatus: 401,
+		},
+		{
+			title:        "empty batch and 400 response",
+			inputRequest: `{"ballots":[]}`,
+			voterId:      "ash",
+			mock: func() {
+				choiceServ.EXPECT().UpdateBatch(gomock.Any(), []entity.Ballot{}).Return(nil, errs.ErrInvalidBatch)
+			},
+			expectedStatus: 400,
+		},
+		{
+			title:          "malformed body and 400 response",
+			inputRequest:   `{"ballots":`,
+			voterId:        "ash",
+			mock:           func() {},
+			expectedStatus: 400,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				"POST",
+				"/api/ballots:batch",
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			if test.voterId != "" {
+				req.Header.Set(voterIdHeader, test.voterId)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
+}
+
+func TestGetResultsETag(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		ifNoneMatch    string
+		mock           mockCall
+		expectedStatus int
+	}{
+		{
+			title:       "matching version and 304 response",
+			ifNoneMatch: `"7"`,
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(7), nil)
+			},
+			expectedStatus: 304,
+		},
+		{
+			title:       "one of the versions matches and 304 response",
+			ifNoneMatch: `"5", W/"7"`,
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(7), nil)
+			},
+			expectedStatus: 304,
+		},
+		{
+			title:       "outdated version and 200 response",
+			ifNoneMatch: `"6"`,
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(7), nil)
+				choiceServ.EXPECT().Get(gomock.Any(), entity.ResultQuery{VoteId: 1}, gomock.Any()).Return(entity.Result{Vote: entity.Vote{Id: 1, Title: "Pokemon", Ballots: 7}, Ballots: 7, Total: 7, Choices: []entity.Choice{{Title: "Mew", VoteId: 1, Count: 7}}}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			title:       "vote not found and 404 response",
+			ifNoneMatch: `"6"`,
+			mock: func() {
+				choiceServ.EXPECT().GetVersionById(gomock.Any(), 1, gomock.Any()).Return(int64(-1), errs.ErrVoteNotExist)
+			},
+			expectedStatus: 404,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest("GET", "/api/votes/1/results", nil)
+			req.Header.Set("If-None-Match", test.ifNoneMatch)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus != 404 {
+				assert.Equal(t, `"7"`, recorder.Header().Get("ETag"))
+			}
+			if test.expectedStatus == 304 {
+				assert.Empty(t, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestLifecycleHandler(t *testing.T) {
+	router := mux.NewRouter()
+	ctrl := gomock.NewController(t)
+	choiceServ := mocks.NewMockChoiceService(ctrl)
+	voteServ := mocks.NewMockVoteService(ctrl)
+	handler := NewVoteHandler(logging.GetLogger("debug"), voteServ, choiceServ)
+	handler.InitRoutes(router)
+	closedAt := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	closesAt := time.Date(2099, 5, 1, 12, 0, 0, 0, time.UTC)
+	type mockCall func()
+	testCases := []struct {
+		title          string
+		url            string
+		inputRequest   string
+		mock           mockCall
+		want           string
+		expectedStatus int
+	}{
+		{
+			title: "vote closed and 200 response with the frozen counts",
+			url:   "/api/votes/1:close",
+			mock: func() {
+				voteServ.EXPECT().Close(gomock.Any(), 1, "ash").Return(nil)
+				vote := entity.Vote{Id: 1, Title: "Pokemon", MinSelections: 1, MaxSelections: 1, Seats: 1, Ballots: 2, Method: entity.MethodPlurality, Status: entity.StatusClosed, ClosedAt: &closedAt, Visibility: entity.VisibilityAlways}
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return([]entity.Choice{{Title: "Mew", VoteId: 1, Count: 2}}, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"closed\",\"closed_at\": \"2022-05-01T12:00:00Z\",\"visibility\": \"always\",\"ballots\": 2,\"choices\": [{\"choice\": \"Mew\",\"vote_count\": 2}]}",
+			expectedStatus: 200,
+		},
+		{
+			title: "vote isn't open and 409 response",
+			url:   "/api/votes/1:close",
+			mock: func() {
+				voteServ.EXPECT().Close(gomock.Any(), 1, "ash").Return(errs.ErrInvalidTransition)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Conflict\",\"status\": 409,\"detail\": \"the vote can't move to this status\",\"code\": \"invalid_status_transition\"}",
+			expectedStatus: 409,
+		},
+		{
+			title: "voter isn't the owner and 403 response",
+			url:   "/api/votes/1:close",
+			mock: func() {
+				voteServ.EXPECT().Close(gomock.Any(), 1, "ash").Return(errs.ErrNotOwner)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Forbidden\",\"status\": 403,\"detail\": \"only the owner of the vote can do this\",\"code\": \"not_owner\"}",
+			expectedStatus: 403,
+		},
+		{
+			title:        "vote reopened until closes_at and 200 response",
+			url:          "/api/votes/1:reopen",
+			inputRequest: `{"closes_at":"2099-05-01T12:00:00Z"}`,
+			mock: func() {
+				voteServ.EXPECT().Reopen(gomock.Any(), 1, &closesAt, "ash").Return(nil)
+				vote := entity.Vote{Id: 1, Title: "Pokemon", MinSelections: 1, MaxSelections: 1, Seats: 1, Method: entity.MethodPlurality, Status: entity.StatusOpen, ClosesAt: &closesAt, Visibility: entity.VisibilityAlways}
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(vote, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return([]entity.Choice{}, nil)
+			},
+			want:           "{\"id\": 1,\"vote\": \"Pokemon\",\"method\": \"plurality\",\"min_selections\": 1,\"max_selections\": 1,\"seats\": 1,\"status\": \"open\",\"closes_at\": \"2099-05-01T12:00:00Z\",\"visibility\": \"always\",\"ballots\": 0,\"choices\": []}",
+			expectedStatus: 200,
+		},
+		{
+			title: "vote reopened without a body and 200 response",
+			url:   "/api/votes/1:reopen",
+			mock: func() {
+				voteServ.EXPECT().Reopen(gomock.Any(), 1, (*time.Time)(nil), "ash").Return(nil)
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Pokemon", Status: entity.StatusOpen}, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return([]entity.Choice{}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			title:          "malformed reopen body and 400 response",
+			url:            "/api/votes/1:reopen",
+			inputRequest:   `{"closes_at":`,
+			mock:           func() {},
+			expectedStatus: 400,
+		},
+		{
+			title: "draft opened and 200 response",
+			url:   "/api/votes/1:open",
+			mock: func() {
+				voteServ.EXPECT().Open(gomock.Any(), 1, "ash").Return(nil)
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Pokemon", Status: entity.StatusOpen}, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return([]entity.Choice{}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			title: "vote archived and 200 response",
+			url:   "/api/votes/1:archive",
+			mock: func() {
+				voteServ.EXPECT().Archive(gomock.Any(), 1, "ash").Return(nil)
+				voteServ.EXPECT().GetById(gomock.Any(), 1).Return(entity.Vote{Id: 1, Title: "Pokemon", Status: entity.StatusArchived}, nil)
+				choiceServ.EXPECT().GetById(gomock.Any(), 1, gomock.Any()).Return([]entity.Choice{}, nil)
+			},
+			expectedStatus: 200,
+		},
+		{
+			title:        "ballot for a closed vote and 409 response",
+			url:          "/api/votes/1/ballots",
+			inputRequest: `{"choice":"Mew"}`,
+			mock: func() {
+				choiceServ.EXPECT().UpdateById(gomock.Any(), 1, []string{"Mew"}, []int(nil), "ash").Return("", errs.ErrVoteClosed)
+			},
+			want:           "{\"type\": \"about:blank\",\"title\": \"Conflict\",\"status\": 409,\"detail\": \"the vote is closed\",\"code\": \"vote_closed\"}",
+			expectedStatus: 409,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.title, func(t *testing.T) {
+			test.mock()
+			req := httptest.NewRequest(
+				"POST",
+				test.url,
+				bytes.NewBufferString(test.inputRequest),
+			)
+			req.Header.Set("Content-type", "application/json")
+			req.Header.Set(voterIdHeader, "ash")
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.want != "" {
+				assert.Equal(t, test.want, clearResponse(recorder.Body.String()))
+			}
+		})
+	}
 }
@@ -0,0 +1,289 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.5
+// source: vote.proto
+
+package vote
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// VoteServiceClient is the client API for VoteService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VoteServiceClient interface {
+	// CreatePoll creates a vote together with all of its choices.
+	CreatePoll(ctx context.Context, in *CreatePollRequest, opts ...grpc.CallOption) (*Poll, error)
+	// GetResults returns the choices of the vote with their counts.
+	GetResults(ctx context.Context, in *GetResultsRequest, opts ...grpc.CallOption) (*Results, error)
+	// CastVote casts the ballot of the voter for the selected choices.
+	CastVote(ctx context.Context, in *CastVoteRequest, opts ...grpc.CallOption) (*CastVoteResponse, error)
+	// DeletePoll deletes the vote and its choices.
+	DeletePoll(ctx context.Context, in *DeletePollRequest, opts ...grpc.CallOption) (*DeletePollResponse, error)
+	// StreamResults sends a snapshot of the results and then the changed
+	// choices and heartbeats until the client cancels the call.
+	StreamResults(ctx context.Context, in *GetResultsRequest, opts ...grpc.CallOption) (VoteService_StreamResultsClient, error)
+}
+
+type voteServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVoteServiceClient(cc grpc.ClientConnInterface) VoteServiceClient {
+	return &voteServiceClient{cc}
+}
+
+func (c *voteServiceClient) CreatePoll(ctx context.Context, in *CreatePollRequest, opts ...grpc.CallOption) (*Poll, error) {
+	out := new(Poll)
+	err := c.cc.Invoke(ctx, "/vote.v1.VoteService/CreatePoll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voteServiceClient) GetResults(ctx context.Context, in *GetResultsRequest, opts ...grpc.CallOption) (*Results, error) {
+	out := new(Results)
+	err := c.cc.Invoke(ctx, "/vote.v1.VoteService/GetResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voteServiceClient) CastVote(ctx context.Context, in *CastVoteRequest, opts ...grpc.CallOption) (*CastVoteResponse, error) {
+	out := new(CastVoteResponse)
+	err := c.cc.Invoke(ctx, "/vote.v1.VoteService/CastVote", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voteServiceClient) DeletePoll(ctx context.Context, in *DeletePollRequest, opts ...grpc.CallOption) (*DeletePollResponse, error) {
+	out := new(DeletePollResponse)
+	err := c.cc.Invoke(ctx, "/vote.v1.VoteService/DeletePoll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *voteServiceClient) StreamResults(ctx context.Context, in *GetResultsRequest, opts ...grpc.CallOption) (VoteService_StreamResultsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VoteService_ServiceDesc.Streams[0], "/vote.v1.VoteService/StreamResults", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &voteServiceStreamResultsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VoteService_StreamResultsClient interface {
+	Recv() (*ResultEvent, error)
+	grpc.ClientStream
+}
+
+type voteServiceStreamResultsClient struct {
+	grpc.ClientStream
+}
+
+func (x *voteServiceStreamResultsClient) Recv() (*ResultEvent, error) {
+	m := new(ResultEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VoteServiceServer is the server API for VoteService service.
+// All implementations must embed UnimplementedVoteServiceServer
+// for forward compatibility
+type VoteServiceServer interface {
+	// CreatePoll creates a vote together with all of its choices.
+	CreatePoll(context.Context, *CreatePollRequest) (*Poll, error)
+	// GetResults returns the choices of the vote with their counts.
+	GetResults(context.Context, *GetResultsRequest) (*Results, error)
+	// CastVote casts the ballot of the voter for the selected choices.
+	CastVote(context.Context, *CastVoteRequest) (*CastVoteResponse, error)
+	// DeletePoll deletes the vote and its choices.
+	DeletePoll(context.Context, *DeletePollRequest) (*DeletePollResponse, error)
+	// StreamResults sends a snapshot of the results and then the changed
+	// choices and heartbeats until the client cancels the call.
+	StreamResults(*GetResultsRequest, VoteService_StreamResultsServer) error
+	mustEmbedUnimplementedVoteServiceServer()
+}
+
+// UnimplementedVoteServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedVoteServiceServer struct {
+}
+
+func (UnimplementedVoteServiceServer) CreatePoll(context.Context, *CreatePollRequest) (*Poll, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePoll not implemented")
+}
+func (UnimplementedVoteServiceServer) GetResults(context.Context, *GetResultsRequest) (*Results, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetResults not implemented")
+}
+func (UnimplementedVoteServiceServer) CastVote(context.Context, *CastVoteRequest) (*CastVoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CastVote not implemented")
+}
+func (UnimplementedVoteServiceServer) DeletePoll(context.Context, *DeletePollRequest) (*DeletePollResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePoll not implemented")
+}
+func (UnimplementedVoteServiceServer) StreamResults(*GetResultsRequest, VoteService_StreamResultsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamResults not implemented")
+}
+func (UnimplementedVoteServiceServer) mustEmbedUnimplementedVoteServiceServer() {}
+
+// UnsafeVoteServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VoteServiceServer will
+// result in compilation errors.
+type UnsafeVoteServiceServer interface {
+	mustEmbedUnimplementedVoteServiceServer()
+}
+
+func RegisterVoteServiceServer(s grpc.ServiceRegistrar, srv VoteServiceServer) {
+	s.RegisterService(&VoteService_ServiceDesc, srv)
+}
+
+func _VoteService_CreatePoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoteServiceServer).CreatePoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vote.v1.VoteService/CreatePoll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoteServiceServer).CreatePoll(ctx, req.(*CreatePollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoteService_GetResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoteServiceServer).GetResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vote.v1.VoteService/GetResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoteServiceServer).GetResults(ctx, req.(*GetResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoteService_CastVote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CastVoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoteServiceServer).CastVote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vote.v1.VoteService/CastVote",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoteServiceServer).CastVote(ctx, req.(*CastVoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoteService_DeletePoll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeletePollRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VoteServiceServer).DeletePoll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/vote.v1.VoteService/DeletePoll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VoteServiceServer).DeletePoll(ctx, req.(*DeletePollRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VoteService_StreamResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VoteServiceServer).StreamResults(m, &voteServiceStreamResultsServer{stream})
+}
+
+type VoteService_StreamResultsServer interface {
+	Send(*ResultEvent) error
+	grpc.ServerStream
+}
+
+type voteServiceStreamResultsServer struct {
+	grpc.ServerStream
+}
+
+func (x *voteServiceStreamResultsServer) Send(m *ResultEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// VoteService_ServiceDesc is the grpc.ServiceDesc for VoteService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VoteService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vote.v1.VoteService",
+	HandlerType: (*VoteServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePoll",
+			Handler:    _VoteService_CreatePoll_Handler,
+		},
+		{
+			MethodName: "GetResults",
+			Handler:    _VoteService_GetResults_Handler,
+		},
+		{
+			MethodName: "CastVote",
+			Handler:    _VoteService_CastVote_Handler,
+		},
+		{
+			MethodName: "DeletePoll",
+			Handler:    _VoteService_DeletePoll_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamResults",
+			Handler:       _VoteService_StreamResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "vote.proto",
+}
@@ -17,6 +17,16 @@ func ParsePgError(err error) error {
 	return err
 }
 
+// uniqueViolation is the sqlstate of a violated unique constraint.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err violates the unique constraint with
+// the name.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == constraint
+}
+
 func ErrCreateQuery(err error) error {
 	return fmt.Errorf("failed to provide query due to %v", err)
 }
@@ -1,10 +1,124 @@
 CREATE TABLE vote(
     vote_id SERIAL PRIMARY KEY,
-    vote_title VARCHAR(200) NOT NULL
+    vote_title VARCHAR(200) NOT NULL CONSTRAINT vote_title_key UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    version BIGINT NOT NULL DEFAULT 0,
+    min_selections INT NOT NULL DEFAULT 1,
+    max_selections INT NOT NULL DEFAULT 1,
+    ballots INT NOT NULL DEFAULT 0,
+    method VARCHAR(20) NOT NULL DEFAULT 'plurality',
+    seats INT NOT NULL DEFAULT 1,
+    surplus_transfer VARCHAR(20) NOT NULL DEFAULT '',
+    min_score INT NOT NULL DEFAULT 0,
+    max_score INT NOT NULL DEFAULT 0,
+    weighted BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    opens_at TIMESTAMPTZ,
+    closes_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    -- final_ballots and the final counts of the choices are frozen when
+    -- the vote is closed and cleared when it is reopened
+    final_ballots INT,
+    visibility VARCHAR(20) NOT NULL DEFAULT 'always',
+    owner_id VARCHAR(200) NOT NULL DEFAULT '',
+    allow_write_in BOOLEAN NOT NULL DEFAULT false,
+    -- the quorum and threshold rules, threshold is empty for the polls
+    -- without an outcome
+    quorum INT NOT NULL DEFAULT 0,
+    quorum_percent INT NOT NULL DEFAULT 0,
+    threshold VARCHAR(20) NOT NULL DEFAULT '',
+    threshold_percent INT NOT NULL DEFAULT 0,
+    abstention VARCHAR(200) NOT NULL DEFAULT '',
+    count_abstentions BOOLEAN NOT NULL DEFAULT false,
+    -- tie_seed picks the winner of a random tie-break, tie_winner is
+    -- the decision of the owner and is cleared when the vote is reopened
+    tie_break VARCHAR(20) NOT NULL DEFAULT 'none',
+    tie_seed BIGINT NOT NULL DEFAULT 0,
+    tie_winner VARCHAR(200) NOT NULL DEFAULT '',
+    -- the ballots of an anonymous vote store a keyed hash of the voter
+    -- in voter_id
+    ballot_privacy VARCHAR(20) NOT NULL DEFAULT 'private',
+    CHECK (status IN ('draft','open','closed','archived')),
+    CHECK (visibility IN ('always','after_vote','after_close')),
+    CHECK (closes_at > opens_at),
+    CHECK (min_selections >= 1 AND max_selections >= min_selections),
+    CHECK (seats >= 1),
+    CHECK (min_score >= 0 AND max_score >= min_score),
+    CHECK (threshold IN ('','plurality','majority','two_thirds','percent')),
+    CHECK (tie_break IN ('none','earliest','random','owner')),
+    CHECK (ballot_privacy IN ('private','public','anonymous')),
+    CHECK (ballot_privacy <> 'anonymous' OR NOT weighted),
+    CHECK (quorum >= 0 AND quorum_percent BETWEEN 0 AND 100 AND threshold_percent BETWEEN 0 AND 100)
 );
+CREATE INDEX vote_created_at_idx ON vote(created_at, vote_id);
+-- the scheduler looks up the drafts to open and the votes to close
+CREATE INDEX vote_opens_at_idx ON vote(opens_at) WHERE status = 'draft';
+CREATE INDEX vote_closes_at_idx ON vote(closes_at) WHERE status = 'open';
 CREATE TABLE choice(
     choice_title VARCHAR(200),
     count int,
     vote_id INT REFERENCES vote(vote_id) ON DELETE CASCADE,
+    voters INT NOT NULL DEFAULT 0,
+    final_count INT,
+    final_voters INT,
+    -- write_in is set for the choices added by the ballots
+    write_in BOOLEAN NOT NULL DEFAULT false,
     PRIMARY KEY(choice_title,vote_id)
-);
\ No newline at end of file
+);
+CREATE TABLE ballot(
+    vote_id INT NOT NULL REFERENCES vote(vote_id) ON DELETE CASCADE,
+    voter_id VARCHAR(200) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    weight INT NOT NULL DEFAULT 1,
+    -- log_seq is the seq of the ballot_log entry of the current selection
+    log_seq BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY(vote_id,voter_id)
+);
+-- voter_weight lists the eligible voters of a weighted vote, a ballot keeps
+-- the weight it was cast with
+CREATE TABLE voter_weight(
+    vote_id INT NOT NULL REFERENCES vote(vote_id) ON DELETE CASCADE,
+    voter_id VARCHAR(200) NOT NULL,
+    weight INT NOT NULL,
+    PRIMARY KEY(vote_id,voter_id),
+    CHECK (weight > 0)
+);
+CREATE TABLE ballot_choice(
+    vote_id INT NOT NULL,
+    voter_id VARCHAR(200) NOT NULL,
+    choice_title VARCHAR(200) NOT NULL,
+    rank INT NOT NULL DEFAULT 1,
+    score INT,
+    PRIMARY KEY(vote_id,voter_id,choice_title),
+    FOREIGN KEY(vote_id,voter_id) REFERENCES ballot(vote_id,voter_id) ON DELETE CASCADE,
+    FOREIGN KEY(choice_title,vote_id) REFERENCES choice(choice_title,vote_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+-- choice_score is the score histogram of every choice of a score vote,
+-- it is kept up to date with the ballots so the results don't scan them
+CREATE TABLE choice_score(
+    vote_id INT NOT NULL,
+    choice_title VARCHAR(200) NOT NULL,
+    score INT NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    PRIMARY KEY(vote_id,choice_title,score),
+    FOREIGN KEY(choice_title,vote_id) REFERENCES choice(choice_title,vote_id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+-- ballot_log is the append-only log of the ballots of every vote, an entry
+-- holds the hash of the entry before it, so the log can't be changed without
+-- breaking the chain. The entries are appended while the vote row is locked.
+CREATE TABLE ballot_log(
+    vote_id INT NOT NULL REFERENCES vote(vote_id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    replaces BIGINT NOT NULL DEFAULT 0,
+    weight INT NOT NULL DEFAULT 0,
+    choices VARCHAR(200)[] NOT NULL DEFAULT '{}',
+    scores INT[],
+    created_at TIMESTAMPTZ NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    PRIMARY KEY(vote_id,seq),
+    UNIQUE(vote_id,hash),
+    CHECK (kind IN ('cast','change','retract','rename','remove','merge'))
+);
+CREATE INDEX ballot_log_replaces_idx ON ballot_log(vote_id,replaces) WHERE replaces > 0;